@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/amirzayi/clean_architect/api/proto/grouppb"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+)
+
+type groupService struct {
+	grouppb.UnimplementedGroupServiceServer
+	group       service.Group
+	authManager auth.Manager
+}
+
+func NewGroupGrpcService(group service.Group, authManager auth.Manager) *groupService {
+	return &groupService{group: group, authManager: authManager}
+}
+
+func (h *groupService) CreateGroup(ctx context.Context, req *grouppb.CreateGroupRequest) (*grouppb.Group, error) {
+	ctx, err := authorizeAdmin(ctx, h.authManager, h.group)
+	if err != nil {
+		return nil, err
+	}
+	group := domain.Group{Name: req.GetName()}
+	for _, role := range req.GetRoles() {
+		group.Roles = append(group.Roles, domain.UserRole(role))
+	}
+
+	group, err = h.group.Create(ctx, group)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return groupToProto(group), nil
+}
+
+func (h *groupService) AddMember(ctx context.Context, req *grouppb.AddMemberRequest) (*grouppb.GroupMember, error) {
+	ctx, err := authorizeAdmin(ctx, h.authManager, h.group)
+	if err != nil {
+		return nil, err
+	}
+	member, err := parseGroupMember(req.GetGroupId(), req.GetKind(), req.GetMemberId())
+	if err != nil {
+		return nil, err
+	}
+
+	member, err = h.group.AddMember(ctx, member)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &grouppb.GroupMember{
+		GroupId:   member.GroupID.String(),
+		Kind:      string(member.Kind),
+		MemberId:  member.MemberID.String(),
+		CreatedAt: timestamppb.New(member.CreatedAt),
+	}, nil
+}
+
+func (h *groupService) RemoveMember(ctx context.Context, req *grouppb.RemoveMemberRequest) (*emptypb.Empty, error) {
+	ctx, err := authorizeAdmin(ctx, h.authManager, h.group)
+	if err != nil {
+		return nil, err
+	}
+	member, err := parseGroupMember(req.GetGroupId(), req.GetKind(), req.GetMemberId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.group.RemoveMember(ctx, member.GroupID, member.Kind, member.MemberID); err != nil {
+		return nil, grpcError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (h *groupService) ListUserGroups(ctx context.Context, req *grouppb.ListUserGroupsRequest) (*grouppb.ListUserGroupsResponse, error) {
+	ctx, err := authorizeAdmin(ctx, h.authManager, h.group)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user_id: %v", err)
+	}
+
+	groups, err := h.group.UserGroups(ctx, userID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &grouppb.ListUserGroupsResponse{Groups: make([]*grouppb.Group, 0, len(groups))}
+	for _, group := range groups {
+		resp.Groups = append(resp.Groups, groupToProto(group))
+	}
+	return resp, nil
+}
+
+// parseGroupMember parses ids of group member, kind is validated by service.
+func parseGroupMember(groupID, kind, memberID string) (domain.GroupMember, error) {
+	groupUUID, err := uuid.Parse(groupID)
+	if err != nil {
+		return domain.GroupMember{}, status.Errorf(codes.InvalidArgument, "invalid group_id: %v", err)
+	}
+	memberUUID, err := uuid.Parse(memberID)
+	if err != nil {
+		return domain.GroupMember{}, status.Errorf(codes.InvalidArgument, "invalid member_id: %v", err)
+	}
+	return domain.GroupMember{GroupID: groupUUID, Kind: domain.GroupMemberKind(kind), MemberID: memberUUID}, nil
+}
+
+func groupToProto(g domain.Group) *grouppb.Group {
+	roles := make([]string, 0, len(g.Roles))
+	for _, role := range g.Roles {
+		roles = append(roles, string(role))
+	}
+	return &grouppb.Group{
+		Id:        g.ID.String(),
+		Name:      g.Name,
+		Roles:     roles,
+		CreatedAt: timestamppb.New(g.CreatedAt),
+		UpdatedAt: timestamppb.New(g.UpdatedAt),
+	}
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/search"
+)
+
+// defaultPageSize is page size of list requests without one, same as per_page of http lists.
+const defaultPageSize = 10
+
+// listPagination returns pagination of a list request, pages are listed by cursors, so page tokens are cursors
+// of http lists, e.g. next_page_token is next_cursor. order_by is comma separated fields optionally followed by
+// asc or desc, e.g. "name, created_at desc". lists searched by query without order_by are ranked most relevant
+// first, which sql and mongodb could not page by cursor, so they are paged by offset and page tokens are page numbers.
+func listPagination(pageSize int32, pageToken, orderBy, query string) (*paginate.Pagination, error) {
+	if pageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	pagination := &paginate.Pagination{PerPage: int(pageSize), Search: query}
+	if strings.TrimSpace(orderBy) == "" && len(search.Terms(query)) > 0 {
+		page := 1
+		if pageToken != "" {
+			var err error
+			if page, err = strconv.Atoi(pageToken); err != nil || page < 2 {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid page_token %q", pageToken)
+			}
+		}
+		// totals are not responded over grpc
+		pagination.Page, pagination.Count = page, paginate.CountNone
+		return pagination, nil
+	}
+	pagination.Cursor = &paginate.Cursor{Token: pageToken}
+	if strings.TrimSpace(orderBy) == "" {
+		return pagination, nil
+	}
+	for _, order := range strings.Split(orderBy, ",") {
+		parts := strings.Fields(order)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid order_by %q", order)
+		}
+		sort := paginate.Sort{Field: parts[0], Arrange: paginate.SortOrderAscending}
+		if len(parts) == 2 {
+			sort.Arrange = strings.ToLower(parts[1])
+		}
+		// invalid arranges are rejected by listing same as fields
+		pagination.Sort = append(pagination.Sort, sort)
+	}
+	return pagination, nil
+}
+
+// nextPageToken returns page token of the page after pagination listed by listPagination, empty for the last page.
+func nextPageToken(pagination *paginate.Pagination) string {
+	if pagination.Cursor != nil {
+		return pagination.NextCursor
+	}
+	if !pagination.HasMore {
+		return ""
+	}
+	return strconv.Itoa(pagination.Page + 1)
+}
+
+// readMaskFields returns fields projected by listing for read mask of messages m, the first fields of its paths,
+// which are the fields parameter of http lists. paths which are not fields of m are rejected, empty masks project
+// no fields, so listed rows are whole.
+func readMaskFields(mask *fieldmaskpb.FieldMask, m proto.Message) ([]string, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+	if !mask.IsValid(m) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid read_mask %q", strings.Join(mask.GetPaths(), ","))
+	}
+	var fields []string
+	for _, path := range mask.GetPaths() {
+		if field := paginate.ProjectionRoot(path); !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// applyReadMask clears fields of m out of read mask, so responses have no zero values of fields which are not read,
+// paths into messages keep only their own fields. m is kept whole by empty masks.
+func applyReadMask(mask *fieldmaskpb.FieldMask, m proto.Message) {
+	if len(mask.GetPaths()) == 0 {
+		return
+	}
+	keepPaths(m.ProtoReflect(), mask.GetPaths())
+}
+
+func keepPaths(m protoreflect.Message, paths []string) {
+	whole := make(map[string]bool)
+	nested := make(map[string][]string)
+	for _, path := range paths {
+		field, rest, ok := strings.Cut(path, ".")
+		if ok {
+			nested[field] = append(nested[field], rest)
+		} else {
+			whole[field] = true
+		}
+	}
+	var cleared []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		switch {
+		case whole[name]:
+		case nested[name] != nil && fd.Message() != nil && !fd.IsList() && !fd.IsMap():
+			keepPaths(v.Message(), nested[name])
+		default:
+			cleared = append(cleared, fd)
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
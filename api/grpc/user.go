@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/amirzayi/clean_architect/api/proto/userpb"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/requestinfo"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+type userService struct {
+	userpb.UnimplementedUserServiceServer
+	user        service.User
+	group       service.Group
+	authManager auth.Manager
+}
+
+func NewUserGrpcService(user service.User, group service.Group, authManager auth.Manager) *userService {
+	return &userService{user: user, group: group, authManager: authManager}
+}
+
+// ListUsers lists users by cursor pages, or ranked searches by offset pages, see listPagination. users have only fields of read mask if it is given.
+func (h *userService) ListUsers(ctx context.Context, req *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	ctx, err := authorizeAdmin(ctx, h.authManager, h.group)
+	if err != nil {
+		return nil, err
+	}
+	pagination, err := listPagination(req.GetPageSize(), req.GetPageToken(), req.GetOrderBy(), req.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	pagination.Expression = req.GetFilter()
+	if pagination.Fields, err = readMaskFields(req.GetReadMask(), &userpb.User{}); err != nil {
+		return nil, err
+	}
+
+	users, err := h.user.List(ctx, pagination)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &userpb.ListUsersResponse{Users: make([]*userpb.User, 0, len(users)), NextPageToken: nextPageToken(pagination)}
+	for _, user := range users {
+		pbUser := userToProto(user)
+		applyReadMask(req.GetReadMask(), pbUser)
+		resp.Users = append(resp.Users, pbUser)
+	}
+	return resp, nil
+}
+
+func (h *userService) Ban(ctx context.Context, req *userpb.ChangeStatusRequest) (*userpb.User, error) {
+	return h.changeStatus(ctx, req, h.user.Ban)
+}
+
+func (h *userService) Unban(ctx context.Context, req *userpb.ChangeStatusRequest) (*userpb.User, error) {
+	return h.changeStatus(ctx, req, h.user.Unban)
+}
+
+func (h *userService) Activate(ctx context.Context, req *userpb.ChangeStatusRequest) (*userpb.User, error) {
+	return h.changeStatus(ctx, req, h.user.Activate)
+}
+
+func (h *userService) Deactivate(ctx context.Context, req *userpb.ChangeStatusRequest) (*userpb.User, error) {
+	return h.changeStatus(ctx, req, h.user.Deactivate)
+}
+
+func (h *userService) changeStatus(ctx context.Context, req *userpb.ChangeStatusRequest,
+	operation func(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error)) (*userpb.User, error) {
+	ctx, err := authorizeAdmin(ctx, h.authManager, h.group)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid id: %v", err)
+	}
+
+	user, err := operation(ctx, id, req.GetReason(), req.GetVersion())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return userToProto(user), nil
+}
+
+// authorizeAdmin verifies bearer token of authorization metadata and returns ctx carrying its claims and tenant,
+// roles granted to user by its groups are resolved by groups same as http requests.
+func authorizeAdmin(ctx context.Context, authManager auth.Manager, groups service.Group) (context.Context, error) {
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	claims, err := authManager.VerifyToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	ctx = tenant.ContextWithID(ctx, claims.TenantID)
+	roles, err := groups.Roles(ctx, claims.UserID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	for _, role := range roles {
+		claims.Roles = append(claims.Roles, string(role))
+	}
+	if !claims.HasRole(string(domain.UserRoleAdmin)) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+	return auth.ContextWithClaims(withRequestInfo(ctx), claims), nil
+}
+
+// withRequestInfo returns ctx carrying client address and x-request-id metadata,
+// client of requests proxied by grpc gateway is taken from x-forwarded-for metadata.
+func withRequestInfo(ctx context.Context) context.Context {
+	var info requestinfo.Info
+	if values := metadata.ValueFromIncomingContext(ctx, "x-forwarded-for"); len(values) > 0 {
+		info.IP = strings.TrimSpace(strings.Split(values[0], ",")[0])
+	} else if p, ok := peer.FromContext(ctx); ok {
+		info.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(info.IP); err == nil {
+			info.IP = host
+		}
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, "x-request-id"); len(values) > 0 {
+		info.RequestID = values[0]
+	}
+	return requestinfo.ContextWithInfo(ctx, info)
+}
+
+func grpcError(err error) error {
+	var appErr *errs.Error
+	if errors.As(err, &appErr) {
+		return status.Error(appErr.Code.GRPCStatus(), appErr.Msg)
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+func userToProto(u domain.User) *userpb.User {
+	return &userpb.User{
+		Id:          u.ID.String(),
+		Name:        u.Name,
+		PhoneNumber: u.PhoneNumber,
+		Email:       u.Email,
+		Status:      u.Status.String(),
+		Role:        string(u.Role),
+		CreatedAt:   timestamppb.New(u.CreatedAt),
+		UpdatedAt:   timestamppb.New(u.UpdatedAt),
+		Version:     u.Version,
+	}
+}
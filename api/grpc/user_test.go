@@ -0,0 +1,98 @@
+package grpc_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	_ "modernc.org/sqlite"
+
+	"github.com/amirzayi/clean_architect/api/grpc"
+	"github.com/amirzayi/clean_architect/api/proto/userpb"
+	"github.com/amirzayi/clean_architect/infra/migrations"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/bus"
+	"github.com/amirzayi/clean_architect/pkg/cache"
+	"github.com/amirzayi/clean_architect/pkg/hash"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+func TestListUsersSearch(t *testing.T) {
+	// same driver as the app, which ranks searches by bm25
+	db, err := sqlx.Open("sqlite", "file::memory:?_time_format=sqlite")
+	require.NoError(t, err)
+	// every connection of an in-memory database is a new database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	migrator, err := migrations.New(db.DB, "sqlite")
+	require.NoError(t, err)
+	if err = migrator.Up(); err != migrate.ErrNoChange {
+		require.NoError(t, err)
+	}
+
+	repos := repository.NewSQLRepositories(db)
+	authManager := auth.NewJWT(jwt.SigningMethodHS512, []byte("testing_key"), time.Hour)
+	services := service.NewServices(&service.Dependencies{
+		Repositories: repos,
+		Hasher:       hash.NewBcryptHasher(bcrypt.MinCost),
+		AuthManager:  authManager,
+		Cache:        cache.NewInMemoryDriver(),
+		Event:        bus.NewInMemoryDriver([]string{}),
+		Logger:       slog.Default(),
+	})
+	users := grpc.NewUserGrpcService(services.User, services.Group, authManager)
+
+	token, err := authManager.CreateToken(uuid.New(), string(domain.UserRoleAdmin), tenant.Default)
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+
+	// the most relevant user is created last, so it is not the first of unranked lists
+	for _, name := range []string{"Sara Zayi", "Sara Amiri", "Ali", "Sara Sara"} {
+		require.NoError(t, repos.User.Create(context.Background(), repotest.NewUser(name)))
+	}
+
+	resp, err := users.ListUsers(ctx, &userpb.ListUsersRequest{PageSize: 2, Query: "sara"})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsers(), 2)
+	require.Equal(t, "Sara Sara", resp.GetUsers()[0].GetName(), "searches are ranked most relevant first")
+	require.Equal(t, "2", resp.GetNextPageToken())
+	names := []string{resp.GetUsers()[0].GetName(), resp.GetUsers()[1].GetName()}
+
+	resp, err = users.ListUsers(ctx, &userpb.ListUsersRequest{PageSize: 2, Query: "sara", PageToken: resp.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsers(), 1)
+	require.Empty(t, resp.GetNextPageToken())
+	names = append(names, resp.GetUsers()[0].GetName())
+	require.ElementsMatch(t, []string{"Sara Sara", "Sara Zayi", "Sara Amiri"}, names)
+
+	_, err = users.ListUsers(ctx, &userpb.ListUsersRequest{Query: "sara", PageToken: "x"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// searches sorted by order_by are paged by cursors
+	resp, err = users.ListUsers(ctx, &userpb.ListUsersRequest{PageSize: 2, Query: "sara", OrderBy: "name desc"})
+	require.NoError(t, err)
+	require.Equal(t, "Sara Zayi", resp.GetUsers()[0].GetName())
+	require.Equal(t, "Sara Sara", resp.GetUsers()[1].GetName())
+	require.Contains(t, resp.GetNextPageToken(), ".")
+
+	resp, err = users.ListUsers(ctx, &userpb.ListUsersRequest{PageSize: 2, Query: "sara", OrderBy: "name desc",
+		PageToken: resp.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsers(), 1)
+	require.Equal(t, "Sara Amiri", resp.GetUsers()[0].GetName())
+	require.Empty(t, resp.GetNextPageToken())
+}
@@ -0,0 +1,83 @@
+package handler_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+func TestAuditLogV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/v2/users/"+user.ID.String()+"/role", strings.NewReader(`{"role":"Admin"}`))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	req.Header.Set("X-Request-Id", "role-request")
+	req.Header.Set("If-Match", `"1"`)
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec = get("/v2/audit-logs?target_id=" + user.ID.String())
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []dto.AuditEntryResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 2)
+
+	roleChange, create := list.Data[0], list.Data[1]
+	require.Equal(t, string(domain.AuditActionUserRoleChange), roleChange.Action)
+	require.Equal(t, string(domain.UserRoleAdmin), roleChange.ActorRole)
+	require.NotNil(t, roleChange.ActorID)
+	require.Equal(t, "192.0.2.1", roleChange.IP)
+	require.Equal(t, "role-request", roleChange.RequestID)
+	require.Equal(t, []domain.AuditChange{{Field: "role", Before: string(domain.UserRoleNormal), After: string(domain.UserRoleAdmin)}}, roleChange.Changes)
+	require.Equal(t, create.Hash, roleChange.PrevHash)
+
+	require.Equal(t, string(domain.AuditActionUserCreate), create.Action)
+	require.Contains(t, create.Changes, domain.AuditChange{Field: "password", After: domain.AuditRedacted})
+	// personal values are pseudonymized, role is kept as it is
+	for _, change := range create.Changes {
+		switch change.Field {
+		case "name", "email", "phone_number":
+			require.IsType(t, "", change.After)
+			require.True(t, strings.HasPrefix(change.After.(string), domain.AuditPseudonymPrefix), change.Field)
+		case "role":
+			require.Equal(t, string(domain.UserRoleNormal), change.After)
+		}
+	}
+	require.NotContains(t, rec.Body.String(), user.Email)
+
+	rec = get("/v2/audit-logs/export?target_id=" + user.ID.String())
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	var exported []dto.AuditEntryResponse
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var entry dto.AuditEntryResponse
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		exported = append(exported, entry)
+	}
+	require.Len(t, exported, 2)
+	require.Equal(t, create.ID, exported[0].ID, "oldest first")
+
+	rec = get("/v2/audit-logs/verify")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"valid":true}`, rec.Body.String())
+}
@@ -0,0 +1,139 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+)
+
+func TestAvatarsV2(t *testing.T) {
+	do := func(method, path, token, contentType string, body io.Reader) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, body)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	encode := func(format string, width, height int) []byte {
+		var buf bytes.Buffer
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		if format == "jpeg" {
+			require.NoError(t, jpeg.Encode(&buf, img, nil))
+		} else {
+			require.NoError(t, png.Encode(&buf, img))
+		}
+		return buf.Bytes()
+	}
+	decodeURLs := func(rec *httptest.ResponseRecorder) dto.AvatarResponse {
+		var urls dto.AvatarResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &urls))
+		return urls
+	}
+
+	user := testCreateUserV2(t)
+	path := "/v2/users/" + user.ID.String() + "/avatar"
+
+	require.Equal(t, http.StatusNotFound, do(http.MethodGet, path, userToken, "", nil).Code)
+
+	original := encode("png", 300, 200)
+	rec := do(http.MethodPut, path, adminToken, "application/octet-stream", bytes.NewReader(original))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	urls := decodeURLs(rec)
+	require.NotEmpty(t, urls.OriginalURL)
+	require.False(t, urls.ExpiresAt.IsZero())
+
+	t.Run("download", func(t *testing.T) {
+		rec := do(http.MethodGet, urls.OriginalURL, "", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+		require.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+		require.Equal(t, original, rec.Body.Bytes())
+
+		rec = do(http.MethodGet, urls.ThumbnailURL, "", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		thumbnail, format, err := image.DecodeConfig(rec.Body)
+		require.NoError(t, err)
+		require.Equal(t, "png", format)
+		require.Equal(t, 128, thumbnail.Width)
+		require.Equal(t, 85, thumbnail.Height)
+
+		u, err := url.Parse(urls.OriginalURL)
+		require.NoError(t, err)
+		query := u.Query()
+		query.Set("key", "avatars/other/original")
+		require.Equal(t, http.StatusForbidden, do(http.MethodGet, u.Path+"?"+query.Encode(), "", "", nil).Code)
+		query = u.Query()
+		query.Set("expires", "1")
+		require.Equal(t, http.StatusForbidden, do(http.MethodGet, u.Path+"?"+query.Encode(), "", "", nil).Code)
+	})
+
+	t.Run("urls", func(t *testing.T) {
+		rec := do(http.MethodGet, path, userToken, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, "users see avatars of others")
+		require.Equal(t, http.StatusOK, do(http.MethodGet, decodeURLs(rec).ThumbnailURL, "", "", nil).Code)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		rec := do(http.MethodPut, path, userToken, "image/png", bytes.NewReader(original))
+		require.Equal(t, http.StatusForbidden, rec.Code, "users change only their own avatar")
+
+		rec = do(http.MethodPut, path, adminToken, "image/png", bytes.NewReader([]byte("<svg onload=alert(1)></svg>")))
+		require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+		rec = do(http.MethodPut, path, adminToken, "image/png", bytes.NewReader(encode("png", 5000, 1)))
+		require.Equal(t, http.StatusBadRequest, rec.Code, "dimensions are limited")
+
+		large := append(bytes.Clone(original), make([]byte, 2<<20)...)
+		rec = do(http.MethodPut, path, adminToken, "image/png", bytes.NewReader(large))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+		rec = do(http.MethodPut, "/v2/users/"+uuid.NewString()+"/avatar", adminToken, "image/png", bytes.NewReader(original))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		require.NoError(t, form.WriteField("note", "ignored"))
+		part, err := form.CreateFormFile("avatar", "me.jpg")
+		require.NoError(t, err)
+		_, err = part.Write(encode("jpeg", 64, 64))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		rec := do(http.MethodPut, path, adminToken, form.FormDataContentType(), &body)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		rec = do(http.MethodGet, decodeURLs(rec).ThumbnailURL, "", "", nil)
+		require.Equal(t, "image/jpeg", rec.Header().Get("Content-Type"))
+
+		var empty bytes.Buffer
+		form = multipart.NewWriter(&empty)
+		require.NoError(t, form.Close())
+		rec = do(http.MethodPut, path, adminToken, form.FormDataContentType(), &empty)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	require.Equal(t, http.StatusForbidden, do(http.MethodDelete, path, userToken, "", nil).Code)
+	require.Equal(t, http.StatusNoContent, do(http.MethodDelete, path, adminToken, "", nil).Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodDelete, path, adminToken, "", nil).Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodGet, path, adminToken, "", nil).Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodGet, urls.OriginalURL, "", "", nil).Code, "signed urls of deleted avatars")
+}
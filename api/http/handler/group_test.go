@@ -0,0 +1,140 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+func TestGroupsV2(t *testing.T) {
+	do := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(b))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	create := func(name string, roles ...string) dto.GroupResponse {
+		rec := do(http.MethodPost, "/v2/groups", adminToken, dto.CreateGroupRequest{Name: name, Roles: roles})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var group dto.GroupResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &group))
+		return group
+	}
+	addMember := func(groupID uuid.UUID, kind string, memberID uuid.UUID) *httptest.ResponseRecorder {
+		return do(http.MethodPost, "/v2/groups/"+groupID.String()+"/members", adminToken,
+			dto.AddGroupMemberRequest{Kind: kind, MemberID: memberID})
+	}
+
+	credentials := dto.RegisterRequest{Name: "amir", Email: uuid.NewString() + "@gmail.com", Password: "password"}
+	rec := do(http.MethodPost, "/v2/auth/register", "", credentials)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	login := func() string {
+		rec := do(http.MethodPost, "/v2/auth/login", "", domain.Auth{Email: credentials.Email, Password: "password"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp dto.LoginResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Token
+	}
+	claims, err := authManager.VerifyToken(login())
+	require.NoError(t, err)
+	require.Empty(t, claims.Roles)
+
+	admins := create("admins-"+uuid.NewString(), string(domain.UserRoleAdmin))
+	team := create("team-" + uuid.NewString())
+	require.Equal(t, []string{string(domain.UserRoleAdmin)}, admins.Roles)
+	require.Empty(t, team.Roles)
+	rec = do(http.MethodPost, "/v2/groups", adminToken, dto.CreateGroupRequest{Name: team.Name})
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = do(http.MethodPost, "/v2/groups", adminToken, dto.CreateGroupRequest{Name: uuid.NewString(), Roles: []string{"Root"}})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	t.Run("members", func(t *testing.T) {
+		rec := addMember(team.ID, "user", claims.UserID)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		rec = addMember(team.ID, "user", claims.UserID)
+		require.Equal(t, http.StatusConflict, rec.Code)
+		rec = addMember(team.ID, "user", uuid.New())
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		rec = addMember(team.ID, "robot", claims.UserID)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = addMember(uuid.New(), "user", claims.UserID)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = addMember(admins.ID, "group", team.ID)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		rec = do(http.MethodGet, "/v2/groups/"+admins.ID.String()+"/members", adminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), team.ID.String())
+
+		rec = do(http.MethodGet, "/v2/users/"+claims.UserID.String()+"/groups", adminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var groups []dto.GroupResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &groups))
+		require.Len(t, groups, 2, "groups of nested groups are groups of user")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		rec := addMember(team.ID, "group", admins.ID)
+		require.Equal(t, http.StatusConflict, rec.Code)
+		rec = addMember(admins.ID, "group", admins.ID)
+		require.Equal(t, http.StatusConflict, rec.Code)
+
+		nested := create("nested-" + uuid.NewString())
+		rec = addMember(team.ID, "group", nested.ID)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		rec = addMember(nested.ID, "group", admins.ID)
+		require.Equal(t, http.StatusConflict, rec.Code, "admins contains nested through team")
+	})
+
+	t.Run("roles", func(t *testing.T) {
+		rec := do(http.MethodGet, "/v2/groups", userToken, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+
+		token := login()
+		claims, err := authManager.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, string(domain.UserRoleNormal), claims.UserRole)
+		require.Empty(t, claims.Roles, "roles of groups are never kept in tokens")
+		rec = do(http.MethodGet, "/v2/groups", token, nil)
+		require.Equal(t, http.StatusOK, rec.Code, "roles of groups grant access")
+		rec = do(http.MethodGet, "/v2/groups", token, nil)
+		require.Equal(t, http.StatusOK, rec.Code, "roles are cached")
+
+		rec = do(http.MethodDelete, "/v2/groups/"+admins.ID.String()+"/members/group/"+team.ID.String(), adminToken, nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		rec = do(http.MethodDelete, "/v2/groups/"+admins.ID.String()+"/members/group/"+team.ID.String(), adminToken, nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodGet, "/v2/groups", token, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code, "removed roles take effect without a new token")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		rec := do(http.MethodDelete, "/v2/groups/"+team.ID.String(), adminToken, nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		rec = do(http.MethodGet, "/v2/groups/"+team.ID.String(), adminToken, nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		rec = do(http.MethodGet, "/v2/users/"+claims.UserID.String()+"/groups", adminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, "[]", rec.Body.String())
+
+		// changes of groups without roles are hashed as they are stored
+		rec = do(http.MethodGet, "/v2/audit-logs/verify", adminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"valid":true}`, rec.Body.String())
+	})
+}
@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+var invitationTokenPattern = regexp.MustCompile(`https://example\.com/invitation\?token=([^ ]+) `)
+
+func TestInvitationsV2(t *testing.T) {
+	do := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(b))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder, code int) dto.InvitationResponse {
+		require.Equal(t, code, rec.Code, rec.Body.String())
+		var invitation dto.InvitationResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &invitation))
+		return invitation
+	}
+	accept := func(token string) *httptest.ResponseRecorder {
+		return do(http.MethodPost, "/v2/invitations:accept", "",
+			dto.AcceptInvitationRequest{Token: token, Name: "amir", Password: "password"})
+	}
+
+	email := uuid.NewString() + "@gmail.com"
+	rec := do(http.MethodPost, "/v2/invitations", userToken, dto.CreateInvitationRequest{Email: email, Role: "Admin"})
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	rec = do(http.MethodPost, "/v2/invitations", adminToken, dto.CreateInvitationRequest{Email: email, Role: "Admin"})
+	invitation := decode(rec, http.StatusCreated)
+	require.Equal(t, "/v2/invitations/"+invitation.ID.String(), rec.Header().Get("Location"))
+	require.Equal(t, string(domain.InvitationPending), invitation.Status)
+	require.Equal(t, string(domain.UserRoleAdmin), invitation.Role)
+	require.NotNil(t, invitation.InvitedBy)
+	require.True(t, invitation.ExpiresAt.After(time.Now()))
+	firstToken := sentInvitationToken(t, email)
+	require.NotContains(t, rec.Body.String(), firstToken, "token is only sent to email")
+
+	rec = do(http.MethodPost, "/v2/invitations", adminToken, dto.CreateInvitationRequest{Email: email, Role: "User"})
+	require.Equal(t, http.StatusConflict, rec.Code, "email has a pending invitation")
+	rec = do(http.MethodPost, "/v2/invitations", adminToken,
+		dto.CreateInvitationRequest{Email: uuid.NewString() + "@gmail.com", Role: "User", ExpiresAt: time.Now().Add(-time.Hour)})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(http.MethodGet, "/v2/invitations?email="+url.QueryEscape(email), adminToken, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), invitation.ID.String())
+
+	resent := decode(do(http.MethodPost, "/v2/invitations/"+invitation.ID.String()+"/resend", adminToken, nil), http.StatusOK)
+	require.False(t, resent.SentAt.Before(invitation.SentAt))
+	token := sentInvitationToken(t, email)
+	require.NotEqual(t, firstToken, token)
+	rec = accept(firstToken)
+	require.Equal(t, http.StatusNotFound, rec.Code, "resend invalidates previous token")
+
+	rec = do(http.MethodPost, "/v2/invitations:accept", "", dto.AcceptInvitationRequest{Token: token, Name: "amir", Password: "short"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = accept(token)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var user dto.UserResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+	require.Equal(t, email, user.Email)
+	require.Equal(t, string(domain.UserRoleAdmin), user.Role)
+
+	rec = do(http.MethodPost, "/v2/auth/login", "", domain.Auth{Email: email, Password: "password"})
+	require.Equal(t, http.StatusOK, rec.Code, "invitee logs in by own password")
+
+	rec = accept(token)
+	require.Equal(t, http.StatusConflict, rec.Code, "invitation is accepted once")
+	accepted := decode(do(http.MethodGet, "/v2/invitations/"+invitation.ID.String(), adminToken, nil), http.StatusOK)
+	require.Equal(t, string(domain.InvitationAccepted), accepted.Status)
+	require.Equal(t, user.ID, *accepted.UserID)
+	rec = do(http.MethodPost, "/v2/invitations/"+invitation.ID.String()+"/revoke", adminToken, nil)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = do(http.MethodPost, "/v2/invitations", adminToken, dto.CreateInvitationRequest{Email: email, Role: "User"})
+	require.Equal(t, http.StatusConflict, rec.Code, "email is taken by the user")
+
+	other := uuid.NewString() + "@gmail.com"
+	invitation = decode(do(http.MethodPost, "/v2/invitations", adminToken, dto.CreateInvitationRequest{Email: other, Role: "User"}),
+		http.StatusCreated)
+	revoked := decode(do(http.MethodPost, "/v2/invitations/"+invitation.ID.String()+"/revoke", adminToken, nil), http.StatusOK)
+	require.Equal(t, string(domain.InvitationRevoked), revoked.Status)
+	rec = accept(sentInvitationToken(t, other))
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = do(http.MethodGet, "/v2/invitations/"+uuid.NewString(), adminToken, nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// sentInvitationToken returns token of the last invitation sent to email.
+func sentInvitationToken(t *testing.T, email string) string {
+	t.Helper()
+	message, ok := messages.last(email)
+	require.True(t, ok, "invitation is sent")
+	match := invitationTokenPattern.FindStringSubmatch(message.Body)
+	require.Len(t, match, 2, message.Body)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
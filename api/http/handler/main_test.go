@@ -1,23 +1,24 @@
 package handler_test
 
 import (
+	"context"
 	"io"
 	"log"
 	"log/slog"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/sqlite3"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
 	"golang.org/x/crypto/bcrypt"
+	_ "modernc.org/sqlite"
 
 	"github.com/amirzayi/clean_architect/api/http/handler"
+	"github.com/amirzayi/clean_architect/infra/migrations"
 	"github.com/amirzayi/clean_architect/internal/domain"
 	"github.com/amirzayi/clean_architect/internal/repository"
 	"github.com/amirzayi/clean_architect/internal/service"
@@ -25,16 +26,48 @@ import (
 	"github.com/amirzayi/clean_architect/pkg/bus"
 	"github.com/amirzayi/clean_architect/pkg/cache"
 	"github.com/amirzayi/clean_architect/pkg/hash"
+	"github.com/amirzayi/clean_architect/pkg/notify"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 )
 
 var (
-	mux        = http.NewServeMux()
-	adminToken string
-	userToken  string
+	mux         = http.NewServeMux()
+	authManager auth.Manager
+	adminToken  string
+	userToken   string
+	messages    = &messageRecorder{}
+	// repos are repositories of services, e.g. to check stored values which are never responded.
+	repos *repository.Repositories
 )
 
+// messageRecorder records sent notifications, e.g. to read tokens of invitations.
+type messageRecorder struct {
+	mu       sync.Mutex
+	messages []notify.Message
+}
+
+func (r *messageRecorder) Send(_ context.Context, message notify.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+// last returns the last message sent to given recipient.
+func (r *messageRecorder) last(to string) (notify.Message, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.messages) - 1; i >= 0; i-- {
+		if r.messages[i].To == to {
+			return r.messages[i], true
+		}
+	}
+	return notify.Message{}, false
+}
+
 func TestMain(m *testing.M) {
-	db, err := sqlx.Open("sqlite3", "file::memory:?cache=shared")
+	// same driver as the app, which has fts5 of user search
+	db, err := sqlx.Open("sqlite", "file::memory:?cache=shared&_time_format=sqlite")
 	if err != nil {
 		log.Fatalf("failed to open database connection: %v", err)
 	}
@@ -43,11 +76,7 @@ func TestMain(m *testing.M) {
 	}
 	defer db.Close()
 
-	driver, err := sqlite3.WithInstance(db.DB, &sqlite3.Config{})
-	if err != nil {
-		log.Fatalf("failed to load database driver: %v", err)
-	}
-	migrator, err := migrate.NewWithDatabaseInstance("file://../../../infra/migrations", "sqlite3", driver)
+	migrator, err := migrations.New(db.DB, "sqlite")
 	if err != nil {
 		log.Fatalf("failed to setup migrator: %v", err)
 	}
@@ -55,26 +84,35 @@ func TestMain(m *testing.M) {
 		log.Fatalf("failed to do migrate: %v", err)
 	}
 
-	repos := repository.NewSQLRepositories(db)
+	repos = repository.NewSQLRepositories(db)
 
-	authManager := auth.NewJWT(jwt.SigningMethodHS512, []byte("testing_key"), time.Hour)
-	adminToken, err = authManager.CreateToken(uuid.New(), string(domain.UserRoleAdmin))
+	authManager = auth.NewJWT(jwt.SigningMethodHS512, []byte("testing_key"), time.Hour)
+	adminToken, err = authManager.CreateToken(uuid.New(), string(domain.UserRoleAdmin), tenant.Default)
 	if err != nil {
 		log.Fatalf("failed to generate token: %v", err)
 	}
-	userToken, err = authManager.CreateToken(uuid.New(), string(domain.UserRoleNormal))
+	userToken, err = authManager.CreateToken(uuid.New(), string(domain.UserRoleNormal), tenant.Default)
 	if err != nil {
 		log.Fatalf("failed to generate token: %v", err)
 	}
 
 	services := service.NewServices(&service.Dependencies{
-		Repositories: repos,
-		Hasher:       hash.NewBcryptHasher(bcrypt.DefaultCost),
-		AuthManager:  authManager,
-		Cache:        cache.NewInMemoryDriver(),
-		Event:        bus.NewInMemoryDriver([]string{}),
-		Logger:       slog.Default(),
+		Repositories:   repos,
+		Hasher:         hash.NewBcryptHasher(bcrypt.DefaultCost),
+		AuthManager:    authManager,
+		Cache:          cache.NewInMemoryDriver(),
+		Event:          bus.NewInMemoryDriver([]string{}),
+		Logger:         slog.Default(),
+		AuditHashChain: true,
+		Notifier:       messages,
+
+		InvitationAcceptURL: "https://example.com/invitation",
 	})
 	handler.Register(mux, log.New(io.Discard, "", 0), services, authManager)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go services.Privacy.Run(ctx)
+
 	m.Run()
 }
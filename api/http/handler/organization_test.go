@@ -0,0 +1,136 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+func TestOrganizationsV2(t *testing.T) {
+	do := func(method, path, token string, body any, header ...string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(b))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	token := func(rec *httptest.ResponseRecorder) string {
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp dto.LoginResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Token
+	}
+	create := func(name string) dto.OrganizationResponse {
+		rec := do(http.MethodPost, "/v2/organizations", adminToken, dto.CreateOrganizationRequest{Name: name})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var org dto.OrganizationResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &org))
+		return org
+	}
+
+	org := create("acme-" + uuid.NewString())
+	other := create("globex-" + uuid.NewString())
+	rec := do(http.MethodPost, "/v2/organizations", adminToken, dto.CreateOrganizationRequest{Name: org.Name})
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	// email of a user of the default organization is free in other organizations
+	credentials := dto.RegisterRequest{Name: "amir", Email: uuid.NewString() + "@gmail.com", Password: "password"}
+	rec = do(http.MethodPost, "/v2/auth/register", "", credentials)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	login := domain.Auth{Email: credentials.Email, Password: "password"}
+	userToken := token(do(http.MethodPost, "/v2/auth/login", "", login))
+	user, err := authManager.VerifyToken(userToken)
+	require.NoError(t, err)
+	require.Equal(t, tenant.Default, user.TenantID)
+
+	rec = do(http.MethodPost, "/v2/auth/register", "", credentials, "X-Tenant-ID", org.ID.String())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = do(http.MethodPost, "/v2/auth/register", "", credentials, "X-Tenant-ID", uuid.NewString())
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	rec = do(http.MethodPost, "/v2/auth/register", "", credentials, "X-Tenant-ID", "invalid")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	claims, err := authManager.VerifyToken(token(do(http.MethodPost, "/v2/auth/login", "", login, "X-Tenant-ID", org.ID.String())))
+	require.NoError(t, err)
+	require.Equal(t, org.ID, claims.TenantID)
+	require.NotEqual(t, user.UserID, claims.UserID)
+
+	t.Run("isolation", func(t *testing.T) {
+		orgAdminToken, err := authManager.CreateToken(uuid.New(), string(domain.UserRoleAdmin), org.ID)
+		require.NoError(t, err)
+
+		rec := do(http.MethodGet, "/v2/users/"+user.UserID.String(), orgAdminToken, nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		rec = do(http.MethodGet, "/v2/users/"+claims.UserID.String(), orgAdminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = do(http.MethodGet, "/v2/users/"+claims.UserID.String(), adminToken, nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodGet, "/v2/organizations/"+org.ID.String(), orgAdminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = do(http.MethodGet, "/v2/organizations/"+other.ID.String(), orgAdminToken, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		rec = do(http.MethodGet, "/v2/organizations", orgAdminToken, nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		rec = do(http.MethodPost, "/v2/organizations", orgAdminToken, dto.CreateOrganizationRequest{Name: uuid.NewString()})
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("membership", func(t *testing.T) {
+		switchTo := func(id uuid.UUID) *httptest.ResponseRecorder {
+			return do(http.MethodPost, "/v2/auth/switch-organization", userToken,
+				dto.SwitchOrganizationRequest{OrganizationID: id})
+		}
+		members := "/v2/organizations/" + org.ID.String() + "/members/"
+
+		rec := switchTo(org.ID)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = do(http.MethodPut, members+user.UserID.String(), adminToken, dto.SaveMemberRequest{Role: string(domain.UserRoleAdmin)})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		rec = do(http.MethodPut, members+claims.UserID.String(), adminToken, dto.SaveMemberRequest{Role: string(domain.UserRoleAdmin)})
+		require.Equal(t, http.StatusConflict, rec.Code)
+		rec = do(http.MethodPut, members+uuid.NewString(), adminToken, dto.SaveMemberRequest{Role: string(domain.UserRoleAdmin)})
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodGet, "/v2/organizations/"+org.ID.String()+"/members", adminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), user.UserID.String())
+
+		switched, err := authManager.VerifyToken(token(switchTo(org.ID)))
+		require.NoError(t, err)
+		require.Equal(t, org.ID, switched.TenantID)
+		require.Equal(t, user.UserID, switched.UserID)
+		require.Equal(t, string(domain.UserRoleAdmin), switched.UserRole)
+
+		back, err := authManager.VerifyToken(token(switchTo(tenant.Default)))
+		require.NoError(t, err)
+		require.Equal(t, string(domain.UserRoleNormal), back.UserRole)
+
+		rec = switchTo(other.ID)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = do(http.MethodDelete, members+user.UserID.String(), adminToken, nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		rec = do(http.MethodDelete, members+user.UserID.String(), adminToken, nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		rec = switchTo(org.ID)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
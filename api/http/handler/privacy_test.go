@@ -0,0 +1,212 @@
+package handler_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+func TestPrivacyJobsV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+token)
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	// wait polls job until it is finished
+	wait := func(location string) dto.PrivacyJobResponse {
+		var job dto.PrivacyJobResponse
+		require.Eventually(t, func() bool {
+			rec := do(http.MethodGet, location, adminToken)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+			return job.Status == string(domain.PrivacyJobCompleted) || job.Status == string(domain.PrivacyJobFailed)
+		}, 5*time.Second, 10*time.Millisecond)
+		return job
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v2/users/"+user.ID.String()+"/ban", http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	req.Header.Set("If-Match", "*")
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = do(http.MethodPost, "/v2/users/"+user.ID.String()+"/privacy-export", adminToken)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	exportLocation := rec.Header().Get("Location")
+	export := wait(exportLocation)
+	require.Equal(t, string(domain.PrivacyJobCompleted), export.Status)
+	require.Equal(t, string(domain.PrivacyJobExport), export.Kind)
+	require.NotNil(t, export.RequestedBy)
+	require.Positive(t, export.ArchiveSize)
+
+	rec = do(http.MethodGet, exportLocation+"/archive", adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[f.Name] = string(b)
+	}
+	require.Contains(t, files["user.json"], user.Email)
+	require.Contains(t, files["user.json"], domain.AuditRedacted)
+	require.NotContains(t, files["user.json"], "$2a$", "password hash must not be exported")
+	require.Contains(t, files["status_history.json"], user.ID.String())
+	require.Contains(t, files["audit_log.json"], string(domain.AuditActionUserCreate))
+	require.Contains(t, files["audit_log.json"], string(domain.AuditActionUserExport))
+	require.Contains(t, files, "cache.json")
+
+	var avatar bytes.Buffer
+	require.NoError(t, png.Encode(&avatar, image.NewRGBA(image.Rect(0, 0, 1, 1))))
+	req = httptest.NewRequest(http.MethodPut, "/v2/users/"+user.ID.String()+"/avatar", &avatar)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = do(http.MethodPost, "/v2/users/"+user.ID.String()+"/erasure", adminToken)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	erasure := wait(rec.Header().Get("Location"))
+	require.Equal(t, string(domain.PrivacyJobCompleted), erasure.Status)
+
+	rec = do(http.MethodGet, "/v2/users?with_deleted=true&id="+user.ID.String(), adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotContains(t, rec.Body.String(), user.Email)
+	require.NotContains(t, rec.Body.String(), user.PhoneNumber)
+	require.Contains(t, rec.Body.String(), user.ID.String()+"@erased.invalid")
+
+	rec = do(http.MethodGet, exportLocation+"/archive", adminToken)
+	require.Equal(t, http.StatusNotFound, rec.Code, "archives of erased users are removed")
+	rec = do(http.MethodGet, "/v2/users/"+user.ID.String()+"/avatar", adminToken)
+	require.Equal(t, http.StatusNotFound, rec.Code, "avatars of erased users are removed")
+
+	rec = do(http.MethodGet, "/v2/privacy-jobs?user_id="+user.ID.String(), adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []dto.PrivacyJobResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 2)
+	require.Equal(t, erasure.ID, list.Data[0].ID, "newest first")
+
+	rec = do(http.MethodGet, "/v2/audit-logs?action=user.erase&target_id="+user.ID.String(), adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"action":"user.erase"`)
+
+	rec = do(http.MethodPost, "/v2/users/"+uuid.NewString()+"/privacy-export", adminToken)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(http.MethodPost, "/v2/users/"+user.ID.String()+"/privacy-export", userToken)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestPrivacyErasureShredsAuditV2(t *testing.T) {
+	do := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(b))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if method == http.MethodPatch {
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			req.Header.Set("If-Match", "*")
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	ctx := tenant.ContextWithID(context.Background(), tenant.Default)
+	// whole audit log, so personal values are found wherever they are recorded
+	auditLog := func() string {
+		rec := do(http.MethodGet, "/v2/audit-logs/export", adminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	email := uuid.NewString() + "@gmail.com"
+	rec := do(http.MethodPost, "/v2/invitations", adminToken, dto.CreateInvitationRequest{Email: email, Role: "User"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var invitation dto.InvitationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &invitation))
+
+	name := "erased " + uuid.NewString()
+	rec = do(http.MethodPost, "/v2/invitations:accept", "",
+		dto.AcceptInvitationRequest{Token: sentInvitationToken(t, email), Name: name, Password: "password"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var user dto.UserResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+
+	phone := fmt.Sprintf("0911%07d", rand.IntN(1e7))
+	rec = do(http.MethodPatch, "/v2/users/"+user.ID.String(), adminToken, map[string]any{"phone_number": phone})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	subjects := []string{
+		domain.AuditSubject(domain.AuditTargetUser, user.ID),
+		domain.AuditSubject(domain.AuditTargetInvitation, invitation.ID),
+	}
+	key, err := repos.AuditKey.Get(ctx, subjects[0])
+	require.NoError(t, err)
+	pseudonym := domain.AuditPseudonym(key.Key, name)
+
+	log := auditLog()
+	require.Contains(t, log, pseudonym, "pseudonyms are linked to values by key of user")
+	for _, value := range []string{email, name, phone} {
+		require.NotContains(t, log, value)
+	}
+
+	rec = do(http.MethodPost, "/v2/users/"+user.ID.String()+"/erasure", adminToken, nil)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	location := rec.Header().Get("Location")
+	require.Eventually(t, func() bool {
+		rec := do(http.MethodGet, location, adminToken, nil)
+		var job dto.PrivacyJobResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+		require.NotEqual(t, string(domain.PrivacyJobFailed), job.Status)
+		return job.Status == string(domain.PrivacyJobCompleted)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, subject := range subjects {
+		_, err = repos.AuditKey.Get(ctx, subject)
+		require.ErrorIs(t, err, domain.ErrAuditKeyNotFound, subject)
+	}
+	log = auditLog()
+	require.Contains(t, log, pseudonym, "entries are kept untouched")
+	for _, value := range []string{email, name, phone} {
+		require.NotContains(t, log, value)
+	}
+	rec = do(http.MethodGet, "/v2/audit-logs/verify", adminToken, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"valid":true}`, rec.Body.String())
+
+	rec = do(http.MethodGet, "/v2/invitations/"+invitation.ID.String(), adminToken, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotContains(t, rec.Body.String(), email, "email of invitation is erased")
+	require.Contains(t, rec.Body.String(), user.ID.String()+"@erased.invalid")
+}
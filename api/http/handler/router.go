@@ -13,7 +13,15 @@ import (
 
 func Register(mux *http.ServeMux, logger *log.Logger, services *service.Services, authManager auth.Manager) {
 	rahjoo.BindRoutesToMux(mux,
-		v2.UserRoutes(middleware.LogRequestBody(logger), services.User, authManager),
-		v2.AuthRoutes(services.Auth),
+		v2.UserRoutes(middleware.LogRequestBody(logger), services.User, authManager, services.Group),
+		v2.AuthRoutes(services.Auth, authManager, services.Group),
+		v2.AuditRoutes(services.Audit, authManager, services.Group),
+		v2.PrivacyRoutes(services.Privacy, authManager, services.Group),
+		v2.OrganizationRoutes(services.Organization, authManager, services.Group),
+		v2.InvitationRoutes(services.Invitation, authManager, services.Group),
+		v2.GroupRoutes(services.Group, authManager),
+		v2.UserAttributeRoutes(services.UserAttribute, authManager, services.Group),
+		v2.AvatarRoutes(services.Avatar, authManager, services.Group),
+		v2.FileRoutes(services.File),
 	)
 }
@@ -0,0 +1,135 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+func TestUserAttributesV2(t *testing.T) {
+	do := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Authorization", "Bearer "+token)
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	// attributes are defined for all users of the default organization, so their names are unique
+	// and they are deleted at the end not to be required by other tests.
+	suffix := strings.ReplaceAll(uuid.NewString(), "-", "")
+	define := func(name string, in dto.CreateUserAttributeRequest) string {
+		in.Name = name + "_" + suffix
+		rec := do(http.MethodPost, "/v2/user-attributes", adminToken, in)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		t.Cleanup(func() {
+			require.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/v2/user-attributes/"+in.Name, adminToken, nil).Code)
+		})
+		return in.Name
+	}
+	createUser := func(attributes map[string]any) *httptest.ResponseRecorder {
+		return do(http.MethodPost, "/v2/users", adminToken, dto.CreateUserRequest{
+			Name:       "amir",
+			Email:      uuid.NewString() + "@gmail.com",
+			Password:   "password",
+			Role:       string(domain.UserRoleNormal),
+			Attributes: attributes,
+		})
+	}
+
+	rec := do(http.MethodPost, "/v2/user-attributes", userToken, dto.CreateUserAttributeRequest{Name: "age", Type: "number"})
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	for _, in := range []dto.CreateUserAttributeRequest{
+		{Name: "Age", Type: "number"},
+		{Name: "age", Type: "decimal"},
+		{Name: "plan", Type: "enum"},
+		{Name: "age", Type: "number", Values: []string{"1"}},
+	} {
+		rec = do(http.MethodPost, "/v2/user-attributes", adminToken, in)
+		require.Equal(t, http.StatusBadRequest, rec.Code, in)
+	}
+
+	age := define("age", dto.CreateUserAttributeRequest{Type: "number"})
+	plan := define("plan", dto.CreateUserAttributeRequest{Type: "enum", Values: []string{"gold", "free"}})
+	code := define("code", dto.CreateUserAttributeRequest{Type: "string", Unique: true})
+	dept := define("dept", dto.CreateUserAttributeRequest{Type: "string", Required: true})
+	rec = do(http.MethodPost, "/v2/user-attributes", adminToken, dto.CreateUserAttributeRequest{Name: age, Type: "bool"})
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = do(http.MethodGet, "/v2/user-attributes", adminToken, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var attributes []dto.UserAttributeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &attributes))
+	i := slices.IndexFunc(attributes, func(a dto.UserAttributeResponse) bool { return a.Name == plan })
+	require.GreaterOrEqual(t, i, 0)
+	require.Equal(t, []string{"free", "gold"}, attributes[i].Values)
+
+	t.Run("validation", func(t *testing.T) {
+		for name, attributes := range map[string]map[string]any{
+			"missing required": {age: 30},
+			"wrong type":       {dept: "it", age: "thirty"},
+			"not in enum":      {dept: "it", plan: "silver"},
+			"undefined":        {dept: "it", "nickname": "x"},
+		} {
+			require.Equal(t, http.StatusBadRequest, createUser(attributes).Code, name)
+		}
+
+		rec := createUser(map[string]any{dept: "it", code: "c-" + suffix})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		rec = createUser(map[string]any{dept: "it", code: "c-" + suffix})
+		require.Equal(t, http.StatusConflict, rec.Code, "unique attribute")
+	})
+
+	t.Run("filter and sort", func(t *testing.T) {
+		for _, value := range []float64{9, 100, 30} {
+			rec := createUser(map[string]any{dept: suffix, age: value, plan: "gold"})
+			require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+			var user dto.UserResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+			require.Equal(t, value, user.Attributes[age])
+		}
+
+		list := func(query string) ([]float64, int) {
+			rec := do(http.MethodGet, "/v2/users?"+query, adminToken, nil)
+			if rec.Code != http.StatusOK {
+				return nil, rec.Code
+			}
+			var resp struct {
+				Data []dto.UserResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			ages := make([]float64, 0, len(resp.Data))
+			for _, user := range resp.Data {
+				ages = append(ages, user.Attributes[age].(float64))
+			}
+			return ages, rec.Code
+		}
+		filter := fmt.Sprintf("attributes.%s=%s", dept, suffix)
+
+		ages, code := list(fmt.Sprintf("%s&sort=attributes.%s&sort=%s", filter, age, paginate.SortOrderDescending))
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, []float64{100, 30, 9}, ages, "numbers are sorted numerically")
+
+		ages, code = list(fmt.Sprintf("%s&attributes.%s=30.0", filter, age))
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, []float64{30}, ages)
+
+		_, code = list(fmt.Sprintf("attributes.%s=thirty", age))
+		require.Equal(t, http.StatusBadRequest, code)
+		_, code = list("sort=attributes.undefined")
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+}
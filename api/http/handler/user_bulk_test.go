@@ -0,0 +1,128 @@
+package handler_test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+)
+
+func TestImportUsersV2(t *testing.T) {
+	email := func() string { return uuid.NewString() + "@gmail.com" }
+	duplicate := email()
+	body := strings.Join([]string{
+		"Name,Email,Password,Role",
+		fmt.Sprintf("ali,%s,password,Admin", duplicate),
+		fmt.Sprintf("reza,%s,short,", email()),
+		fmt.Sprintf("sara,%s,password", email()),
+		fmt.Sprintf("mina,%s,password,", duplicate),
+		fmt.Sprintf("nima,%s,password,Owner", email()),
+		fmt.Sprintf("neda,%s,password,", email()),
+	}, "\n")
+
+	importUsers := func(query string) (int, dto.ImportUsersResponse) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v2/users:import"+query, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		req.Header.Set("Content-Type", "text/csv")
+		mux.ServeHTTP(rec, req)
+
+		var resp dto.ImportUsersResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+
+	lines := func(resp dto.ImportUsersResponse) []int {
+		var lines []int
+		for _, rowErr := range resp.Errors {
+			lines = append(lines, rowErr.Line)
+		}
+		return lines
+	}
+
+	code, resp := importUsers("?dry_run=true")
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, resp.DryRun)
+	require.Equal(t, 6, resp.Total)
+	require.Equal(t, 2, resp.Imported)
+	require.Equal(t, []int{3, 4, 5, 6}, lines(resp))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v2/users?email="+duplicate, http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+	require.Contains(t, rec.Body.String(), `"total_items":0`, "dry run must not create users")
+
+	code, resp = importUsers("")
+	require.Equal(t, http.StatusOK, code)
+	require.False(t, resp.DryRun)
+	require.Equal(t, 2, resp.Imported)
+	require.Equal(t, 4, resp.Failed)
+	require.Equal(t, []int{3, 4, 5, 6}, lines(resp))
+
+	code, resp = importUsers("")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, 0, resp.Imported, "existing users are reported")
+	require.Len(t, resp.Errors, 6)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/v2/users:import", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+userToken)
+	req.Header.Set("Content-Type", "text/csv")
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/v2/users:import", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	req.Header.Set("Content-Type", "application/xml")
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestExportUsersV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	export := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v2/users:export"+query, http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := export("?email=" + user.Email)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, dto.UserRecordColumns, records[0])
+	require.Equal(t, user.ID.String(), records[1][0])
+	require.NotContains(t, strings.Join(records[0], ","), "password")
+
+	rec = export("?format=ndjson&email=" + user.Email)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	scanner := bufio.NewScanner(rec.Body)
+	var exported []map[string]string
+	for scanner.Scan() {
+		var record map[string]string
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		exported = append(exported, record)
+	}
+	require.Len(t, exported, 1)
+	require.Equal(t, user.Email, exported[0]["email"])
+
+	rec = export("?format=xml")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
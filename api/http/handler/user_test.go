@@ -2,16 +2,24 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
 	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 	"github.com/golang-jwt/jwt/v5/request"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestListUserV2(t *testing.T) {
@@ -40,6 +48,269 @@ func TestListUserV2(t *testing.T) {
 	}
 }
 
+func TestListUserCursorV2(t *testing.T) {
+	for range 3 {
+		testCreateUserV2(t)
+	}
+
+	list := func(query string) (*httptest.ResponseRecorder, map[string]any) {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/v2/users?per_page=2&"+query, http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		mux.ServeHTTP(rec, req)
+		var body map[string]any
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body
+	}
+
+	rec, first := list("after=")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, first["data"], 2)
+	require.NotEmpty(t, first["next_cursor"])
+	require.NotContains(t, first, "prev_cursor")
+
+	rec, second := list("after=" + first["next_cursor"].(string))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotEmpty(t, second["prev_cursor"])
+	require.NotEqual(t, first["data"], second["data"])
+
+	rec, back := list("before=" + second["prev_cursor"].(string))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, first["data"], back["data"])
+
+	rec, _ = list("after=invalid")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListUserUnknownFieldsV2(t *testing.T) {
+	for _, tc := range []struct {
+		name, query, param, field string
+	}{
+		{"sort", "sort=password", "sort", "password"},
+		{"injected sort", "sort=" + url.QueryEscape("name,(select 1)"), "sort", "name,(select 1)"},
+		{"fields", "fields=password", "fields", "password"},
+		{"filter", "filter=" + url.QueryEscape("name==amir,password==x"), "filter", "password"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/v2/users?"+tc.query, http.NoBody)
+			req.Header.Set("Authorization", "Bearer "+adminToken)
+			mux.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var body struct {
+				Details []struct {
+					Parameter string   `json:"parameter"`
+					Field     string   `json:"field"`
+					Allowed   []string `json:"allowed"`
+				} `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Len(t, body.Details, 1)
+			require.Equal(t, tc.param, body.Details[0].Parameter)
+			require.Equal(t, tc.field, body.Details[0].Field)
+			require.Contains(t, body.Details[0].Allowed, "name")
+			require.NotContains(t, body.Details[0].Allowed, "password")
+		})
+	}
+}
+
+func TestListUserFilterExpressionV2(t *testing.T) {
+	user := testCreateUserV2(t)
+	other := testCreateUserV2(t)
+
+	list := func(expression string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/v2/users?filter="+url.QueryEscape(expression), http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := list(fmt.Sprintf("(email==%q,email==%q);id!=%s", user.Email, other.Email, other.ID))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var users struct {
+		Data []struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &users))
+	require.Len(t, users.Data, 1)
+	require.Equal(t, user.ID, users.Data[0].ID)
+
+	rec = list("name==amir;(status==1")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var body struct {
+		Details []struct {
+			Parameter string `json:"parameter"`
+			Position  int    `json:"position"`
+			Message   string `json:"message"`
+		} `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Details, 1)
+	require.Equal(t, "filter", body.Details[0].Parameter)
+	require.Equal(t, 21, body.Details[0].Position)
+	require.Equal(t, "missing closing parenthesis", body.Details[0].Message)
+}
+
+func TestListUserTypedFilterV2(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v2/users?status=abc&status=gt", http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var body struct {
+		Details []struct {
+			Field     string `json:"field"`
+			Condition string `json:"condition"`
+			Value     string `json:"value"`
+			Message   string `json:"message"`
+		} `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Details, 1)
+	require.Equal(t, "status", body.Details[0].Field)
+	require.Equal(t, "gt", body.Details[0].Condition)
+	require.Equal(t, "abc", body.Details[0].Value)
+	require.Equal(t, "value must be int", body.Details[0].Message)
+}
+
+func TestListUserFacetsV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v2/users?per_page=1&facet=role&facet=created_at:day&id="+user.ID.String(), http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Facets []paginate.Facet `json:"facets"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Facets, 2)
+	require.Equal(t, []paginate.Group{{Key: "User", Count: 1}}, body.Facets[0].Groups)
+	require.Equal(t, "day", body.Facets[1].Bucket)
+	require.Len(t, body.Facets[1].Groups, 1)
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/v2/users?facet=name:day", http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestSearchUsersV2(t *testing.T) {
+	user := testCreateUserV2(t)
+	local, _, _ := strings.Cut(user.Email, "@")
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v2/users?q="+url.QueryEscape("AMIR "+local), http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data       []dto.UserResponse           `json:"data"`
+		Highlights map[string]map[string]string `json:"highlights"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data, 1)
+	require.Equal(t, user.ID, body.Data[0].ID)
+	require.Equal(t, "<mark>amir</mark>", body.Highlights[user.ID.String()]["name"])
+}
+
+func TestListUserLinksV2(t *testing.T) {
+	testCreateUserV2(t)
+	testCreateUserV2(t)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v2/users?per_page=1&name=amir&sort=created_at&sort=asc", http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	next := "/v2/users?name=amir&page=2&per_page=1&sort=created_at&sort=asc"
+	require.Contains(t, rec.Header().Get("Link"), "<"+next+`>; rel="next"`)
+	var body struct {
+		Links paginate.Links `json:"links"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, next, body.Links.Next)
+	require.Empty(t, body.Links.Prev)
+	require.NotEmpty(t, body.Links.Last)
+
+	// cursor links keep their cursor
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/v2/users?per_page=1&after=", http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var cursorBody struct {
+		NextCursor string         `json:"next_cursor"`
+		Links      paginate.Links `json:"links"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cursorBody))
+	require.Equal(t, "/v2/users?"+url.Values{"after": {cursorBody.NextCursor}, "per_page": {"1"}}.Encode(), cursorBody.Links.Next)
+	require.Equal(t, "/v2/users?before=&per_page=1", cursorBody.Links.Last)
+}
+
+func TestSparseFieldsetsV2(t *testing.T) {
+	user := testCreateUserV2(t)
+	get := func(path string) (*httptest.ResponseRecorder, string) {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		mux.ServeHTTP(rec, req)
+		return rec, rec.Body.String()
+	}
+
+	rec, body := get("/v2/users?fields=id,phone_number&id=" + user.ID.String())
+	require.Equal(t, http.StatusOK, rec.Code, body)
+	var list struct {
+		Data []map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Equal(t, []map[string]any{{"id": user.ID.String(), "phone_number": user.PhoneNumber}}, list.Data)
+
+	// nested paths write only their fields, users without attributes have none of them
+	rec, body = get("/v2/users?fields=id,attributes.plan&id=" + user.ID.String())
+	require.Equal(t, http.StatusOK, rec.Code, body)
+	list.Data = nil
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Equal(t, []map[string]any{{"id": user.ID.String()}}, list.Data)
+
+	rec, body = get("/v2/users/" + user.ID.String() + "?fields=name,password")
+	require.Equal(t, http.StatusOK, rec.Code, body)
+	require.JSONEq(t, `{"name":"amir"}`, body)
+
+	// passwords are never written, not even hashed
+	rec, body = get("/v2/users/" + user.ID.String())
+	require.Equal(t, http.StatusOK, rec.Code, body)
+	require.NotContains(t, strings.ToLower(body), "password")
+}
+
+func TestListUserWithoutCountV2(t *testing.T) {
+	testCreateUserV2(t)
+	testCreateUserV2(t)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v2/users?per_page=1&count=none", http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Pagination paginate.Pagination `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.True(t, body.Pagination.HasMore)
+	require.Zero(t, body.Pagination.TotalItems)
+}
+
 func TestCreateUserV2(t *testing.T) {
 	for _, tc := range []struct {
 		name         string
@@ -71,7 +342,13 @@ func TestCreateUserV2(t *testing.T) {
 	}
 
 	t.Run("valid", func(t *testing.T) {
-		testCreateUserV2(t)
+		user := testCreateUserV2(t)
+
+		// password of testCreateUserV2 is stored hashed
+		stored, err := repos.User.GetByID(tenant.ContextWithID(context.Background(), tenant.Default), user.ID)
+		require.NoError(t, err)
+		require.NotEqual(t, "password", stored.Password)
+		require.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("password")))
 	})
 }
 
@@ -107,10 +384,11 @@ func TestGetUserV2(t *testing.T) {
 		req.Header.Set("Authorization", "Bearer "+adminToken)
 		mux.ServeHTTP(rec, req)
 		require.Equal(t, http.StatusOK, rec.Code)
-		var newUser domain.User
+		var newUser dto.UserResponse
 		err := json.Unmarshal(rec.Body.Bytes(), &newUser)
 		// some issue with sqlite save datetime
 		newUser.CreatedAt = user.CreatedAt
+		newUser.UpdatedAt = user.UpdatedAt
 		require.NoError(t, err)
 		require.Equal(t, user, newUser)
 	})
@@ -128,7 +406,8 @@ func TestDeleteUserV2(t *testing.T) {
 		{"bad token", "123", map[string]string{"Authorization": userToken}, http.StatusUnauthorized},
 		{"invalid role", "123", map[string]string{"Authorization": "Bearer " + userToken}, http.StatusForbidden},
 		{"bad id parameter", "123", map[string]string{"Authorization": "Bearer " + adminToken}, http.StatusBadRequest},
-		{"not found", uuid.New().String(), map[string]string{"Authorization": "Bearer " + adminToken}, http.StatusInternalServerError},
+		{"no version", uuid.New().String(), map[string]string{"Authorization": "Bearer " + adminToken}, http.StatusPreconditionRequired},
+		{"not found", uuid.New().String(), map[string]string{"Authorization": "Bearer " + adminToken, "If-Match": "*"}, http.StatusNotFound},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			rec := httptest.NewRecorder()
@@ -146,15 +425,312 @@ func TestDeleteUserV2(t *testing.T) {
 		rec := httptest.NewRecorder()
 		req, _ := http.NewRequest(http.MethodDelete, "/v2/users/"+user.ID.String(), http.NoBody)
 		req.Header.Set("Authorization", "Bearer "+adminToken)
+		req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, user.Version+1))
+		mux.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code, "stale version")
+
+		rec = httptest.NewRecorder()
+		req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, user.Version))
 		mux.ServeHTTP(rec, req)
 		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusNotFound, rec.Code, "already deleted user")
+	})
+}
+
+func TestUpdateUserV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/v2/users/"+user.ID.String(), http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	update := func(ifMatch string, body dto.UpdateUserRequest) *httptest.ResponseRecorder {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/v2/users/"+user.ID.String(), bytes.NewReader(b))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	body := dto.UpdateUserRequest{
+		Name:        "updated",
+		PhoneNumber: user.PhoneNumber,
+		Email:       user.Email,
+		Password:    "password",
+	}
+
+	rec := get("")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.Equal(t, `"1"`, etag)
+	require.Equal(t, http.StatusNotModified, get(etag).Code)
+
+	require.Equal(t, http.StatusBadRequest, update("W/"+etag, body).Code)
+	require.Equal(t, http.StatusPreconditionRequired, update("", body).Code, "neither If-Match nor version")
+
+	rec = update(etag, body)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	require.Equal(t, http.StatusPreconditionFailed, update(etag, body).Code, "stale If-Match")
+	body.Version = 1
+	require.Equal(t, http.StatusConflict, update("", body).Code, "stale version")
+
+	body.Version = 0
+	require.Equal(t, http.StatusNoContent, update("*", body).Code)
+
+	rec = get(etag)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `"3"`, rec.Header().Get("ETag"))
+}
+
+func TestPatchUserV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	patch := func(contentType, ifMatch, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, "/v2/users/"+user.ID.String(), bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		req.Header.Set("Content-Type", contentType)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	const (
+		mergePatch = "application/merge-patch+json"
+		jsonPatch  = "application/json-patch+json"
+	)
+
+	for _, tc := range []struct {
+		name         string
+		contentType  string
+		body         string
+		expectedCode int
+	}{
+		{"unsupported media type", "text/plain", `{"name":"ali"}`, http.StatusUnsupportedMediaType},
+		{"role", mergePatch, `{"role":"Admin"}`, http.StatusBadRequest},
+		{"status", jsonPatch, `[{"op":"add","path":"/status","value":"banned"}]`, http.StatusBadRequest},
+		{"unknown field", mergePatch, `{"age":20}`, http.StatusBadRequest},
+		{"invalid email", mergePatch, `{"email":"invalid"}`, http.StatusBadRequest},
+		{"remove required name", mergePatch, `{"name":null}`, http.StatusBadRequest},
+		{"short password", mergePatch, `{"password":"123"}`, http.StatusBadRequest},
+		{"wrong type", mergePatch, `{"name":1}`, http.StatusBadRequest},
+		{"invalid json patch", jsonPatch, `[{"op":"replace","path":"/missing","value":1}]`, http.StatusBadRequest},
+		{"failed test", jsonPatch, `[{"op":"test","path":"/name","value":"someone"}]`, http.StatusConflict},
+		{"stale If-Match", mergePatch, `{"name":"ali"}`, http.StatusPreconditionFailed},
+		{"missing If-Match", mergePatch, `{"name":"ali"}`, http.StatusPreconditionRequired},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ifMatch := "*"
+			switch tc.expectedCode {
+			case http.StatusPreconditionFailed:
+				ifMatch = `"99"`
+			case http.StatusPreconditionRequired:
+				ifMatch = ""
+			}
+			require.Equal(t, tc.expectedCode, patch(tc.contentType, ifMatch, tc.body).Code)
+		})
+	}
+
+	t.Run("merge patch", func(t *testing.T) {
+		rec := patch(mergePatch, `"1"`, `{"name":"ali","phone_number":null,"password":"new_password"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+		var got dto.UserResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, "ali", got.Name)
+		require.Empty(t, got.PhoneNumber)
+		require.Equal(t, user.Email, got.Email)
+		require.Equal(t, string(domain.UserRoleNormal), got.Role)
+
+		// password is hashed
+		b, err := json.Marshal(domain.Auth{Email: user.Email, Password: "new_password"})
+		require.NoError(t, err)
+		rec = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/v2/auth/login", bytes.NewReader(b))
+		mux.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("json patch", func(t *testing.T) {
+		rec := patch(jsonPatch, "*", `[{"op":"test","path":"/name","value":"ali"},{"op":"replace","path":"/name","value":"amir"}]`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+		var got dto.UserResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, "amir", got.Name)
 	})
 }
 
-func testCreateUserV2(t *testing.T) domain.User {
+func TestChangeUserRoleV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/v2/users/"+user.ID.String()+"/role", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusBadRequest, put(`{"role":"Owner","version":1}`).Code)
+	require.Equal(t, http.StatusPreconditionRequired, put(`{"role":"Admin"}`).Code)
+	require.Equal(t, http.StatusConflict, put(`{"role":"Admin","version":5}`).Code)
+
+	rec := put(`{"role":"Admin","version":1}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got dto.UserResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Equal(t, string(domain.UserRoleAdmin), got.Role)
+	require.Equal(t, int64(2), got.Version)
+}
+
+func TestChangeUserStatusV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	post := func(operation, body string, headers map[string]string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/v2/users/"+user.ID.String()+"/"+operation, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	requireStatus := func(rec *httptest.ResponseRecorder, status domain.UserStatus, version int64) {
+		t.Helper()
+		require.Equal(t, http.StatusOK, rec.Code)
+		var got dto.UserResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, status.String(), got.Status)
+		require.Equal(t, version, got.Version)
+		require.Equal(t, fmt.Sprintf("%q", fmt.Sprint(version)), rec.Header().Get("ETag"))
+	}
+
+	anyVersion := map[string]string{"If-Match": "*"}
+	require.Equal(t, http.StatusPreconditionRequired, post("ban", `{"reason":"spam"}`, nil).Code)
+	require.Equal(t, http.StatusConflict, post("unban", "", anyVersion).Code, "user is not banned")
+	require.Equal(t, http.StatusPreconditionFailed, post("ban", "", map[string]string{"If-Match": `"5"`}).Code)
+	require.Equal(t, http.StatusConflict, post("ban", `{"version":5}`, nil).Code)
+	require.Equal(t, http.StatusBadRequest, post("ban", `{"reason":1}`, anyVersion).Code)
+
+	requireStatus(post("ban", `{"reason":"spam"}`, anyVersion), domain.UserStatusBanned, 2)
+	require.Equal(t, http.StatusConflict, post("activate", "", anyVersion).Code, "banned user must be unbanned")
+	requireStatus(post("unban", "", map[string]string{"If-Match": `"2"`}), domain.UserStatusActive, 3)
+	require.Equal(t, http.StatusPreconditionFailed, post("deactivate", "", map[string]string{"If-Match": `"2"`}).Code,
+		"status changed since version")
+	requireStatus(post("deactivate", `{"version":3}`, nil), domain.UserStatusInactive, 4)
+	require.Equal(t, http.StatusConflict, post("deactivate", "", anyVersion).Code, "user is already inactive")
+
 	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v2/users/"+user.ID.String()+"/status-history", http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var history struct {
+		Data []dto.UserStatusTransitionResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+	require.Len(t, history.Data, 3)
+	require.Equal(t, domain.UserStatusActive.String(), history.Data[0].From)
+	require.Equal(t, domain.UserStatusInactive.String(), history.Data[0].To)
+	require.Equal(t, "spam", history.Data[2].Reason)
+}
+
+func TestRestoreUserV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	restore := func(ifMatch string) int {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/v2/users/"+user.ID.String()+"/restore", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
 
-	body := dto.CreateUserRequest{"amir", "09101234567", "mirzayi994@gmail.com", "password", string(domain.UserRoleNormal)}
+	require.Equal(t, http.StatusNotFound, restore("*"), "not deleted user")
+
+	testDeleteUserV2(t, user.ID)
+	require.Equal(t, http.StatusPreconditionRequired, restore(""))
+	require.Equal(t, http.StatusPreconditionFailed, restore(fmt.Sprintf(`"%d"`, user.Version)), "version before delete")
+	require.Equal(t, http.StatusNoContent, restore(fmt.Sprintf(`"%d"`, user.Version+1)))
+	require.Equal(t, http.StatusNotFound, restore("*"), "restored user")
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v2/audit-logs?action="+string(domain.AuditActionUserRestore)+"&target_id="+user.ID.String(), http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []dto.AuditEntryResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	require.Equal(t, []domain.AuditChange{
+		{Field: "status", Before: domain.UserStatusDeleted.String(), After: domain.UserStatusActive.String()},
+	}, list.Data[0].Changes)
+}
+
+func TestPurgeUserV2(t *testing.T) {
+	user := testCreateUserV2(t)
+
+	purge := func() int {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/v2/users/"+user.ID.String()+"/purge", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusNotFound, purge(), "not deleted user")
+
+	testDeleteUserV2(t, user.ID)
+	require.Equal(t, http.StatusNoContent, purge())
+	require.Equal(t, http.StatusNotFound, purge())
+}
+
+func testDeleteUserV2(t *testing.T, id uuid.UUID) {
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/v2/users/"+id.String(), http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	req.Header.Set("If-Match", "*")
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func testCreateUserV2(t *testing.T) dto.UserResponse {
+	rec := httptest.NewRecorder()
+
+	// email and phone number are unique
+	body := dto.CreateUserRequest{
+		Name:        "amir",
+		PhoneNumber: fmt.Sprintf("0910%07d", rand.IntN(1e7)),
+		Email:       fmt.Sprintf("%s@gmail.com", uuid.NewString()),
+		Password:    "password",
+		Role:        string(domain.UserRoleNormal),
+	}
 	b, err := json.Marshal(body)
 	require.NoError(t, err)
 
@@ -164,7 +740,7 @@ func testCreateUserV2(t *testing.T) domain.User {
 	mux.ServeHTTP(rec, req)
 	require.Equal(t, http.StatusCreated, rec.Code)
 
-	var user domain.User
+	var user dto.UserResponse
 	err = json.Unmarshal(rec.Body.Bytes(), &user)
 	require.NoError(t, err)
 	return user
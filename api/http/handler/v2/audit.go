@@ -0,0 +1,89 @@
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/amirzayi/rahjoo"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/bulkio"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+type auditRouter struct {
+	auditService service.Audit
+}
+
+func AuditRoutes(auditService service.Audit, authManager auth.Manager, groupService service.Group) rahjoo.Route {
+	audit := &auditRouter{auditService: auditService}
+	return rahjoo.NewGroupRoute("/v2/audit-logs", rahjoo.Route{
+		"": {
+			http.MethodGet: rahjoo.NewHandler(audit.list),
+		},
+		"/export": {
+			http.MethodGet: rahjoo.NewHandler(audit.export),
+		},
+		"/verify": {
+			http.MethodGet: rahjoo.NewHandler(audit.verify),
+		},
+	}.SetMiddleware(
+		appmiddleware.MustHaveAtLeastOneRole(authManager, groupService, []domain.UserRole{domain.UserRoleAdmin}),
+	),
+	)
+}
+
+func (a *auditRouter) list(w http.ResponseWriter, r *http.Request) {
+	p := paginate.ParseFromRequest(r)
+
+	entries, err := a.auditService.List(r.Context(), p)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, dto.AuditEntryDomainToDTO(entry))
+	}
+	paginate.SetLinks(w, r, p)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(responses, p))
+}
+
+// export streams entries matching filters as newline delimited json, oldest first.
+func (a *auditRouter) export(w http.ResponseWriter, r *http.Request) {
+	p := paginate.ParseFromRequest(r)
+
+	w.Header().Set("Content-Type", bulkio.NDJSONMediaType)
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-log.ndjson"`)
+	encoder := json.NewEncoder(w)
+	var written bool
+	err := a.auditService.Export(r.Context(), p, func(entry domain.AuditEntry) error {
+		written = true
+		return encoder.Encode(dto.AuditEntryDomainToDTO(entry))
+	})
+	// status is already sent once an entry is written
+	if err != nil && !written {
+		w.Header().Del("Content-Disposition")
+		jsonutil.EncodeError(w, err)
+	}
+}
+
+func (a *auditRouter) verify(w http.ResponseWriter, r *http.Request) {
+	err := a.auditService.Verify(r.Context())
+	if errors.Is(err, domain.ErrAuditChainBroken) {
+		jsonutil.Encode(w, http.StatusOK, dto.VerifyAuditResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusOK, dto.VerifyAuditResponse{Valid: true})
+}
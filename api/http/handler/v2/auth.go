@@ -4,8 +4,10 @@ import (
 	"net/http"
 
 	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
 	"github.com/amirzayi/clean_architect/internal/domain"
 	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
 	"github.com/amirzayi/clean_architect/pkg/jsonutil"
 	"github.com/amirzayi/rahjoo"
 )
@@ -14,15 +16,20 @@ type authRouter struct {
 	authService service.Auth
 }
 
-func AuthRoutes(auth service.Auth) rahjoo.Route {
-	router := &authRouter{authService: auth}
+// AuthRoutes registers and logs in users of organization of X-Tenant-ID header, or the default organization.
+func AuthRoutes(authService service.Auth, authManager auth.Manager, groupService service.Group) rahjoo.Route {
+	router := &authRouter{authService: authService}
 
 	return rahjoo.NewGroupRoute("/v2/auth", rahjoo.Route{
 		"/register": {
-			http.MethodPost: rahjoo.NewHandler(router.register),
+			http.MethodPost: rahjoo.NewHandler(router.register, appmiddleware.Tenant),
 		},
 		"/login": {
-			http.MethodPost: rahjoo.NewHandler(router.login),
+			http.MethodPost: rahjoo.NewHandler(router.login, appmiddleware.Tenant),
+		},
+		"/switch-organization": {
+			http.MethodPost: rahjoo.NewHandler(router.switchOrganization, appmiddleware.MustHaveAtLeastOneRole(
+				authManager, groupService, []domain.UserRole{domain.UserRoleNormal, domain.UserRoleAdmin})),
 		},
 	}) // todo: add throttle middleware
 }
@@ -61,3 +68,18 @@ func (a *authRouter) login(w http.ResponseWriter, r *http.Request) {
 	}
 	_ = jsonutil.Encode(w, http.StatusOK, dto.LoginResponse{Token: token})
 }
+
+func (a *authRouter) switchOrganization(w http.ResponseWriter, r *http.Request) {
+	in, err := jsonutil.DecodeAndValidate[dto.SwitchOrganizationRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	token, err := a.authService.SwitchOrganization(r.Context(), in.OrganizationID)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	_ = jsonutil.Encode(w, http.StatusOK, dto.LoginResponse{Token: token})
+}
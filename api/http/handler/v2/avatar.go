@@ -0,0 +1,119 @@
+package v2
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/amirzayi/rahjoo"
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+)
+
+// avatarFormField is the field of multipart/form-data uploads holding the image.
+const avatarFormField = "avatar"
+
+var errMissingAvatarField = errors.New(`multipart body has no "` + avatarFormField + `" field`)
+
+type avatarRouter struct {
+	avatarService service.Avatar
+}
+
+// AvatarRoutes lets users upload their avatars, admins could change avatars of all users of their organization.
+// Avatars are downloaded by signed urls of FileRoutes.
+func AvatarRoutes(avatarService service.Avatar, authManager auth.Manager, groupService service.Group) rahjoo.Route {
+	avatar := &avatarRouter{avatarService: avatarService}
+	return rahjoo.NewGroupRoute("/v2/users/{id}/avatar", rahjoo.Route{
+		"": {
+			http.MethodPut:    rahjoo.NewHandler(avatar.upload),
+			http.MethodGet:    rahjoo.NewHandler(avatar.urls),
+			http.MethodDelete: rahjoo.NewHandler(avatar.delete),
+		},
+	}.SetMiddleware(
+		appmiddleware.MustHaveAtLeastOneRole(authManager, groupService,
+			[]domain.UserRole{domain.UserRoleNormal, domain.UserRoleAdmin}),
+		appmiddleware.RequestInfo,
+	))
+}
+
+// upload accepts image as the raw body or as the avatar field of a multipart/form-data body,
+// content type of request is not trusted and image type is sniffed from its content.
+func (a *avatarRouter) upload(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.Encode(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var body io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		if body, err = formFile(r, avatarFormField); err != nil {
+			jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+			return
+		}
+	}
+
+	urls, err := a.avatarService.Upload(r.Context(), id, body)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusOK, dto.AvatarURLsDomainToDTO(urls))
+}
+
+// formFile returns content of field of a multipart body without buffering other fields.
+func formFile(r *http.Request, field string) (io.Reader, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errMissingAvatarField
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == field {
+			return part, nil
+		}
+	}
+}
+
+func (a *avatarRouter) urls(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.Encode(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	urls, err := a.avatarService.URLs(r.Context(), id)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusOK, dto.AvatarURLsDomainToDTO(urls))
+}
+
+func (a *avatarRouter) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.Encode(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err = a.avatarService.Delete(r.Context(), id); err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
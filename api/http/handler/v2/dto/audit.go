@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type AuditEntryResponse struct {
+	ID         uuid.UUID            `json:"id"`
+	Sequence   int64                `json:"sequence"`
+	ActorID    *uuid.UUID           `json:"actor_id,omitempty"`
+	ActorRole  string               `json:"actor_role,omitempty"`
+	Action     string               `json:"action"`
+	TargetType string               `json:"target_type"`
+	TargetID   string               `json:"target_id"`
+	Changes    []domain.AuditChange `json:"changes,omitempty"`
+	IP         string               `json:"ip,omitempty"`
+	RequestID  string               `json:"request_id,omitempty"`
+	At         time.Time            `json:"at"`
+	PrevHash   string               `json:"prev_hash,omitempty"`
+	Hash       string               `json:"hash,omitempty"`
+}
+
+func AuditEntryDomainToDTO(e domain.AuditEntry) AuditEntryResponse {
+	resp := AuditEntryResponse{
+		ID:         e.ID,
+		Sequence:   e.Sequence,
+		ActorRole:  e.ActorRole,
+		Action:     string(e.Action),
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Changes:    e.Changes,
+		IP:         e.IP,
+		RequestID:  e.RequestID,
+		At:         e.At,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+	if e.ActorID != uuid.Nil {
+		resp.ActorID = &e.ActorID
+	}
+	return resp
+}
+
+type VerifyAuditResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+type AvatarResponse struct {
+	OriginalURL  string    `json:"original_url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func AvatarURLsDomainToDTO(urls domain.AvatarURLs) AvatarResponse {
+	return AvatarResponse{
+		OriginalURL:  urls.Original,
+		ThumbnailURL: urls.Thumbnail,
+		ExpiresAt:    urls.ExpiresAt,
+	}
+}
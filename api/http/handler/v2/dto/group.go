@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type CreateGroupRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// Roles are granted to all users of group, directly or through nested groups.
+	Roles []string `json:"roles" validate:"dive,oneof=User Admin"`
+}
+
+func (r CreateGroupRequest) ToDomain() domain.Group {
+	roles := make([]domain.UserRole, 0, len(r.Roles))
+	for _, role := range r.Roles {
+		roles = append(roles, domain.UserRole(role))
+	}
+	return domain.Group{Name: r.Name, Roles: roles}
+}
+
+type GroupResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func GroupDomainToDTO(g domain.Group) GroupResponse {
+	roles := make([]string, 0, len(g.Roles))
+	for _, role := range g.Roles {
+		roles = append(roles, string(role))
+	}
+	return GroupResponse{
+		ID:        g.ID,
+		Name:      g.Name,
+		Roles:     roles,
+		CreatedAt: g.CreatedAt,
+		UpdatedAt: g.UpdatedAt,
+	}
+}
+
+type AddGroupMemberRequest struct {
+	Kind     string    `json:"kind" validate:"required,oneof=user group"`
+	MemberID uuid.UUID `json:"member_id" validate:"required"`
+}
+
+type GroupMemberResponse struct {
+	GroupID   uuid.UUID `json:"group_id"`
+	Kind      string    `json:"kind"`
+	MemberID  uuid.UUID `json:"member_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func GroupMemberDomainToDTO(m domain.GroupMember) GroupMemberResponse {
+	return GroupMemberResponse{
+		GroupID:   m.GroupID,
+		Kind:      string(m.Kind),
+		MemberID:  m.MemberID,
+		CreatedAt: m.CreatedAt,
+	}
+}
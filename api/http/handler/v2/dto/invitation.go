@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=User Admin"`
+	// ExpiresAt defaults to configured life time of invitations.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AcceptInvitationRequest struct {
+	Token       string `json:"token" validate:"required"`
+	Name        string `json:"name" validate:"required,max=100"`
+	PhoneNumber string `json:"phone_number" validate:"max=20"`
+	Password    string `json:"password" validate:"required,min=8,max=72"`
+}
+
+func (r AcceptInvitationRequest) ToDomain() domain.User {
+	return domain.User{
+		Name:        r.Name,
+		PhoneNumber: r.PhoneNumber,
+		Password:    r.Password,
+	}
+}
+
+// InvitationResponse never contains token of invitation, which is only sent to its email.
+type InvitationResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	Status    string     `json:"status"`
+	InvitedBy *uuid.UUID `json:"invited_by,omitempty"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	SentAt    time.Time  `json:"sent_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func InvitationDomainToDTO(i domain.Invitation) InvitationResponse {
+	resp := InvitationResponse{
+		ID:        i.ID,
+		Email:     i.Email,
+		Role:      string(i.Role),
+		Status:    string(i.StatusAt(time.Now())),
+		ExpiresAt: i.ExpiresAt,
+		SentAt:    i.SentAt,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+	if i.InvitedBy != uuid.Nil {
+		resp.InvitedBy = &i.InvitedBy
+	}
+	if i.UserID != uuid.Nil {
+		resp.UserID = &i.UserID
+	}
+	return resp
+}
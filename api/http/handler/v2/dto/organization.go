@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type OrganizationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func OrganizationDomainToDTO(o domain.Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        o.ID,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+type SaveMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=User Admin"`
+}
+
+type MembershipResponse struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func MembershipDomainToDTO(m domain.Membership) MembershipResponse {
+	return MembershipResponse{
+		OrganizationID: m.OrganizationID,
+		UserID:         m.UserID,
+		Role:           string(m.Role),
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+type SwitchOrganizationRequest struct {
+	// OrganizationID is nil uuid for the default organization.
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
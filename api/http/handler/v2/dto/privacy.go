@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type PrivacyJobResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Kind        string     `json:"kind"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Status      string     `json:"status"`
+	RequestedBy *uuid.UUID `json:"requested_by,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	// ArchiveSize is the size of archive of completed export jobs in bytes.
+	ArchiveSize int        `json:"archive_size,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+func PrivacyJobDomainToDTO(j domain.PrivacyJob) PrivacyJobResponse {
+	resp := PrivacyJobResponse{
+		ID:          j.ID,
+		Kind:        string(j.Kind),
+		UserID:      j.UserID,
+		Status:      string(j.Status),
+		Error:       j.Error,
+		ArchiveSize: len(j.Archive),
+		CreatedAt:   j.CreatedAt,
+	}
+	if j.RequestedBy != uuid.Nil {
+		resp.RequestedBy = &j.RequestedBy
+	}
+	if !j.StartedAt.IsZero() {
+		resp.StartedAt = &j.StartedAt
+	}
+	if !j.FinishedAt.IsZero() {
+		resp.FinishedAt = &j.FinishedAt
+	}
+	return resp
+}
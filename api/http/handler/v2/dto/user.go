@@ -1,9 +1,15 @@
 package dto
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
 	"github.com/google/uuid"
 )
 
@@ -15,6 +21,10 @@ type UserResponse struct {
 	Status      string    `json:"status"`
 	Role        string    `json:"role"`
 	CreatedAt   time.Time `json:"created_at,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	// Attributes are custom attributes of user by name, see /v2/user-attributes.
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Version    int64          `json:"version"`
 }
 
 type CreateUserRequest struct {
@@ -23,6 +33,8 @@ type CreateUserRequest struct {
 	Email       string `json:"email"`
 	Password    string `json:"password"`
 	Role        string `json:"role"`
+	// Attributes are validated against custom attributes defined for users.
+	Attributes map[string]any `json:"attributes"`
 }
 
 func (r CreateUserRequest) ToDomain() domain.User {
@@ -32,6 +44,7 @@ func (r CreateUserRequest) ToDomain() domain.User {
 		Email:       r.Email,
 		Password:    r.Password,
 		Role:        domain.UserRole(r.Role),
+		Attributes:  r.Attributes,
 	}
 }
 
@@ -41,6 +54,10 @@ type UpdateUserRequest struct {
 	Email       string `json:"email"`
 	Password    string `json:"password"`
 	Role        string `json:"role"`
+	// Attributes replace all custom attributes of user, omitted attributes keep current ones.
+	Attributes map[string]any `json:"attributes"`
+	// Version is optional expected version of user, If-Match header takes precedence.
+	Version int64 `json:"version,omitempty"`
 }
 
 func (r UpdateUserRequest) ToDomain() domain.User {
@@ -50,9 +67,21 @@ func (r UpdateUserRequest) ToDomain() domain.User {
 		Email:       r.Email,
 		Password:    r.Password,
 		Role:        domain.UserRole(r.Role),
+		Attributes:  r.Attributes,
+		Version:     r.Version,
 	}
 }
 
+// HiddenUserFields are fields of users which responses never have, even if they are requested by fields parameter,
+// see jsonutil.Project.
+var HiddenUserFields = []string{"password"}
+
+// ProjectUsers returns json of given user responses, a response or a list of them, having only given fields,
+// e.g. of fields parameter, see jsonutil.Project.
+func ProjectUsers(v any, fields []string) (json.RawMessage, error) {
+	return jsonutil.Project(v, fields, HiddenUserFields...)
+}
+
 func UserDomainToDTO(u domain.User) UserResponse {
 	return UserResponse{
 		ID:          u.ID,
@@ -62,5 +91,112 @@ func UserDomainToDTO(u domain.User) UserResponse {
 		Status:      u.Status.String(),
 		Role:        string(u.Role),
 		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+		Attributes:  u.Attributes,
+		Version:     u.Version,
+	}
+}
+
+// PatchUserDocument is the json document of user which PATCH requests apply on,
+// password is write-only so it is always null in document.
+type PatchUserDocument struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	PhoneNumber string  `json:"phone_number" validate:"max=20"`
+	Email       string  `json:"email" validate:"required,email"`
+	Password    *string `json:"password" validate:"omitempty,min=8,max=72"`
+	// Attributes is an object of custom attributes, merge patches remove attributes by null values.
+	Attributes map[string]any `json:"attributes"`
+}
+
+func NewPatchUserDocument(u domain.User) PatchUserDocument {
+	return PatchUserDocument{
+		Name:        u.Name,
+		PhoneNumber: u.PhoneNumber,
+		Email:       u.Email,
+		Attributes:  u.Attributes,
+	}
+}
+
+// DecodePatchUserDocument decodes and validates a patched user document.
+func DecodePatchUserDocument(b []byte) (PatchUserDocument, error) {
+	var doc PatchUserDocument
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return doc, errs.New(errors.New("patched user must be a json object"), errs.CodeInvalidArgument)
+	}
+	for field := range fields {
+		switch field {
+		case "name", "phone_number", "email", "password", "attributes":
+		case "role":
+			return doc, errs.New(errors.New("role could not be patched, use PUT /v2/users/{id}/role instead"), errs.CodeInvalidArgument)
+		case "status":
+			return doc, errs.New(errors.New("status could not be patched, use ban, unban, activate or deactivate operations instead"), errs.CodeInvalidArgument)
+		default:
+			return doc, errs.New(fmt.Errorf("unknown field %q", field), errs.CodeInvalidArgument)
+		}
+	}
+
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return doc, errs.New(fmt.Errorf("invalid patched user: %w", err), errs.CodeInvalidArgument)
+	}
+	return doc, jsonutil.Validate(doc)
+}
+
+// ToDomain returns changes of document compared to given user.
+func (d PatchUserDocument) ToDomain(u domain.User) domain.UserPatch {
+	var patch domain.UserPatch
+	if d.Name != u.Name {
+		patch.Name = &d.Name
+	}
+	if d.PhoneNumber != u.PhoneNumber {
+		patch.PhoneNumber = &d.PhoneNumber
+	}
+	if d.Email != u.Email {
+		patch.Email = &d.Email
+	}
+	patch.Password = d.Password
+	if len(d.Attributes) > 0 || len(u.Attributes) > 0 {
+		if !reflect.DeepEqual(d.Attributes, u.Attributes) {
+			// non-nil attributes replace all attributes of user
+			patch.Attributes = d.Attributes
+			if patch.Attributes == nil {
+				patch.Attributes = map[string]any{}
+			}
+		}
+	}
+	return patch
+}
+
+type ChangeRoleRequest struct {
+	Role    string `json:"role" validate:"required,oneof=User Admin"`
+	Version int64  `json:"version,omitempty"`
+}
+
+// ChangeStatusRequest is the optional body of ban, unban, activate and deactivate operations.
+type ChangeStatusRequest struct {
+	Reason  string `json:"reason" validate:"max=1024"`
+	Version int64  `json:"version,omitempty"`
+}
+
+type UserStatusTransitionResponse struct {
+	ID      uuid.UUID  `json:"id"`
+	From    string     `json:"from"`
+	To      string     `json:"to"`
+	Reason  string     `json:"reason,omitempty"`
+	ActorID *uuid.UUID `json:"actor_id,omitempty"`
+	At      time.Time  `json:"at"`
+}
+
+func UserStatusTransitionDomainToDTO(t domain.UserStatusTransition) UserStatusTransitionResponse {
+	resp := UserStatusTransitionResponse{
+		ID:     t.ID,
+		From:   t.From.String(),
+		To:     t.To.String(),
+		Reason: t.Reason,
+		At:     t.At,
+	}
+	if t.ActorID != uuid.Nil {
+		resp.ActorID = &t.ActorID
 	}
+	return resp
 }
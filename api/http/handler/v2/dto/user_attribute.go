@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+type CreateUserAttributeRequest struct {
+	Name     string `json:"name" validate:"required,max=63"`
+	Type     string `json:"type" validate:"required,oneof=string number bool date enum"`
+	Required bool   `json:"required"`
+	Unique   bool   `json:"unique"`
+	// Values are allowed values of enum attributes.
+	Values []string `json:"values" validate:"dive,required,max=255"`
+}
+
+func (r CreateUserAttributeRequest) ToDomain() domain.UserAttribute {
+	return domain.UserAttribute{
+		Name:     r.Name,
+		Type:     domain.UserAttributeType(r.Type),
+		Required: r.Required,
+		Unique:   r.Unique,
+		Values:   r.Values,
+	}
+}
+
+type UserAttributeResponse struct {
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Required  bool      `json:"required"`
+	Unique    bool      `json:"unique"`
+	Values    []string  `json:"values,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func UserAttributeDomainToDTO(a domain.UserAttribute) UserAttributeResponse {
+	return UserAttributeResponse{
+		Name:      a.Name,
+		Type:      string(a.Type),
+		Required:  a.Required,
+		Unique:    a.Unique,
+		Values:    a.Values,
+		CreatedAt: a.CreatedAt,
+	}
+}
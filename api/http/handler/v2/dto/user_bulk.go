@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+)
+
+// UserRecordColumns are columns of exported users, passwords are never exported.
+var UserRecordColumns = []string{"id", "name", "phone_number", "email", "role", "status", "created_at", "updated_at"}
+
+// ImportUserRecord is a row of bulk import, unknown columns are ignored.
+type ImportUserRecord struct {
+	Name        string `validate:"required,max=100"`
+	PhoneNumber string `validate:"max=20"`
+	Email       string `validate:"required,email"`
+	Password    string `validate:"required,min=8,max=72"`
+	Role        string `validate:"omitempty,oneof=User Admin"`
+}
+
+// UserFromRecord validates a row of bulk import and returns its user.
+func UserFromRecord(record map[string]string) (domain.User, error) {
+	r := ImportUserRecord{
+		Name:        record["name"],
+		PhoneNumber: record["phone_number"],
+		Email:       record["email"],
+		Password:    record["password"],
+		Role:        record["role"],
+	}
+	if err := jsonutil.Validate(r); err != nil {
+		var appErr *errs.Error
+		if errors.As(err, &appErr) {
+			appErr.Msg = "given row is not valid"
+		}
+		return domain.User{}, err
+	}
+	return domain.User{
+		Name:        r.Name,
+		PhoneNumber: r.PhoneNumber,
+		Email:       r.Email,
+		Password:    r.Password,
+		Role:        domain.UserRole(r.Role),
+	}, nil
+}
+
+func UserToRecord(u domain.User) map[string]string {
+	return map[string]string{
+		"id":           u.ID.String(),
+		"name":         u.Name,
+		"phone_number": u.PhoneNumber,
+		"email":        u.Email,
+		"role":         string(u.Role),
+		"status":       u.Status.String(),
+		"created_at":   u.CreatedAt.UTC().Format(time.RFC3339),
+		"updated_at":   u.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+type ImportUsersResponse struct {
+	Total    int              `json:"total"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	DryRun   bool             `json:"dry_run"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+	Details []any  `json:"details,omitempty"`
+}
+
+// NewImportRowError returns error of a failed row, internal errors are hidden same as error responses.
+func NewImportRowError(line int, err error) ImportRowError {
+	rowErr := ImportRowError{Line: line, Message: err.Error()}
+	var appErr *errs.Error
+	if errors.As(err, &appErr) {
+		rowErr.Message = appErr.Msg
+		rowErr.Details = appErr.Details
+	}
+	return rowErr
+}
@@ -0,0 +1,79 @@
+package v2
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+)
+
+var (
+	errInvalidIfMatch       = errors.New("If-Match header must be a single strong entity tag or *")
+	errPreconditionRequired = errors.New("If-Match header or version is required")
+)
+
+// versionETag returns strong entity tag of given version.
+func versionETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseIfMatch returns version of If-Match header, ok is false when header is absent or "*".
+func parseIfMatch(r *http.Request) (version int64, ok bool, err error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, false, nil
+	}
+
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, false, errInvalidIfMatch
+	}
+	version, err = strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 {
+		return 0, false, errInvalidIfMatch
+	}
+	return version, true, nil
+}
+
+// expectedVersion returns version of If-Match header if given, otherwise version of body.
+func expectedVersion(r *http.Request, bodyVersion int64) (version int64, ifMatch bool, err error) {
+	version, ifMatch, err = parseIfMatch(r)
+	if err != nil {
+		return 0, false, errs.New(err, errs.CodeInvalidArgument)
+	}
+	if !ifMatch {
+		version = bodyVersion
+	}
+	return version, ifMatch, nil
+}
+
+// requiredVersion is expectedVersion of writes which must be conditional, so they never overwrite changes silently,
+// requests with neither If-Match header nor version of body are rejected. If-Match: * applies on any version.
+func requiredVersion(r *http.Request, bodyVersion int64) (version int64, ifMatch bool, err error) {
+	if strings.TrimSpace(r.Header.Get("If-Match")) == "" && bodyVersion == 0 {
+		return 0, false, errs.New(errPreconditionRequired, errs.CodePreconditionRequired)
+	}
+	return expectedVersion(r, bodyVersion)
+}
+
+// preconditionError converts conflict of version given by If-Match header to precondition failed.
+func preconditionError(err error, ifMatch bool) error {
+	if ifMatch && errors.Is(err, domain.ErrConcurrentModification) {
+		return errs.New(err, errs.CodePreconditionFailed)
+	}
+	return err
+}
+
+// matchIfNoneMatch reports whether If-None-Match header matches given entity tag.
+func matchIfNoneMatch(r *http.Request, etag string) bool {
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
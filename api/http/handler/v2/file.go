@@ -0,0 +1,47 @@
+package v2
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/amirzayi/rahjoo"
+
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+)
+
+type fileRouter struct {
+	fileService service.File
+}
+
+// FileRoutes serves files by signed urls of service.File without authentication, e.g. avatars.
+func FileRoutes(fileService service.File) rahjoo.Route {
+	file := &fileRouter{fileService: fileService}
+	return rahjoo.NewGroupRoute("/v2/files", rahjoo.Route{
+		"": {
+			http.MethodGet: rahjoo.NewHandler(file.download),
+		},
+	})
+}
+
+func (f *fileRouter) download(w http.ResponseWriter, r *http.Request) {
+	content, object, err := f.fileService.Open(r.Context(), r.URL.Query())
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	// stored content types are sniffed on upload, browsers must not guess others, e.g. html
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", "inline")
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	if !object.ModifiedAt.IsZero() {
+		w.Header().Set("Last-Modified", object.ModifiedAt.UTC().Format(http.TimeFormat))
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, content)
+}
@@ -0,0 +1,189 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/amirzayi/rahjoo"
+	"github.com/amirzayi/rahjoo/middleware"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+type groupRouter struct {
+	groupService service.Group
+}
+
+// GroupRoutes lets admins manage groups of their organization and see groups of users.
+func GroupRoutes(groupService service.Group, authManager auth.Manager) rahjoo.Route {
+	group := &groupRouter{groupService: groupService}
+	middlewares := []middleware.Middleware{
+		appmiddleware.MustHaveAtLeastOneRole(authManager, groupService, []domain.UserRole{domain.UserRoleAdmin}),
+		appmiddleware.RequestInfo,
+	}
+	return rahjoo.MergeRoutes(
+		rahjoo.NewGroupRoute("/v2/groups", rahjoo.Route{
+			"": {
+				http.MethodGet:  rahjoo.NewHandler(group.list),
+				http.MethodPost: rahjoo.NewHandler(group.create),
+			},
+			"/{id}": {
+				http.MethodGet:    rahjoo.NewHandler(group.get),
+				http.MethodDelete: rahjoo.NewHandler(group.delete),
+			},
+			"/{id}/members": {
+				http.MethodGet:  rahjoo.NewHandler(group.members),
+				http.MethodPost: rahjoo.NewHandler(group.addMember),
+			},
+			"/{id}/members/{kind}/{member_id}": {
+				http.MethodDelete: rahjoo.NewHandler(group.removeMember),
+			},
+		}.SetMiddleware(middlewares...)),
+		rahjoo.NewGroupRoute("/v2/users", rahjoo.Route{
+			"/{id}/groups": {
+				http.MethodGet: rahjoo.NewHandler(group.userGroups),
+			},
+		}.SetMiddleware(middlewares...)),
+	)
+}
+
+func (g *groupRouter) create(w http.ResponseWriter, r *http.Request) {
+	in, err := jsonutil.DecodeAndValidate[dto.CreateGroupRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	group, err := g.groupService.Create(r.Context(), in.ToDomain())
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusCreated, dto.GroupDomainToDTO(group))
+}
+
+func (g *groupRouter) list(w http.ResponseWriter, r *http.Request) {
+	pagination := paginate.ParseFromRequest(r)
+
+	groups, err := g.groupService.List(r.Context(), pagination)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.GroupResponse, 0, len(groups))
+	for _, group := range groups {
+		responses = append(responses, dto.GroupDomainToDTO(group))
+	}
+	paginate.SetLinks(w, r, pagination)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(responses, pagination))
+}
+
+func (g *groupRouter) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	group, err := g.groupService.Get(r.Context(), id)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusOK, dto.GroupDomainToDTO(group))
+}
+
+func (g *groupRouter) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := g.groupService.Delete(r.Context(), id); err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (g *groupRouter) members(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	pagination := paginate.ParseFromRequest(r)
+
+	members, err := g.groupService.Members(r.Context(), id, pagination)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.GroupMemberResponse, 0, len(members))
+	for _, member := range members {
+		responses = append(responses, dto.GroupMemberDomainToDTO(member))
+	}
+	paginate.SetLinks(w, r, pagination)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(responses, pagination))
+}
+
+func (g *groupRouter) addMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	in, err := jsonutil.DecodeAndValidate[dto.AddGroupMemberRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	member, err := g.groupService.AddMember(r.Context(), domain.GroupMember{
+		GroupID:  id,
+		Kind:     domain.GroupMemberKind(in.Kind),
+		MemberID: in.MemberID,
+	})
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusCreated, dto.GroupMemberDomainToDTO(member))
+}
+
+func (g *groupRouter) removeMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := pathUUID(w, r, "member_id")
+	if !ok {
+		return
+	}
+	err := g.groupService.RemoveMember(r.Context(), id, domain.GroupMemberKind(r.PathValue("kind")), memberID)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (g *groupRouter) userGroups(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	groups, err := g.groupService.UserGroups(r.Context(), id)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.GroupResponse, 0, len(groups))
+	for _, group := range groups {
+		responses = append(responses, dto.GroupDomainToDTO(group))
+	}
+	jsonutil.Encode(w, http.StatusOK, responses)
+}
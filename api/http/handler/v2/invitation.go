@@ -0,0 +1,116 @@
+package v2
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/amirzayi/rahjoo"
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+type invitationRouter struct {
+	invitationService service.Invitation
+}
+
+// InvitationRoutes lets admins manage invitations, accepting them needs no authentication but the token.
+func InvitationRoutes(invitationService service.Invitation, authManager auth.Manager,
+	groupService service.Group) rahjoo.Route {
+	invitation := &invitationRouter{invitationService: invitationService}
+	return rahjoo.MergeRoutes(
+		rahjoo.NewGroupRoute("/v2/invitations", rahjoo.Route{
+			"": {
+				http.MethodGet:  rahjoo.NewHandler(invitation.list),
+				http.MethodPost: rahjoo.NewHandler(invitation.create),
+			},
+			"/{id}": {
+				http.MethodGet: rahjoo.NewHandler(invitation.operate(service.Invitation.Get)),
+			},
+			"/{id}/resend": {
+				http.MethodPost: rahjoo.NewHandler(invitation.operate(service.Invitation.Resend)),
+			},
+			"/{id}/revoke": {
+				http.MethodPost: rahjoo.NewHandler(invitation.operate(service.Invitation.Revoke)),
+			},
+		}.SetMiddleware(
+			appmiddleware.MustHaveAtLeastOneRole(authManager, groupService, []domain.UserRole{domain.UserRoleAdmin}),
+			appmiddleware.RequestInfo,
+		)),
+		rahjoo.NewGroupRoute("/v2", rahjoo.Route{
+			"/invitations:accept": {
+				http.MethodPost: rahjoo.NewHandler(invitation.accept, appmiddleware.RequestInfo),
+			},
+		}),
+	)
+}
+
+func (i *invitationRouter) create(w http.ResponseWriter, r *http.Request) {
+	in, err := jsonutil.DecodeAndValidate[dto.CreateInvitationRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	invitation, err := i.invitationService.Invite(r.Context(), in.Email, domain.UserRole(in.Role), in.ExpiresAt)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/v2/invitations/"+invitation.ID.String())
+	jsonutil.Encode(w, http.StatusCreated, dto.InvitationDomainToDTO(invitation))
+}
+
+func (i *invitationRouter) list(w http.ResponseWriter, r *http.Request) {
+	pagination := paginate.ParseFromRequest(r)
+
+	invitations, err := i.invitationService.List(r.Context(), pagination)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, dto.InvitationDomainToDTO(invitation))
+	}
+	paginate.SetLinks(w, r, pagination)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(responses, pagination))
+}
+
+// operate applies given method of invitation service on invitation of path and responds the invitation.
+func (i *invitationRouter) operate(operation func(s service.Invitation, ctx context.Context, id uuid.UUID) (domain.Invitation, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathUUID(w, r, "id")
+		if !ok {
+			return
+		}
+		invitation, err := operation(i.invitationService, r.Context(), id)
+		if err != nil {
+			jsonutil.EncodeError(w, err)
+			return
+		}
+		jsonutil.Encode(w, http.StatusOK, dto.InvitationDomainToDTO(invitation))
+	}
+}
+
+func (i *invitationRouter) accept(w http.ResponseWriter, r *http.Request) {
+	in, err := jsonutil.DecodeAndValidate[dto.AcceptInvitationRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	user, err := i.invitationService.Accept(r.Context(), in.Token, in.ToDomain())
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusCreated, dto.UserDomainToDTO(user))
+}
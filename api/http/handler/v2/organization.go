@@ -0,0 +1,164 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/amirzayi/rahjoo"
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+type organizationRouter struct {
+	organizationService service.Organization
+}
+
+func OrganizationRoutes(organizationService service.Organization, authManager auth.Manager,
+	groupService service.Group) rahjoo.Route {
+	organization := &organizationRouter{organizationService: organizationService}
+	return rahjoo.NewGroupRoute("/v2/organizations", rahjoo.Route{
+		"": {
+			http.MethodGet:  rahjoo.NewHandler(organization.list),
+			http.MethodPost: rahjoo.NewHandler(organization.create),
+		},
+		"/{id}": {
+			http.MethodGet: rahjoo.NewHandler(organization.get),
+		},
+		"/{id}/members": {
+			http.MethodGet: rahjoo.NewHandler(organization.members),
+		},
+		"/{id}/members/{user_id}": {
+			http.MethodPut:    rahjoo.NewHandler(organization.saveMember),
+			http.MethodDelete: rahjoo.NewHandler(organization.removeMember),
+		},
+	}.SetMiddleware(
+		appmiddleware.MustHaveAtLeastOneRole(authManager, groupService, []domain.UserRole{domain.UserRoleAdmin}),
+		appmiddleware.RequestInfo,
+	),
+	)
+}
+
+func (o *organizationRouter) create(w http.ResponseWriter, r *http.Request) {
+	in, err := jsonutil.DecodeAndValidate[dto.CreateOrganizationRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	organization, err := o.organizationService.Create(r.Context(), domain.Organization{Name: in.Name})
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusCreated, dto.OrganizationDomainToDTO(organization))
+}
+
+func (o *organizationRouter) list(w http.ResponseWriter, r *http.Request) {
+	pagination := paginate.ParseFromRequest(r)
+
+	organizations, err := o.organizationService.List(r.Context(), pagination)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.OrganizationResponse, 0, len(organizations))
+	for _, organization := range organizations {
+		responses = append(responses, dto.OrganizationDomainToDTO(organization))
+	}
+	paginate.SetLinks(w, r, pagination)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(responses, pagination))
+}
+
+func (o *organizationRouter) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	organization, err := o.organizationService.Get(r.Context(), id)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusOK, dto.OrganizationDomainToDTO(organization))
+}
+
+func (o *organizationRouter) members(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	pagination := paginate.ParseFromRequest(r)
+
+	members, err := o.organizationService.Members(r.Context(), id, pagination)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.MembershipResponse, 0, len(members))
+	for _, member := range members {
+		responses = append(responses, dto.MembershipDomainToDTO(member))
+	}
+	paginate.SetLinks(w, r, pagination)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(responses, pagination))
+}
+
+func (o *organizationRouter) saveMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := pathUUID(w, r, "user_id")
+	if !ok {
+		return
+	}
+	in, err := jsonutil.DecodeAndValidate[dto.SaveMemberRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	membership, err := o.organizationService.SaveMember(r.Context(), domain.Membership{
+		OrganizationID: id,
+		UserID:         userID,
+		Role:           domain.UserRole(in.Role),
+	})
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusOK, dto.MembershipDomainToDTO(membership))
+}
+
+func (o *organizationRouter) removeMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := pathUUID(w, r, "user_id")
+	if !ok {
+		return
+	}
+	if err := o.organizationService.RemoveMember(r.Context(), id, userID); err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pathUUID parses named path value as uuid, responds 400 if it is invalid.
+func pathUUID(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		jsonutil.Encode(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,127 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/amirzayi/rahjoo"
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+var errArchiveNotAvailable = errors.New("archive is available only for completed export jobs")
+
+type privacyRouter struct {
+	privacyService service.Privacy
+}
+
+func PrivacyRoutes(privacyService service.Privacy, authManager auth.Manager, groupService service.Group) rahjoo.Route {
+	privacy := &privacyRouter{privacyService: privacyService}
+	return rahjoo.NewGroupRoute("/v2", rahjoo.Route{
+		"/users/{id}/privacy-export": {
+			http.MethodPost: rahjoo.NewHandler(privacy.request(service.Privacy.RequestExport)),
+		},
+		"/users/{id}/erasure": {
+			http.MethodPost: rahjoo.NewHandler(privacy.request(service.Privacy.RequestErasure)),
+		},
+		"/privacy-jobs": {
+			http.MethodGet: rahjoo.NewHandler(privacy.list),
+		},
+		"/privacy-jobs/{id}": {
+			http.MethodGet: rahjoo.NewHandler(privacy.get),
+		},
+		"/privacy-jobs/{id}/archive": {
+			http.MethodGet: rahjoo.NewHandler(privacy.archive),
+		},
+	}.SetMiddleware(
+		appmiddleware.MustHaveAtLeastOneRole(authManager, groupService, []domain.UserRole{domain.UserRoleAdmin}),
+		appmiddleware.RequestInfo,
+	),
+	)
+}
+
+// request queues a job of user of path by given method of privacy service and responds 202 with Location of the job.
+func (p *privacyRouter) request(queue func(s service.Privacy, ctx context.Context, userID uuid.UUID) (domain.PrivacyJob, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			jsonutil.Encode(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		job, err := queue(p.privacyService, r.Context(), id)
+		if err != nil {
+			jsonutil.EncodeError(w, err)
+			return
+		}
+		w.Header().Set("Location", "/v2/privacy-jobs/"+job.ID.String())
+		jsonutil.Encode(w, http.StatusAccepted, dto.PrivacyJobDomainToDTO(job))
+	}
+}
+
+func (p *privacyRouter) list(w http.ResponseWriter, r *http.Request) {
+	pagination := paginate.ParseFromRequest(r)
+
+	jobs, err := p.privacyService.Jobs(r.Context(), pagination)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.PrivacyJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		responses = append(responses, dto.PrivacyJobDomainToDTO(job))
+	}
+	paginate.SetLinks(w, r, pagination)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(responses, pagination))
+}
+
+func (p *privacyRouter) get(w http.ResponseWriter, r *http.Request) {
+	job, ok := p.job(w, r)
+	if !ok {
+		return
+	}
+	jsonutil.Encode(w, http.StatusOK, dto.PrivacyJobDomainToDTO(job))
+}
+
+// archive downloads zip archive of a completed export job.
+func (p *privacyRouter) archive(w http.ResponseWriter, r *http.Request) {
+	job, ok := p.job(w, r)
+	if !ok {
+		return
+	}
+	if job.Kind != domain.PrivacyJobExport || job.Status != domain.PrivacyJobCompleted || len(job.Archive) == 0 {
+		jsonutil.EncodeError(w, errs.New(errArchiveNotAvailable, errs.CodeNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Length", strconv.Itoa(len(job.Archive)))
+	w.Header().Set("Content-Disposition", `attachment; filename="user-`+job.UserID.String()+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(job.Archive)
+}
+
+func (p *privacyRouter) job(w http.ResponseWriter, r *http.Request) (domain.PrivacyJob, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.Encode(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return domain.PrivacyJob{}, false
+	}
+	job, err := p.privacyService.Job(r.Context(), id)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return domain.PrivacyJob{}, false
+	}
+	return job, true
+}
@@ -2,6 +2,11 @@ package v2
 
 import (
 	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
 
 	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
@@ -9,6 +14,7 @@ import (
 	"github.com/amirzayi/clean_architect/internal/domain"
 	"github.com/amirzayi/clean_architect/internal/service"
 	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/errs"
 	"github.com/amirzayi/clean_architect/pkg/jsonutil"
 	"github.com/amirzayi/clean_architect/pkg/paginate"
 	"github.com/amirzayi/rahjoo"
@@ -16,24 +22,60 @@ import (
 	"github.com/google/uuid"
 )
 
+// maxPatchSize is the maximum size of PATCH request body.
+const maxPatchSize = 1 << 16
+
 type userRouter struct {
 	userService service.User
 }
 
-func UserRoutes(logMiddleware middleware.Middleware, userService service.User, authManager auth.Manager) rahjoo.Route {
+func UserRoutes(logMiddleware middleware.Middleware, userService service.User, authManager auth.Manager,
+	groupService service.Group) rahjoo.Route {
 	user := &userRouter{userService: userService}
 	return rahjoo.NewGroupRoute("/v2/users", rahjoo.Route{
 		"": {
 			http.MethodGet:  rahjoo.NewHandler(user.list, appmiddleware.GzipCompress(gzip.BestCompression)),
 			http.MethodPost: rahjoo.NewHandler(user.create),
 		},
+		":import": {
+			http.MethodPost: rahjoo.NewHandler(user.importUsers),
+		},
+		":export": {
+			http.MethodGet: rahjoo.NewHandler(user.exportUsers),
+		},
 		"/{id}": {
 			http.MethodGet:    rahjoo.NewHandler(user.get),
 			http.MethodDelete: rahjoo.NewHandler(user.delete),
 			http.MethodPut:    rahjoo.NewHandler(user.update),
+			http.MethodPatch:  rahjoo.NewHandler(user.patch),
+		},
+		"/{id}/role": {
+			http.MethodPut: rahjoo.NewHandler(user.changeRole),
+		},
+		"/{id}/ban": {
+			http.MethodPost: rahjoo.NewHandler(user.changeStatus(service.User.Ban)),
+		},
+		"/{id}/unban": {
+			http.MethodPost: rahjoo.NewHandler(user.changeStatus(service.User.Unban)),
+		},
+		"/{id}/activate": {
+			http.MethodPost: rahjoo.NewHandler(user.changeStatus(service.User.Activate)),
+		},
+		"/{id}/deactivate": {
+			http.MethodPost: rahjoo.NewHandler(user.changeStatus(service.User.Deactivate)),
+		},
+		"/{id}/status-history": {
+			http.MethodGet: rahjoo.NewHandler(user.statusHistory),
+		},
+		"/{id}/restore": {
+			http.MethodPost: rahjoo.NewHandler(user.restore),
+		},
+		"/{id}/purge": {
+			http.MethodDelete: rahjoo.NewHandler(user.purge),
 		},
 	}.SetMiddleware(
-		appmiddleware.MustHaveAtLeastOneRole(authManager, []domain.UserRole{domain.UserRoleAdmin}),
+		appmiddleware.MustHaveAtLeastOneRole(authManager, groupService, []domain.UserRole{domain.UserRoleAdmin}),
+		appmiddleware.RequestInfo,
 	),
 	)
 }
@@ -43,7 +85,7 @@ func (u *userRouter) list(w http.ResponseWriter, r *http.Request) {
 
 	users, err := u.userService.List(r.Context(), p)
 	if err != nil {
-		jsonutil.Encode(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		jsonutil.EncodeError(w, err)
 		return
 	}
 
@@ -51,11 +93,15 @@ func (u *userRouter) list(w http.ResponseWriter, r *http.Request) {
 	for _, user := range users {
 		userResponses = append(userResponses, dto.UserDomainToDTO(user))
 	}
+	// users are projected by repositories, responses have only their projected fields too
+	data, err := dto.ProjectUsers(userResponses, p.Fields)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
 
-	jsonutil.Encode(w, http.StatusOK, paginate.ListResponse{
-		Data:       userResponses,
-		Pagination: p,
-	})
+	paginate.SetLinks(w, r, p)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(data, p))
 }
 
 func (u *userRouter) create(w http.ResponseWriter, r *http.Request) {
@@ -67,27 +113,34 @@ func (u *userRouter) create(w http.ResponseWriter, r *http.Request) {
 
 	user, err := u.userService.Create(r.Context(), req.ToDomain())
 	if err != nil {
-		jsonutil.Encode(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		jsonutil.EncodeError(w, err)
 		return
 	}
-	jsonutil.Encode(w, http.StatusCreated, user)
+	jsonutil.Encode(w, http.StatusCreated, dto.UserDomainToDTO(user))
 }
 
+// delete applies only on version given by If-Match header, same as update without body,
+// it responds 412 when user has been modified since that version, or 428 without it.
 func (u *userRouter) delete(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	uid, err := uuid.Parse(id)
+	uid, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		jsonutil.Encode(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
 		return
 	}
-	err = u.userService.Delete(r.Context(), uid)
+	version, ifMatch, err := requiredVersion(r, 0)
 	if err != nil {
-		jsonutil.Encode(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	if err = u.userService.Delete(r.Context(), uid, version); err != nil {
+		jsonutil.EncodeError(w, preconditionError(err, ifMatch))
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// update applies only on version given by If-Match header or version field of body
+// and responds 412 or 409 respectively when user has been modified since that version, or 428 without any of them.
 func (u *userRouter) update(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	uid, err := uuid.Parse(id)
@@ -102,14 +155,182 @@ func (u *userRouter) update(w http.ResponseWriter, r *http.Request) {
 	}
 	user := req.ToDomain()
 	user.ID = uid
+	var ifMatch bool
+	user.Version, ifMatch, err = requiredVersion(r, req.Version)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
 	err = u.userService.Update(r.Context(), user)
 	if err != nil {
-		jsonutil.Encode(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		jsonutil.EncodeError(w, preconditionError(err, ifMatch))
 		return
 	}
+	if user.Version != 0 {
+		w.Header().Set("ETag", versionETag(user.Version+1))
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// patch applies json merge patch or json patch on user depends on content type,
+// password is write-only and always null in patched document.
+func (u *userRouter) patch(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+		return
+	}
+	version, ifMatch, err := requiredVersion(r, 0)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	var apply func(doc, patch []byte) ([]byte, error)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case jsonutil.MergePatchMediaType, "application/json":
+		apply = jsonutil.MergePatch
+	case jsonutil.JSONPatchMediaType:
+		apply = jsonutil.JSONPatch
+	default:
+		w.Header().Set("Accept-Patch", jsonutil.MergePatchMediaType+", "+jsonutil.JSONPatchMediaType)
+		jsonutil.Encode(w, http.StatusUnsupportedMediaType, map[string]string{"error": "unsupported patch media type"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+	if err != nil {
+		jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+		return
+	}
+
+	current, err := u.userService.GetByID(r.Context(), uid)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	if ifMatch && version != current.Version {
+		jsonutil.EncodeError(w, errs.New(domain.ErrConcurrentModification, errs.CodePreconditionFailed))
+		return
+	}
+
+	doc, err := json.Marshal(dto.NewPatchUserDocument(current))
+	if err != nil {
+		jsonutil.EncodeError(w, errs.New(err, errs.CodeInternal))
+		return
+	}
+	patched, err := apply(doc, body)
+	if err != nil {
+		code := errs.CodeInvalidArgument
+		if errors.Is(err, jsonutil.ErrPatchTestFailed) {
+			code = errs.CodeConflict
+		}
+		jsonutil.EncodeError(w, errs.New(err, code))
+		return
+	}
+	patchedDoc, err := dto.DecodePatchUserDocument(patched)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	// patch computed on current version, so concurrent changes are not overwritten
+	patch := patchedDoc.ToDomain(current)
+	patch.Version = current.Version
+	user, err := u.userService.Patch(r.Context(), uid, patch)
+	if err != nil {
+		jsonutil.EncodeError(w, preconditionError(err, ifMatch))
+		return
+	}
+	w.Header().Set("ETag", versionETag(user.Version))
+	jsonutil.Encode(w, http.StatusOK, dto.UserDomainToDTO(user))
+}
+
+func (u *userRouter) changeRole(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+		return
+	}
+	req, err := jsonutil.DecodeAndValidate[dto.ChangeRoleRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	version, ifMatch, err := requiredVersion(r, req.Version)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	user, err := u.userService.ChangeRole(r.Context(), uid, domain.UserRole(req.Role), version)
+	if err != nil {
+		jsonutil.EncodeError(w, preconditionError(err, ifMatch))
+		return
+	}
+	w.Header().Set("ETag", versionETag(user.Version))
+	jsonutil.Encode(w, http.StatusOK, dto.UserDomainToDTO(user))
+}
+
+// statusOperation is one of ban, unban, activate or deactivate methods of user service,
+// method expressions are used since routes are also built without services to be listed.
+type statusOperation func(s service.User, ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error)
+
+// changeStatus returns handler of given status operation, request body is optional but version is required
+// by If-Match header or version of body, same as update, so concurrent status changes never overwrite each other.
+func (u *userRouter) changeStatus(operation statusOperation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+			return
+		}
+		var req dto.ChangeStatusRequest
+		if r.ContentLength != 0 {
+			if req, err = jsonutil.DecodeAndValidate[dto.ChangeStatusRequest](r); err != nil {
+				jsonutil.EncodeError(w, err)
+				return
+			}
+		}
+		version, ifMatch, err := requiredVersion(r, req.Version)
+		if err != nil {
+			jsonutil.EncodeError(w, err)
+			return
+		}
+
+		user, err := operation(u.userService, r.Context(), uid, req.Reason, version)
+		if err != nil {
+			jsonutil.EncodeError(w, preconditionError(err, ifMatch))
+			return
+		}
+		w.Header().Set("ETag", versionETag(user.Version))
+		jsonutil.Encode(w, http.StatusOK, dto.UserDomainToDTO(user))
+	}
+}
+
+func (u *userRouter) statusHistory(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+		return
+	}
+	p := paginate.ParseFromRequest(r)
+
+	history, err := u.userService.StatusHistory(r.Context(), uid, p)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	transitions := make([]dto.UserStatusTransitionResponse, 0, len(history))
+	for _, transition := range history {
+		transitions = append(transitions, dto.UserStatusTransitionDomainToDTO(transition))
+	}
+	paginate.SetLinks(w, r, p)
+	jsonutil.Encode(w, http.StatusOK, paginate.NewListResponse(transitions, p))
+}
+
 func (u *userRouter) get(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	uid, err := uuid.Parse(id)
@@ -122,5 +343,50 @@ func (u *userRouter) get(w http.ResponseWriter, r *http.Request) {
 		jsonutil.Encode(w, http.StatusNotFound, map[string]string{"error": err.Error()})
 		return
 	}
-	jsonutil.Encode(w, http.StatusOK, user)
+
+	etag := versionETag(user.Version)
+	w.Header().Set("ETag", etag)
+	if matchIfNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	// fields parameter is a sparse fieldset same as of lists, unknown fields are left out
+	data, err := dto.ProjectUsers(dto.UserDomainToDTO(user), paginate.ParseFromRequest(r).Fields)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusOK, data)
+}
+
+// restore applies only on version of deleted user given by If-Match header, same as delete.
+func (u *userRouter) restore(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+		return
+	}
+	version, ifMatch, err := requiredVersion(r, 0)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	if err = u.userService.Restore(r.Context(), uid, version); err != nil {
+		jsonutil.EncodeError(w, preconditionError(err, ifMatch))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *userRouter) purge(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+		return
+	}
+	if err = u.userService.Purge(r.Context(), uid); err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
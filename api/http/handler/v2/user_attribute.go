@@ -0,0 +1,73 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/amirzayi/rahjoo"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+)
+
+type userAttributeRouter struct {
+	attributeService service.UserAttribute
+}
+
+// UserAttributeRoutes lets admins define custom attributes of users of their organization.
+func UserAttributeRoutes(attributeService service.UserAttribute, authManager auth.Manager,
+	groupService service.Group) rahjoo.Route {
+	attribute := &userAttributeRouter{attributeService: attributeService}
+	return rahjoo.NewGroupRoute("/v2/user-attributes", rahjoo.Route{
+		"": {
+			http.MethodGet:  rahjoo.NewHandler(attribute.list),
+			http.MethodPost: rahjoo.NewHandler(attribute.create),
+		},
+		"/{name}": {
+			http.MethodDelete: rahjoo.NewHandler(attribute.delete),
+		},
+	}.SetMiddleware(
+		appmiddleware.MustHaveAtLeastOneRole(authManager, groupService, []domain.UserRole{domain.UserRoleAdmin}),
+		appmiddleware.RequestInfo,
+	))
+}
+
+func (a *userAttributeRouter) create(w http.ResponseWriter, r *http.Request) {
+	in, err := jsonutil.DecodeAndValidate[dto.CreateUserAttributeRequest](r)
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	attribute, err := a.attributeService.Create(r.Context(), in.ToDomain())
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	jsonutil.Encode(w, http.StatusCreated, dto.UserAttributeDomainToDTO(attribute))
+}
+
+func (a *userAttributeRouter) list(w http.ResponseWriter, r *http.Request) {
+	attributes, err := a.attributeService.List(r.Context())
+	if err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	responses := make([]dto.UserAttributeResponse, 0, len(attributes))
+	for _, attribute := range attributes {
+		responses = append(responses, dto.UserAttributeDomainToDTO(attribute))
+	}
+	jsonutil.Encode(w, http.StatusOK, responses)
+}
+
+func (a *userAttributeRouter) delete(w http.ResponseWriter, r *http.Request) {
+	if err := a.attributeService.Delete(r.Context(), r.PathValue("name")); err != nil {
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,119 @@
+package v2
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/bulkio"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+// maxImportSize is the maximum size of bulk import request body.
+const maxImportSize = 32 << 20
+
+// UserImportRows returns rows of bulk import read from r, malformed and invalid records
+// are returned as failed rows and io errors stop reading.
+func UserImportRows(r bulkio.Reader) iter.Seq2[service.UserImportRow, error] {
+	return func(yield func(service.UserImportRow, error) bool) {
+		for {
+			record, line, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			row := service.UserImportRow{Line: line}
+			var recordErr *bulkio.RecordError
+			switch {
+			case errors.As(err, &recordErr):
+				row.Err = errs.New(recordErr.Err, errs.CodeInvalidArgument)
+			case err != nil:
+				yield(row, err)
+				return
+			default:
+				row.User, row.Err = dto.UserFromRecord(record)
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
+// importUsers creates users of csv or ndjson body by Content-Type, ?dry_run=true only validates them.
+func (u *userRouter) importUsers(w http.ResponseWriter, r *http.Request) {
+	format, err := bulkio.ParseFormat(r.Header.Get("Content-Type"))
+	if err != nil {
+		w.Header().Set("Accept", bulkio.CSVMediaType+", "+bulkio.NDJSONMediaType)
+		jsonutil.Encode(w, http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	reader, _ := bulkio.NewReader(format, http.MaxBytesReader(w, r.Body, maxImportSize))
+	resp := dto.ImportUsersResponse{Errors: []dto.ImportRowError{}}
+	summary, err := u.userService.Import(r.Context(), UserImportRows(reader), dryRun, func(result service.UserImportResult) {
+		if result.Err != nil {
+			resp.Errors = append(resp.Errors, dto.NewImportRowError(result.Line, result.Err))
+		}
+	})
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = errs.New(err, errs.CodeInvalidArgument)
+		}
+		jsonutil.EncodeError(w, err)
+		return
+	}
+
+	resp.Total, resp.Imported, resp.Failed, resp.DryRun = summary.Total, summary.Imported, summary.Failed, summary.DryRun
+	jsonutil.Encode(w, http.StatusOK, resp)
+}
+
+// exportUsers streams users matching pagination filters as csv or ndjson by ?format, defaults to csv.
+func (u *userRouter) exportUsers(w http.ResponseWriter, r *http.Request) {
+	format := bulkio.FormatCSV
+	if name := r.URL.Query().Get("format"); name != "" {
+		var err error
+		if format, err = bulkio.ParseFormat(name); err != nil {
+			jsonutil.EncodeError(w, errs.New(err, errs.CodeInvalidArgument))
+			return
+		}
+	}
+	p := paginate.ParseFromRequest(r)
+	// format is not a filter of users
+	p.Filters = removeFilter(p.Filters, "format")
+
+	writer, _ := bulkio.NewWriter(format, w, dto.UserRecordColumns)
+	w.Header().Set("Content-Type", format.MediaType())
+	w.Header().Set("Content-Disposition", `attachment; filename="users.`+string(format)+`"`)
+
+	var written bool
+	err := u.userService.Export(r.Context(), p, func(user domain.User) error {
+		written = true
+		return writer.Write(dto.UserToRecord(user))
+	})
+	if err != nil && !written {
+		w.Header().Del("Content-Disposition")
+		jsonutil.EncodeError(w, err)
+		return
+	}
+	writer.Flush()
+}
+
+func removeFilter(filters []paginate.Filter, key string) []paginate.Filter {
+	result := filters[:0]
+	for _, filter := range filters {
+		if filter.Key != key {
+			result = append(result, filter)
+		}
+	}
+	return result
+}
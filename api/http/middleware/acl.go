@@ -1,16 +1,29 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/amirzayi/clean_architect/internal/domain"
 	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 	"github.com/golang-jwt/jwt/v5/request"
+	"github.com/google/uuid"
 )
 
+// TenantHeader names the header selecting organization of unauthenticated requests.
+const TenantHeader = "X-Tenant-ID"
+
+// RoleResolver resolves roles granted to user besides its own role, e.g. by its groups.
+type RoleResolver interface {
+	Roles(ctx context.Context, userID uuid.UUID) ([]domain.UserRole, error)
+}
+
 // MustHaveAtLeastOneRole will check request for authorization header
-// and prohibit access if not found any roles.
-func MustHaveAtLeastOneRole(authManager auth.Manager, roles []domain.UserRole) func(next http.Handler) http.Handler {
+// and prohibit access if not found any roles, verified claims and their tenant are added to request context.
+// roles granted to user by its groups are resolved by groups and kept in claims, they are never taken from token.
+func MustHaveAtLeastOneRole(authManager auth.Manager, groups RoleResolver,
+	roles []domain.UserRole) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token, err := request.BearerExtractor{}.ExtractToken(r)
@@ -25,9 +38,19 @@ func MustHaveAtLeastOneRole(authManager auth.Manager, roles []domain.UserRole) f
 				return
 			}
 
+			groupRoles, err := groups.Roles(tenant.ContextWithID(r.Context(), claims.TenantID), claims.UserID)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			claims.Roles = make([]string, 0, len(groupRoles))
+			for _, role := range groupRoles {
+				claims.Roles = append(claims.Roles, string(role))
+			}
+
 			var hasRole bool
 			for _, role := range roles {
-				if claims.UserRole == string(role) {
+				if claims.HasRole(string(role)) {
 					hasRole = true
 				}
 			}
@@ -35,7 +58,26 @@ func MustHaveAtLeastOneRole(authManager auth.Manager, roles []domain.UserRole) f
 				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 				return
 			}
-			next.ServeHTTP(w, r)
+			ctx := tenant.ContextWithID(auth.ContextWithClaims(r.Context(), claims), claims.TenantID)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// Tenant adds organization of X-Tenant-ID header to request context, requests without the header
+// belong to the default organization.
+func Tenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value := r.Header.Get(TenantHeader)
+		if value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			http.Error(w, "invalid "+TenantHeader+" header", http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(tenant.ContextWithID(r.Context(), id)))
+	})
+}
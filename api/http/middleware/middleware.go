@@ -7,7 +7,12 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"path"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
 )
 
 func MeterResponseTime(logger *log.Logger) func(next http.Handler) http.Handler {
@@ -22,6 +27,10 @@ func MeterResponseTime(logger *log.Logger) func(next http.Handler) http.Handler
 	}
 }
 
+// secretFields are redacted from logged request bodies.
+var secretFields = []string{"password", "token", "secret"}
+
+// LogRequestBody logs json body of request, values of secretFields are redacted.
 func LogRequestBody(logger *log.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -38,9 +47,44 @@ func LogRequestBody(logger *log.Logger) func(next http.Handler) http.Handler {
 			if err = json.Unmarshal(b, &rb); err != nil {
 				http.Error(w, err.Error(), http.StatusBadRequest)
 			}
-			logger.Println(rb)
+			logger.Println(redactSecrets(rb))
 			r.Body = io.NopCloser(bytes.NewBuffer(b))
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+func redactSecrets(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if slices.Contains(secretFields, strings.ToLower(key)) {
+				v[key] = domain.AuditRedacted
+				continue
+			}
+			v[key] = redactSecrets(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactSecrets(value)
+		}
+	}
+	return v
+}
+
+// SkipPaths applies mw to all requests except requests of paths matching given patterns of path.Match,
+// eg: EnforceJSON is skipped for endpoints accepting csv bodies or images, like /v2/users/*/avatar
+func SkipPaths(mw func(http.Handler) http.Handler, patterns ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, pattern := range patterns {
+				if matched, _ := path.Match(pattern, r.URL.Path); matched {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
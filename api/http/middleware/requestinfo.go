@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	chim "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/amirzayi/clean_architect/pkg/requestinfo"
+)
+
+// RequestInfo adds client ip and request id to request context, request id is taken from
+// chi RequestID middleware or X-Request-Id header.
+func RequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			// RealIP middleware sets remote address without port
+			ip = r.RemoteAddr
+		}
+		requestID := chim.GetReqID(r.Context())
+		if requestID == "" {
+			requestID = r.Header.Get(chim.RequestIDHeader)
+		}
+		next.ServeHTTP(w, r.WithContext(requestinfo.ContextWithInfo(r.Context(), requestinfo.Info{
+			IP:        ip,
+			RequestID: requestID,
+		})))
+	})
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"flag"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/amirzayi/rahjoo/middleware"
 	"github.com/amirzayi/rahjoo/middleware/cors"
@@ -21,18 +23,20 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/sqlite"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/hkdf"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
 	_ "modernc.org/sqlite"
 
+	appmiddleware "github.com/amirzayi/clean_architect/api/http/middleware"
+	"github.com/amirzayi/clean_architect/infra/migrations"
 	"github.com/amirzayi/clean_architect/internal/delivery"
+	"github.com/amirzayi/clean_architect/internal/domain"
 	"github.com/amirzayi/clean_architect/internal/repository"
 	"github.com/amirzayi/clean_architect/internal/service"
 	"github.com/amirzayi/clean_architect/pkg/auth"
@@ -42,8 +46,12 @@ import (
 	"github.com/amirzayi/clean_architect/pkg/hash"
 	"github.com/amirzayi/clean_architect/pkg/interceptor"
 	"github.com/amirzayi/clean_architect/pkg/logger"
+	"github.com/amirzayi/clean_architect/pkg/notify"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
 	"github.com/amirzayi/clean_architect/pkg/server/grpcserver"
 	"github.com/amirzayi/clean_architect/pkg/server/webserver"
+	"github.com/amirzayi/clean_architect/pkg/storage"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 )
 
 func main() {
@@ -91,11 +99,47 @@ func EventDriver(driver, url string, queues []string) (bus.Driver, error) {
 	}
 }
 
+// NotifierDriver returns driver of notifications, e.g. invitation emails.
+func NotifierDriver(driver string, logger *slog.Logger) notify.Driver {
+	switch driver {
+	// todo: add smtp driver
+	default: // "log", meant for development
+		return notify.NewLogDriver(logger)
+	}
+}
+
+// StorageDriver returns driver of uploaded files, e.g. avatars.
+func StorageDriver(cfg config.AppConfig) (storage.Driver, error) {
+	switch cfg.Storage().Driver() {
+	case "local":
+		return storage.NewLocalDriver(cfg.Storage().Path())
+	case "s3":
+		return storage.NewS3Driver(storage.S3Config{
+			Endpoint:  cfg.Storage().Endpoint(),
+			Region:    cfg.Storage().Region(),
+			Bucket:    cfg.Storage().Bucket(),
+			AccessKey: cfg.Storage().AccessKey(),
+			SecretKey: cfg.Storage().SecretKey(),
+		})
+	default: // files are lost on restart, meant for development
+		return storage.NewInMemoryDriver(), nil
+	}
+}
+
+// DeriveSecret derives a key of given purpose from secret by hkdf, so keys of different purposes
+// are independent of each other while only one secret is configured.
+func DeriveSecret(secret, label string) []byte {
+	key := make([]byte, sha256.Size)
+	// hkdf could derive up to 255 hashes, so reading one never fails
+	io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(label)), key)
+	return key
+}
+
 func run(ctx context.Context, cfg config.AppConfig) error {
 	eventDriver, err := EventDriver(
 		cfg.Event().Driver(),
 		cfg.Event().ConnectionString(),
-		[]string{}, // todo: add some queues
+		[]string{service.UserStatusChangedEvent, service.UserErasedEvent},
 	)
 	if err != nil {
 		return err
@@ -110,21 +154,24 @@ func run(ctx context.Context, cfg config.AppConfig) error {
 		return err
 	}
 
-	db, err := sqlx.Connect(cfg.DB().Driver(), cfg.DB().ConnectionString())
+	storageDriver, err := StorageDriver(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
+		return fmt.Errorf("failed to setup storage: %w", err)
 	}
 
-	driver, err := sqlite.WithInstance(db.DB, &sqlite.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to load database driver: %v", err)
-	}
-	migrator, err := migrate.NewWithDatabaseInstance("file://infra/migrations", "sqlite", driver)
+	db, err := sqlx.Connect(cfg.DB().Driver(), cfg.DB().ConnectionString())
 	if err != nil {
-		return fmt.Errorf("failed to setup migrator: %v", err)
+		return fmt.Errorf("failed to connect database: %w", err)
 	}
-	if err = migrator.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to do migrate: %v", err)
+
+	if cfg.DB().AutoMigrate() {
+		migrator, err := migrations.New(db.DB, cfg.DB().Driver())
+		if err != nil {
+			return err
+		}
+		if err = migrator.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("failed to do migrate: %v", err)
+		}
 	}
 
 	var logWriters []io.Writer
@@ -158,14 +205,28 @@ func run(ctx context.Context, cfg config.AppConfig) error {
 	repos := repository.NewSQLRepositories(db)
 
 	authManager := auth.NewJWT(jwt.SigningMethodHS512, []byte(cfg.Auth().Secret()), cfg.Auth().LifeTime())
+	// cursors of lists stay valid across restarts and instances, they are never signed by the token key itself
+	paginate.SetCursorSecret(DeriveSecret(cfg.Auth().Secret(), "cursor"))
 
 	services := service.NewServices(&service.Dependencies{
-		Repositories: repos,
-		Hasher:       hash.NewBcryptHasher(bcrypt.DefaultCost),
-		AuthManager:  authManager,
-		Cache:        cacheDriver,
-		Event:        eventDriver,
-		Logger:       defaultLogger,
+		Repositories:   repos,
+		Hasher:         hash.NewBcryptHasher(bcrypt.DefaultCost),
+		AuthManager:    authManager,
+		Cache:          cacheDriver,
+		Event:          eventDriver,
+		Logger:         defaultLogger,
+		AuditHashChain: cfg.Audit().HashChain(),
+		Notifier:       NotifierDriver(cfg.Notifier().Driver(), defaultLogger),
+
+		InvitationLifeTime:  cfg.Invitation().LifeTime(),
+		InvitationAcceptURL: cfg.Invitation().AcceptURL(),
+
+		Storage:             storageDriver,
+		FileURLSecret:       []byte(cfg.Storage().URLSecret()),
+		FileURLLifeTime:     cfg.Storage().URLLifeTime(),
+		FileDownloadURL:     cfg.Storage().DownloadURL(),
+		AvatarMaxSize:       cfg.Avatar().MaxSize(),
+		AvatarThumbnailSize: cfg.Avatar().ThumbnailSize(),
 	})
 
 	gwMux := runtime.NewServeMux()
@@ -176,8 +237,10 @@ func run(ctx context.Context, cfg config.AppConfig) error {
 	apiHandler := middleware.Chain(muxHandler,
 		cors.CORSHandler(),
 		chim.Recoverer,
-		middleware.EnforceJSON,
+		// bulk import accepts csv and ndjson bodies, avatar uploads accept images
+		appmiddleware.SkipPaths(middleware.EnforceJSON, "/v2/users:import", "/v2/users/*/avatar"),
 		chim.RealIP,
+		chim.RequestID,
 		chim.Logger,
 	)
 
@@ -215,7 +278,7 @@ func run(ctx context.Context, cfg config.AppConfig) error {
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	}
 
-	delivery.SetupGRPC(grpcServer.Server, services)
+	delivery.SetupGRPC(grpcServer.Server, services, authManager)
 
 	if err = delivery.SetupGRPCGateway(ctx, cfg.GRPC().Address(), gwMux, grpcDialOptions...); err != nil {
 		return err
@@ -224,6 +287,16 @@ func run(ctx context.Context, cfg config.AppConfig) error {
 	exitCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGKILL)
 	defer stop()
 
+	if err = logUserStatusChanges(exitCtx, eventDriver); err != nil {
+		return err
+	}
+
+	go services.Privacy.Run(exitCtx)
+
+	if retention := cfg.DB().DeletedRetention(); retention > 0 {
+		go purgeDeletedUsers(exitCtx, services.User, retention)
+	}
+
 	errCh := make(chan error)
 
 	go func() {
@@ -273,3 +346,58 @@ func run(ctx context.Context, cfg config.AppConfig) error {
 	}
 	return err
 }
+
+// purgeDeletedUsers removes users of all tenants which are soft deleted longer than retention,
+// every hour until ctx is done.
+func purgeDeletedUsers(ctx context.Context, userService service.User, retention time.Duration) {
+	ctx = tenant.ContextWithAllTenants(ctx)
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		// errors are already logged by service
+		if count, err := userService.PurgeDeleted(ctx, retention); err == nil && count > 0 {
+			slog.Info("purged deleted users", slog.Int64("count", count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// logUserStatusChanges logs user status transitions published by user service until ctx is done.
+func logUserStatusChanges(ctx context.Context, eventDriver bus.Driver) error {
+	transitions, errCh, err := bus.New[domain.UserStatusTransition](eventDriver).Subscribe(service.UserStatusChangedEvent)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe user status changes: %w", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case transition, ok := <-transitions:
+				if !ok {
+					return
+				}
+				slog.Info("user status changed",
+					slog.String("user_id", transition.UserID.String()),
+					slog.String("from", transition.From.String()),
+					slog.String("to", transition.To.String()),
+					slog.String("reason", transition.Reason),
+				)
+			case err, ok := <-errCh:
+				if ok {
+					slog.Warn("failed to receive user status change", slog.Any("error", err))
+				} else {
+					errCh = nil
+				}
+			}
+		}
+	}()
+	return nil
+}
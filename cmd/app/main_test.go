@@ -20,3 +20,12 @@ func TestRun(t *testing.T) {
 
 	t.Cleanup(cancel)
 }
+
+func TestDeriveSecret(t *testing.T) {
+	cursor := DeriveSecret("secret", "cursor")
+	require.Len(t, cursor, 32)
+	require.Equal(t, cursor, DeriveSecret("secret", "cursor"))
+	require.NotEqual(t, cursor, DeriveSecret("secret", "file"))
+	require.NotEqual(t, cursor, DeriveSecret("other", "cursor"))
+	require.NotContains(t, string(cursor), "secret")
+}
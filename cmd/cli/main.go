@@ -14,6 +14,8 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(
 		routingCmd,
+		migrateCmd,
+		usersCmd,
 	)
 }
 
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
+
+	"github.com/amirzayi/clean_architect/infra/migrations"
+	"github.com/amirzayi/clean_architect/pkg/config"
+)
+
+var migrateConfigPath string
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "database migrations of configured driver",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up [N]",
+	Short: "apply all or N up migrations",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(m *migrate.Migrate) error {
+			if len(args) == 0 {
+				return m.Up()
+			}
+			n, err := parseSteps(args[0])
+			if err != nil {
+				return err
+			}
+			return m.Steps(n)
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [N]",
+	Short: "apply N down migrations, defaults to 1",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = parseSteps(args[0]); err != nil {
+				return err
+			}
+		}
+		return withMigrator(func(m *migrate.Migrate) error {
+			return m.Steps(-n)
+		})
+	},
+}
+
+var migrateGotoCmd = &cobra.Command{
+	Use:   "goto V",
+	Short: "migrate to version V",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[0], err)
+		}
+		return withMigrator(func(m *migrate.Migrate) error {
+			return m.Migrate(uint(version))
+		})
+	},
+}
+
+var migrateVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "print current migration version",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(m *migrate.Migrate) error {
+			version, dirty, err := m.Version()
+			if errors.Is(err, migrate.ErrNilVersion) {
+				fmt.Println("no migration applied")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("version: %d, dirty: %t\n", version, dirty)
+			return nil
+		})
+	},
+}
+
+var migrateForceCmd = &cobra.Command{
+	Use:   "force V",
+	Short: "set version V without running migrations and clear dirty state",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[0], err)
+		}
+		return withMigrator(func(m *migrate.Migrate) error {
+			return m.Force(version)
+		})
+	},
+}
+
+var migrateCreateDir string
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "create empty up and down migration files for configured driver",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := migrateCreateDir
+		if dir == "" {
+			cfg, err := config.LoadConfig(migrateConfigPath)
+			if err != nil {
+				return err
+			}
+			dialect, err := migrations.Dialect(cfg.DB().Driver())
+			if err != nil {
+				return err
+			}
+			dir = "infra/migrations/" + dialect
+		}
+
+		up, down, err := migrations.Create(dir, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return nil
+	},
+}
+
+func init() {
+	migrateCmd.PersistentFlags().StringVar(&migrateConfigPath, "config", "config.json", "config file path, eg: --config=/path/to/file.json")
+	migrateCreateCmd.Flags().StringVar(&migrateCreateDir, "dir", "", "migrations directory, defaults to infra/migrations/<dialect of configured driver>")
+
+	migrateCmd.AddCommand(
+		migrateUpCmd,
+		migrateDownCmd,
+		migrateGotoCmd,
+		migrateVersionCmd,
+		migrateForceCmd,
+		migrateCreateCmd,
+	)
+}
+
+// withMigrator connects to configured database and passes an embedded migrations migrator to fn.
+func withMigrator(fn func(m *migrate.Migrate) error) error {
+	cfg, err := config.LoadConfig(migrateConfigPath)
+	if err != nil {
+		return err
+	}
+
+	db, err := sqlx.Connect(cfg.DB().Driver(), cfg.DB().ConnectionString())
+	if err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+
+	migrator, err := migrations.New(db.DB, cfg.DB().Driver())
+	if err != nil {
+		db.Close()
+		return err
+	}
+	// closing migrator closes database too
+	defer migrator.Close()
+
+	if err = fn(migrator); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+func parseSteps(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number of migrations %q", arg)
+	}
+	return n, nil
+}
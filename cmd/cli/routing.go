@@ -22,10 +22,19 @@ var routingCmd = &cobra.Command{
 const projectName = "github.com/amirzayi/clean_architect"
 
 func routeList() {
-	userV2Routes := v2.UserRoutes(nil, nil, nil)
-	authV2Routes := v2.AuthRoutes(nil)
+	userV2Routes := v2.UserRoutes(nil, nil, nil, nil)
+	authV2Routes := v2.AuthRoutes(nil, nil, nil)
+	auditV2Routes := v2.AuditRoutes(nil, nil, nil)
+	privacyV2Routes := v2.PrivacyRoutes(nil, nil, nil)
+	organizationV2Routes := v2.OrganizationRoutes(nil, nil, nil)
+	invitationV2Routes := v2.InvitationRoutes(nil, nil, nil)
+	groupV2Routes := v2.GroupRoutes(nil, nil)
+	userAttributeV2Routes := v2.UserAttributeRoutes(nil, nil, nil)
+	avatarV2Routes := v2.AvatarRoutes(nil, nil, nil)
+	fileV2Routes := v2.FileRoutes(nil)
 
-	routes := rahjoo.MergeRoutes(userV2Routes, authV2Routes)
+	routes := rahjoo.MergeRoutes(userV2Routes, authV2Routes, auditV2Routes, privacyV2Routes, organizationV2Routes,
+		invitationV2Routes, groupV2Routes, userAttributeV2Routes, avatarV2Routes, fileV2Routes)
 
 	fmt.Println("--------------------------------------------------")
 	fmt.Println("|  Route  |  Method  |  Handler  |  Middlewares  |")
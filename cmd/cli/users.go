@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/bcrypt"
+
+	v2 "github.com/amirzayi/clean_architect/api/http/handler/v2"
+	"github.com/amirzayi/clean_architect/api/http/handler/v2/dto"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/service"
+	"github.com/amirzayi/clean_architect/pkg/bulkio"
+	"github.com/amirzayi/clean_architect/pkg/bus"
+	"github.com/amirzayi/clean_architect/pkg/cache"
+	"github.com/amirzayi/clean_architect/pkg/config"
+	"github.com/amirzayi/clean_architect/pkg/hash"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+var (
+	usersConfigPath string
+	usersFormat     string
+	usersDryRun     bool
+	usersOutput     string
+	usersQuery      string
+	usersTenant     string
+)
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "bulk import and export of users of configured database",
+}
+
+var usersImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "import users of csv or ndjson FILE, format defaults to extension of FILE",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := bulkio.ParseFormat(cmp.Or(usersFormat, strings.TrimPrefix(filepath.Ext(args[0]), ".")))
+		if err != nil {
+			return err
+		}
+		ctx, err := usersContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return withUserService(func(users service.User) error {
+			reader, _ := bulkio.NewReader(format, f)
+			summary, err := users.Import(ctx, v2.UserImportRows(reader), usersDryRun, func(result service.UserImportResult) {
+				if result.Err != nil {
+					rowErr := dto.NewImportRowError(result.Line, result.Err)
+					fmt.Fprintf(os.Stderr, "line %d: %s %v\n", rowErr.Line, rowErr.Message, rowErr.Details)
+				}
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("total: %d, imported: %d, failed: %d, dry run: %t\n",
+				summary.Total, summary.Imported, summary.Failed, summary.DryRun)
+			return nil
+		})
+	},
+}
+
+var usersExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export users matching --query as csv or ndjson",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := bulkio.ParseFormat(cmp.Or(usersFormat, string(bulkio.FormatCSV)))
+		if err != nil {
+			return err
+		}
+		ctx, err := usersContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		query, err := url.ParseQuery(usersQuery)
+		if err != nil {
+			return fmt.Errorf("invalid query %q: %w", usersQuery, err)
+		}
+		// reuse query parsing of api
+		pagination := paginate.ParseFromRequest(&http.Request{URL: &url.URL{RawQuery: query.Encode()}})
+
+		out := io.Writer(os.Stdout)
+		if usersOutput != "" {
+			f, err := os.Create(usersOutput)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		buffered := bufio.NewWriter(out)
+
+		return withUserService(func(users service.User) error {
+			writer, _ := bulkio.NewWriter(format, buffered, dto.UserRecordColumns)
+			err := users.Export(ctx, pagination, func(user domain.User) error {
+				return writer.Write(dto.UserToRecord(user))
+			})
+			if err != nil {
+				return err
+			}
+			if err = writer.Flush(); err != nil {
+				return err
+			}
+			return buffered.Flush()
+		})
+	},
+}
+
+func init() {
+	usersCmd.PersistentFlags().StringVar(&usersConfigPath, "config", "config.json", "config file path, eg: --config=/path/to/file.json")
+	usersCmd.PersistentFlags().StringVar(&usersFormat, "format", "", "csv or ndjson")
+	usersCmd.PersistentFlags().StringVar(&usersTenant, "tenant", "", "organization id of users, defaults to the default organization")
+	usersImportCmd.Flags().BoolVar(&usersDryRun, "dry-run", false, "validate rows without creating users")
+	usersExportCmd.Flags().StringVarP(&usersOutput, "output", "o", "", "output file, defaults to stdout")
+	usersExportCmd.Flags().StringVar(&usersQuery, "query", "", "filters and sort of api query form, eg: --query='role=Admin&sort=created_at&sort=desc'")
+
+	usersCmd.AddCommand(
+		usersImportCmd,
+		usersExportCmd,
+	)
+}
+
+// withUserService connects to configured database and passes user service to fn,
+// cache and events of cli are kept in memory.
+func withUserService(fn func(users service.User) error) error {
+	cfg, err := config.LoadConfig(usersConfigPath)
+	if err != nil {
+		return err
+	}
+
+	db, err := sqlx.Connect(cfg.DB().Driver(), cfg.DB().ConnectionString())
+	if err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+	defer db.Close()
+
+	eventDriver := bus.NewInMemoryDriver([]string{service.UserStatusChangedEvent, service.UserErasedEvent})
+	services := service.NewServices(&service.Dependencies{
+		Repositories:   repository.NewSQLRepositories(db),
+		Hasher:         hash.NewBcryptHasher(bcrypt.DefaultCost),
+		Cache:          cache.NewInMemoryDriver(),
+		Event:          eventDriver,
+		Logger:         slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		AuditHashChain: cfg.Audit().HashChain(),
+	})
+	return fn(services.User)
+}
+
+// usersContext returns ctx of organization of --tenant flag.
+func usersContext(ctx context.Context) (context.Context, error) {
+	if usersTenant == "" {
+		return ctx, nil
+	}
+	id, err := uuid.Parse(usersTenant)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenant %q: %w", usersTenant, err)
+	}
+	return tenant.ContextWithID(ctx, id), nil
+}
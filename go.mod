@@ -18,7 +18,6 @@ require (
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.19.1
 	github.com/jmoiron/sqlx v1.4.0
 	github.com/lib/pq v1.10.9
-	github.com/mattn/go-sqlite3 v1.14.28
 	github.com/nats-io/nats.go v1.41.2
 	github.com/o1egl/paseto v1.0.0
 	github.com/rabbitmq/amqp091-go v1.10.0
@@ -0,0 +1,120 @@
+// Package migrations embeds sql migrations of every supported dialect into the binary,
+// so migrating does not depend on the working directory.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+const (
+	DialectSQLite   = "sqlite"
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+)
+
+//go:embed sqlite/*.sql postgres/*.sql mysql/*.sql
+var FS embed.FS
+
+// Dialect returns migrations directory name of given database driver.
+func Dialect(driver string) (string, error) {
+	switch driver {
+	case "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	case "postgres", "pgx":
+		return DialectPostgres, nil
+	case "mysql":
+		return DialectMySQL, nil
+	default:
+		return "", fmt.Errorf("migrations are not supported for %q driver", driver)
+	}
+}
+
+// Source returns embedded migrations of given database driver's dialect.
+func Source(driver string) (source.Driver, error) {
+	dialect, err := Dialect(driver)
+	if err != nil {
+		return nil, err
+	}
+	return iofs.New(FS, dialect)
+}
+
+// New returns a migrator which applies embedded migrations on given database.
+// note: closing the migrator will close the database too.
+func New(db *sql.DB, driver string) (*migrate.Migrate, error) {
+	src, err := Source(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect, _ := Dialect(driver)
+
+	var dbDriver database.Driver
+	switch dialect {
+	case DialectSQLite:
+		dbDriver, err = sqlite.WithInstance(db, &sqlite.Config{})
+	case DialectPostgres:
+		dbDriver, err = postgres.WithInstance(db, &postgres.Config{})
+	case DialectMySQL:
+		dbDriver, err = mysql.WithInstance(db, &mysql.Config{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load database driver: %w", err)
+	}
+
+	migrator, err := migrate.NewWithInstance("iofs", src, dialect, dbDriver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup migrator: %w", err)
+	}
+	return migrator, nil
+}
+
+var versionRegex = regexp.MustCompile(`^(\d+)_.+\.sql$`)
+
+// Create writes empty up and down migration files with the next sequential version
+// into given directory and returns their paths.
+func Create(dir, name string) (up, down string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	var last uint64
+	for _, entry := range entries {
+		matches := versionRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(matches[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		last = max(last, version)
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%06d_%s", last+1, name))
+	up, down = base+".up.sql", base+".down.sql"
+	for _, file := range []string{up, down} {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return "", "", err
+		}
+		if err = f.Close(); err != nil {
+			return "", "", err
+		}
+	}
+	return up, down, nil
+}
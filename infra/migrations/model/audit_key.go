@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+// AuditKey keeps key of subject as secret since key is reserved in mysql.
+type AuditKey struct {
+	Subject   string    `db:"subject"`
+	Secret    []byte    `db:"secret"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func ConvertAuditKeyToDomain(k AuditKey) domain.AuditKey {
+	return domain.AuditKey{
+		Subject:   k.Subject,
+		Key:       k.Secret,
+		CreatedAt: k.CreatedAt,
+	}
+}
+
+func ConvertAuditKeyFromDomain(k domain.AuditKey) AuditKey {
+	return AuditKey{
+		Subject:   k.Subject,
+		Secret:    k.Key,
+		CreatedAt: k.CreatedAt,
+	}
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type AuditLog struct {
+	ID         uuid.UUID     `db:"id"`
+	TenantID   uuid.UUID     `db:"tenant_id"`
+	Sequence   int64         `db:"sequence"`
+	ActorID    uuid.NullUUID `db:"actor_id"`
+	ActorRole  string        `db:"actor_role"`
+	Action     string        `db:"action"`
+	TargetType string        `db:"target_type"`
+	TargetID   string        `db:"target_id"`
+	// Changes is json array of domain.AuditChange
+	Changes   string    `db:"changes"`
+	IP        string    `db:"ip"`
+	RequestID string    `db:"request_id"`
+	CreatedAt time.Time `db:"created_at"`
+	PrevHash  string    `db:"prev_hash"`
+	Hash      string    `db:"hash"`
+}
+
+func ConvertAuditLogToDomain(l AuditLog) domain.AuditEntry {
+	var changes []domain.AuditChange
+	_ = json.Unmarshal([]byte(l.Changes), &changes)
+	return domain.AuditEntry{
+		ID:         l.ID,
+		TenantID:   l.TenantID,
+		Sequence:   l.Sequence,
+		ActorID:    l.ActorID.UUID,
+		ActorRole:  l.ActorRole,
+		Action:     domain.AuditAction(l.Action),
+		TargetType: l.TargetType,
+		TargetID:   l.TargetID,
+		Changes:    changes,
+		IP:         l.IP,
+		RequestID:  l.RequestID,
+		At:         l.CreatedAt,
+		PrevHash:   l.PrevHash,
+		Hash:       l.Hash,
+	}
+}
+
+func ConvertAuditLogsToDomains(logs []AuditLog) []domain.AuditEntry {
+	entries := make([]domain.AuditEntry, 0, len(logs))
+	for _, l := range logs {
+		entries = append(entries, ConvertAuditLogToDomain(l))
+	}
+	return entries
+}
+
+// ConvertAuditLogFromDomain converts entry to model, entries without actor saved as null.
+func ConvertAuditLogFromDomain(e domain.AuditEntry) AuditLog {
+	changes, _ := json.Marshal(e.Changes)
+	return AuditLog{
+		ID:         e.ID,
+		TenantID:   e.TenantID,
+		Sequence:   e.Sequence,
+		ActorID:    uuid.NullUUID{UUID: e.ActorID, Valid: e.ActorID != uuid.Nil},
+		ActorRole:  e.ActorRole,
+		Action:     string(e.Action),
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Changes:    string(changes),
+		IP:         e.IP,
+		RequestID:  e.RequestID,
+		CreatedAt:  e.At.UTC(),
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Group keeps roles of group comma separated.
+type Group struct {
+	ID        uuid.UUID `db:"id"`
+	TenantID  uuid.UUID `db:"tenant_id"`
+	Name      string    `db:"name"`
+	Roles     string    `db:"roles"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func ConvertGroupToDomain(g Group) domain.Group {
+	var roles []domain.UserRole
+	for _, role := range strings.Split(g.Roles, ",") {
+		if role != "" {
+			roles = append(roles, domain.UserRole(role))
+		}
+	}
+	return domain.Group{
+		ID:        g.ID,
+		TenantID:  g.TenantID,
+		Name:      g.Name,
+		Roles:     roles,
+		CreatedAt: g.CreatedAt,
+		UpdatedAt: g.UpdatedAt,
+	}
+}
+
+func ConvertGroupsToDomains(groups []Group) []domain.Group {
+	result := make([]domain.Group, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, ConvertGroupToDomain(g))
+	}
+	return result
+}
+
+func ConvertGroupFromDomain(g domain.Group) Group {
+	roles := make([]string, 0, len(g.Roles))
+	for _, role := range g.Roles {
+		roles = append(roles, string(role))
+	}
+	return Group{
+		ID:        g.ID,
+		TenantID:  g.TenantID,
+		Name:      g.Name,
+		Roles:     strings.Join(roles, ","),
+		CreatedAt: g.CreatedAt.UTC(),
+		UpdatedAt: g.UpdatedAt.UTC(),
+	}
+}
+
+type GroupMember struct {
+	GroupID    uuid.UUID `db:"group_id"`
+	TenantID   uuid.UUID `db:"tenant_id"`
+	MemberKind string    `db:"member_kind"`
+	MemberID   uuid.UUID `db:"member_id"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func ConvertGroupMemberToDomain(m GroupMember) domain.GroupMember {
+	return domain.GroupMember{
+		GroupID:   m.GroupID,
+		Kind:      domain.GroupMemberKind(m.MemberKind),
+		MemberID:  m.MemberID,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func ConvertGroupMembersToDomains(members []GroupMember) []domain.GroupMember {
+	result := make([]domain.GroupMember, 0, len(members))
+	for _, m := range members {
+		result = append(result, ConvertGroupMemberToDomain(m))
+	}
+	return result
+}
+
+func ConvertGroupMemberFromDomain(m domain.GroupMember, tenantID uuid.UUID) GroupMember {
+	return GroupMember{
+		GroupID:    m.GroupID,
+		TenantID:   tenantID,
+		MemberKind: string(m.Kind),
+		MemberID:   m.MemberID,
+		CreatedAt:  m.CreatedAt.UTC(),
+	}
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type Invitation struct {
+	ID        uuid.UUID     `db:"id"`
+	TenantID  uuid.UUID     `db:"tenant_id"`
+	Email     string        `db:"email"`
+	Role      string        `db:"role"`
+	Status    string        `db:"status"`
+	TokenHash string        `db:"token_hash"`
+	InvitedBy uuid.NullUUID `db:"invited_by"`
+	UserID    uuid.NullUUID `db:"user_id"`
+	ExpiresAt time.Time     `db:"expires_at"`
+	SentAt    time.Time     `db:"sent_at"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
+}
+
+func ConvertInvitationToDomain(i Invitation) domain.Invitation {
+	return domain.Invitation{
+		ID:        i.ID,
+		TenantID:  i.TenantID,
+		Email:     i.Email,
+		Role:      domain.UserRole(i.Role),
+		Status:    domain.InvitationStatus(i.Status),
+		TokenHash: i.TokenHash,
+		InvitedBy: i.InvitedBy.UUID,
+		UserID:    i.UserID.UUID,
+		ExpiresAt: i.ExpiresAt,
+		SentAt:    i.SentAt,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+}
+
+func ConvertInvitationsToDomains(invitations []Invitation) []domain.Invitation {
+	result := make([]domain.Invitation, 0, len(invitations))
+	for _, i := range invitations {
+		result = append(result, ConvertInvitationToDomain(i))
+	}
+	return result
+}
+
+// ConvertInvitationFromDomain converts invitation to model, absent inviter and user saved as null.
+func ConvertInvitationFromDomain(i domain.Invitation) Invitation {
+	return Invitation{
+		ID:        i.ID,
+		TenantID:  i.TenantID,
+		Email:     i.Email,
+		Role:      string(i.Role),
+		Status:    string(i.Status),
+		TokenHash: i.TokenHash,
+		InvitedBy: uuid.NullUUID{UUID: i.InvitedBy, Valid: i.InvitedBy != uuid.Nil},
+		UserID:    uuid.NullUUID{UUID: i.UserID, Valid: i.UserID != uuid.Nil},
+		ExpiresAt: i.ExpiresAt.UTC(),
+		SentAt:    i.SentAt.UTC(),
+		CreatedAt: i.CreatedAt.UTC(),
+		UpdatedAt: i.UpdatedAt.UTC(),
+	}
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type Organization struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func ConvertOrganizationToDomain(o Organization) domain.Organization {
+	return domain.Organization{
+		ID:        o.ID,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+func ConvertOrganizationsToDomains(organizations []Organization) []domain.Organization {
+	result := make([]domain.Organization, 0, len(organizations))
+	for _, o := range organizations {
+		result = append(result, ConvertOrganizationToDomain(o))
+	}
+	return result
+}
+
+func ConvertOrganizationFromDomain(o domain.Organization) Organization {
+	return Organization{
+		ID:        o.ID,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt.UTC(),
+		UpdatedAt: o.UpdatedAt.UTC(),
+	}
+}
+
+type OrganizationMember struct {
+	OrganizationID uuid.UUID `db:"organization_id"`
+	UserID         uuid.UUID `db:"user_id"`
+	Role           string    `db:"role"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+func ConvertOrganizationMemberToDomain(m OrganizationMember) domain.Membership {
+	return domain.Membership{
+		OrganizationID: m.OrganizationID,
+		UserID:         m.UserID,
+		Role:           domain.UserRole(m.Role),
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+func ConvertOrganizationMembersToDomains(members []OrganizationMember) []domain.Membership {
+	result := make([]domain.Membership, 0, len(members))
+	for _, m := range members {
+		result = append(result, ConvertOrganizationMemberToDomain(m))
+	}
+	return result
+}
+
+func ConvertOrganizationMemberFromDomain(m domain.Membership) OrganizationMember {
+	return OrganizationMember{
+		OrganizationID: m.OrganizationID,
+		UserID:         m.UserID,
+		Role:           string(m.Role),
+		CreatedAt:      m.CreatedAt.UTC(),
+	}
+}
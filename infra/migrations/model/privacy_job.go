@@ -0,0 +1,64 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type PrivacyJob struct {
+	ID          uuid.UUID     `db:"id"`
+	TenantID    uuid.UUID     `db:"tenant_id"`
+	Kind        string        `db:"kind"`
+	UserID      uuid.UUID     `db:"user_id"`
+	Status      string        `db:"status"`
+	RequestedBy uuid.NullUUID `db:"requested_by"`
+	Error       string        `db:"error"`
+	Archive     []byte        `db:"archive"`
+	CreatedAt   time.Time     `db:"created_at"`
+	StartedAt   sql.NullTime  `db:"started_at"`
+	FinishedAt  sql.NullTime  `db:"finished_at"`
+}
+
+func ConvertPrivacyJobToDomain(j PrivacyJob) domain.PrivacyJob {
+	return domain.PrivacyJob{
+		ID:          j.ID,
+		TenantID:    j.TenantID,
+		Kind:        domain.PrivacyJobKind(j.Kind),
+		UserID:      j.UserID,
+		Status:      domain.PrivacyJobStatus(j.Status),
+		RequestedBy: j.RequestedBy.UUID,
+		Error:       j.Error,
+		Archive:     j.Archive,
+		CreatedAt:   j.CreatedAt,
+		StartedAt:   j.StartedAt.Time,
+		FinishedAt:  j.FinishedAt.Time,
+	}
+}
+
+func ConvertPrivacyJobsToDomains(jobs []PrivacyJob) []domain.PrivacyJob {
+	result := make([]domain.PrivacyJob, 0, len(jobs))
+	for _, j := range jobs {
+		result = append(result, ConvertPrivacyJobToDomain(j))
+	}
+	return result
+}
+
+// ConvertPrivacyJobFromDomain converts job to model, zero times and absent requester saved as null.
+func ConvertPrivacyJobFromDomain(j domain.PrivacyJob) PrivacyJob {
+	return PrivacyJob{
+		ID:          j.ID,
+		TenantID:    j.TenantID,
+		Kind:        string(j.Kind),
+		UserID:      j.UserID,
+		Status:      string(j.Status),
+		RequestedBy: uuid.NullUUID{UUID: j.RequestedBy, Valid: j.RequestedBy != uuid.Nil},
+		Error:       j.Error,
+		Archive:     j.Archive,
+		CreatedAt:   j.CreatedAt.UTC(),
+		StartedAt:   sql.NullTime{Time: j.StartedAt.UTC(), Valid: !j.StartedAt.IsZero()},
+		FinishedAt:  sql.NullTime{Time: j.FinishedAt.UTC(), Valid: !j.FinishedAt.IsZero()},
+	}
+}
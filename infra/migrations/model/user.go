@@ -1,6 +1,8 @@
 package model
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/amirzayi/clean_architect/internal/domain"
@@ -8,30 +10,67 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID `db:"id"`
-	Name      string    `db:"name"`
-	Phone     string    `db:"phone"`
-	Email     string    `db:"email"`
-	Password  string    `db:"password"`
-	Status    int       `db:"status"`
-	Role      string    `db:"role"`
-	CreatedAt string    `db:"created_at"`
+	ID        uuid.UUID      `db:"id"`
+	TenantID  uuid.UUID      `db:"tenant_id"`
+	Name      string         `db:"name"`
+	Phone     sql.NullString `db:"phone"`
+	Email     string         `db:"email"`
+	Password  string         `db:"password"`
+	Status    int            `db:"status"`
+	Role      string         `db:"role"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+	DeletedAt sql.NullTime   `db:"deleted_at"`
+	// Attributes holds custom attributes as json object, null if user has none.
+	Attributes sql.NullString `db:"attributes"`
+	Version    int64          `db:"version"`
 }
 
 func ConvertUserToDomain(user User) domain.User {
-	createdAt, _ := time.Parse(time.RFC3339, user.CreatedAt)
 	return domain.User{
 		ID:          user.ID,
+		TenantID:    user.TenantID,
 		Name:        user.Name,
-		PhoneNumber: user.Phone,
+		PhoneNumber: user.Phone.String,
 		Email:       user.Email,
 		Password:    user.Password,
 		Status:      domain.UserStatus(user.Status),
 		Role:        domain.UserRole(user.Role),
-		CreatedAt:   createdAt,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		DeletedAt:   user.DeletedAt.Time,
+		Attributes:  ConvertAttributeValuesToDomain(user.Attributes),
+		Version:     user.Version,
 	}
 }
 
+// ConvertAttributeValuesToDomain decodes json object of attributes, numbers are decoded as float64 same as domain.UserAttribute.Normalize.
+func ConvertAttributeValuesToDomain(attributes sql.NullString) map[string]any {
+	if !attributes.Valid {
+		return nil
+	}
+	var result map[string]any
+	// column is written only by ConvertAttributeValuesFromDomain, so it is always a valid object
+	_ = json.Unmarshal([]byte(attributes.String), &result)
+	if len(result) == 0 {
+		// all attributes may have been removed by deleting their definitions
+		return nil
+	}
+	return result
+}
+
+// ConvertAttributeValuesFromDomain encodes attributes as json object, empty attributes are saved as null.
+func ConvertAttributeValuesFromDomain(attributes map[string]any) sql.NullString {
+	if len(attributes) == 0 {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
 func ConvertUsersToDomains(users []User) []domain.User {
 	userDomains := make([]domain.User, 0, len(users))
 	for _, user := range users {
@@ -39,3 +78,23 @@ func ConvertUsersToDomains(users []User) []domain.User {
 	}
 	return userDomains
 }
+
+// ConvertUserFromDomain converts domain.User to model, empty phone number saved as null
+// so unique index of phone does not apply on users without phone number.
+func ConvertUserFromDomain(user domain.User) User {
+	return User{
+		ID:         user.ID,
+		TenantID:   user.TenantID,
+		Name:       user.Name,
+		Phone:      sql.NullString{String: user.PhoneNumber, Valid: user.PhoneNumber != ""},
+		Email:      user.Email,
+		Password:   user.Password,
+		Status:     int(user.Status),
+		Role:       string(user.Role),
+		CreatedAt:  user.CreatedAt.UTC(),
+		UpdatedAt:  user.UpdatedAt.UTC(),
+		DeletedAt:  sql.NullTime{Time: user.DeletedAt.UTC(), Valid: !user.DeletedAt.IsZero()},
+		Attributes: ConvertAttributeValuesFromDomain(user.Attributes),
+		Version:    user.Version,
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+// UserAttribute keeps values of enum attribute as json array.
+type UserAttribute struct {
+	TenantID  uuid.UUID `db:"tenant_id"`
+	Name      string    `db:"name"`
+	Type      string    `db:"type"`
+	Required  bool      `db:"is_required"`
+	Unique    bool      `db:"is_unique"`
+	Values    string    `db:"enum_values"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func ConvertUserAttributeToDomain(a UserAttribute) domain.UserAttribute {
+	var values []string
+	_ = json.Unmarshal([]byte(a.Values), &values)
+	return domain.UserAttribute{
+		TenantID:  a.TenantID,
+		Name:      a.Name,
+		Type:      domain.UserAttributeType(a.Type),
+		Required:  a.Required,
+		Unique:    a.Unique,
+		Values:    values,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+func ConvertUserAttributesToDomains(attributes []UserAttribute) []domain.UserAttribute {
+	result := make([]domain.UserAttribute, 0, len(attributes))
+	for _, a := range attributes {
+		result = append(result, ConvertUserAttributeToDomain(a))
+	}
+	return result
+}
+
+func ConvertUserAttributeFromDomain(a domain.UserAttribute) UserAttribute {
+	values, _ := json.Marshal(a.Values)
+	if a.Values == nil {
+		values = []byte("[]")
+	}
+	return UserAttribute{
+		TenantID:  a.TenantID,
+		Name:      a.Name,
+		Type:      string(a.Type),
+		Required:  a.Required,
+		Unique:    a.Unique,
+		Values:    string(values),
+		CreatedAt: a.CreatedAt.UTC(),
+	}
+}
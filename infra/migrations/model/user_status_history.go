@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/google/uuid"
+)
+
+type UserStatusHistory struct {
+	ID         uuid.UUID     `db:"id"`
+	TenantID   uuid.UUID     `db:"tenant_id"`
+	UserID     uuid.UUID     `db:"user_id"`
+	FromStatus int           `db:"from_status"`
+	ToStatus   int           `db:"to_status"`
+	Reason     string        `db:"reason"`
+	ActorID    uuid.NullUUID `db:"actor_id"`
+	CreatedAt  time.Time     `db:"created_at"`
+}
+
+func ConvertUserStatusHistoryToDomain(h UserStatusHistory) domain.UserStatusTransition {
+	return domain.UserStatusTransition{
+		ID:      h.ID,
+		UserID:  h.UserID,
+		From:    domain.UserStatus(h.FromStatus),
+		To:      domain.UserStatus(h.ToStatus),
+		Reason:  h.Reason,
+		ActorID: h.ActorID.UUID,
+		At:      h.CreatedAt,
+	}
+}
+
+func ConvertUserStatusHistoriesToDomains(histories []UserStatusHistory) []domain.UserStatusTransition {
+	transitions := make([]domain.UserStatusTransition, 0, len(histories))
+	for _, h := range histories {
+		transitions = append(transitions, ConvertUserStatusHistoryToDomain(h))
+	}
+	return transitions
+}
+
+// ConvertUserStatusHistoryFromDomain converts transition to model, system changes without actor saved as null.
+func ConvertUserStatusHistoryFromDomain(t domain.UserStatusTransition) UserStatusHistory {
+	return UserStatusHistory{
+		ID:         t.ID,
+		UserID:     t.UserID,
+		FromStatus: int(t.From),
+		ToStatus:   int(t.To),
+		Reason:     t.Reason,
+		ActorID:    uuid.NullUUID{UUID: t.ActorID, Valid: t.ActorID != uuid.Nil},
+		CreatedAt:  t.At.UTC(),
+	}
+}
@@ -0,0 +1,23 @@
+ALTER TABLE `user`
+  DROP INDEX user_phone_unique,
+  DROP INDEX user_email_unique,
+  DROP PRIMARY KEY,
+  DROP COLUMN deleted_at,
+  DROP COLUMN updated_at,
+  ADD COLUMN created_at_tmp text NULL;
+
+UPDATE `user` SET
+  created_at_tmp = date_format(created_at, '%Y-%m-%dT%H:%i:%sZ'),
+  phone = coalesce(phone, '');
+
+ALTER TABLE `user` DROP COLUMN created_at;
+
+ALTER TABLE `user`
+  CHANGE COLUMN created_at_tmp created_at text NULL,
+  MODIFY id text NULL,
+  MODIFY name text NULL,
+  MODIFY phone text NULL,
+  MODIFY email text NULL,
+  MODIFY password text NULL,
+  MODIFY status integer NULL,
+  MODIFY role text NULL;
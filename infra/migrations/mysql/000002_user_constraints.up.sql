@@ -0,0 +1,38 @@
+UPDATE `user` SET
+  name = coalesce(name, ''),
+  phone = nullif(phone, ''),
+  password = coalesce(password, ''),
+  status = coalesce(status, 1),
+  role = coalesce(role, 'User');
+
+ALTER TABLE `user`
+  MODIFY id char(36) NOT NULL,
+  MODIFY name varchar(255) NOT NULL DEFAULT '',
+  MODIFY phone varchar(32) NULL,
+  MODIFY email varchar(255) NOT NULL,
+  MODIFY password varchar(255) NOT NULL,
+  MODIFY status tinyint unsigned NOT NULL,
+  MODIFY role varchar(32) NOT NULL,
+  ADD COLUMN created_at_tmp datetime(6) NULL,
+  ADD COLUMN updated_at datetime(6) NULL,
+  ADD COLUMN deleted_at datetime(6) NULL,
+  ADD PRIMARY KEY (id),
+  ADD UNIQUE INDEX user_email_unique (email),
+  ADD UNIQUE INDEX user_phone_unique (phone);
+
+-- created_at was saved as RFC3339 text, e.g. 2024-01-02T15:04:05+03:30, it is converted to utc by its offset.
+-- values which could not be parsed are left null, so the migration fails below before the text column is dropped.
+UPDATE `user` SET
+  created_at_tmp = convert_tz(
+    str_to_date(left(created_at, 19), '%Y-%m-%dT%H:%i:%s'),
+    CASE substr(created_at, 20) WHEN 'Z' THEN '+00:00' ELSE substr(created_at, 20) END,
+    '+00:00'),
+  updated_at = created_at_tmp;
+
+ALTER TABLE `user`
+  MODIFY created_at_tmp datetime(6) NOT NULL,
+  MODIFY updated_at datetime(6) NOT NULL;
+
+ALTER TABLE `user`
+  DROP COLUMN created_at,
+  CHANGE COLUMN created_at_tmp created_at datetime(6) NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN version bigint unsigned NOT NULL DEFAULT 1;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE user_status_history (
+  id          char(36)         NOT NULL PRIMARY KEY,
+  user_id     char(36)         NOT NULL,
+  from_status tinyint unsigned NOT NULL,
+  to_status   tinyint unsigned NOT NULL,
+  reason      varchar(1024)    NOT NULL DEFAULT '',
+  actor_id    char(36)         NULL,
+  created_at  datetime(6)      NOT NULL,
+  INDEX user_status_history_user_id (user_id, created_at)
+);
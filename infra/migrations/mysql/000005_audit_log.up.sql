@@ -0,0 +1,18 @@
+CREATE TABLE audit_log (
+  id          char(36)     NOT NULL PRIMARY KEY,
+  sequence    bigint       NOT NULL,
+  actor_id    char(36)     NULL,
+  actor_role  varchar(20)  NOT NULL DEFAULT '',
+  action      varchar(50)  NOT NULL,
+  target_type varchar(50)  NOT NULL,
+  target_id   varchar(100) NOT NULL,
+  changes     text         NOT NULL,
+  ip          varchar(45)  NOT NULL DEFAULT '',
+  request_id  varchar(100) NOT NULL DEFAULT '',
+  created_at  datetime(6)  NOT NULL,
+  prev_hash   varchar(64)  NOT NULL DEFAULT '',
+  hash        varchar(64)  NOT NULL DEFAULT '',
+  UNIQUE INDEX audit_log_sequence (sequence),
+  INDEX audit_log_target (target_type, target_id),
+  INDEX audit_log_actor_id (actor_id)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE privacy_job (
+  id           char(36)    NOT NULL PRIMARY KEY,
+  kind         varchar(20) NOT NULL,
+  user_id      char(36)    NOT NULL,
+  status       varchar(20) NOT NULL,
+  requested_by char(36)    NULL,
+  error        text        NOT NULL,
+  archive      longblob    NULL,
+  created_at   datetime(6) NOT NULL,
+  started_at   datetime(6) NULL,
+  finished_at  datetime(6) NULL,
+  INDEX privacy_job_user_id (user_id),
+  INDEX privacy_job_status (status)
+);
@@ -0,0 +1,19 @@
+ALTER TABLE privacy_job
+  DROP INDEX privacy_job_tenant_id,
+  DROP COLUMN tenant_id;
+
+ALTER TABLE audit_log
+  DROP INDEX audit_log_tenant_id,
+  DROP COLUMN tenant_id;
+
+ALTER TABLE user_status_history DROP COLUMN tenant_id;
+
+ALTER TABLE `user`
+  DROP INDEX user_phone_unique,
+  DROP INDEX user_email_unique,
+  DROP COLUMN tenant_id,
+  ADD UNIQUE INDEX user_email_unique (email),
+  ADD UNIQUE INDEX user_phone_unique (phone);
+
+DROP TABLE organization_member;
+DROP TABLE organization;
@@ -0,0 +1,38 @@
+CREATE TABLE organization (
+  id         char(36)     NOT NULL PRIMARY KEY,
+  name       varchar(255) NOT NULL,
+  created_at datetime(6)  NOT NULL,
+  updated_at datetime(6)  NOT NULL,
+  UNIQUE INDEX organization_name_unique (name)
+);
+
+-- the default organization owns data created before organizations.
+INSERT INTO organization (id, name, created_at, updated_at)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default', utc_timestamp(6), utc_timestamp(6));
+
+CREATE TABLE organization_member (
+  organization_id char(36)    NOT NULL,
+  user_id         char(36)    NOT NULL,
+  role            varchar(32) NOT NULL,
+  created_at      datetime(6) NOT NULL,
+  PRIMARY KEY (organization_id, user_id),
+  INDEX organization_member_user_id (user_id)
+);
+
+ALTER TABLE `user`
+  ADD COLUMN tenant_id char(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+  DROP INDEX user_email_unique,
+  DROP INDEX user_phone_unique,
+  ADD UNIQUE INDEX user_email_unique (tenant_id, email),
+  ADD UNIQUE INDEX user_phone_unique (tenant_id, phone);
+
+ALTER TABLE user_status_history
+  ADD COLUMN tenant_id char(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+ALTER TABLE audit_log
+  ADD COLUMN tenant_id char(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+  ADD INDEX audit_log_tenant_id (tenant_id, sequence);
+
+ALTER TABLE privacy_job
+  ADD COLUMN tenant_id char(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+  ADD INDEX privacy_job_tenant_id (tenant_id);
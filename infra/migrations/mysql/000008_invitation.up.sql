@@ -0,0 +1,16 @@
+CREATE TABLE invitation (
+  id         char(36)     NOT NULL PRIMARY KEY,
+  tenant_id  char(36)     NOT NULL,
+  email      varchar(255) NOT NULL,
+  role       varchar(20)  NOT NULL,
+  status     varchar(20)  NOT NULL,
+  token_hash char(64)     NOT NULL,
+  invited_by char(36)     NULL,
+  user_id    char(36)     NULL,
+  expires_at datetime(6)  NOT NULL,
+  sent_at    datetime(6)  NOT NULL,
+  created_at datetime(6)  NOT NULL,
+  updated_at datetime(6)  NOT NULL,
+  UNIQUE INDEX invitation_token_hash (token_hash),
+  INDEX invitation_tenant_id_email (tenant_id, email)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE user_group (
+  id         char(36)     NOT NULL PRIMARY KEY,
+  tenant_id  char(36)     NOT NULL,
+  name       varchar(255) NOT NULL,
+  roles      varchar(255) NOT NULL,
+  created_at datetime(6)  NOT NULL,
+  updated_at datetime(6)  NOT NULL,
+  UNIQUE INDEX user_group_name_unique (tenant_id, name)
+);
+
+CREATE TABLE user_group_member (
+  group_id    char(36)    NOT NULL,
+  tenant_id   char(36)    NOT NULL,
+  member_kind varchar(20) NOT NULL,
+  member_id   char(36)    NOT NULL,
+  created_at  datetime(6) NOT NULL,
+  PRIMARY KEY (group_id, member_kind, member_id),
+  INDEX user_group_member_member (tenant_id, member_kind, member_id)
+);
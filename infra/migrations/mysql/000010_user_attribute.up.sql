@@ -0,0 +1,12 @@
+CREATE TABLE user_attribute (
+  tenant_id    char(36)     NOT NULL,
+  name         varchar(63)  NOT NULL,
+  type         varchar(20)  NOT NULL,
+  is_required  boolean      NOT NULL,
+  is_unique    boolean      NOT NULL,
+  enum_values  text         NOT NULL,
+  created_at   datetime(6)  NOT NULL,
+  PRIMARY KEY (tenant_id, name)
+);
+
+ALTER TABLE `user` ADD COLUMN attributes json NULL;
@@ -0,0 +1 @@
+ALTER TABLE `user` DROP INDEX user_search;
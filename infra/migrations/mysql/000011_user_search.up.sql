@@ -0,0 +1,2 @@
+-- full-text index of users searched by sqlutil.SearchIndex.
+ALTER TABLE `user` ADD FULLTEXT INDEX user_search (name, email);
@@ -0,0 +1,5 @@
+CREATE TABLE audit_key (
+  subject     varchar(100)   NOT NULL PRIMARY KEY,
+  secret      varbinary(64)  NOT NULL,
+  created_at  datetime(6)    NOT NULL
+);
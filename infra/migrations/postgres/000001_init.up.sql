@@ -0,0 +1,10 @@
+CREATE TABLE "user" (
+  id         text,
+  name       text,
+  phone      text,
+  email      text,
+  password   text,
+  status     integer,
+  role       text,
+  created_at text
+);
\ No newline at end of file
@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS user_phone_unique;
+DROP INDEX IF EXISTS user_email_unique;
+
+ALTER TABLE "user"
+  DROP CONSTRAINT IF EXISTS user_pkey,
+  DROP COLUMN IF EXISTS deleted_at,
+  DROP COLUMN IF EXISTS updated_at,
+  ALTER COLUMN id TYPE text USING id::text,
+  ALTER COLUMN id DROP NOT NULL,
+  ALTER COLUMN name DROP DEFAULT,
+  ALTER COLUMN name DROP NOT NULL,
+  ALTER COLUMN email DROP NOT NULL,
+  ALTER COLUMN password DROP NOT NULL,
+  ALTER COLUMN status TYPE integer,
+  ALTER COLUMN status DROP NOT NULL,
+  ALTER COLUMN role DROP NOT NULL,
+  ALTER COLUMN created_at TYPE text USING to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+  ALTER COLUMN created_at DROP NOT NULL;
+
+UPDATE "user" SET phone = '' WHERE phone IS NULL;
@@ -0,0 +1,29 @@
+UPDATE "user" SET
+  name = coalesce(name, ''),
+  phone = nullif(phone, ''),
+  password = coalesce(password, ''),
+  status = coalesce(status, 1),
+  role = coalesce(role, 'User'),
+  created_at = coalesce(created_at, now()::text);
+
+ALTER TABLE "user"
+  ALTER COLUMN id TYPE uuid USING id::uuid,
+  ADD PRIMARY KEY (id),
+  ALTER COLUMN name SET NOT NULL,
+  ALTER COLUMN name SET DEFAULT '',
+  ALTER COLUMN email SET NOT NULL,
+  ALTER COLUMN password SET NOT NULL,
+  ALTER COLUMN status TYPE smallint,
+  ALTER COLUMN status SET NOT NULL,
+  ALTER COLUMN role SET NOT NULL,
+  ALTER COLUMN created_at TYPE timestamptz USING created_at::timestamptz,
+  ALTER COLUMN created_at SET NOT NULL,
+  ADD COLUMN updated_at timestamptz NULL,
+  ADD COLUMN deleted_at timestamptz NULL;
+
+UPDATE "user" SET updated_at = created_at;
+
+ALTER TABLE "user" ALTER COLUMN updated_at SET NOT NULL;
+
+CREATE UNIQUE INDEX user_email_unique ON "user" (email);
+CREATE UNIQUE INDEX user_phone_unique ON "user" (phone);
@@ -0,0 +1,11 @@
+CREATE TABLE user_status_history (
+  id          uuid        NOT NULL PRIMARY KEY,
+  user_id     uuid        NOT NULL,
+  from_status smallint    NOT NULL,
+  to_status   smallint    NOT NULL,
+  reason      text        NOT NULL DEFAULT '',
+  actor_id    uuid        NULL,
+  created_at  timestamptz NOT NULL
+);
+
+CREATE INDEX user_status_history_user_id ON user_status_history (user_id, created_at);
@@ -0,0 +1,19 @@
+CREATE TABLE audit_log (
+  id          uuid         NOT NULL PRIMARY KEY,
+  sequence    bigint       NOT NULL,
+  actor_id    uuid         NULL,
+  actor_role  varchar(20)  NOT NULL DEFAULT '',
+  action      varchar(50)  NOT NULL,
+  target_type varchar(50)  NOT NULL,
+  target_id   varchar(100) NOT NULL,
+  changes     text         NOT NULL,
+  ip          varchar(45)  NOT NULL DEFAULT '',
+  request_id  varchar(100) NOT NULL DEFAULT '',
+  created_at  timestamptz  NOT NULL,
+  prev_hash   varchar(64)  NOT NULL DEFAULT '',
+  hash        varchar(64)  NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX audit_log_sequence ON audit_log (sequence);
+CREATE INDEX audit_log_target ON audit_log (target_type, target_id);
+CREATE INDEX audit_log_actor_id ON audit_log (actor_id);
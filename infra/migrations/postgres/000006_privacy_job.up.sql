@@ -0,0 +1,15 @@
+CREATE TABLE privacy_job (
+  id           uuid        NOT NULL PRIMARY KEY,
+  kind         varchar(20) NOT NULL,
+  user_id      uuid        NOT NULL,
+  status       varchar(20) NOT NULL,
+  requested_by uuid        NULL,
+  error        text        NOT NULL DEFAULT '',
+  archive      bytea       NULL,
+  created_at   timestamptz NOT NULL,
+  started_at   timestamptz NULL,
+  finished_at  timestamptz NULL
+);
+
+CREATE INDEX privacy_job_user_id ON privacy_job (user_id);
+CREATE INDEX privacy_job_status ON privacy_job (status);
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS privacy_job_tenant_id;
+DROP INDEX IF EXISTS audit_log_tenant_id;
+DROP INDEX IF EXISTS user_phone_unique;
+DROP INDEX IF EXISTS user_email_unique;
+
+ALTER TABLE privacy_job DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE user_status_history DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE "user" DROP COLUMN IF EXISTS tenant_id;
+
+CREATE UNIQUE INDEX user_email_unique ON "user" (email);
+CREATE UNIQUE INDEX user_phone_unique ON "user" (phone);
+
+DROP TABLE IF EXISTS organization_member;
+DROP TABLE IF EXISTS organization;
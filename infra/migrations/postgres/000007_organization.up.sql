@@ -0,0 +1,34 @@
+CREATE TABLE organization (
+  id         uuid         NOT NULL PRIMARY KEY,
+  name       varchar(255) NOT NULL,
+  created_at timestamptz  NOT NULL,
+  updated_at timestamptz  NOT NULL
+);
+
+CREATE UNIQUE INDEX organization_name_unique ON organization (name);
+
+-- the default organization owns data created before organizations.
+INSERT INTO organization (id, name, created_at, updated_at)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default', now(), now());
+
+CREATE TABLE organization_member (
+  organization_id uuid        NOT NULL,
+  user_id         uuid        NOT NULL,
+  role            varchar(32) NOT NULL,
+  created_at      timestamptz NOT NULL,
+  PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX organization_member_user_id ON organization_member (user_id);
+
+ALTER TABLE "user" ADD COLUMN tenant_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE user_status_history ADD COLUMN tenant_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE audit_log ADD COLUMN tenant_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE privacy_job ADD COLUMN tenant_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+DROP INDEX user_email_unique;
+DROP INDEX user_phone_unique;
+CREATE UNIQUE INDEX user_email_unique ON "user" (tenant_id, email);
+CREATE UNIQUE INDEX user_phone_unique ON "user" (tenant_id, phone);
+CREATE INDEX audit_log_tenant_id ON audit_log (tenant_id, sequence);
+CREATE INDEX privacy_job_tenant_id ON privacy_job (tenant_id);
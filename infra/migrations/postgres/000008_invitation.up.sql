@@ -0,0 +1,17 @@
+CREATE TABLE invitation (
+  id         uuid         NOT NULL PRIMARY KEY,
+  tenant_id  uuid         NOT NULL,
+  email      varchar(255) NOT NULL,
+  role       varchar(20)  NOT NULL,
+  status     varchar(20)  NOT NULL,
+  token_hash char(64)     NOT NULL,
+  invited_by uuid         NULL,
+  user_id    uuid         NULL,
+  expires_at timestamptz  NOT NULL,
+  sent_at    timestamptz  NOT NULL,
+  created_at timestamptz  NOT NULL,
+  updated_at timestamptz  NOT NULL
+);
+
+CREATE UNIQUE INDEX invitation_token_hash ON invitation (token_hash);
+CREATE INDEX invitation_tenant_id_email ON invitation (tenant_id, email);
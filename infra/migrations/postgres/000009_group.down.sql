@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_group_member;
+DROP TABLE IF EXISTS user_group;
@@ -0,0 +1,21 @@
+CREATE TABLE user_group (
+  id         uuid         NOT NULL PRIMARY KEY,
+  tenant_id  uuid         NOT NULL,
+  name       varchar(255) NOT NULL,
+  roles      varchar(255) NOT NULL,
+  created_at timestamptz  NOT NULL,
+  updated_at timestamptz  NOT NULL
+);
+
+CREATE UNIQUE INDEX user_group_name_unique ON user_group (tenant_id, name);
+
+CREATE TABLE user_group_member (
+  group_id    uuid        NOT NULL,
+  tenant_id   uuid        NOT NULL,
+  member_kind varchar(20) NOT NULL,
+  member_id   uuid        NOT NULL,
+  created_at  timestamptz NOT NULL,
+  PRIMARY KEY (group_id, member_kind, member_id)
+);
+
+CREATE INDEX user_group_member_member ON user_group_member (tenant_id, member_kind, member_id);
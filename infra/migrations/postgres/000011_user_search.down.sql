@@ -0,0 +1 @@
+DROP INDEX user_search;
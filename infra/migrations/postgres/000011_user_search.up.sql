@@ -0,0 +1,2 @@
+-- full-text index of users searched by sqlutil.SearchIndex, its expression must be sqlutil.SearchVector of name and email.
+CREATE INDEX user_search ON "user" USING gin (to_tsvector('simple', translate(coalesce(name, '') || ' ' || coalesce(email, ''), '@.', '  ')));
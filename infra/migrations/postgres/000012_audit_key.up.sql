@@ -0,0 +1,5 @@
+CREATE TABLE audit_key (
+  subject     varchar(100)  NOT NULL PRIMARY KEY,
+  secret      bytea         NOT NULL,
+  created_at  timestamptz   NOT NULL
+);
@@ -0,0 +1,18 @@
+CREATE TABLE user_old (
+  id         text,
+  name       text,
+  phone      text,
+  email      text,
+  password   text,
+  status     integer,
+  role       text,
+  created_at string
+);
+
+INSERT INTO user_old (id, name, phone, email, password, status, role, created_at)
+SELECT id, name, coalesce(phone, ''), email, password, status, role, strftime('%Y-%m-%dT%H:%M:%SZ', created_at)
+FROM user;
+
+DROP TABLE user;
+
+ALTER TABLE user_old RENAME TO user;
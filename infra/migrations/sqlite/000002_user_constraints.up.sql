@@ -0,0 +1,32 @@
+CREATE TABLE user_new (
+  id         text     NOT NULL PRIMARY KEY,
+  name       text     NOT NULL DEFAULT '',
+  phone      text     NULL,
+  email      text     NOT NULL,
+  password   text     NOT NULL,
+  status     integer  NOT NULL,
+  role       text     NOT NULL,
+  created_at datetime NOT NULL,
+  updated_at datetime NOT NULL,
+  deleted_at datetime NULL
+);
+
+INSERT INTO user_new (id, name, phone, email, password, status, role, created_at, updated_at)
+SELECT
+  id,
+  coalesce(name, ''),
+  nullif(phone, ''),
+  email,
+  coalesce(password, ''),
+  coalesce(status, 1),
+  coalesce(role, 'User'),
+  coalesce(strftime('%Y-%m-%d %H:%M:%f+00:00', created_at), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+  coalesce(strftime('%Y-%m-%d %H:%M:%f+00:00', created_at), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+FROM user;
+
+DROP TABLE user;
+
+ALTER TABLE user_new RENAME TO user;
+
+CREATE UNIQUE INDEX user_email_unique ON user (email);
+CREATE UNIQUE INDEX user_phone_unique ON user (phone);
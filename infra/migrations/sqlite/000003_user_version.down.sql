@@ -0,0 +1 @@
+ALTER TABLE user DROP COLUMN version;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE user_status_history (
+  id          text     NOT NULL PRIMARY KEY,
+  user_id     text     NOT NULL,
+  from_status integer  NOT NULL,
+  to_status   integer  NOT NULL,
+  reason      text     NOT NULL DEFAULT '',
+  actor_id    text     NULL,
+  created_at  datetime NOT NULL
+);
+
+CREATE INDEX user_status_history_user_id ON user_status_history (user_id, created_at);
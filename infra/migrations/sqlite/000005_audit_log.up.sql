@@ -0,0 +1,19 @@
+CREATE TABLE audit_log (
+  id          text     NOT NULL PRIMARY KEY,
+  sequence    integer  NOT NULL,
+  actor_id    text     NULL,
+  actor_role  text     NOT NULL DEFAULT '',
+  action      text     NOT NULL,
+  target_type text     NOT NULL,
+  target_id   text     NOT NULL,
+  changes     text     NOT NULL,
+  ip          text     NOT NULL DEFAULT '',
+  request_id  text     NOT NULL DEFAULT '',
+  created_at  datetime NOT NULL,
+  prev_hash   text     NOT NULL DEFAULT '',
+  hash        text     NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX audit_log_sequence ON audit_log (sequence);
+CREATE INDEX audit_log_target ON audit_log (target_type, target_id);
+CREATE INDEX audit_log_actor_id ON audit_log (actor_id);
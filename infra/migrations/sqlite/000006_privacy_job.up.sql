@@ -0,0 +1,15 @@
+CREATE TABLE privacy_job (
+  id           text     NOT NULL PRIMARY KEY,
+  kind         text     NOT NULL,
+  user_id      text     NOT NULL,
+  status       text     NOT NULL,
+  requested_by text     NULL,
+  error        text     NOT NULL DEFAULT '',
+  archive      blob     NULL,
+  created_at   datetime NOT NULL,
+  started_at   datetime NULL,
+  finished_at  datetime NULL
+);
+
+CREATE INDEX privacy_job_user_id ON privacy_job (user_id);
+CREATE INDEX privacy_job_status ON privacy_job (status);
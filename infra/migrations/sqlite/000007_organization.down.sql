@@ -0,0 +1,15 @@
+DROP INDEX privacy_job_tenant_id;
+DROP INDEX audit_log_tenant_id;
+DROP INDEX user_phone_unique;
+DROP INDEX user_email_unique;
+
+ALTER TABLE privacy_job DROP COLUMN tenant_id;
+ALTER TABLE audit_log DROP COLUMN tenant_id;
+ALTER TABLE user_status_history DROP COLUMN tenant_id;
+ALTER TABLE user DROP COLUMN tenant_id;
+
+CREATE UNIQUE INDEX user_email_unique ON user (email);
+CREATE UNIQUE INDEX user_phone_unique ON user (phone);
+
+DROP TABLE organization_member;
+DROP TABLE organization;
@@ -0,0 +1,35 @@
+CREATE TABLE organization (
+  id         text     NOT NULL PRIMARY KEY,
+  name       text     NOT NULL,
+  created_at datetime NOT NULL,
+  updated_at datetime NOT NULL
+);
+
+CREATE UNIQUE INDEX organization_name_unique ON organization (name);
+
+-- the default organization owns data created before organizations.
+INSERT INTO organization (id, name, created_at, updated_at)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default',
+  strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+
+CREATE TABLE organization_member (
+  organization_id text     NOT NULL,
+  user_id         text     NOT NULL,
+  role            text     NOT NULL,
+  created_at      datetime NOT NULL,
+  PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX organization_member_user_id ON organization_member (user_id);
+
+ALTER TABLE user ADD COLUMN tenant_id text NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE user_status_history ADD COLUMN tenant_id text NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE audit_log ADD COLUMN tenant_id text NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE privacy_job ADD COLUMN tenant_id text NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+DROP INDEX user_email_unique;
+DROP INDEX user_phone_unique;
+CREATE UNIQUE INDEX user_email_unique ON user (tenant_id, email);
+CREATE UNIQUE INDEX user_phone_unique ON user (tenant_id, phone);
+CREATE INDEX audit_log_tenant_id ON audit_log (tenant_id, sequence);
+CREATE INDEX privacy_job_tenant_id ON privacy_job (tenant_id);
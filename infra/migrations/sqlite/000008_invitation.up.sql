@@ -0,0 +1,17 @@
+CREATE TABLE invitation (
+  id         text     NOT NULL PRIMARY KEY,
+  tenant_id  text     NOT NULL,
+  email      text     NOT NULL,
+  role       text     NOT NULL,
+  status     text     NOT NULL,
+  token_hash text     NOT NULL,
+  invited_by text     NULL,
+  user_id    text     NULL,
+  expires_at datetime NOT NULL,
+  sent_at    datetime NOT NULL,
+  created_at datetime NOT NULL,
+  updated_at datetime NOT NULL
+);
+
+CREATE UNIQUE INDEX invitation_token_hash ON invitation (token_hash);
+CREATE INDEX invitation_tenant_id_email ON invitation (tenant_id, email);
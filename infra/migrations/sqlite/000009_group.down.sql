@@ -0,0 +1,2 @@
+DROP TABLE user_group_member;
+DROP TABLE user_group;
@@ -0,0 +1,21 @@
+CREATE TABLE user_group (
+  id         text     NOT NULL PRIMARY KEY,
+  tenant_id  text     NOT NULL,
+  name       text     NOT NULL,
+  roles      text     NOT NULL,
+  created_at datetime NOT NULL,
+  updated_at datetime NOT NULL
+);
+
+CREATE UNIQUE INDEX user_group_name_unique ON user_group (tenant_id, name);
+
+CREATE TABLE user_group_member (
+  group_id    text     NOT NULL,
+  tenant_id   text     NOT NULL,
+  member_kind text     NOT NULL,
+  member_id   text     NOT NULL,
+  created_at  datetime NOT NULL,
+  PRIMARY KEY (group_id, member_kind, member_id)
+);
+
+CREATE INDEX user_group_member_member ON user_group_member (tenant_id, member_kind, member_id);
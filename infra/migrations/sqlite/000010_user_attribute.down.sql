@@ -0,0 +1,2 @@
+ALTER TABLE user DROP COLUMN attributes;
+DROP TABLE user_attribute;
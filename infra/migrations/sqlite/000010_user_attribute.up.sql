@@ -0,0 +1,12 @@
+CREATE TABLE user_attribute (
+  tenant_id    text     NOT NULL,
+  name         text     NOT NULL,
+  type         text     NOT NULL,
+  is_required  boolean  NOT NULL,
+  is_unique    boolean  NOT NULL,
+  enum_values  text     NOT NULL,
+  created_at   datetime NOT NULL,
+  PRIMARY KEY (tenant_id, name)
+);
+
+ALTER TABLE user ADD COLUMN attributes text NULL;
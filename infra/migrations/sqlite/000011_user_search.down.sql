@@ -0,0 +1,4 @@
+DROP TRIGGER user_search_delete;
+DROP TRIGGER user_search_update;
+DROP TRIGGER user_search_insert;
+DROP TABLE user_search;
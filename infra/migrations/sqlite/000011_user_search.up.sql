@@ -0,0 +1,21 @@
+-- full-text index of users searched by sqlutil.SearchIndex, its words are split same as search.Terms.
+CREATE VIRTUAL TABLE user_search USING fts5(
+  id UNINDEXED,
+  name,
+  email,
+  tokenize = "unicode61 remove_diacritics 0"
+);
+
+INSERT INTO user_search (id, name, email) SELECT id, name, email FROM user;
+
+CREATE TRIGGER user_search_insert AFTER INSERT ON user BEGIN
+  INSERT INTO user_search (id, name, email) VALUES (new.id, new.name, new.email);
+END;
+
+CREATE TRIGGER user_search_update AFTER UPDATE OF name, email ON user BEGIN
+  UPDATE user_search SET name = new.name, email = new.email WHERE id = old.id;
+END;
+
+CREATE TRIGGER user_search_delete AFTER DELETE ON user BEGIN
+  DELETE FROM user_search WHERE id = old.id;
+END;
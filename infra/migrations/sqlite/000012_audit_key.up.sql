@@ -0,0 +1,5 @@
+CREATE TABLE audit_key (
+  subject     text     NOT NULL PRIMARY KEY,
+  secret      blob     NOT NULL,
+  created_at  datetime NOT NULL
+);
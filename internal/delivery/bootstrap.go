@@ -11,6 +11,8 @@ import (
 	grpcapi "github.com/amirzayi/clean_architect/api/grpc"
 	"github.com/amirzayi/clean_architect/api/http/handler"
 	"github.com/amirzayi/clean_architect/api/proto/authpb"
+	"github.com/amirzayi/clean_architect/api/proto/grouppb"
+	"github.com/amirzayi/clean_architect/api/proto/userpb"
 	"github.com/amirzayi/clean_architect/internal/service"
 	"github.com/amirzayi/clean_architect/pkg/auth"
 )
@@ -19,15 +21,27 @@ func SetupHTTPRouter(mux *http.ServeMux, logger *log.Logger, services *service.S
 	handler.Register(mux, logger, services, authManager)
 }
 
-func SetupGRPC(server *grpc.Server, services *service.Services) {
+func SetupGRPC(server *grpc.Server, services *service.Services, authManager auth.Manager) {
 	authService := grpcapi.NewAuthGrpcService(services.Auth)
 	authpb.RegisterAuthServiceServer(server, authService)
+
+	userService := grpcapi.NewUserGrpcService(services.User, services.Group, authManager)
+	userpb.RegisterUserServiceServer(server, userService)
+
+	groupService := grpcapi.NewGroupGrpcService(services.Group, authManager)
+	grouppb.RegisterGroupServiceServer(server, groupService)
 }
 
 func SetupGRPCGateway(ctx context.Context, grpcAddress string, mux *runtime.ServeMux, options ...grpc.DialOption) error {
 	if err := authpb.RegisterAuthServiceHandlerFromEndpoint(ctx, mux, grpcAddress, options); err != nil {
 		return err
 	}
+	if err := userpb.RegisterUserServiceHandlerFromEndpoint(ctx, mux, grpcAddress, options); err != nil {
+		return err
+	}
+	if err := grouppb.RegisterGroupServiceHandlerFromEndpoint(ctx, mux, grpcAddress, options); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAuditSequenceConflict returned when another entry has been appended with same sequence meanwhile.
+	ErrAuditSequenceConflict = errors.New("audit entry sequence already exists")
+	ErrAuditChainBroken      = errors.New("audit chain broken")
+	ErrAuditKeyNotFound      = errors.New("audit key not found")
+)
+
+type AuditAction string
+
+const (
+	AuditActionUserCreate       AuditAction = "user.create"
+	AuditActionUserUpdate       AuditAction = "user.update"
+	AuditActionUserDelete       AuditAction = "user.delete"
+	AuditActionUserRestore      AuditAction = "user.restore"
+	AuditActionUserPurge        AuditAction = "user.purge"
+	AuditActionUserRoleChange   AuditAction = "user.role_change"
+	AuditActionUserStatusChange AuditAction = "user.status_change"
+	AuditActionUserExport       AuditAction = "user.export"
+	AuditActionUserErase        AuditAction = "user.erase"
+	AuditActionUserAvatarUpdate AuditAction = "user.avatar_update"
+	AuditActionUserAvatarDelete AuditAction = "user.avatar_delete"
+
+	AuditActionOrganizationCreate       AuditAction = "organization.create"
+	AuditActionOrganizationMemberSave   AuditAction = "organization.member_save"
+	AuditActionOrganizationMemberRemove AuditAction = "organization.member_remove"
+
+	AuditActionInvitationCreate AuditAction = "invitation.create"
+	AuditActionInvitationResend AuditAction = "invitation.resend"
+	AuditActionInvitationRevoke AuditAction = "invitation.revoke"
+	AuditActionInvitationAccept AuditAction = "invitation.accept"
+
+	AuditActionGroupCreate       AuditAction = "group.create"
+	AuditActionGroupDelete       AuditAction = "group.delete"
+	AuditActionGroupMemberAdd    AuditAction = "group.member_add"
+	AuditActionGroupMemberRemove AuditAction = "group.member_remove"
+
+	AuditActionUserAttributeCreate AuditAction = "user_attribute.create"
+	AuditActionUserAttributeDelete AuditAction = "user_attribute.delete"
+)
+
+const (
+	AuditTargetUser          = "user"
+	AuditTargetOrganization  = "organization"
+	AuditTargetInvitation    = "invitation"
+	AuditTargetGroup         = "group"
+	AuditTargetUserAttribute = "user_attribute"
+
+	// AuditRedacted replaces values of secret fields in audit changes.
+	AuditRedacted = "[REDACTED]"
+	// AuditPseudonymPrefix prefixes pseudonyms of personal values in audit changes.
+	AuditPseudonymPrefix = "pseudonym:"
+	// AuditKeySize is the size of keys of audit subjects in bytes.
+	AuditKeySize = 32
+)
+
+// AuditKey is the secret key of an audit subject, e.g. a user, personal values of the subject are recorded
+// in audit changes as pseudonyms by its key. Deleting the key shreds them, pseudonyms are kept untouched
+// so hash chain stays valid but they can no longer be linked to any value.
+type AuditKey struct {
+	Subject   string
+	Key       []byte
+	CreatedAt time.Time
+}
+
+// AuditSubject returns subject of audit keys of given target, e.g. user:<id>.
+func AuditSubject(targetType string, id uuid.UUID) string {
+	return targetType + ":" + id.String()
+}
+
+// AuditPseudonymizer replaces personal values of a subject in audit changes, nil and empty values are kept.
+type AuditPseudonymizer func(value any) any
+
+// NewAuditPseudonymizer returns AuditPseudonymizer by key of a subject, see AuditPseudonym. key is called once
+// on the first personal value, so subjects get keys only when they have personal values.
+func NewAuditPseudonymizer(key func() []byte) AuditPseudonymizer {
+	key = sync.OnceValue(key)
+	return func(value any) any {
+		if value == nil || value == "" {
+			return value
+		}
+		return AuditPseudonym(key(), value)
+	}
+}
+
+// AuditPseudonym returns pseudonym of personal value by key of its subject, which is the truncated hmac-sha256
+// of value in json, so equal values of a subject have equal pseudonyms and changes can still be followed.
+// value is redacted if key is empty, personal values are never recorded as they are.
+func AuditPseudonym(key []byte, value any) string {
+	if len(key) == 0 {
+		return AuditRedacted
+	}
+	b, _ := json.Marshal(value)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return AuditPseudonymPrefix + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// AuditChange is the change of a field of audit target, nil values mean absent.
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// AuditEntry is an append-only record of an action, entries are ordered by Sequence
+// and each entry is linked to previous one by PrevHash when hash chain is enabled.
+type AuditEntry struct {
+	ID         uuid.UUID
+	TenantID   uuid.UUID
+	Sequence   int64
+	ActorID    uuid.UUID
+	ActorRole  string
+	Action     AuditAction
+	TargetType string
+	TargetID   string
+	Changes    []AuditChange
+	IP         string
+	RequestID  string
+	At         time.Time
+	PrevHash   string
+	Hash       string
+}
+
+// ComputeHash returns sha256 of all fields of entry except Hash, in hex.
+// default tenant is left out so hashes of entries created before organizations stay valid.
+func (e AuditEntry) ComputeHash() string {
+	changes, _ := json.Marshal(e.Changes)
+	id := e.ID.String()
+	if e.TenantID != uuid.Nil {
+		id += "\n" + e.TenantID.String()
+	}
+	h := sha256.New()
+	h.Write([]byte(strings.Join([]string{
+		id,
+		strconv.FormatInt(e.Sequence, 10),
+		e.ActorID.String(),
+		e.ActorRole,
+		string(e.Action),
+		e.TargetType,
+		e.TargetID,
+		string(changes),
+		e.IP,
+		e.RequestID,
+		e.At.UTC().Format(time.RFC3339Nano),
+		e.PrevHash,
+	}, "\n")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyAuditChain checks entries ordered by sequence are untouched and linked to prev,
+// prev is the zero entry when entries start from the beginning of log.
+func VerifyAuditChain(prev AuditEntry, entries []AuditEntry) error {
+	for _, e := range entries {
+		if e.Sequence != prev.Sequence+1 || e.PrevHash != prev.Hash || e.Hash != e.ComputeHash() {
+			return fmt.Errorf("%w at sequence %d", ErrAuditChainBroken, e.Sequence)
+		}
+		prev = e
+	}
+	return nil
+}
+
+// UserAuditChanges returns changed fields of user, password is redacted and personal values, which are name,
+// phone number, email and attributes, are replaced by pseudonymize. only role and status are kept as they are.
+func UserAuditChanges(before, after User, pseudonymize AuditPseudonymizer) []AuditChange {
+	var changes []AuditChange
+	add := func(field, before, after string, personal bool) {
+		if before == after {
+			return
+		}
+		change := AuditChange{Field: field}
+		if before != "" {
+			change.Before = before
+		}
+		if after != "" {
+			change.After = after
+		}
+		if personal {
+			change.Before, change.After = pseudonymize(change.Before), pseudonymize(change.After)
+		}
+		changes = append(changes, change)
+	}
+
+	add("name", before.Name, after.Name, true)
+	add("phone_number", before.PhoneNumber, after.PhoneNumber, true)
+	add("email", before.Email, after.Email, true)
+	if before.Password != after.Password {
+		// hashes are never kept, both sides of a changed password are redacted
+		change := AuditChange{Field: "password", After: AuditRedacted}
+		if before.Password != "" {
+			change.Before = AuditRedacted
+		}
+		changes = append(changes, change)
+	}
+	add("role", string(before.Role), string(after.Role), false)
+	add("status", before.Status.String(), after.Status.String(), false)
+	// attribute values are normalized scalars, so they are comparable
+	names := append(slices.Collect(maps.Keys(before.Attributes)), slices.Collect(maps.Keys(after.Attributes))...)
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		if b, a := before.Attributes[name], after.Attributes[name]; b != a {
+			changes = append(changes, AuditChange{Field: UserAttributePrefix + name, Before: pseudonymize(b), After: pseudonymize(a)})
+		}
+	}
+	return changes
+}
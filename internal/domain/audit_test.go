@@ -0,0 +1,89 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+func TestUserAuditChanges(t *testing.T) {
+	key := []byte("key")
+	var loaded int
+	pseudonymize := domain.NewAuditPseudonymizer(func() []byte {
+		loaded++
+		return key
+	})
+	pseudonym := func(value any) string { return domain.AuditPseudonym(key, value) }
+
+	before := domain.User{Name: "amir", Email: "a@b.c", Password: "hash", Role: domain.UserRoleNormal, Status: domain.UsereStatusNew}
+	after := before
+	after.Name = "ali"
+	after.PhoneNumber = "0912"
+	after.Password = "new hash"
+	after.Status = domain.UserStatusBanned
+
+	require.Equal(t, []domain.AuditChange{
+		{Field: "name", Before: pseudonym("amir"), After: pseudonym("ali")},
+		{Field: "phone_number", After: pseudonym("0912")},
+		{Field: "password", Before: domain.AuditRedacted, After: domain.AuditRedacted},
+		{Field: "status", Before: domain.UsereStatusNew.String(), After: domain.UserStatusBanned.String()},
+	}, domain.UserAuditChanges(before, after, pseudonymize))
+	require.Equal(t, 1, loaded)
+
+	require.Empty(t, domain.UserAuditChanges(before, before, pseudonymize))
+
+	before.Attributes = map[string]any{"age": 30.0, "plan": "free"}
+	after = before
+	after.Attributes = map[string]any{"age": 31.0, "vip": true}
+	require.Equal(t, []domain.AuditChange{
+		{Field: "attributes.age", Before: pseudonym(30.0), After: pseudonym(31.0)},
+		{Field: "attributes.plan", Before: pseudonym("free")},
+		{Field: "attributes.vip", After: pseudonym(true)},
+	}, domain.UserAuditChanges(before, after, pseudonymize))
+}
+
+func TestAuditPseudonym(t *testing.T) {
+	key := []byte("key")
+	require.Equal(t, domain.AuditPseudonym(key, "amir"), domain.AuditPseudonym(key, "amir"))
+	require.NotEqual(t, domain.AuditPseudonym(key, "amir"), domain.AuditPseudonym(key, "ali"))
+	require.NotEqual(t, domain.AuditPseudonym(key, "amir"), domain.AuditPseudonym([]byte("other"), "amir"))
+	require.NotContains(t, domain.AuditPseudonym(key, "amir"), "amir")
+	require.Equal(t, domain.AuditRedacted, domain.AuditPseudonym(nil, "amir"))
+
+	pseudonymize := domain.NewAuditPseudonymizer(func() []byte { return nil })
+	require.Nil(t, pseudonymize(nil))
+	require.Equal(t, "", pseudonymize(""))
+	require.Equal(t, domain.AuditRedacted, pseudonymize("amir"))
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	var entries []domain.AuditEntry
+	var prev domain.AuditEntry
+	for i := range 3 {
+		e := domain.AuditEntry{
+			ID:         uuid.New(),
+			Sequence:   int64(i + 1),
+			Action:     domain.AuditActionUserUpdate,
+			TargetType: domain.AuditTargetUser,
+			TargetID:   uuid.NewString(),
+			Changes:    []domain.AuditChange{{Field: "name", Before: "amir", After: "ali"}},
+			At:         time.Now(),
+			PrevHash:   prev.Hash,
+		}
+		e.Hash = e.ComputeHash()
+		entries = append(entries, e)
+		prev = e
+	}
+	require.NoError(t, domain.VerifyAuditChain(domain.AuditEntry{}, entries))
+	require.NoError(t, domain.VerifyAuditChain(entries[0], entries[1:]))
+
+	tampered := append([]domain.AuditEntry{}, entries...)
+	tampered[1].Changes = []domain.AuditChange{{Field: "name", Before: "amir", After: "reza"}}
+	require.ErrorIs(t, domain.VerifyAuditChain(domain.AuditEntry{}, tampered), domain.ErrAuditChainBroken)
+
+	require.ErrorIs(t, domain.VerifyAuditChain(domain.AuditEntry{}, []domain.AuditEntry{entries[0], entries[2]}), domain.ErrAuditChainBroken)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAvatar returned when uploaded avatar is not a supported image or is too large.
+var ErrInvalidAvatar = errors.New("invalid avatar")
+
+type AvatarSize string
+
+const (
+	// AvatarOriginal is the uploaded image as is.
+	AvatarOriginal  AvatarSize = "original"
+	AvatarThumbnail AvatarSize = "thumbnail"
+)
+
+// AvatarContentTypes are the sniffed content types of images accepted as avatars.
+var AvatarContentTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+// AvatarKey returns storage key of avatar of user in given size, avatars of each organization are kept apart.
+func AvatarKey(tenantID, userID uuid.UUID, size AvatarSize) string {
+	return path.Join("avatars", tenantID.String(), userID.String(), string(size))
+}
+
+// AvatarURLs are signed download urls of avatar of a user, valid until ExpiresAt.
+type AvatarURLs struct {
+	Original  string
+	Thumbnail string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrGroupNotFound            = errors.New("group not found")
+	ErrGroupAlreadyExists       = errors.New("group already exists")
+	ErrGroupMemberNotFound      = errors.New("group member not found")
+	ErrGroupMemberAlreadyExists = errors.New("group member already exists")
+	// ErrGroupCycle returned when a group would become member of itself, directly or through its members.
+	ErrGroupCycle = errors.New("group membership cycle")
+)
+
+// Group grants its roles to its members, members are users or other groups,
+// so users of nested groups get roles of all groups containing them.
+type Group struct {
+	ID        uuid.UUID
+	TenantID  uuid.UUID
+	Name      string
+	Roles     []UserRole
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type GroupMemberKind string
+
+const (
+	GroupMemberUser  GroupMemberKind = "user"
+	GroupMemberGroup GroupMemberKind = "group"
+)
+
+func (kind GroupMemberKind) IsValid() bool {
+	return kind == GroupMemberUser || kind == GroupMemberGroup
+}
+
+// GroupMember is a direct member of a group, a user or a nested group by Kind.
+type GroupMember struct {
+	GroupID   uuid.UUID
+	Kind      GroupMemberKind
+	MemberID  uuid.UUID
+	CreatedAt time.Time
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvitationNotPending returned when invitation is already accepted or revoked.
+	ErrInvitationNotPending = errors.New("invitation is not pending")
+)
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+	// InvitationExpired is never stored, pending invitations are expired after their ExpiresAt.
+	InvitationExpired InvitationStatus = "expired"
+)
+
+// Invitation invites an email into a role of the organization of TenantID, the invitee accepts it
+// by the secret token sent to the email, only hash of the token is stored.
+type Invitation struct {
+	ID        uuid.UUID
+	TenantID  uuid.UUID
+	Email     string
+	Role      UserRole
+	Status    InvitationStatus
+	TokenHash string
+	InvitedBy uuid.UUID
+	// UserID is the user created by accepting invitation.
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	SentAt    time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// StatusAt returns status of invitation at given time, pending invitations turn expired after ExpiresAt.
+func (i Invitation) StatusAt(now time.Time) InvitationStatus {
+	if i.Status == InvitationPending && !now.Before(i.ExpiresAt) {
+		return InvitationExpired
+	}
+	return i.Status
+}
+
+// HashInvitationToken returns the stored form of invitation token.
+func HashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
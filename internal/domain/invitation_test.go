@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+func TestInvitationStatusAt(t *testing.T) {
+	expiresAt := time.Now()
+	for _, tc := range []struct {
+		status   domain.InvitationStatus
+		at       time.Time
+		expected domain.InvitationStatus
+	}{
+		{domain.InvitationPending, expiresAt.Add(-time.Second), domain.InvitationPending},
+		{domain.InvitationPending, expiresAt, domain.InvitationExpired},
+		{domain.InvitationAccepted, expiresAt.Add(time.Hour), domain.InvitationAccepted},
+		{domain.InvitationRevoked, expiresAt.Add(time.Hour), domain.InvitationRevoked},
+	} {
+		invitation := domain.Invitation{Status: tc.status, ExpiresAt: expiresAt}
+		require.Equal(t, tc.expected, invitation.StatusAt(tc.at), "%s at %s", tc.status, tc.at.Sub(expiresAt))
+	}
+}
+
+func TestHashInvitationToken(t *testing.T) {
+	hash := domain.HashInvitationToken("token")
+	require.Len(t, hash, 64)
+	require.Equal(t, hash, domain.HashInvitationToken("token"))
+	require.NotEqual(t, hash, domain.HashInvitationToken("other"))
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOrganizationNotFound      = errors.New("organization not found")
+	ErrOrganizationAlreadyExists = errors.New("organization already exists")
+	ErrMembershipNotFound        = errors.New("membership not found")
+)
+
+// Organization is a tenant, its users and their data are never visible to other organizations.
+// ID of organization is the tenant id of its data.
+type Organization struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Membership grants a user a role in an organization other than the one it was created in,
+// users belong to their own organization with their own role without membership.
+type Membership struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+	Role           UserRole
+	CreatedAt      time.Time
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPrivacyJobNotFound = errors.New("privacy job not found")
+	// ErrPrivacyJobClaimed returned when job is not pending anymore, e.g. claimed by another instance.
+	ErrPrivacyJobClaimed = errors.New("privacy job already claimed")
+)
+
+type PrivacyJobKind string
+
+const (
+	// PrivacyJobExport gathers all data stored about a user into an archive.
+	PrivacyJobExport PrivacyJobKind = "export"
+	// PrivacyJobErasure anonymises personal data of a user.
+	PrivacyJobErasure PrivacyJobKind = "erasure"
+)
+
+func (kind PrivacyJobKind) IsValid() bool {
+	return kind == PrivacyJobExport || kind == PrivacyJobErasure
+}
+
+type PrivacyJobStatus string
+
+const (
+	PrivacyJobPending   PrivacyJobStatus = "pending"
+	PrivacyJobRunning   PrivacyJobStatus = "running"
+	PrivacyJobCompleted PrivacyJobStatus = "completed"
+	PrivacyJobFailed    PrivacyJobStatus = "failed"
+)
+
+// PrivacyJob is a long-running export or erasure of data of a user,
+// Archive is the zip archive of a completed export job.
+type PrivacyJob struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	Kind        PrivacyJobKind
+	UserID      uuid.UUID
+	Status      PrivacyJobStatus
+	RequestedBy uuid.UUID
+	Error       string
+	Archive     []byte
+	CreatedAt   time.Time
+	StartedAt   time.Time
+	FinishedAt  time.Time
+}
+
+// UserErasure is published when personal data of a user is erased,
+// downstream consumers must erase their copies of it.
+type UserErasure struct {
+	TenantID uuid.UUID
+	UserID   uuid.UUID
+	JobID    uuid.UUID
+	At       time.Time
+}
+
+// Anonymized returns user with its personal data replaced, email stays unique and
+// empty password never matches, so erased user could not login.
+func (u User) Anonymized() User {
+	u.Name = ""
+	u.PhoneNumber = ""
+	u.Email = fmt.Sprintf("%s@erased.invalid", u.ID)
+	u.Password = ""
+	return u
+}
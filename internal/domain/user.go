@@ -11,6 +11,8 @@ import (
 var (
 	ErrUserAlreadyExists = errors.New("user already exists")
 	ErrUserNotFound      = errors.New("user not found")
+	// ErrConcurrentModification returned when user has been modified since given version was read.
+	ErrConcurrentModification = errors.New("user has been modified concurrently")
 )
 
 type UserStatus uint8
@@ -20,6 +22,7 @@ const (
 	UserStatusActive
 	UserStatusBanned
 	UserStatusDeleted
+	UserStatusInactive
 )
 
 func (status UserStatus) String() string {
@@ -32,12 +35,24 @@ func (status UserStatus) String() string {
 		return "banned"
 	case UserStatusDeleted:
 		return "deleted"
+	case UserStatusInactive:
+		return "inactive"
 
 	default:
 		return ""
 	}
 }
 
+// ParseUserStatus returns status of given name, ok is false for unknown names.
+func ParseUserStatus(name string) (status UserStatus, ok bool) {
+	for status := UsereStatusNew; status <= UserStatusInactive; status++ {
+		if status.String() == name {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
 type UserRole string
 
 const (
@@ -45,8 +60,15 @@ const (
 	UserRoleAdmin  UserRole = "Admin"
 )
 
+func (role UserRole) IsValid() bool {
+	return role == UserRoleNormal || role == UserRoleAdmin
+}
+
 type User struct {
-	ID          uuid.UUID
+	ID uuid.UUID
+	// TenantID is the organization owning user, repositories take it from context on creation.
+	// mongodb repository stores users as is, so it is named same as tenant field of other collections.
+	TenantID    uuid.UUID `bson:"tenant_id"`
 	Name        string
 	PhoneNumber string
 	Email       string
@@ -54,4 +76,57 @@ type User struct {
 	Status      UserStatus
 	Role        UserRole
 	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   time.Time
+	// Attributes holds values of custom attributes by name, see UserAttribute.
+	Attributes map[string]any
+	// Version increments on every modification, used for optimistic concurrency control.
+	// zero version on update means no version check.
+	Version int64
+}
+
+// UserPatch holds changes of a partial user update, nil fields are left unchanged.
+// status is changed only by UserStatusTransition.
+type UserPatch struct {
+	Name        *string
+	PhoneNumber *string
+	Email       *string
+	// Password must be hashed already.
+	Password *string
+	Role     *UserRole
+	// Attributes replaces all custom attributes of user when not nil.
+	Attributes map[string]any
+	UpdatedAt  time.Time
+	// Version is expected version of user, zero means no version check.
+	Version int64
+}
+
+// IsEmpty reports whether patch does not change any field.
+func (p UserPatch) IsEmpty() bool {
+	return p.Name == nil && p.PhoneNumber == nil && p.Email == nil &&
+		p.Password == nil && p.Role == nil && p.Attributes == nil
+}
+
+// Apply returns user with changes of patch, version is not changed.
+func (p UserPatch) Apply(user User) User {
+	if p.Name != nil {
+		user.Name = *p.Name
+	}
+	if p.PhoneNumber != nil {
+		user.PhoneNumber = *p.PhoneNumber
+	}
+	if p.Email != nil {
+		user.Email = *p.Email
+	}
+	if p.Password != nil {
+		user.Password = *p.Password
+	}
+	if p.Role != nil {
+		user.Role = *p.Role
+	}
+	if p.Attributes != nil {
+		user.Attributes = p.Attributes
+	}
+	user.UpdatedAt = p.UpdatedAt
+	return user
 }
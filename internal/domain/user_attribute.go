@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserAttributeNotFound      = errors.New("user attribute not found")
+	ErrUserAttributeAlreadyExists = errors.New("user attribute already exists")
+	// ErrInvalidUserAttribute returned when a definition or a value of attribute is not valid.
+	ErrInvalidUserAttribute = errors.New("invalid user attribute")
+	// ErrUserAttributeTaken returned when value of a unique attribute belongs to another user.
+	ErrUserAttributeTaken = errors.New("user attribute value is taken")
+)
+
+type UserAttributeType string
+
+const (
+	UserAttributeString UserAttributeType = "string"
+	UserAttributeNumber UserAttributeType = "number"
+	UserAttributeBool   UserAttributeType = "bool"
+	UserAttributeDate   UserAttributeType = "date"
+	UserAttributeEnum   UserAttributeType = "enum"
+)
+
+func (t UserAttributeType) IsValid() bool {
+	switch t {
+	case UserAttributeString, UserAttributeNumber, UserAttributeBool, UserAttributeDate, UserAttributeEnum:
+		return true
+	}
+	return false
+}
+
+// UserAttributeDateLayout is the layout of date attribute values.
+const UserAttributeDateLayout = time.DateOnly
+
+// UserAttributePrefix prefixes names of attributes in list filters and sorts, e.g. ?filter=attributes.age>18
+const UserAttributePrefix = "attributes."
+
+// attribute names are used as json keys in sql queries, so they are restricted to plain identifiers.
+var userAttributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// UserAttribute defines a custom attribute of users of a tenant,
+// values of users are kept in User.Attributes by name of attribute.
+type UserAttribute struct {
+	TenantID uuid.UUID
+	Name     string
+	Type     UserAttributeType
+	Required bool
+	// Unique attribute could not have same value for two users of tenant, deleted users included.
+	Unique bool
+	// Values are allowed values of enum attribute.
+	Values    []string
+	CreatedAt time.Time
+}
+
+// Validate checks name and type of definition, only enum attributes have values.
+func (a UserAttribute) Validate() error {
+	if !userAttributeNamePattern.MatchString(a.Name) {
+		return fmt.Errorf("%w: name %q must be lowercase letters, digits and underscores starting with a letter", ErrInvalidUserAttribute, a.Name)
+	}
+	if !a.Type.IsValid() {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidUserAttribute, a.Type)
+	}
+	if a.Type == UserAttributeEnum && len(a.Values) == 0 {
+		return fmt.Errorf("%w: enum %q has no values", ErrInvalidUserAttribute, a.Name)
+	}
+	if a.Type != UserAttributeEnum && len(a.Values) > 0 {
+		return fmt.Errorf("%w: values are allowed only for enum", ErrInvalidUserAttribute)
+	}
+	return nil
+}
+
+// Normalize returns value as stored for type of attribute, number as float64, bool as bool
+// and others as string, dates are formatted by UserAttributeDateLayout.
+func (a UserAttribute) Normalize(value any) (any, error) {
+	switch a.Type {
+	case UserAttributeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f, nil
+			}
+		}
+	case UserAttributeBool:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	default:
+		if v, ok := value.(string); ok {
+			return a.normalizeString(v)
+		}
+	}
+	return nil, fmt.Errorf("%w: %q must be %s", ErrInvalidUserAttribute, a.Name, a.Type)
+}
+
+// ParseValue parses value of attribute from its string form, e.g. a list filter value.
+func (a UserAttribute) ParseValue(value string) (any, error) {
+	switch a.Type {
+	case UserAttributeNumber:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q must be number", ErrInvalidUserAttribute, a.Name)
+		}
+		return f, nil
+	case UserAttributeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q must be bool", ErrInvalidUserAttribute, a.Name)
+		}
+		return b, nil
+	}
+	return a.normalizeString(value)
+}
+
+func (a UserAttribute) normalizeString(value string) (any, error) {
+	switch a.Type {
+	case UserAttributeDate:
+		date, err := time.Parse(UserAttributeDateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q must be date of %s layout", ErrInvalidUserAttribute, a.Name, UserAttributeDateLayout)
+		}
+		return date.Format(UserAttributeDateLayout), nil
+	case UserAttributeEnum:
+		if !slices.Contains(a.Values, value) {
+			return nil, fmt.Errorf("%w: %q must be one of %v", ErrInvalidUserAttribute, a.Name, a.Values)
+		}
+	}
+	return value, nil
+}
+
+// ValidateUserAttributes checks attributes against definitions and returns their normalized copy,
+// unknown attributes and missing required ones are rejected, nil values are dropped.
+func ValidateUserAttributes(definitions []UserAttribute, attributes map[string]any) (map[string]any, error) {
+	normalized := make(map[string]any, len(attributes))
+	for _, definition := range definitions {
+		value, ok := attributes[definition.Name]
+		if !ok || value == nil {
+			if definition.Required {
+				return nil, fmt.Errorf("%w: %q is required", ErrInvalidUserAttribute, definition.Name)
+			}
+			continue
+		}
+		v, err := definition.Normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[definition.Name] = v
+	}
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		if _, ok := normalized[name]; !ok && attributes[name] != nil {
+			return nil, fmt.Errorf("%w: %q is not defined", ErrInvalidUserAttribute, name)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// FormatUserAttributeValue returns string form of a normalized value, which UserAttribute.ParseValue parses back.
+func FormatUserAttributeValue(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,77 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+func TestValidateUserAttributes(t *testing.T) {
+	definitions := []domain.UserAttribute{
+		{Name: "age", Type: domain.UserAttributeNumber},
+		{Name: "vip", Type: domain.UserAttributeBool},
+		{Name: "joined", Type: domain.UserAttributeDate},
+		{Name: "plan", Type: domain.UserAttributeEnum, Values: []string{"free", "gold"}, Required: true},
+	}
+
+	attributes, err := domain.ValidateUserAttributes(definitions, map[string]any{"age": 30, "vip": true, "joined": "2024-02-29", "plan": "gold"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"age": 30.0, "vip": true, "joined": "2024-02-29", "plan": "gold"}, attributes)
+
+	attributes, err = domain.ValidateUserAttributes(definitions, map[string]any{"plan": "free", "age": nil})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"plan": "free"}, attributes, "nil values are dropped")
+
+	for name, attributes := range map[string]map[string]any{
+		"missing required": {"age": 30},
+		"number":           {"plan": "free", "age": "30"},
+		"bool":             {"plan": "free", "vip": "true"},
+		"date":             {"plan": "free", "joined": "2024-02-30"},
+		"enum":             {"plan": "silver"},
+		"undefined":        {"plan": "free", "nickname": "x"},
+	} {
+		_, err := domain.ValidateUserAttributes(definitions, attributes)
+		require.ErrorIs(t, err, domain.ErrInvalidUserAttribute, name)
+	}
+
+	attributes, err = domain.ValidateUserAttributes(nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, attributes)
+}
+
+func TestUserAttributeParseValue(t *testing.T) {
+	for _, tc := range []struct {
+		attribute domain.UserAttribute
+		value     string
+		expected  any
+	}{
+		{domain.UserAttribute{Type: domain.UserAttributeNumber}, "30.50", 30.5},
+		{domain.UserAttribute{Type: domain.UserAttributeBool}, "1", true},
+		{domain.UserAttribute{Type: domain.UserAttributeString}, "30", "30"},
+	} {
+		v, err := tc.attribute.ParseValue(tc.value)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, v)
+	}
+	require.Equal(t, "30.5", domain.FormatUserAttributeValue(30.5))
+	require.Equal(t, "true", domain.FormatUserAttributeValue(true))
+
+	_, err := domain.UserAttribute{Type: domain.UserAttributeNumber}.ParseValue("thirty")
+	require.ErrorIs(t, err, domain.ErrInvalidUserAttribute)
+}
+
+func TestUserAttributeValidate(t *testing.T) {
+	require.NoError(t, domain.UserAttribute{Name: "plan_2", Type: domain.UserAttributeEnum, Values: []string{"a"}}.Validate())
+	for _, attribute := range []domain.UserAttribute{
+		{Name: "Plan", Type: domain.UserAttributeString},
+		{Name: "plan'", Type: domain.UserAttributeString},
+		{Name: "2plan", Type: domain.UserAttributeString},
+		{Name: "plan", Type: "decimal"},
+		{Name: "plan", Type: domain.UserAttributeEnum},
+		{Name: "plan", Type: domain.UserAttributeString, Values: []string{"a"}},
+	} {
+		require.ErrorIs(t, attribute.Validate(), domain.ErrInvalidUserAttribute, attribute)
+	}
+}
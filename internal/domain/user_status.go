@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidStatusTransition = errors.New("invalid user status transition")
+
+// userStatusTransitions is the user status state machine, maps each status to statuses it could move to.
+var userStatusTransitions = map[UserStatus][]UserStatus{
+	UsereStatusNew:     {UserStatusActive, UserStatusBanned, UserStatusDeleted},
+	UserStatusActive:   {UserStatusInactive, UserStatusBanned, UserStatusDeleted},
+	UserStatusInactive: {UserStatusActive, UserStatusBanned, UserStatusDeleted},
+	UserStatusBanned:   {UserStatusActive, UserStatusDeleted},
+	UserStatusDeleted:  {UserStatusActive},
+}
+
+// CanTransitionTo reports whether status is allowed to move to given status.
+func (status UserStatus) CanTransitionTo(to UserStatus) bool {
+	for _, allowed := range userStatusTransitions[status] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UserStatusTransition is a change of user status, also kept as status history.
+type UserStatusTransition struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	From   UserStatus
+	To     UserStatus
+	Reason string
+	// ActorID is the user who changed status, zero for system changes.
+	ActorID uuid.UUID
+	At      time.Time
+}
+
+// TransitionStatus returns transition of user to given status,
+// ErrInvalidStatusTransition returned if state machine does not allow it.
+func (u User) TransitionStatus(to UserStatus, reason string, actorID uuid.UUID, at time.Time) (UserStatusTransition, error) {
+	if !u.Status.CanTransitionTo(to) {
+		return UserStatusTransition{}, fmt.Errorf("%w from %s to %s", ErrInvalidStatusTransition, u.Status, to)
+	}
+	return UserStatusTransition{
+		ID:      uuid.New(),
+		UserID:  u.ID,
+		From:    u.Status,
+		To:      to,
+		Reason:  reason,
+		ActorID: actorID,
+		At:      at,
+	}, nil
+}
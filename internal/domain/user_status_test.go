@@ -0,0 +1,52 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+func TestUserTransitionStatus(t *testing.T) {
+	for _, tc := range []struct {
+		from, to domain.UserStatus
+		allowed  bool
+	}{
+		{domain.UsereStatusNew, domain.UserStatusActive, true},
+		{domain.UsereStatusNew, domain.UserStatusInactive, false},
+		{domain.UserStatusActive, domain.UserStatusInactive, true},
+		{domain.UserStatusActive, domain.UserStatusActive, false},
+		{domain.UserStatusActive, domain.UserStatusBanned, true},
+		{domain.UserStatusInactive, domain.UserStatusActive, true},
+		{domain.UserStatusBanned, domain.UserStatusActive, true},
+		{domain.UserStatusBanned, domain.UserStatusBanned, false},
+		{domain.UserStatusBanned, domain.UserStatusInactive, false},
+		{domain.UserStatusDeleted, domain.UserStatusActive, true},
+		{domain.UserStatusDeleted, domain.UserStatusBanned, false},
+	} {
+		t.Run(tc.from.String()+" to "+tc.to.String(), func(t *testing.T) {
+			user := domain.User{ID: uuid.New(), Status: tc.from}
+			actor, at := uuid.New(), time.Now()
+
+			transition, err := user.TransitionStatus(tc.to, "reason", actor, at)
+			if !tc.allowed {
+				require.ErrorIs(t, err, domain.ErrInvalidStatusTransition)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEqual(t, uuid.Nil, transition.ID)
+			require.Equal(t, domain.UserStatusTransition{
+				ID:      transition.ID,
+				UserID:  user.ID,
+				From:    tc.from,
+				To:      tc.to,
+				Reason:  "reason",
+				ActorID: actor,
+				At:      at,
+			}, transition)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package audit
+
+import "github.com/amirzayi/clean_architect/pkg/paginate"
+
+// fields are queryable fields of audit log, same keys in all repositories.
+var fields = paginate.Fields{Filter: map[string]string{
+	"id":          "id",
+	"sequence":    "sequence",
+	"actor_id":    "actor_id",
+	"action":      "action",
+	"target_type": "target_type",
+	"target_id":   "target_id",
+	"request_id":  "request_id",
+	"created_at":  "created_at",
+}, Types: map[string]paginate.Field{
+	"id":         {Type: paginate.TypeUUID},
+	"sequence":   {Type: paginate.TypeInt},
+	"actor_id":   {Type: paginate.TypeUUID},
+	"created_at": {Type: paginate.TypeTime},
+}}
+
+// sortBySequence sorts entries newest first if pagination does not sort them.
+func sortBySequence(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 {
+		pagination.Sort = []paginate.Sort{{Field: "sequence", Arrange: paginate.SortOrderDescending}}
+	}
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+type auditKeyInMemoryRepo struct {
+	mu   sync.RWMutex
+	keys map[string]domain.AuditKey
+}
+
+func NewAuditKeyInMemoryRepo() *auditKeyInMemoryRepo {
+	return &auditKeyInMemoryRepo{keys: map[string]domain.AuditKey{}}
+}
+
+func (r *auditKeyInMemoryRepo) Create(_ context.Context, key domain.AuditKey) (domain.AuditKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.keys[key.Subject]; ok {
+		return stored, nil
+	}
+	key.Key = slices.Clone(key.Key)
+	r.keys[key.Subject] = key
+	return key, nil
+}
+
+func (r *auditKeyInMemoryRepo) Get(_ context.Context, subject string) (domain.AuditKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[subject]
+	if !ok {
+		return domain.AuditKey{}, domain.ErrAuditKeyNotFound
+	}
+	return key, nil
+}
+
+func (r *auditKeyInMemoryRepo) Delete(_ context.Context, subject string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, subject)
+	return nil
+}
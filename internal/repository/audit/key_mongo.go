@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+)
+
+const auditKeyCollectionName = "audit_key"
+
+type auditKeyMongoRepo struct {
+	db *mongo.Collection
+}
+
+func NewAuditKeyMongoRepository(db *mongo.Database) *auditKeyMongoRepo {
+	return &auditKeyMongoRepo{db: db.Collection(auditKeyCollectionName)}
+}
+
+// auditKeyDocument keeps field names same as sql columns.
+type auditKeyDocument struct {
+	Subject   string    `bson:"subject"`
+	Secret    []byte    `bson:"secret"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// Create inserts key only if subject has none and returns the stored one, so it is atomic without a unique index.
+func (r *auditKeyMongoRepo) Create(ctx context.Context, key domain.AuditKey) (domain.AuditKey, error) {
+	var doc auditKeyDocument
+	err := r.db.FindOneAndUpdate(ctx,
+		bson.M{"subject": key.Subject},
+		bson.M{"$setOnInsert": auditKeyDocument{
+			Subject:   key.Subject,
+			Secret:    key.Key,
+			CreatedAt: key.CreatedAt,
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&doc)
+	if err != nil {
+		return domain.AuditKey{}, err
+	}
+	return keyDocumentToDomain(doc), nil
+}
+
+func (r *auditKeyMongoRepo) Get(ctx context.Context, subject string) (domain.AuditKey, error) {
+	var doc auditKeyDocument
+	err := r.db.FindOne(ctx, bson.M{"subject": subject}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.AuditKey{}, domain.ErrAuditKeyNotFound
+	}
+	if err != nil {
+		return domain.AuditKey{}, err
+	}
+	return keyDocumentToDomain(doc), nil
+}
+
+func (r *auditKeyMongoRepo) Delete(ctx context.Context, subject string) error {
+	_, err := r.db.DeleteOne(ctx, bson.M{"subject": subject})
+	return err
+}
+
+func keyDocumentToDomain(doc auditKeyDocument) domain.AuditKey {
+	return domain.AuditKey{
+		Subject:   doc.Subject,
+		Key:       doc.Secret,
+		CreatedAt: doc.CreatedAt,
+	}
+}
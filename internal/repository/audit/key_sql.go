@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/amirzayi/clean_architect/infra/migrations/model"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/sqlutil"
+)
+
+const auditKeyTableName = "audit_key"
+
+type auditKeySQLRepo struct {
+	db    *sqlx.DB
+	table string
+}
+
+func NewAuditKeySQLRepository(db *sqlx.DB) *auditKeySQLRepo {
+	return &auditKeySQLRepo{
+		db:    db,
+		table: sqlutil.QuoteIdentifier(db.DriverName(), auditKeyTableName),
+	}
+}
+
+// Create relies on primary key of subject, so concurrent calls get the same key.
+func (r *auditKeySQLRepo) Create(ctx context.Context, key domain.AuditKey) (domain.AuditKey, error) {
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (subject,secret,created_at)
+	VALUES(:subject,:secret,:created_at)`, r.table), model.ConvertAuditKeyFromDomain(key))
+	if sqlutil.IsUniqueViolation(err) {
+		return r.Get(ctx, key.Subject)
+	}
+	return key, err
+}
+
+func (r *auditKeySQLRepo) Get(ctx context.Context, subject string) (domain.AuditKey, error) {
+	var key model.AuditKey
+	err := r.db.GetContext(ctx, &key, r.db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE subject=?", r.table)), subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.AuditKey{}, domain.ErrAuditKeyNotFound
+	}
+	return model.ConvertAuditKeyToDomain(key), err
+}
+
+func (r *auditKeySQLRepo) Delete(ctx context.Context, subject string) error {
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(fmt.Sprintf("DELETE FROM %s WHERE subject=?", r.table)), subject)
+	return err
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+type auditInMemoryRepo struct {
+	mu sync.RWMutex
+	// entries are kept in order of sequence
+	entries []domain.AuditEntry
+}
+
+func NewAuditInMemoryRepo() *auditInMemoryRepo {
+	return &auditInMemoryRepo{}
+}
+
+func (r *auditInMemoryRepo) Append(_ context.Context, entry domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) > 0 && r.entries[len(r.entries)-1].Sequence >= entry.Sequence {
+		return domain.ErrAuditSequenceConflict
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *auditInMemoryRepo) Last(_ context.Context) (domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.entries) == 0 {
+		return domain.AuditEntry{}, nil
+	}
+	return r.entries[len(r.entries)-1], nil
+}
+
+// List supports only equal filters and sequence sort.
+func (r *auditInMemoryRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.AuditEntry, error) {
+	if pagination.Cursor != nil {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+	sortBySequence(pagination)
+	scope := tenant.ScopeOf(ctx)
+
+	r.mu.RLock()
+	entries := slices.DeleteFunc(slices.Clone(r.entries), func(e domain.AuditEntry) bool {
+		return !scope.Includes(e.TenantID) || !matchFilters(e, pagination.Filters) ||
+			!paginate.Match(expr, func(filter paginate.Filter) bool { return matchFilters(e, []paginate.Filter{filter}) })
+	})
+	r.mu.RUnlock()
+
+	if pagination.Sort[0].Field != "sequence" || pagination.Sort[0].Arrange == paginate.SortOrderDescending {
+		slices.Reverse(entries)
+	}
+
+	pagination.SetTotalItems(int64(len(entries)))
+
+	start := min((pagination.Page-1)*pagination.PerPage, len(entries))
+	end := min(start+pagination.PerPage, len(entries))
+	return entries[start:end], nil
+}
+
+func matchFilters(e domain.AuditEntry, filters []paginate.Filter) bool {
+	for _, filter := range filters {
+		var value string
+		switch filter.Key {
+		case "actor_id":
+			value = e.ActorID.String()
+		case "action":
+			value = string(e.Action)
+		case "target_type":
+			value = e.TargetType
+		case "target_id":
+			value = e.TargetID
+		case "request_id":
+			value = e.RequestID
+		default:
+			continue
+		}
+		if filter.Condition == paginate.FilterEqual && value != filter.Value {
+			return false
+		}
+	}
+	return true
+}
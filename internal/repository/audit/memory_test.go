@@ -0,0 +1,21 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/audit"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+)
+
+func TestAuditInMemoryRepo(t *testing.T) {
+	repotest.RunAuditSuite(t, func(t *testing.T) repository.Audit {
+		return audit.NewAuditInMemoryRepo()
+	})
+}
+
+func TestAuditKeyInMemoryRepo(t *testing.T) {
+	repotest.RunAuditKeySuite(t, func(t *testing.T) repository.AuditKey {
+		return audit.NewAuditKeyInMemoryRepo()
+	})
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/mongoutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const auditLogCollectionName = "audit_log"
+
+type auditMongoRepo struct {
+	db *mongo.Collection
+}
+
+func NewAuditMongoRepository(db *mongo.Database) *auditMongoRepo {
+	return &auditMongoRepo{db: db.Collection(auditLogCollectionName)}
+}
+
+// auditLogDocument keeps field names same as sql columns so audit fields need no mapping,
+// actor id is kept as string to be filterable by query values.
+type auditLogDocument struct {
+	ID         uuid.UUID            `bson:"id"`
+	TenantID   uuid.UUID            `bson:"tenant_id"`
+	Sequence   int64                `bson:"sequence"`
+	ActorID    string               `bson:"actor_id"`
+	ActorRole  string               `bson:"actor_role"`
+	Action     string               `bson:"action"`
+	TargetType string               `bson:"target_type"`
+	TargetID   string               `bson:"target_id"`
+	Changes    []domain.AuditChange `bson:"changes"`
+	IP         string               `bson:"ip"`
+	RequestID  string               `bson:"request_id"`
+	CreatedAt  time.Time            `bson:"created_at"`
+	PrevHash   string               `bson:"prev_hash"`
+	Hash       string               `bson:"hash"`
+}
+
+// Append checks sequence before inserting which is not atomic without a unique index on sequence.
+func (r *auditMongoRepo) Append(ctx context.Context, entry domain.AuditEntry) error {
+	count, err := r.db.CountDocuments(ctx, bson.M{"sequence": entry.Sequence})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrAuditSequenceConflict
+	}
+
+	var actorID string
+	if entry.ActorID != uuid.Nil {
+		actorID = entry.ActorID.String()
+	}
+	_, err = r.db.InsertOne(ctx, auditLogDocument{
+		ID:         entry.ID,
+		TenantID:   entry.TenantID,
+		Sequence:   entry.Sequence,
+		ActorID:    actorID,
+		ActorRole:  entry.ActorRole,
+		Action:     string(entry.Action),
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Changes:    entry.Changes,
+		IP:         entry.IP,
+		RequestID:  entry.RequestID,
+		CreatedAt:  entry.At,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	})
+	return err
+}
+
+func (r *auditMongoRepo) Last(ctx context.Context) (domain.AuditEntry, error) {
+	var doc auditLogDocument
+	err := r.db.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"sequence": -1})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.AuditEntry{}, nil
+	}
+	if err != nil {
+		return domain.AuditEntry{}, err
+	}
+	return documentToDomain(doc), nil
+}
+
+func (r *auditMongoRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.AuditEntry, error) {
+	sortBySequence(pagination)
+	docs, err := mongoutil.PaginatedList[auditLogDocument](ctx, r.db, tenant.ScopeOf(ctx), pagination, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.AuditEntry, 0, len(docs))
+	for _, doc := range docs {
+		entries = append(entries, documentToDomain(doc))
+	}
+	return entries, nil
+}
+
+func documentToDomain(doc auditLogDocument) domain.AuditEntry {
+	// absent actor is kept as empty string and parsed to uuid.Nil
+	actorID, _ := uuid.Parse(doc.ActorID)
+	return domain.AuditEntry{
+		ID:         doc.ID,
+		TenantID:   doc.TenantID,
+		Sequence:   doc.Sequence,
+		ActorID:    actorID,
+		ActorRole:  doc.ActorRole,
+		Action:     domain.AuditAction(doc.Action),
+		TargetType: doc.TargetType,
+		TargetID:   doc.TargetID,
+		Changes:    doc.Changes,
+		IP:         doc.IP,
+		RequestID:  doc.RequestID,
+		At:         doc.CreatedAt,
+		PrevHash:   doc.PrevHash,
+		Hash:       doc.Hash,
+	}
+}
@@ -0,0 +1,52 @@
+package audit_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/audit"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+)
+
+// TestAuditMongoRepo runs only if MONGODB_URI is set, e.g. mongodb://127.0.0.1:27017
+func TestAuditMongoRepo(t *testing.T) {
+	uri := os.Getenv("MONGODB_URI")
+	if uri == "" {
+		t.Skip("MONGODB_URI is not set")
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	repotest.RunAuditSuite(t, func(t *testing.T) repository.Audit {
+		db := client.Database(strings.NewReplacer("/", "_", " ", "_").Replace(t.Name()))
+		t.Cleanup(func() { db.Drop(context.Background()) })
+		return audit.NewAuditMongoRepository(db)
+	})
+}
+
+// TestAuditKeyMongoRepo runs only if MONGODB_URI is set, same as TestAuditMongoRepo.
+func TestAuditKeyMongoRepo(t *testing.T) {
+	uri := os.Getenv("MONGODB_URI")
+	if uri == "" {
+		t.Skip("MONGODB_URI is not set")
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	repotest.RunAuditKeySuite(t, func(t *testing.T) repository.AuditKey {
+		db := client.Database(strings.NewReplacer("/", "_", " ", "_").Replace(t.Name()))
+		t.Cleanup(func() { db.Drop(context.Background()) })
+		return audit.NewAuditKeyMongoRepository(db)
+	})
+}
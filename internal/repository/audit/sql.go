@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/amirzayi/clean_architect/infra/migrations/model"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/sqlutil"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+)
+
+const auditLogTableName = "audit_log"
+
+type auditSQLRepo struct {
+	db    *sqlx.DB
+	table string
+}
+
+func NewAuditSQLRepository(db *sqlx.DB) *auditSQLRepo {
+	return &auditSQLRepo{
+		db:    db,
+		table: sqlutil.QuoteIdentifier(db.DriverName(), auditLogTableName),
+	}
+}
+
+func (r *auditSQLRepo) Append(ctx context.Context, entry domain.AuditEntry) error {
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(id,tenant_id,sequence,actor_id,actor_role,action,target_type,target_id,changes,ip,request_id,created_at,prev_hash,hash)
+	VALUES(:id,:tenant_id,:sequence,:actor_id,:actor_role,:action,:target_type,:target_id,:changes,:ip,:request_id,:created_at,:prev_hash,:hash)`, r.table),
+		model.ConvertAuditLogFromDomain(entry))
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrAuditSequenceConflict
+	}
+	return err
+}
+
+func (r *auditSQLRepo) Last(ctx context.Context) (domain.AuditEntry, error) {
+	var log model.AuditLog
+	err := r.db.GetContext(ctx, &log, fmt.Sprintf("SELECT * FROM %s ORDER BY sequence DESC LIMIT 1", r.table))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.AuditEntry{}, nil
+	}
+	return model.ConvertAuditLogToDomain(log), err
+}
+
+func (r *auditSQLRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.AuditEntry, error) {
+	sortBySequence(pagination)
+	logs, err := sqlutil.PaginatedList[model.AuditLog](ctx, r.db, r.table, tenant.ScopeOf(ctx), pagination, fields)
+	return model.ConvertAuditLogsToDomains(logs), err
+}
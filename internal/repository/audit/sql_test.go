@@ -0,0 +1,44 @@
+package audit_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/amirzayi/clean_architect/infra/migrations"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/audit"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+)
+
+func TestAuditSQLiteRepo(t *testing.T) {
+	repotest.RunAuditSuite(t, func(t *testing.T) repository.Audit {
+		return audit.NewAuditSQLRepository(newSQLiteDB(t))
+	})
+}
+
+func TestAuditKeySQLiteRepo(t *testing.T) {
+	repotest.RunAuditKeySuite(t, func(t *testing.T) repository.AuditKey {
+		return audit.NewAuditKeySQLRepository(newSQLiteDB(t))
+	})
+}
+
+func newSQLiteDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	db, err := sqlx.Connect("sqlite", filepath.Join(t.TempDir(), "test.sqlite")+"?_time_format=sqlite")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db.DB, "sqlite")
+	require.NoError(t, err)
+	if err = migrator.Up(); err != nil && err != migrate.ErrNoChange {
+		require.NoError(t, err)
+	}
+	return db
+}
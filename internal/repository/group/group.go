@@ -0,0 +1,43 @@
+package group
+
+import (
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+// fields are queryable fields of groups, same keys in all repositories.
+var fields = paginate.Fields{Filter: map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}, Types: map[string]paginate.Field{
+	"id":         {Type: paginate.TypeUUID},
+	"created_at": {Type: paginate.TypeTime},
+	"updated_at": {Type: paginate.TypeTime},
+}}
+
+// memberFields are queryable fields of group members, same keys in all repositories.
+var memberFields = paginate.Fields{Filter: map[string]string{
+	"kind":       "member_kind",
+	"member_id":  "member_id",
+	"created_at": "created_at",
+}, Types: map[string]paginate.Field{
+	"kind":       {Type: paginate.TypeEnum, Values: []string{string(domain.GroupMemberUser), string(domain.GroupMemberGroup)}},
+	"member_id":  {Type: paginate.TypeUUID},
+	"created_at": {Type: paginate.TypeTime},
+}}
+
+// sortByName sorts groups by name if pagination does not sort them.
+func sortByName(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 {
+		pagination.Sort = []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}}
+	}
+}
+
+// sortByCreation sorts group members oldest first if pagination does not sort them.
+func sortByCreation(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 {
+		pagination.Sort = []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderAscending}}
+	}
+}
@@ -0,0 +1,234 @@
+package group
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+type memberKey struct {
+	groupID  uuid.UUID
+	kind     domain.GroupMemberKind
+	memberID uuid.UUID
+}
+
+// storedMember is a group member with tenant of its group.
+type storedMember struct {
+	domain.GroupMember
+	tenantID uuid.UUID
+}
+
+type groupInMemoryRepo struct {
+	mu      sync.RWMutex
+	groups  map[uuid.UUID]domain.Group
+	members map[memberKey]storedMember
+}
+
+func NewGroupInMemoryRepo() *groupInMemoryRepo {
+	return &groupInMemoryRepo{
+		groups:  make(map[uuid.UUID]domain.Group),
+		members: make(map[memberKey]storedMember),
+	}
+}
+
+func (r *groupInMemoryRepo) Create(ctx context.Context, group domain.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	group.TenantID = tenant.FromContext(ctx)
+	for _, g := range r.groups {
+		if g.ID == group.ID || (g.TenantID == group.TenantID && g.Name == group.Name) {
+			return domain.ErrGroupAlreadyExists
+		}
+	}
+	group.Roles = slices.Clone(group.Roles)
+	r.groups[group.ID] = group
+	return nil
+}
+
+func (r *groupInMemoryRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	group, ok := r.get(ctx, id)
+	if !ok {
+		return domain.Group{}, domain.ErrGroupNotFound
+	}
+	return group, nil
+}
+
+// List supports only equal and not equal filters and name sort.
+func (r *groupInMemoryRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Group, error) {
+	if pagination.Cursor != nil {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+	sortByName(pagination)
+	scope := tenant.ScopeOf(ctx)
+
+	r.mu.RLock()
+	groups := make([]domain.Group, 0, len(r.groups))
+	for _, g := range r.groups {
+		if values := map[string]string{"id": g.ID.String(), "name": g.Name}; scope.Includes(g.TenantID) && matchExpression(pagination.Filters, expr, values) {
+			groups = append(groups, clone(g))
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(groups, compareByName)
+	if pagination.Sort[0].Field != "name" || pagination.Sort[0].Arrange == paginate.SortOrderDescending {
+		slices.Reverse(groups)
+	}
+	return page(groups, pagination), nil
+}
+
+func (r *groupInMemoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.get(ctx, id); !ok {
+		return domain.ErrGroupNotFound
+	}
+	delete(r.groups, id)
+	for key := range r.members {
+		if key.groupID == id || (key.kind == domain.GroupMemberGroup && key.memberID == id) {
+			delete(r.members, key)
+		}
+	}
+	return nil
+}
+
+func (r *groupInMemoryRepo) AddMember(ctx context.Context, member domain.GroupMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{member.GroupID, member.Kind, member.MemberID}
+	if _, ok := r.members[key]; ok {
+		return domain.ErrGroupMemberAlreadyExists
+	}
+	r.members[key] = storedMember{GroupMember: member, tenantID: tenant.FromContext(ctx)}
+	return nil
+}
+
+func (r *groupInMemoryRepo) RemoveMember(ctx context.Context, groupID uuid.UUID, kind domain.GroupMemberKind, memberID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{groupID, kind, memberID}
+	if m, ok := r.members[key]; !ok || !tenant.ScopeOf(ctx).Includes(m.tenantID) {
+		return domain.ErrGroupMemberNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
+
+// Members supports only equal and not equal filters and created_at sort.
+func (r *groupInMemoryRepo) Members(ctx context.Context, groupID uuid.UUID, pagination *paginate.Pagination) ([]domain.GroupMember, error) {
+	if pagination.Cursor != nil {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+	sortByCreation(pagination)
+	scope := tenant.ScopeOf(ctx)
+
+	r.mu.RLock()
+	var members []domain.GroupMember
+	for key, m := range r.members {
+		if key.groupID == groupID && scope.Includes(m.tenantID) &&
+			matchExpression(pagination.Filters, expr, map[string]string{"kind": string(m.Kind), "member_id": m.MemberID.String()}) {
+			members = append(members, m.GroupMember)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(members, func(a, b domain.GroupMember) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.MemberID.String(), b.MemberID.String()))
+	})
+	if pagination.Sort[0].Field != "created_at" || pagination.Sort[0].Arrange == paginate.SortOrderDescending {
+		slices.Reverse(members)
+	}
+	return page(members, pagination), nil
+}
+
+func (r *groupInMemoryRepo) GroupsOf(ctx context.Context, kind domain.GroupMemberKind, memberID uuid.UUID) ([]domain.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var groups []domain.Group
+	for key := range r.members {
+		if key.kind != kind || key.memberID != memberID {
+			continue
+		}
+		if g, ok := r.get(ctx, key.groupID); ok {
+			groups = append(groups, clone(g))
+		}
+	}
+	slices.SortFunc(groups, compareByName)
+	return groups, nil
+}
+
+// get returns group of given id if it is in tenant scope of ctx, caller must hold the lock.
+func (r *groupInMemoryRepo) get(ctx context.Context, id uuid.UUID) (domain.Group, bool) {
+	group, ok := r.groups[id]
+	if !ok || !tenant.ScopeOf(ctx).Includes(group.TenantID) {
+		return domain.Group{}, false
+	}
+	return clone(group), true
+}
+
+// clone copies roles of group so stored groups are never changed by callers.
+func clone(group domain.Group) domain.Group {
+	group.Roles = slices.Clone(group.Roles)
+	return group
+}
+
+func compareByName(a, b domain.Group) int {
+	return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID.String(), b.ID.String()))
+}
+
+func matchFilters(filters []paginate.Filter, values map[string]string) bool {
+	for _, filter := range filters {
+		value, ok := values[filter.Key]
+		if !ok {
+			continue
+		}
+		switch filter.Condition {
+		case paginate.FilterEqual:
+			if value != filter.Value {
+				return false
+			}
+		case paginate.FilterNotEqual:
+			if value == filter.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func page[T any](items []T, pagination *paginate.Pagination) []T {
+	pagination.SetTotalItems(int64(len(items)))
+
+	start := min((pagination.Page-1)*pagination.PerPage, len(items))
+	end := min(start+pagination.PerPage, len(items))
+	return items[start:end]
+}
+
+// matchExpression reports whether values match both filters and filter expression of pagination.
+func matchExpression(filters []paginate.Filter, expr paginate.Expr, values map[string]string) bool {
+	return matchFilters(filters, values) &&
+		paginate.Match(expr, func(filter paginate.Filter) bool { return matchFilters([]paginate.Filter{filter}, values) })
+}
@@ -0,0 +1,15 @@
+package group_test
+
+import (
+	"testing"
+
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/group"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+)
+
+func TestGroupInMemoryRepo(t *testing.T) {
+	repotest.RunGroupSuite(t, func(t *testing.T) repository.Group {
+		return group.NewGroupInMemoryRepo()
+	})
+}
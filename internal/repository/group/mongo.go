@@ -0,0 +1,225 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/mongoutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const (
+	groupCollectionName       = "user_group"
+	groupMemberCollectionName = "user_group_member"
+)
+
+type groupMongoRepo struct {
+	db      *mongo.Collection
+	members *mongo.Collection
+}
+
+func NewGroupMongoRepository(db *mongo.Database) *groupMongoRepo {
+	return &groupMongoRepo{
+		db:      db.Collection(groupCollectionName),
+		members: db.Collection(groupMemberCollectionName),
+	}
+}
+
+// groupDocument and memberDocument keep field names same as sql columns so fields need no mapping,
+// member ids are kept as strings to be filterable by query values.
+type groupDocument struct {
+	ID        uuid.UUID `bson:"id"`
+	TenantID  uuid.UUID `bson:"tenant_id"`
+	Name      string    `bson:"name"`
+	Roles     []string  `bson:"roles"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type memberDocument struct {
+	GroupID    uuid.UUID `bson:"group_id"`
+	TenantID   uuid.UUID `bson:"tenant_id"`
+	MemberKind string    `bson:"member_kind"`
+	MemberID   string    `bson:"member_id"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+// Create checks name before inserting which is not atomic without a unique index on tenant and name.
+func (r *groupMongoRepo) Create(ctx context.Context, group domain.Group) error {
+	group.TenantID = tenant.FromContext(ctx)
+	count, err := r.db.CountDocuments(ctx, bson.M{"$or": bson.A{
+		bson.M{"id": group.ID},
+		scoped(ctx, bson.M{"name": group.Name}),
+	}})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrGroupAlreadyExists
+	}
+
+	_, err = r.db.InsertOne(ctx, groupFromDomain(group))
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrGroupAlreadyExists
+	}
+	return err
+}
+
+func (r *groupMongoRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.Group, error) {
+	var doc groupDocument
+	err := r.db.FindOne(ctx, scoped(ctx, bson.M{"id": id})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Group{}, domain.ErrGroupNotFound
+	}
+	if err != nil {
+		return domain.Group{}, err
+	}
+	return groupToDomain(doc), nil
+}
+
+func (r *groupMongoRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Group, error) {
+	sortByName(pagination)
+	docs, err := mongoutil.PaginatedList[groupDocument](ctx, r.db, tenant.ScopeOf(ctx), pagination, fields)
+	if err != nil {
+		return nil, err
+	}
+	return groupsToDomains(docs), nil
+}
+
+func (r *groupMongoRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.DeleteOne(ctx, scoped(ctx, bson.M{"id": id}))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrGroupNotFound
+	}
+	_, err = r.members.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"group_id": id},
+		bson.M{"member_kind": string(domain.GroupMemberGroup), "member_id": id.String()},
+	}})
+	return err
+}
+
+// AddMember inserts member only if it does not exist, so it is atomic without a unique index.
+func (r *groupMongoRepo) AddMember(ctx context.Context, member domain.GroupMember) error {
+	res, err := r.members.UpdateOne(ctx,
+		bson.M{"group_id": member.GroupID, "member_kind": string(member.Kind), "member_id": member.MemberID.String()},
+		bson.M{"$setOnInsert": bson.M{"tenant_id": tenant.FromContext(ctx), "created_at": member.CreatedAt}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	if res.UpsertedCount == 0 {
+		return domain.ErrGroupMemberAlreadyExists
+	}
+	return nil
+}
+
+func (r *groupMongoRepo) RemoveMember(ctx context.Context, groupID uuid.UUID, kind domain.GroupMemberKind, memberID uuid.UUID) error {
+	res, err := r.members.DeleteOne(ctx, scoped(ctx,
+		bson.M{"group_id": groupID, "member_kind": string(kind), "member_id": memberID.String()}))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrGroupMemberNotFound
+	}
+	return nil
+}
+
+func (r *groupMongoRepo) Members(ctx context.Context, groupID uuid.UUID, pagination *paginate.Pagination) ([]domain.GroupMember, error) {
+	sortByCreation(pagination)
+	docs, err := mongoutil.PaginatedList[memberDocument](ctx, r.members, tenant.ScopeOf(ctx), pagination, memberFields,
+		bson.E{Key: "group_id", Value: groupID})
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]domain.GroupMember, 0, len(docs))
+	for _, doc := range docs {
+		memberID, _ := uuid.Parse(doc.MemberID)
+		members = append(members, domain.GroupMember{
+			GroupID:   doc.GroupID,
+			Kind:      domain.GroupMemberKind(doc.MemberKind),
+			MemberID:  memberID,
+			CreatedAt: doc.CreatedAt,
+		})
+	}
+	return members, nil
+}
+
+func (r *groupMongoRepo) GroupsOf(ctx context.Context, kind domain.GroupMemberKind, memberID uuid.UUID) ([]domain.Group, error) {
+	groupIDs, err := r.members.Distinct(ctx, "group_id",
+		scoped(ctx, bson.M{"member_kind": string(kind), "member_id": memberID.String()}))
+	if err != nil {
+		return nil, err
+	}
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.db.Find(ctx, scoped(ctx, bson.M{"id": bson.M{"$in": groupIDs}}),
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []groupDocument
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return groupsToDomains(docs), nil
+}
+
+// scoped restricts filter to documents of tenant scope of ctx.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	return mongoutil.WithTenant(tenant.ScopeOf(ctx), filter)
+}
+
+func groupFromDomain(group domain.Group) groupDocument {
+	roles := make([]string, 0, len(group.Roles))
+	for _, role := range group.Roles {
+		roles = append(roles, string(role))
+	}
+	return groupDocument{
+		ID:        group.ID,
+		TenantID:  group.TenantID,
+		Name:      group.Name,
+		Roles:     roles,
+		CreatedAt: group.CreatedAt,
+		UpdatedAt: group.UpdatedAt,
+	}
+}
+
+func groupToDomain(doc groupDocument) domain.Group {
+	var roles []domain.UserRole
+	for _, role := range doc.Roles {
+		roles = append(roles, domain.UserRole(role))
+	}
+	return domain.Group{
+		ID:        doc.ID,
+		TenantID:  doc.TenantID,
+		Name:      doc.Name,
+		Roles:     roles,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+	}
+}
+
+func groupsToDomains(docs []groupDocument) []domain.Group {
+	groups := make([]domain.Group, 0, len(docs))
+	for _, doc := range docs {
+		groups = append(groups, groupToDomain(doc))
+	}
+	return groups
+}
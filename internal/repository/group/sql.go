@@ -0,0 +1,147 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/amirzayi/clean_architect/infra/migrations/model"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/sqlutil"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const (
+	groupTableName       = "user_group"
+	groupMemberTableName = "user_group_member"
+)
+
+type groupSQLRepo struct {
+	db          *sqlx.DB
+	table       string
+	memberTable string
+}
+
+func NewGroupSQLRepository(db *sqlx.DB) *groupSQLRepo {
+	return &groupSQLRepo{
+		db:          db,
+		table:       sqlutil.QuoteIdentifier(db.DriverName(), groupTableName),
+		memberTable: sqlutil.QuoteIdentifier(db.DriverName(), groupMemberTableName),
+	}
+}
+
+func (r *groupSQLRepo) Create(ctx context.Context, group domain.Group) error {
+	group.TenantID = tenant.FromContext(ctx)
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(id,tenant_id,name,roles,created_at,updated_at)
+	VALUES(:id,:tenant_id,:name,:roles,:created_at,:updated_at)`, r.table),
+		model.ConvertGroupFromDomain(group))
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrGroupAlreadyExists
+	}
+	return err
+}
+
+func (r *groupSQLRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.Group, error) {
+	var group model.Group
+	cond, args := tenantCondition(ctx)
+	err := r.db.GetContext(ctx, &group,
+		r.db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE id=?%s LIMIT 1", r.table, cond)),
+		append([]any{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Group{}, domain.ErrGroupNotFound
+	}
+	return model.ConvertGroupToDomain(group), err
+}
+
+func (r *groupSQLRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Group, error) {
+	sortByName(pagination)
+	groups, err := sqlutil.PaginatedList[model.Group](ctx, r.db, r.table, tenant.ScopeOf(ctx), pagination, fields)
+	return model.ConvertGroupsToDomains(groups), err
+}
+
+func (r *groupSQLRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	cond, args := tenantCondition(ctx)
+	res, err := tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf("DELETE FROM %s WHERE id=?%s", r.table, cond)),
+		append([]any{id}, args...)...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrGroupNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf(
+		"DELETE FROM %s WHERE (group_id=? OR (member_kind=? AND member_id=?))%s", r.memberTable, cond)),
+		append([]any{id, string(domain.GroupMemberGroup), id}, args...)...)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *groupSQLRepo) AddMember(ctx context.Context, member domain.GroupMember) error {
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(group_id,tenant_id,member_kind,member_id,created_at)
+	VALUES(:group_id,:tenant_id,:member_kind,:member_id,:created_at)`, r.memberTable),
+		model.ConvertGroupMemberFromDomain(member, tenant.FromContext(ctx)))
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrGroupMemberAlreadyExists
+	}
+	return err
+}
+
+func (r *groupSQLRepo) RemoveMember(ctx context.Context, groupID uuid.UUID, kind domain.GroupMemberKind, memberID uuid.UUID) error {
+	cond, args := tenantCondition(ctx)
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(fmt.Sprintf(
+		"DELETE FROM %s WHERE group_id=? AND member_kind=? AND member_id=?%s", r.memberTable, cond)),
+		append([]any{groupID, string(kind), memberID}, args...)...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrGroupMemberNotFound
+	}
+	return nil
+}
+
+func (r *groupSQLRepo) Members(ctx context.Context, groupID uuid.UUID, pagination *paginate.Pagination) ([]domain.GroupMember, error) {
+	sortByCreation(pagination)
+	members, err := sqlutil.PaginatedList[model.GroupMember](ctx, r.db, r.memberTable, tenant.ScopeOf(ctx), pagination,
+		memberFields, sqlutil.Predicate{Query: "group_id=?", Args: []any{groupID}})
+	return model.ConvertGroupMembersToDomains(members), err
+}
+
+func (r *groupSQLRepo) GroupsOf(ctx context.Context, kind domain.GroupMemberKind, memberID uuid.UUID) ([]domain.Group, error) {
+	var groups []model.Group
+	cond, args := tenantCondition(ctx)
+	err := r.db.SelectContext(ctx, &groups, r.db.Rebind(fmt.Sprintf(
+		"SELECT * FROM %s WHERE id IN (SELECT group_id FROM %s WHERE member_kind=? AND member_id=?)%s ORDER BY name",
+		r.table, r.memberTable, cond)),
+		append([]any{string(kind), memberID}, args...)...)
+	return model.ConvertGroupsToDomains(groups), err
+}
+
+// tenantCondition returns condition of tenant scope of ctx, to be appended to where clause.
+func tenantCondition(ctx context.Context) (string, []any) {
+	return sqlutil.TenantCondition(tenant.ScopeOf(ctx))
+}
@@ -0,0 +1,32 @@
+package invitation
+
+import (
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+// fields are queryable fields of invitations, same keys in all repositories.
+var fields = paginate.Fields{Filter: map[string]string{
+	"id":         "id",
+	"email":      "email",
+	"role":       "role",
+	"status":     "status",
+	"invited_by": "invited_by",
+	"expires_at": "expires_at",
+	"created_at": "created_at",
+}, Types: map[string]paginate.Field{
+	"id":   {Type: paginate.TypeUUID},
+	"role": {Type: paginate.TypeEnum, Values: []string{string(domain.UserRoleNormal), string(domain.UserRoleAdmin)}},
+	"status": {Type: paginate.TypeEnum, Values: []string{string(domain.InvitationPending), string(domain.InvitationAccepted),
+		string(domain.InvitationRevoked), string(domain.InvitationExpired)}},
+	"invited_by": {Type: paginate.TypeUUID},
+	"expires_at": {Type: paginate.TypeTime},
+	"created_at": {Type: paginate.TypeTime},
+}}
+
+// sortByCreation sorts invitations newest first if pagination does not sort them.
+func sortByCreation(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 {
+		pagination.Sort = []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderDescending}}
+	}
+}
@@ -0,0 +1,172 @@
+package invitation
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+type invitationInMemoryRepo struct {
+	mu          sync.RWMutex
+	invitations map[uuid.UUID]domain.Invitation
+}
+
+func NewInvitationInMemoryRepo() *invitationInMemoryRepo {
+	return &invitationInMemoryRepo{invitations: make(map[uuid.UUID]domain.Invitation)}
+}
+
+func (r *invitationInMemoryRepo) Create(ctx context.Context, invitation domain.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation.TenantID = tenant.FromContext(ctx)
+	r.invitations[invitation.ID] = invitation
+	return nil
+}
+
+func (r *invitationInMemoryRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invitation, ok := r.get(ctx, id)
+	if !ok {
+		return domain.Invitation{}, domain.ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+func (r *invitationInMemoryRepo) GetByTokenHash(ctx context.Context, tokenHash string) (domain.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scope := tenant.ScopeOf(ctx)
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash && scope.Includes(invitation.TenantID) {
+			return invitation, nil
+		}
+	}
+	return domain.Invitation{}, domain.ErrInvitationNotFound
+}
+
+// List supports only equal filters and created_at sort.
+func (r *invitationInMemoryRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Invitation, error) {
+	if pagination.Cursor != nil {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+	sortByCreation(pagination)
+	scope := tenant.ScopeOf(ctx)
+
+	r.mu.RLock()
+	invitations := make([]domain.Invitation, 0, len(r.invitations))
+	for _, invitation := range r.invitations {
+		if scope.Includes(invitation.TenantID) && matchFilters(invitation, pagination.Filters) &&
+			paginate.Match(expr, func(filter paginate.Filter) bool { return matchFilters(invitation, []paginate.Filter{filter}) }) {
+			invitations = append(invitations, invitation)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(invitations, func(a, b domain.Invitation) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID.String(), b.ID.String()))
+	})
+	if pagination.Sort[0].Field != "created_at" || pagination.Sort[0].Arrange == paginate.SortOrderDescending {
+		slices.Reverse(invitations)
+	}
+
+	pagination.SetTotalItems(int64(len(invitations)))
+
+	start := min((pagination.Page-1)*pagination.PerPage, len(invitations))
+	end := min(start+pagination.PerPage, len(invitations))
+	return invitations[start:end], nil
+}
+
+func matchFilters(invitation domain.Invitation, filters []paginate.Filter) bool {
+	for _, filter := range filters {
+		var value string
+		switch filter.Key {
+		case "email":
+			value = invitation.Email
+		case "role":
+			value = string(invitation.Role)
+		case "status":
+			value = string(invitation.Status)
+		case "invited_by":
+			value = invitation.InvitedBy.String()
+		default:
+			continue
+		}
+		if filter.Condition == paginate.FilterEqual && value != filter.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *invitationInMemoryRepo) Update(ctx context.Context, invitation domain.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.get(ctx, invitation.ID)
+	if !ok {
+		return domain.ErrInvitationNotFound
+	}
+	if stored.Status != domain.InvitationPending {
+		return domain.ErrInvitationNotPending
+	}
+	stored.Status = invitation.Status
+	stored.TokenHash = invitation.TokenHash
+	stored.UserID = invitation.UserID
+	stored.ExpiresAt = invitation.ExpiresAt
+	stored.SentAt = invitation.SentAt
+	stored.UpdatedAt = invitation.UpdatedAt
+	r.invitations[invitation.ID] = stored
+	return nil
+}
+
+func (r *invitationInMemoryRepo) Anonymize(ctx context.Context, user, anonymized domain.User) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope := tenant.ScopeOf(ctx)
+	var invitations []domain.Invitation
+	for id, invitation := range r.invitations {
+		if !scope.Includes(invitation.TenantID) {
+			continue
+		}
+		if invitation.Email == user.Email || (invitation.UserID != uuid.Nil && invitation.UserID == user.ID) {
+			invitation.Email = anonymized.Email
+			invitation.UpdatedAt = anonymized.UpdatedAt
+			r.invitations[id] = invitation
+		}
+		if invitation.Email == anonymized.Email {
+			invitations = append(invitations, invitation)
+		}
+	}
+	slices.SortFunc(invitations, func(a, b domain.Invitation) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	ids := make([]uuid.UUID, 0, len(invitations))
+	for _, invitation := range invitations {
+		ids = append(ids, invitation.ID)
+	}
+	return ids, nil
+}
+
+// get returns invitation of given id if it is in tenant scope of ctx, caller must hold the lock.
+func (r *invitationInMemoryRepo) get(ctx context.Context, id uuid.UUID) (domain.Invitation, bool) {
+	invitation, ok := r.invitations[id]
+	if !ok || !tenant.ScopeOf(ctx).Includes(invitation.TenantID) {
+		return domain.Invitation{}, false
+	}
+	return invitation, true
+}
@@ -0,0 +1,15 @@
+package invitation_test
+
+import (
+	"testing"
+
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/invitation"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+)
+
+func TestInvitationInMemoryRepo(t *testing.T) {
+	repotest.RunInvitationSuite(t, func(t *testing.T) repository.Invitation {
+		return invitation.NewInvitationInMemoryRepo()
+	})
+}
@@ -0,0 +1,185 @@
+package invitation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/mongoutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const invitationCollectionName = "invitation"
+
+type invitationMongoRepo struct {
+	db *mongo.Collection
+}
+
+func NewInvitationMongoRepository(db *mongo.Database) *invitationMongoRepo {
+	return &invitationMongoRepo{db: db.Collection(invitationCollectionName)}
+}
+
+// invitationDocument keeps field names same as sql columns so invitation fields need no mapping,
+// ids are kept as strings to be filterable by query values.
+type invitationDocument struct {
+	ID        uuid.UUID `bson:"id"`
+	TenantID  uuid.UUID `bson:"tenant_id"`
+	Email     string    `bson:"email"`
+	Role      string    `bson:"role"`
+	Status    string    `bson:"status"`
+	TokenHash string    `bson:"token_hash"`
+	InvitedBy string    `bson:"invited_by"`
+	UserID    string    `bson:"user_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	SentAt    time.Time `bson:"sent_at"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+func (r *invitationMongoRepo) Create(ctx context.Context, invitation domain.Invitation) error {
+	invitation.TenantID = tenant.FromContext(ctx)
+	_, err := r.db.InsertOne(ctx, documentFromDomain(invitation))
+	return err
+}
+
+func (r *invitationMongoRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.Invitation, error) {
+	return r.getBy(ctx, bson.M{"id": id})
+}
+
+func (r *invitationMongoRepo) GetByTokenHash(ctx context.Context, tokenHash string) (domain.Invitation, error) {
+	return r.getBy(ctx, bson.M{"token_hash": tokenHash})
+}
+
+func (r *invitationMongoRepo) getBy(ctx context.Context, filter bson.M) (domain.Invitation, error) {
+	var doc invitationDocument
+	err := r.db.FindOne(ctx, scoped(ctx, filter)).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Invitation{}, domain.ErrInvitationNotFound
+	}
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+	return documentToDomain(doc), nil
+}
+
+func (r *invitationMongoRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Invitation, error) {
+	sortByCreation(pagination)
+	docs, err := mongoutil.PaginatedList[invitationDocument](ctx, r.db, tenant.ScopeOf(ctx), pagination, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]domain.Invitation, 0, len(docs))
+	for _, doc := range docs {
+		invitations = append(invitations, documentToDomain(doc))
+	}
+	return invitations, nil
+}
+
+func (r *invitationMongoRepo) Update(ctx context.Context, invitation domain.Invitation) error {
+	doc := documentFromDomain(invitation)
+	res, err := r.db.UpdateOne(ctx, scoped(ctx, bson.M{"id": invitation.ID, "status": domain.InvitationPending}),
+		bson.M{"$set": bson.M{
+			"status":     doc.Status,
+			"token_hash": doc.TokenHash,
+			"user_id":    doc.UserID,
+			"expires_at": doc.ExpiresAt,
+			"sent_at":    doc.SentAt,
+			"updated_at": doc.UpdatedAt,
+		}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+
+	// distinguish accepted or revoked invitation from missing one
+	count, err := r.db.CountDocuments(ctx, scoped(ctx, bson.M{"id": invitation.ID}))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrInvitationNotPending
+	}
+	return domain.ErrInvitationNotFound
+}
+
+func (r *invitationMongoRepo) Anonymize(ctx context.Context, user, anonymized domain.User) ([]uuid.UUID, error) {
+	_, err := r.db.UpdateMany(ctx,
+		scoped(ctx, bson.M{"$or": bson.A{bson.M{"email": user.Email}, bson.M{"user_id": user.ID.String()}}}),
+		bson.M{"$set": bson.M{"email": anonymized.Email, "updated_at": anonymized.UpdatedAt}})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.db.Find(ctx, scoped(ctx, bson.M{"email": anonymized.Email}),
+		options.Find().SetSort(bson.M{"created_at": 1}).SetProjection(bson.M{"id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []invitationDocument
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	return ids, nil
+}
+
+// scoped restricts filter to invitations of tenant scope of ctx.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	return mongoutil.WithTenant(tenant.ScopeOf(ctx), filter)
+}
+
+// documentFromDomain converts invitation to document, absent inviter and user kept as empty strings.
+func documentFromDomain(invitation domain.Invitation) invitationDocument {
+	doc := invitationDocument{
+		ID:        invitation.ID,
+		TenantID:  invitation.TenantID,
+		Email:     invitation.Email,
+		Role:      string(invitation.Role),
+		Status:    string(invitation.Status),
+		TokenHash: invitation.TokenHash,
+		ExpiresAt: invitation.ExpiresAt,
+		SentAt:    invitation.SentAt,
+		CreatedAt: invitation.CreatedAt,
+		UpdatedAt: invitation.UpdatedAt,
+	}
+	if invitation.InvitedBy != uuid.Nil {
+		doc.InvitedBy = invitation.InvitedBy.String()
+	}
+	if invitation.UserID != uuid.Nil {
+		doc.UserID = invitation.UserID.String()
+	}
+	return doc
+}
+
+func documentToDomain(doc invitationDocument) domain.Invitation {
+	// absent inviter and user are kept as empty strings and parsed to uuid.Nil
+	invitedBy, _ := uuid.Parse(doc.InvitedBy)
+	userID, _ := uuid.Parse(doc.UserID)
+	return domain.Invitation{
+		ID:        doc.ID,
+		TenantID:  doc.TenantID,
+		Email:     doc.Email,
+		Role:      domain.UserRole(doc.Role),
+		Status:    domain.InvitationStatus(doc.Status),
+		TokenHash: doc.TokenHash,
+		InvitedBy: invitedBy,
+		UserID:    userID,
+		ExpiresAt: doc.ExpiresAt,
+		SentAt:    doc.SentAt,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+	}
+}
@@ -0,0 +1,34 @@
+package invitation_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/invitation"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+)
+
+// TestInvitationMongoRepo runs only if MONGODB_URI is set, e.g. mongodb://127.0.0.1:27017
+func TestInvitationMongoRepo(t *testing.T) {
+	uri := os.Getenv("MONGODB_URI")
+	if uri == "" {
+		t.Skip("MONGODB_URI is not set")
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	repotest.RunInvitationSuite(t, func(t *testing.T) repository.Invitation {
+		db := client.Database(strings.NewReplacer("/", "_", " ", "_").Replace(t.Name()))
+		t.Cleanup(func() { db.Drop(context.Background()) })
+		return invitation.NewInvitationMongoRepository(db)
+	})
+}
@@ -0,0 +1,111 @@
+package invitation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/amirzayi/clean_architect/infra/migrations/model"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/sqlutil"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const invitationTableName = "invitation"
+
+type invitationSQLRepo struct {
+	db    *sqlx.DB
+	table string
+}
+
+func NewInvitationSQLRepository(db *sqlx.DB) *invitationSQLRepo {
+	return &invitationSQLRepo{
+		db:    db,
+		table: sqlutil.QuoteIdentifier(db.DriverName(), invitationTableName),
+	}
+}
+
+func (r *invitationSQLRepo) Create(ctx context.Context, invitation domain.Invitation) error {
+	invitation.TenantID = tenant.FromContext(ctx)
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(id,tenant_id,email,role,status,token_hash,invited_by,user_id,expires_at,sent_at,created_at,updated_at)
+	VALUES(:id,:tenant_id,:email,:role,:status,:token_hash,:invited_by,:user_id,:expires_at,:sent_at,:created_at,:updated_at)`,
+		r.table), model.ConvertInvitationFromDomain(invitation))
+	return err
+}
+
+func (r *invitationSQLRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.Invitation, error) {
+	return r.getBy(ctx, "id", id)
+}
+
+func (r *invitationSQLRepo) GetByTokenHash(ctx context.Context, tokenHash string) (domain.Invitation, error) {
+	return r.getBy(ctx, "token_hash", tokenHash)
+}
+
+func (r *invitationSQLRepo) getBy(ctx context.Context, column string, value any) (domain.Invitation, error) {
+	var invitation model.Invitation
+	cond, args := tenantCondition(ctx)
+	err := r.db.GetContext(ctx, &invitation,
+		r.db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE %s=?%s LIMIT 1", r.table, column, cond)),
+		append([]any{value}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Invitation{}, domain.ErrInvitationNotFound
+	}
+	return model.ConvertInvitationToDomain(invitation), err
+}
+
+func (r *invitationSQLRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Invitation, error) {
+	sortByCreation(pagination)
+	invitations, err := sqlutil.PaginatedList[model.Invitation](ctx, r.db, r.table, tenant.ScopeOf(ctx), pagination, fields)
+	return model.ConvertInvitationsToDomains(invitations), err
+}
+
+func (r *invitationSQLRepo) Update(ctx context.Context, invitation domain.Invitation) error {
+	query := `UPDATE %s SET status=:status, token_hash=:token_hash, user_id=:user_id, expires_at=:expires_at,
+	sent_at=:sent_at, updated_at=:updated_at WHERE id=:id AND status='pending'`
+	if scope := tenant.ScopeOf(ctx); !scope.All {
+		query += " AND tenant_id=:tenant_id"
+		invitation.TenantID = scope.ID
+	}
+	res, err := r.db.NamedExecContext(ctx, fmt.Sprintf(query, r.table), model.ConvertInvitationFromDomain(invitation))
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// distinguish accepted or revoked invitation from missing one
+	if _, err = r.GetByID(ctx, invitation.ID); err != nil {
+		return err
+	}
+	return domain.ErrInvitationNotPending
+}
+
+func (r *invitationSQLRepo) Anonymize(ctx context.Context, user, anonymized domain.User) ([]uuid.UUID, error) {
+	cond, args := tenantCondition(ctx)
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(fmt.Sprintf("UPDATE %s SET email=?, updated_at=? WHERE (email=? OR user_id=?)%s",
+		r.table, cond)), append([]any{anonymized.Email, anonymized.UpdatedAt, user.Email, user.ID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	err = r.db.SelectContext(ctx, &ids, r.db.Rebind(fmt.Sprintf("SELECT id FROM %s WHERE email=?%s ORDER BY created_at",
+		r.table, cond)), append([]any{anonymized.Email}, args...)...)
+	return ids, err
+}
+
+// tenantCondition returns condition of tenant scope of ctx, to be appended to where clause.
+func tenantCondition(ctx context.Context) (string, []any) {
+	return sqlutil.TenantCondition(tenant.ScopeOf(ctx))
+}
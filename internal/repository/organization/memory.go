@@ -0,0 +1,186 @@
+package organization
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+type memberKey struct {
+	organizationID uuid.UUID
+	userID         uuid.UUID
+}
+
+type organizationInMemoryRepo struct {
+	mu            sync.RWMutex
+	organizations map[uuid.UUID]domain.Organization
+	members       map[memberKey]domain.Membership
+}
+
+// NewOrganizationInMemoryRepo returns repository which has only the default organization, same as sql migrations.
+func NewOrganizationInMemoryRepo() *organizationInMemoryRepo {
+	return &organizationInMemoryRepo{
+		organizations: map[uuid.UUID]domain.Organization{
+			tenant.Default: {ID: tenant.Default, Name: "default"},
+		},
+		members: make(map[memberKey]domain.Membership),
+	}
+}
+
+func (r *organizationInMemoryRepo) Create(_ context.Context, organization domain.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, o := range r.organizations {
+		if o.ID == organization.ID || o.Name == organization.Name {
+			return domain.ErrOrganizationAlreadyExists
+		}
+	}
+	r.organizations[organization.ID] = organization
+	return nil
+}
+
+func (r *organizationInMemoryRepo) GetByID(_ context.Context, id uuid.UUID) (domain.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	organization, ok := r.organizations[id]
+	if !ok {
+		return domain.Organization{}, domain.ErrOrganizationNotFound
+	}
+	return organization, nil
+}
+
+// List supports only equal and not equal filters and name sort.
+func (r *organizationInMemoryRepo) List(_ context.Context, pagination *paginate.Pagination) ([]domain.Organization, error) {
+	if pagination.Cursor != nil {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+	sortByName(pagination)
+
+	r.mu.RLock()
+	organizations := make([]domain.Organization, 0, len(r.organizations))
+	for _, o := range r.organizations {
+		if matchExpression(pagination.Filters, expr, map[string]string{"id": o.ID.String(), "name": o.Name}) {
+			organizations = append(organizations, o)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(organizations, func(a, b domain.Organization) int { return cmp.Compare(a.Name, b.Name) })
+	if pagination.Sort[0].Field != "name" || pagination.Sort[0].Arrange == paginate.SortOrderDescending {
+		slices.Reverse(organizations)
+	}
+	return page(organizations, pagination), nil
+}
+
+func (r *organizationInMemoryRepo) SaveMember(_ context.Context, membership domain.Membership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{membership.OrganizationID, membership.UserID}
+	if stored, ok := r.members[key]; ok {
+		stored.Role = membership.Role
+		membership = stored
+	}
+	r.members[key] = membership
+	return nil
+}
+
+func (r *organizationInMemoryRepo) Member(_ context.Context, organizationID, userID uuid.UUID) (domain.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	membership, ok := r.members[memberKey{organizationID, userID}]
+	if !ok {
+		return domain.Membership{}, domain.ErrMembershipNotFound
+	}
+	return membership, nil
+}
+
+// Members supports only equal and not equal filters and created_at sort.
+func (r *organizationInMemoryRepo) Members(_ context.Context, organizationID uuid.UUID, pagination *paginate.Pagination) ([]domain.Membership, error) {
+	if pagination.Cursor != nil {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+	sortByCreation(pagination)
+
+	r.mu.RLock()
+	var members []domain.Membership
+	for key, m := range r.members {
+		if key.organizationID == organizationID &&
+			matchExpression(pagination.Filters, expr, map[string]string{"user_id": m.UserID.String(), "role": string(m.Role)}) {
+			members = append(members, m)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(members, func(a, b domain.Membership) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.UserID.String(), b.UserID.String()))
+	})
+	if pagination.Sort[0].Field != "created_at" || pagination.Sort[0].Arrange == paginate.SortOrderDescending {
+		slices.Reverse(members)
+	}
+	return page(members, pagination), nil
+}
+
+func (r *organizationInMemoryRepo) RemoveMember(_ context.Context, organizationID, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{organizationID, userID}
+	if _, ok := r.members[key]; !ok {
+		return domain.ErrMembershipNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
+
+func matchFilters(filters []paginate.Filter, values map[string]string) bool {
+	for _, filter := range filters {
+		value, ok := values[filter.Key]
+		if !ok {
+			continue
+		}
+		switch filter.Condition {
+		case paginate.FilterEqual:
+			if value != filter.Value {
+				return false
+			}
+		case paginate.FilterNotEqual:
+			if value == filter.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func page[T any](items []T, pagination *paginate.Pagination) []T {
+	pagination.SetTotalItems(int64(len(items)))
+
+	start := min((pagination.Page-1)*pagination.PerPage, len(items))
+	end := min(start+pagination.PerPage, len(items))
+	return items[start:end]
+}
+
+// matchExpression reports whether values match both filters and filter expression of pagination.
+func matchExpression(filters []paginate.Filter, expr paginate.Expr, values map[string]string) bool {
+	return matchFilters(filters, values) &&
+		paginate.Match(expr, func(filter paginate.Filter) bool { return matchFilters([]paginate.Filter{filter}, values) })
+}
@@ -0,0 +1,15 @@
+package organization_test
+
+import (
+	"testing"
+
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/organization"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+)
+
+func TestOrganizationInMemoryRepo(t *testing.T) {
+	repotest.RunOrganizationSuite(t, func(t *testing.T) repository.Organization {
+		return organization.NewOrganizationInMemoryRepo()
+	})
+}
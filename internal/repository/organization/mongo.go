@@ -0,0 +1,149 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/mongoutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const (
+	organizationCollectionName       = "organization"
+	organizationMemberCollectionName = "organization_member"
+)
+
+type organizationMongoRepo struct {
+	db      *mongo.Collection
+	members *mongo.Collection
+}
+
+func NewOrganizationMongoRepository(db *mongo.Database) *organizationMongoRepo {
+	return &organizationMongoRepo{
+		db:      db.Collection(organizationCollectionName),
+		members: db.Collection(organizationMemberCollectionName),
+	}
+}
+
+// organizationDocument and membershipDocument keep field names same as sql columns so fields need no mapping.
+type organizationDocument struct {
+	ID        uuid.UUID `bson:"id"`
+	Name      string    `bson:"name"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type membershipDocument struct {
+	OrganizationID uuid.UUID       `bson:"organization_id"`
+	UserID         uuid.UUID       `bson:"user_id"`
+	Role           domain.UserRole `bson:"role"`
+	CreatedAt      time.Time       `bson:"created_at"`
+}
+
+// Create checks name before inserting which is not atomic without a unique index on name.
+func (r *organizationMongoRepo) Create(ctx context.Context, organization domain.Organization) error {
+	count, err := r.db.CountDocuments(ctx, bson.M{"$or": bson.A{
+		bson.M{"id": organization.ID},
+		bson.M{"name": organization.Name},
+	}})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrOrganizationAlreadyExists
+	}
+
+	_, err = r.db.InsertOne(ctx, organizationDocument(organization))
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrOrganizationAlreadyExists
+	}
+	return err
+}
+
+// GetByID returns the default organization even if it is not stored, since mongodb has no migrations.
+func (r *organizationMongoRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.Organization, error) {
+	var doc organizationDocument
+	err := r.db.FindOne(ctx, bson.M{"id": id}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if id == tenant.Default {
+			return domain.Organization{ID: tenant.Default, Name: "default"}, nil
+		}
+		return domain.Organization{}, domain.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return domain.Organization{}, err
+	}
+	return domain.Organization(doc), nil
+}
+
+func (r *organizationMongoRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Organization, error) {
+	sortByName(pagination)
+	docs, err := mongoutil.PaginatedList[organizationDocument](ctx, r.db, tenant.AllTenants, pagination, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := make([]domain.Organization, 0, len(docs))
+	for _, doc := range docs {
+		organizations = append(organizations, domain.Organization(doc))
+	}
+	return organizations, nil
+}
+
+func (r *organizationMongoRepo) SaveMember(ctx context.Context, membership domain.Membership) error {
+	_, err := r.members.UpdateOne(ctx,
+		bson.M{"organization_id": membership.OrganizationID, "user_id": membership.UserID},
+		bson.M{
+			"$set":         bson.M{"role": membership.Role},
+			"$setOnInsert": bson.M{"created_at": membership.CreatedAt},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *organizationMongoRepo) Member(ctx context.Context, organizationID, userID uuid.UUID) (domain.Membership, error) {
+	var doc membershipDocument
+	err := r.members.FindOne(ctx, bson.M{"organization_id": organizationID, "user_id": userID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Membership{}, domain.ErrMembershipNotFound
+	}
+	if err != nil {
+		return domain.Membership{}, err
+	}
+	return domain.Membership(doc), nil
+}
+
+func (r *organizationMongoRepo) Members(ctx context.Context, organizationID uuid.UUID, pagination *paginate.Pagination) ([]domain.Membership, error) {
+	sortByCreation(pagination)
+	// organization_id is the tenant of memberships
+	docs, err := mongoutil.PaginatedList[membershipDocument](ctx, r.members, tenant.AllTenants, pagination, memberFields,
+		bson.E{Key: "organization_id", Value: organizationID})
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]domain.Membership, 0, len(docs))
+	for _, doc := range docs {
+		members = append(members, domain.Membership(doc))
+	}
+	return members, nil
+}
+
+func (r *organizationMongoRepo) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	res, err := r.members.DeleteOne(ctx, bson.M{"organization_id": organizationID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrMembershipNotFound
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package organization
+
+import (
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+// fields are queryable fields of organizations, same keys in all repositories.
+var fields = paginate.Fields{Filter: map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}, Types: map[string]paginate.Field{
+	"id":         {Type: paginate.TypeUUID},
+	"created_at": {Type: paginate.TypeTime},
+	"updated_at": {Type: paginate.TypeTime},
+}}
+
+// memberFields are queryable fields of memberships, same keys in all repositories.
+var memberFields = paginate.Fields{Filter: map[string]string{
+	"user_id":    "user_id",
+	"role":       "role",
+	"created_at": "created_at",
+}, Types: map[string]paginate.Field{
+	"user_id":    {Type: paginate.TypeUUID},
+	"role":       {Type: paginate.TypeEnum, Values: []string{string(domain.UserRoleNormal), string(domain.UserRoleAdmin)}},
+	"created_at": {Type: paginate.TypeTime},
+}}
+
+// sortByName sorts organizations by name if pagination does not sort them.
+func sortByName(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 {
+		pagination.Sort = []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}}
+	}
+}
+
+// sortByCreation sorts memberships oldest first if pagination does not sort them.
+func sortByCreation(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 {
+		pagination.Sort = []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderAscending}}
+	}
+}
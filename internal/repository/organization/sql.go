@@ -0,0 +1,124 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/amirzayi/clean_architect/infra/migrations/model"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/sqlutil"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const (
+	organizationTableName       = "organization"
+	organizationMemberTableName = "organization_member"
+)
+
+type organizationSQLRepo struct {
+	db          *sqlx.DB
+	table       string
+	memberTable string
+}
+
+func NewOrganizationSQLRepository(db *sqlx.DB) *organizationSQLRepo {
+	return &organizationSQLRepo{
+		db:          db,
+		table:       sqlutil.QuoteIdentifier(db.DriverName(), organizationTableName),
+		memberTable: sqlutil.QuoteIdentifier(db.DriverName(), organizationMemberTableName),
+	}
+}
+
+func (r *organizationSQLRepo) Create(ctx context.Context, organization domain.Organization) error {
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(id,name,created_at,updated_at)
+	VALUES(:id,:name,:created_at,:updated_at)`, r.table),
+		model.ConvertOrganizationFromDomain(organization))
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrOrganizationAlreadyExists
+	}
+	return err
+}
+
+func (r *organizationSQLRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.Organization, error) {
+	var organization model.Organization
+	err := r.db.GetContext(ctx, &organization, r.db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE id=? LIMIT 1", r.table)), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Organization{}, domain.ErrOrganizationNotFound
+	}
+	return model.ConvertOrganizationToDomain(organization), err
+}
+
+func (r *organizationSQLRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Organization, error) {
+	sortByName(pagination)
+	organizations, err := sqlutil.PaginatedList[model.Organization](ctx, r.db, r.table, tenant.AllTenants, pagination, fields)
+	return model.ConvertOrganizationsToDomains(organizations), err
+}
+
+func (r *organizationSQLRepo) SaveMember(ctx context.Context, membership domain.Membership) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf("UPDATE %s SET role=? WHERE organization_id=? AND user_id=?", r.memberTable)),
+		string(membership.Role), membership.OrganizationID, membership.UserID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		_, err = tx.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+		(organization_id,user_id,role,created_at)
+		VALUES(:organization_id,:user_id,:role,:created_at)`, r.memberTable),
+			model.ConvertOrganizationMemberFromDomain(membership))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *organizationSQLRepo) Member(ctx context.Context, organizationID, userID uuid.UUID) (domain.Membership, error) {
+	var member model.OrganizationMember
+	err := r.db.GetContext(ctx, &member, r.db.Rebind(fmt.Sprintf(
+		"SELECT * FROM %s WHERE organization_id=? AND user_id=? LIMIT 1", r.memberTable)), organizationID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Membership{}, domain.ErrMembershipNotFound
+	}
+	return model.ConvertOrganizationMemberToDomain(member), err
+}
+
+func (r *organizationSQLRepo) Members(ctx context.Context, organizationID uuid.UUID, pagination *paginate.Pagination) ([]domain.Membership, error) {
+	sortByCreation(pagination)
+	// organization_id is the tenant of memberships
+	members, err := sqlutil.PaginatedList[model.OrganizationMember](ctx, r.db, r.memberTable, tenant.AllTenants, pagination,
+		memberFields, sqlutil.Predicate{Query: "organization_id=?", Args: []any{organizationID}})
+	return model.ConvertOrganizationMembersToDomains(members), err
+}
+
+func (r *organizationSQLRepo) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(fmt.Sprintf(
+		"DELETE FROM %s WHERE organization_id=? AND user_id=?", r.memberTable)), organizationID, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrMembershipNotFound
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package privacy
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+type privacyInMemoryRepo struct {
+	mu   sync.RWMutex
+	jobs map[uuid.UUID]domain.PrivacyJob
+}
+
+func NewPrivacyInMemoryRepo() *privacyInMemoryRepo {
+	return &privacyInMemoryRepo{jobs: make(map[uuid.UUID]domain.PrivacyJob)}
+}
+
+func (r *privacyInMemoryRepo) Create(ctx context.Context, job domain.PrivacyJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.TenantID = tenant.FromContext(ctx)
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func (r *privacyInMemoryRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.PrivacyJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.get(ctx, id)
+	if !ok {
+		return domain.PrivacyJob{}, domain.ErrPrivacyJobNotFound
+	}
+	return job, nil
+}
+
+// List supports only equal filters and created_at sort.
+func (r *privacyInMemoryRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.PrivacyJob, error) {
+	if pagination.Cursor != nil {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+	sortByCreation(pagination)
+	scope := tenant.ScopeOf(ctx)
+
+	r.mu.RLock()
+	jobs := make([]domain.PrivacyJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		if scope.Includes(job.TenantID) && matchFilters(job, pagination.Filters) &&
+			paginate.Match(expr, func(filter paginate.Filter) bool { return matchFilters(job, []paginate.Filter{filter}) }) {
+			jobs = append(jobs, job)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(jobs, func(a, b domain.PrivacyJob) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID.String(), b.ID.String()))
+	})
+	if pagination.Sort[0].Field != "created_at" || pagination.Sort[0].Arrange == paginate.SortOrderDescending {
+		slices.Reverse(jobs)
+	}
+
+	pagination.SetTotalItems(int64(len(jobs)))
+
+	start := min((pagination.Page-1)*pagination.PerPage, len(jobs))
+	end := min(start+pagination.PerPage, len(jobs))
+	return jobs[start:end], nil
+}
+
+func matchFilters(job domain.PrivacyJob, filters []paginate.Filter) bool {
+	for _, filter := range filters {
+		var value string
+		switch filter.Key {
+		case "kind":
+			value = string(job.Kind)
+		case "user_id":
+			value = job.UserID.String()
+		case "status":
+			value = string(job.Status)
+		case "requested_by":
+			value = job.RequestedBy.String()
+		default:
+			continue
+		}
+		if filter.Condition == paginate.FilterEqual && value != filter.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *privacyInMemoryRepo) Claim(ctx context.Context, id uuid.UUID, startedAt, staleBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.get(ctx, id)
+	if !ok {
+		return domain.ErrPrivacyJobNotFound
+	}
+	stale := job.Status == domain.PrivacyJobRunning && job.StartedAt.Before(staleBefore)
+	if job.Status != domain.PrivacyJobPending && !stale {
+		return domain.ErrPrivacyJobClaimed
+	}
+	job.Status = domain.PrivacyJobRunning
+	job.StartedAt = startedAt
+	r.jobs[id] = job
+	return nil
+}
+
+func (r *privacyInMemoryRepo) Finish(ctx context.Context, job domain.PrivacyJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.get(ctx, job.ID)
+	if !ok {
+		return domain.ErrPrivacyJobNotFound
+	}
+	stored.Status = job.Status
+	stored.Error = job.Error
+	stored.Archive = job.Archive
+	stored.FinishedAt = job.FinishedAt
+	r.jobs[job.ID] = stored
+	return nil
+}
+
+func (r *privacyInMemoryRepo) ClearArchives(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope := tenant.ScopeOf(ctx)
+	for id, job := range r.jobs {
+		if job.UserID == userID && scope.Includes(job.TenantID) {
+			job.Archive = nil
+			r.jobs[id] = job
+		}
+	}
+	return nil
+}
+
+// get returns job of given id if it is in tenant scope of ctx, caller must hold the lock.
+func (r *privacyInMemoryRepo) get(ctx context.Context, id uuid.UUID) (domain.PrivacyJob, bool) {
+	job, ok := r.jobs[id]
+	if !ok || !tenant.ScopeOf(ctx).Includes(job.TenantID) {
+		return domain.PrivacyJob{}, false
+	}
+	return job, true
+}
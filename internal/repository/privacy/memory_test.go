@@ -0,0 +1,15 @@
+package privacy_test
+
+import (
+	"testing"
+
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/privacy"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+)
+
+func TestPrivacyInMemoryRepo(t *testing.T) {
+	repotest.RunPrivacyJobSuite(t, func(t *testing.T) repository.PrivacyJob {
+		return privacy.NewPrivacyInMemoryRepo()
+	})
+}
@@ -0,0 +1,159 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/mongoutil"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const privacyJobCollectionName = "privacy_job"
+
+type privacyMongoRepo struct {
+	db *mongo.Collection
+}
+
+func NewPrivacyMongoRepository(db *mongo.Database) *privacyMongoRepo {
+	return &privacyMongoRepo{db: db.Collection(privacyJobCollectionName)}
+}
+
+// privacyJobDocument keeps field names same as sql columns so privacy job fields need no mapping,
+// ids are kept as strings to be filterable by query values.
+type privacyJobDocument struct {
+	ID          uuid.UUID `bson:"id"`
+	TenantID    uuid.UUID `bson:"tenant_id"`
+	Kind        string    `bson:"kind"`
+	UserID      string    `bson:"user_id"`
+	Status      string    `bson:"status"`
+	RequestedBy string    `bson:"requested_by"`
+	Error       string    `bson:"error"`
+	Archive     []byte    `bson:"archive"`
+	CreatedAt   time.Time `bson:"created_at"`
+	StartedAt   time.Time `bson:"started_at"`
+	FinishedAt  time.Time `bson:"finished_at"`
+}
+
+func (r *privacyMongoRepo) Create(ctx context.Context, job domain.PrivacyJob) error {
+	var requestedBy string
+	if job.RequestedBy != uuid.Nil {
+		requestedBy = job.RequestedBy.String()
+	}
+	_, err := r.db.InsertOne(ctx, privacyJobDocument{
+		ID:          job.ID,
+		TenantID:    tenant.FromContext(ctx),
+		Kind:        string(job.Kind),
+		UserID:      job.UserID.String(),
+		Status:      string(job.Status),
+		RequestedBy: requestedBy,
+		Error:       job.Error,
+		Archive:     job.Archive,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+	})
+	return err
+}
+
+func (r *privacyMongoRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.PrivacyJob, error) {
+	var doc privacyJobDocument
+	err := r.db.FindOne(ctx, scoped(ctx, bson.M{"id": id})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.PrivacyJob{}, domain.ErrPrivacyJobNotFound
+	}
+	if err != nil {
+		return domain.PrivacyJob{}, err
+	}
+	return documentToDomain(doc), nil
+}
+
+func (r *privacyMongoRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.PrivacyJob, error) {
+	sortByCreation(pagination)
+	docs, err := mongoutil.PaginatedList[privacyJobDocument](ctx, r.db, tenant.ScopeOf(ctx), pagination, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]domain.PrivacyJob, 0, len(docs))
+	for _, doc := range docs {
+		jobs = append(jobs, documentToDomain(doc))
+	}
+	return jobs, nil
+}
+
+func (r *privacyMongoRepo) Claim(ctx context.Context, id uuid.UUID, startedAt, staleBefore time.Time) error {
+	res, err := r.db.UpdateOne(ctx,
+		scoped(ctx, bson.M{"id": id, "$or": bson.A{
+			bson.M{"status": domain.PrivacyJobPending},
+			bson.M{"status": domain.PrivacyJobRunning, "started_at": bson.M{"$lt": staleBefore}},
+		}}),
+		bson.M{"$set": bson.M{"status": domain.PrivacyJobRunning, "started_at": startedAt}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+
+	// distinguish claimed job from missing one
+	count, err := r.db.CountDocuments(ctx, scoped(ctx, bson.M{"id": id}))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrPrivacyJobClaimed
+	}
+	return domain.ErrPrivacyJobNotFound
+}
+
+func (r *privacyMongoRepo) Finish(ctx context.Context, job domain.PrivacyJob) error {
+	res, err := r.db.UpdateOne(ctx, scoped(ctx, bson.M{"id": job.ID}), bson.M{"$set": bson.M{
+		"status":      job.Status,
+		"error":       job.Error,
+		"archive":     job.Archive,
+		"finished_at": job.FinishedAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrPrivacyJobNotFound
+	}
+	return nil
+}
+
+func (r *privacyMongoRepo) ClearArchives(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.UpdateMany(ctx, scoped(ctx, bson.M{"user_id": userID.String()}), bson.M{"$set": bson.M{"archive": nil}})
+	return err
+}
+
+// scoped restricts filter to jobs of tenant scope of ctx.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	return mongoutil.WithTenant(tenant.ScopeOf(ctx), filter)
+}
+
+func documentToDomain(doc privacyJobDocument) domain.PrivacyJob {
+	// absent requester is kept as empty string and parsed to uuid.Nil
+	userID, _ := uuid.Parse(doc.UserID)
+	requestedBy, _ := uuid.Parse(doc.RequestedBy)
+	return domain.PrivacyJob{
+		ID:          doc.ID,
+		TenantID:    doc.TenantID,
+		Kind:        domain.PrivacyJobKind(doc.Kind),
+		UserID:      userID,
+		Status:      domain.PrivacyJobStatus(doc.Status),
+		RequestedBy: requestedBy,
+		Error:       doc.Error,
+		Archive:     doc.Archive,
+		CreatedAt:   doc.CreatedAt,
+		StartedAt:   doc.StartedAt,
+		FinishedAt:  doc.FinishedAt,
+	}
+}
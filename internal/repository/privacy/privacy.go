@@ -0,0 +1,31 @@
+package privacy
+
+import (
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+// fields are queryable fields of privacy jobs, same keys in all repositories.
+var fields = paginate.Fields{Filter: map[string]string{
+	"id":           "id",
+	"kind":         "kind",
+	"user_id":      "user_id",
+	"status":       "status",
+	"requested_by": "requested_by",
+	"created_at":   "created_at",
+}, Types: map[string]paginate.Field{
+	"id":      {Type: paginate.TypeUUID},
+	"kind":    {Type: paginate.TypeEnum, Values: []string{string(domain.PrivacyJobExport), string(domain.PrivacyJobErasure)}},
+	"user_id": {Type: paginate.TypeUUID},
+	"status": {Type: paginate.TypeEnum, Values: []string{string(domain.PrivacyJobPending), string(domain.PrivacyJobRunning),
+		string(domain.PrivacyJobCompleted), string(domain.PrivacyJobFailed)}},
+	"requested_by": {Type: paginate.TypeUUID},
+	"created_at":   {Type: paginate.TypeTime},
+}}
+
+// sortByCreation sorts jobs newest first if pagination does not sort them.
+func sortByCreation(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 {
+		pagination.Sort = []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderDescending}}
+	}
+}
@@ -0,0 +1,124 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/amirzayi/clean_architect/infra/migrations/model"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/sqlutil"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const privacyJobTableName = "privacy_job"
+
+type privacySQLRepo struct {
+	db    *sqlx.DB
+	table string
+}
+
+func NewPrivacySQLRepository(db *sqlx.DB) *privacySQLRepo {
+	return &privacySQLRepo{
+		db:    db,
+		table: sqlutil.QuoteIdentifier(db.DriverName(), privacyJobTableName),
+	}
+}
+
+func (r *privacySQLRepo) Create(ctx context.Context, job domain.PrivacyJob) error {
+	job.TenantID = tenant.FromContext(ctx)
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(id,tenant_id,kind,user_id,status,requested_by,error,archive,created_at,started_at,finished_at)
+	VALUES(:id,:tenant_id,:kind,:user_id,:status,:requested_by,:error,:archive,:created_at,:started_at,:finished_at)`, r.table),
+		model.ConvertPrivacyJobFromDomain(job))
+	return err
+}
+
+func (r *privacySQLRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.PrivacyJob, error) {
+	var job model.PrivacyJob
+	cond, args := tenantCondition(ctx)
+	err := r.db.GetContext(ctx, &job, r.db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE id=?%s LIMIT 1", r.table, cond)),
+		append([]any{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PrivacyJob{}, domain.ErrPrivacyJobNotFound
+	}
+	return model.ConvertPrivacyJobToDomain(job), err
+}
+
+func (r *privacySQLRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.PrivacyJob, error) {
+	sortByCreation(pagination)
+	jobs, err := sqlutil.PaginatedList[model.PrivacyJob](ctx, r.db, r.table, tenant.ScopeOf(ctx), pagination, fields)
+	return model.ConvertPrivacyJobsToDomains(jobs), err
+}
+
+func (r *privacySQLRepo) Claim(ctx context.Context, id uuid.UUID, startedAt, staleBefore time.Time) error {
+	cond, args := tenantCondition(ctx)
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(fmt.Sprintf(
+		"UPDATE %s SET status=?, started_at=? WHERE id=? AND (status=? OR (status=? AND started_at<?))%s", r.table, cond)),
+		append([]any{domain.PrivacyJobRunning, startedAt.UTC(), id, domain.PrivacyJobPending, domain.PrivacyJobRunning, staleBefore.UTC()},
+			args...)...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return r.notClaimed(ctx, id)
+	}
+	return nil
+}
+
+// notClaimed distinguishes claimed job from missing one.
+func (r *privacySQLRepo) notClaimed(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	cond, args := tenantCondition(ctx)
+	err := r.db.GetContext(ctx, &exists, r.db.Rebind(fmt.Sprintf("SELECT COUNT(*) > 0 FROM %s WHERE id=?%s", r.table, cond)),
+		append([]any{id}, args...)...)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrPrivacyJobClaimed
+	}
+	return domain.ErrPrivacyJobNotFound
+}
+
+func (r *privacySQLRepo) Finish(ctx context.Context, job domain.PrivacyJob) error {
+	query := "UPDATE %s SET status=:status, error=:error, archive=:archive, finished_at=:finished_at WHERE id=:id"
+	if scope := tenant.ScopeOf(ctx); !scope.All {
+		query += " AND tenant_id=:tenant_id"
+		job.TenantID = scope.ID
+	}
+	res, err := r.db.NamedExecContext(ctx, fmt.Sprintf(query, r.table), model.ConvertPrivacyJobFromDomain(job))
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrPrivacyJobNotFound
+	}
+	return nil
+}
+
+func (r *privacySQLRepo) ClearArchives(ctx context.Context, userID uuid.UUID) error {
+	cond, args := tenantCondition(ctx)
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(fmt.Sprintf("UPDATE %s SET archive=NULL WHERE user_id=?%s", r.table, cond)),
+		append([]any{userID}, args...)...)
+	return err
+}
+
+// tenantCondition returns condition of tenant scope of ctx, to be appended to where clause.
+func tenantCondition(ctx context.Context) (string, []any) {
+	return sqlutil.TenantCondition(tenant.ScopeOf(ctx))
+}
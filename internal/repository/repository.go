@@ -2,8 +2,14 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository/audit"
+	"github.com/amirzayi/clean_architect/internal/repository/group"
+	"github.com/amirzayi/clean_architect/internal/repository/invitation"
+	"github.com/amirzayi/clean_architect/internal/repository/organization"
+	"github.com/amirzayi/clean_architect/internal/repository/privacy"
 	"github.com/amirzayi/clean_architect/internal/repository/user"
 	"github.com/amirzayi/clean_architect/pkg/paginate"
 	"github.com/google/uuid"
@@ -11,33 +17,198 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// User is the storage of users, deleted users are hidden from all methods
+// except List with user.FilterWithDeleted filter, GetDeletedByID, ChangeStatus from deleted status and Purge.
+// all methods are scoped by tenant of ctx, see tenant.ScopeOf, and Create takes tenant of user from it.
 type User interface {
 	Create(ctx context.Context, user domain.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (domain.User, error)
+	// GetDeletedByID returns a soft deleted user, domain.ErrUserNotFound returned if user is not deleted.
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (domain.User, error)
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
+	// List lists users by pagination, users of its search have all its words in their name or email and are sorted
+	// most relevant first unless sorted by pagination, their matched fields are set as its highlights.
 	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.User, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Update modifies user and increments its version, when user.Version is not zero
+	// update applies only on same stored version otherwise domain.ErrConcurrentModification returned.
 	Update(ctx context.Context, user domain.User) error
+	// Patch modifies only given fields of user and increments its version,
+	// version check is same as Update.
+	Patch(ctx context.Context, id uuid.UUID, patch domain.UserPatch) error
+	// Purge removes a deleted user and its status history permanently.
+	Purge(ctx context.Context, id uuid.UUID) error
+	// PurgeDeleted removes users which are deleted before given time permanently and returns their count.
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// ChangeStatus applies transition if user status is still transition.From and keeps it in status history,
+	// domain.ErrConcurrentModification returned if status or given non-zero version has been changed meanwhile.
+	// moving to or from deleted status soft deletes or restores user.
+	ChangeStatus(ctx context.Context, transition domain.UserStatusTransition, version int64) error
+	// StatusHistory lists status transitions of user, newest first unless sorted by pagination.
+	StatusHistory(ctx context.Context, userID uuid.UUID, pagination *paginate.Pagination) ([]domain.UserStatusTransition, error)
+	// Anonymize replaces name, phone number, email and password of user, whether it is deleted or not,
+	// clears its custom attributes and reasons of its status history and increments its version.
+	Anonymize(ctx context.Context, user domain.User) error
+	// AttributeTaken reports whether a user of tenant other than exceptID, deleted or not,
+	// has given normalized value of custom attribute.
+	AttributeTaken(ctx context.Context, name string, value any, exceptID uuid.UUID) (bool, error)
+}
+
+// UserAttribute is the storage of custom user attribute definitions, all methods are scoped by tenant of ctx
+// and Create takes tenant of attribute from it.
+type UserAttribute interface {
+	// Create returns domain.ErrUserAttributeAlreadyExists if name is taken in tenant.
+	Create(ctx context.Context, attribute domain.UserAttribute) error
+	// List returns all attributes, ordered by name.
+	List(ctx context.Context) ([]domain.UserAttribute, error)
+	// Delete removes attribute and its values of all users of tenant,
+	// domain.ErrUserAttributeNotFound returned if attribute does not exist.
+	Delete(ctx context.Context, name string) error
+}
+
+// Audit is the append-only storage of audit log.
+type Audit interface {
+	// Append stores entry, domain.ErrAuditSequenceConflict returned if its sequence is not greater than
+	// sequence of last entry. tenant of entry is part of its hash, so it is kept as given.
+	Append(ctx context.Context, entry domain.AuditEntry) error
+	// Last returns entry with the greatest sequence of all tenants, zero entry if log is empty.
+	Last(ctx context.Context) (domain.AuditEntry, error)
+	// List lists entries of tenant scope of ctx, newest first unless sorted by pagination.
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.AuditEntry, error)
+}
+
+// AuditKey is the storage of keys of audit subjects, keys are not scoped by tenant since subjects are unique.
+type AuditKey interface {
+	// Create stores key unless its subject already has one, stored key of subject is returned either way.
+	Create(ctx context.Context, key domain.AuditKey) (domain.AuditKey, error)
+	// Get returns key of subject, domain.ErrAuditKeyNotFound returned if subject has no key.
+	Get(ctx context.Context, subject string) (domain.AuditKey, error)
+	// Delete deletes key of subject, it is not an error if subject has no key.
+	Delete(ctx context.Context, subject string) error
+}
+
+// PrivacyJob is the storage of privacy export and erasure jobs,
+// all methods are scoped by tenant of ctx same as User.
+type PrivacyJob interface {
+	Create(ctx context.Context, job domain.PrivacyJob) error
+	// GetByID returns domain.ErrPrivacyJobNotFound if job does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (domain.PrivacyJob, error)
+	// List lists jobs, newest first unless sorted by pagination.
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.PrivacyJob, error)
+	// Claim marks job as running since startedAt if it is pending or has been running since before staleBefore,
+	// which means its runner has stopped, otherwise domain.ErrPrivacyJobClaimed returned.
+	Claim(ctx context.Context, id uuid.UUID, startedAt, staleBefore time.Time) error
+	// Finish stores status, error, archive and finish time of job.
+	Finish(ctx context.Context, job domain.PrivacyJob) error
+	// ClearArchives removes archives of all jobs of user.
+	ClearArchives(ctx context.Context, userID uuid.UUID) error
+}
+
+// Invitation is the storage of invitations, all methods are scoped by tenant of ctx, see tenant.ScopeOf,
+// and Create takes tenant of invitation from it.
+type Invitation interface {
+	Create(ctx context.Context, invitation domain.Invitation) error
+	// GetByID returns domain.ErrInvitationNotFound if invitation does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (domain.Invitation, error)
+	// GetByTokenHash returns domain.ErrInvitationNotFound if no invitation has given token hash.
+	GetByTokenHash(ctx context.Context, tokenHash string) (domain.Invitation, error)
+	// List lists invitations, newest first unless sorted by pagination.
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Invitation, error)
+	// Update stores status, token hash, user, expiry, send and update times of invitation if it is still pending,
+	// otherwise domain.ErrInvitationNotPending returned.
+	Update(ctx context.Context, invitation domain.Invitation) error
+	// Anonymize replaces email of invitations sent to user or accepted by it with email of anonymized user,
+	// ids of them are returned. invitations anonymized before are returned too, so it is safe to run again.
+	Anonymize(ctx context.Context, user, anonymized domain.User) ([]uuid.UUID, error)
+}
+
+// Organization is the storage of organizations and their memberships, organizations are tenants
+// themselves so they are not scoped by tenant. the default organization always exists.
+type Organization interface {
+	// Create returns domain.ErrOrganizationAlreadyExists if name is taken.
+	Create(ctx context.Context, organization domain.Organization) error
+	// GetByID returns domain.ErrOrganizationNotFound if organization does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (domain.Organization, error)
+	// List lists organizations, ordered by name unless sorted by pagination.
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Organization, error)
+	// SaveMember adds membership, or changes role of the existing one.
+	SaveMember(ctx context.Context, membership domain.Membership) error
+	// Member returns domain.ErrMembershipNotFound if user is not member of organization.
+	Member(ctx context.Context, organizationID, userID uuid.UUID) (domain.Membership, error)
+	// Members lists memberships of organization, oldest first unless sorted by pagination.
+	Members(ctx context.Context, organizationID uuid.UUID, pagination *paginate.Pagination) ([]domain.Membership, error)
+	// RemoveMember returns domain.ErrMembershipNotFound if user is not member of organization.
+	RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error
+}
+
+// Group is the storage of groups and their members, all methods are scoped by tenant of ctx, see tenant.ScopeOf,
+// and Create and AddMember take tenant from it.
+type Group interface {
+	// Create returns domain.ErrGroupAlreadyExists if name is taken in tenant.
+	Create(ctx context.Context, group domain.Group) error
+	// GetByID returns domain.ErrGroupNotFound if group does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (domain.Group, error)
+	// List lists groups, ordered by name unless sorted by pagination.
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Group, error)
+	// Delete removes group with its members and its memberships in other groups,
+	// domain.ErrGroupNotFound returned if group does not exist.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// AddMember returns domain.ErrGroupMemberAlreadyExists if member is a direct member of group already.
+	AddMember(ctx context.Context, member domain.GroupMember) error
+	// RemoveMember returns domain.ErrGroupMemberNotFound if member is not a direct member of group.
+	RemoveMember(ctx context.Context, groupID uuid.UUID, kind domain.GroupMemberKind, memberID uuid.UUID) error
+	// Members lists direct members of group, oldest first unless sorted by pagination.
+	Members(ctx context.Context, groupID uuid.UUID, pagination *paginate.Pagination) ([]domain.GroupMember, error)
+	// GroupsOf returns groups which given user or group is a direct member of, ordered by name.
+	GroupsOf(ctx context.Context, kind domain.GroupMemberKind, memberID uuid.UUID) ([]domain.Group, error)
 }
 
 type Repositories struct {
-	User User
+	User          User
+	UserAttribute UserAttribute
+	Audit         Audit
+	AuditKey      AuditKey
+	PrivacyJob    PrivacyJob
+	Organization  Organization
+	Invitation    Invitation
+	Group         Group
 }
 
 func NewMongoRepositories(db *mongo.Database) *Repositories {
 	return &Repositories{
-		User: user.NewUserMongoRepository(db),
+		User:          user.NewUserMongoRepository(db),
+		UserAttribute: user.NewUserAttributeMongoRepository(db),
+		Audit:         audit.NewAuditMongoRepository(db),
+		AuditKey:      audit.NewAuditKeyMongoRepository(db),
+		PrivacyJob:    privacy.NewPrivacyMongoRepository(db),
+		Organization:  organization.NewOrganizationMongoRepository(db),
+		Invitation:    invitation.NewInvitationMongoRepository(db),
+		Group:         group.NewGroupMongoRepository(db),
 	}
 }
 
 func NewSQLRepositories(db *sqlx.DB) *Repositories {
 	return &Repositories{
-		User: user.NewUserSQLRepository(db),
+		User:          user.NewUserSQLRepository(db),
+		UserAttribute: user.NewUserAttributeSQLRepository(db),
+		Audit:         audit.NewAuditSQLRepository(db),
+		AuditKey:      audit.NewAuditKeySQLRepository(db),
+		PrivacyJob:    privacy.NewPrivacySQLRepository(db),
+		Organization:  organization.NewOrganizationSQLRepository(db),
+		Invitation:    invitation.NewInvitationSQLRepository(db),
+		Group:         group.NewGroupSQLRepository(db),
 	}
 }
 
 func NewInMemoryRepositories() *Repositories {
+	users := user.NewUserInMemoryRepo()
 	return &Repositories{
-		User: user.NewUserInMemoryRepo(),
+		User:          users,
+		UserAttribute: user.NewUserAttributeInMemoryRepo(users),
+		Audit:         audit.NewAuditInMemoryRepo(),
+		AuditKey:      audit.NewAuditKeyInMemoryRepo(),
+		PrivacyJob:    privacy.NewPrivacyInMemoryRepo(),
+		Organization:  organization.NewOrganizationInMemoryRepo(),
+		Invitation:    invitation.NewInvitationInMemoryRepo(),
+		Group:         group.NewGroupInMemoryRepo(),
 	}
 }
@@ -0,0 +1,178 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// AuditFactory returns a new and empty repository.Audit for every call.
+type AuditFactory func(t *testing.T) repository.Audit
+
+// RunAuditSuite runs the same scenarios against given repository.Audit implementation.
+// Each scenario gets a fresh repository from newRepo.
+func RunAuditSuite(t *testing.T, newRepo AuditFactory) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, repo repository.Audit)
+	}{
+		{"append and last", testAuditAppendAndLast},
+		{"sequence conflict", testAuditSequenceConflict},
+		{"list", testAuditList},
+		{"hash chain", testAuditHashChain},
+		{"tenant isolation", testAuditTenantIsolation},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+// NewAuditEntry returns an entry of given sequence linked to prev.
+func NewAuditEntry(sequence int64, prev domain.AuditEntry) domain.AuditEntry {
+	e := domain.AuditEntry{
+		ID:         uuid.New(),
+		Sequence:   sequence,
+		ActorID:    uuid.New(),
+		ActorRole:  string(domain.UserRoleAdmin),
+		Action:     domain.AuditActionUserUpdate,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   uuid.NewString(),
+		Changes: []domain.AuditChange{
+			{Field: "name", Before: "amir", After: "ali"},
+			{Field: "password", Before: domain.AuditRedacted, After: domain.AuditRedacted},
+		},
+		IP:        "127.0.0.1",
+		RequestID: uuid.NewString(),
+		At:        time.Now().UTC().Truncate(time.Millisecond),
+		PrevHash:  prev.Hash,
+	}
+	e.Hash = e.ComputeHash()
+	return e
+}
+
+func testAuditAppendAndLast(t *testing.T, repo repository.Audit) {
+	ctx := context.Background()
+
+	last, err := repo.Last(ctx)
+	require.NoError(t, err)
+	require.Zero(t, last.Sequence)
+
+	first := NewAuditEntry(1, last)
+	require.NoError(t, repo.Append(ctx, first))
+	second := NewAuditEntry(2, first)
+	second.ActorID = uuid.Nil
+	second.Changes = nil
+	require.NoError(t, repo.Append(ctx, second))
+
+	last, err = repo.Last(ctx)
+	require.NoError(t, err)
+	requireEqualAuditEntry(t, second, last)
+}
+
+func testAuditSequenceConflict(t *testing.T, repo repository.Audit) {
+	ctx := context.Background()
+
+	first := NewAuditEntry(1, domain.AuditEntry{})
+	require.NoError(t, repo.Append(ctx, first))
+	require.ErrorIs(t, repo.Append(ctx, NewAuditEntry(1, domain.AuditEntry{})), domain.ErrAuditSequenceConflict)
+}
+
+func testAuditList(t *testing.T, repo repository.Audit) {
+	ctx := context.Background()
+
+	var prev domain.AuditEntry
+	target := uuid.NewString()
+	for i := range 5 {
+		e := NewAuditEntry(int64(i+1), prev)
+		if i%2 == 0 {
+			e.TargetID = target
+		}
+		require.NoError(t, repo.Append(ctx, e))
+		prev = e
+	}
+
+	p := &paginate.Pagination{Page: 1, PerPage: 2}
+	entries, err := repo.List(ctx, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, p.TotalItems)
+	require.Len(t, entries, 2)
+	require.EqualValues(t, 5, entries[0].Sequence, "newest first")
+	require.EqualValues(t, 4, entries[1].Sequence)
+
+	p = &paginate.Pagination{Page: 1, PerPage: 10,
+		Sort:    []paginate.Sort{{Field: "sequence", Arrange: paginate.SortOrderAscending}},
+		Filters: []paginate.Filter{{Key: "target_id", Value: target, Condition: paginate.FilterEqual}},
+	}
+	entries, err = repo.List(ctx, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, p.TotalItems)
+	require.Len(t, entries, 3)
+	for i, e := range entries {
+		require.EqualValues(t, 2*i+1, e.Sequence)
+		require.Equal(t, target, e.TargetID)
+	}
+}
+
+func testAuditHashChain(t *testing.T, repo repository.Audit) {
+	ctx := context.Background()
+
+	var prev domain.AuditEntry
+	for i := range 3 {
+		e := NewAuditEntry(int64(i+1), prev)
+		require.NoError(t, repo.Append(ctx, e))
+		prev = e
+	}
+
+	// stored entries must hash same as appended ones
+	entries, err := repo.List(ctx, &paginate.Pagination{Page: 1, PerPage: 10,
+		Sort: []paginate.Sort{{Field: "sequence", Arrange: paginate.SortOrderAscending}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, domain.VerifyAuditChain(domain.AuditEntry{}, entries))
+}
+
+func requireEqualAuditEntry(t *testing.T, expected, actual domain.AuditEntry) {
+	t.Helper()
+	require.True(t, expected.At.Equal(actual.At), "expected at %v, got %v", expected.At, actual.At)
+	expected.At, actual.At = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
+
+func testAuditTenantIsolation(t *testing.T, repo repository.Audit) {
+	acme := tenant.ContextWithID(context.Background(), uuid.New())
+	other := tenant.ContextWithID(context.Background(), uuid.New())
+
+	first := NewAuditEntry(1, domain.AuditEntry{})
+	first.TenantID = tenant.FromContext(acme)
+	first.Hash = first.ComputeHash()
+	require.NoError(t, repo.Append(acme, first))
+	second := NewAuditEntry(2, first)
+	second.TenantID = tenant.FromContext(other)
+	second.Hash = second.ComputeHash()
+	require.NoError(t, repo.Append(other, second))
+
+	last, err := repo.Last(acme)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, last.Sequence, "sequence is shared by all tenants")
+
+	p := &paginate.Pagination{Page: 1, PerPage: 10}
+	entries, err := repo.List(acme, p)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	requireEqualAuditEntry(t, first, entries[0])
+
+	entries, err = repo.List(tenant.ContextWithAllTenants(context.Background()), &paginate.Pagination{Page: 1, PerPage: 10,
+		Sort: []paginate.Sort{{Field: "sequence", Arrange: paginate.SortOrderAscending}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, domain.VerifyAuditChain(domain.AuditEntry{}, entries))
+}
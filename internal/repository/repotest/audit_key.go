@@ -0,0 +1,98 @@
+package repotest
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+)
+
+// AuditKeyFactory returns a new and empty repository.AuditKey for every call.
+type AuditKeyFactory func(t *testing.T) repository.AuditKey
+
+// RunAuditKeySuite runs the same scenarios against given repository.AuditKey implementation.
+// Each scenario gets a fresh repository from newRepo.
+func RunAuditKeySuite(t *testing.T, newRepo AuditKeyFactory) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, repo repository.AuditKey)
+	}{
+		{"create and get", testAuditKeyCreateAndGet},
+		{"create keeps existing", testAuditKeyCreateKeepsExisting},
+		{"delete", testAuditKeyDelete},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+// NewAuditKey returns a random key of subject of a new user.
+func NewAuditKey() domain.AuditKey {
+	key := make([]byte, domain.AuditKeySize)
+	rand.Read(key)
+	return domain.AuditKey{
+		Subject:   domain.AuditSubject(domain.AuditTargetUser, uuid.New()),
+		Key:       key,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+func testAuditKeyCreateAndGet(t *testing.T, repo repository.AuditKey) {
+	ctx := context.Background()
+	key := NewAuditKey()
+
+	_, err := repo.Get(ctx, key.Subject)
+	require.ErrorIs(t, err, domain.ErrAuditKeyNotFound)
+
+	created, err := repo.Create(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, key.Key, created.Key)
+
+	got, err := repo.Get(ctx, key.Subject)
+	require.NoError(t, err)
+	require.Equal(t, key.Subject, got.Subject)
+	require.Equal(t, key.Key, got.Key)
+	require.True(t, key.CreatedAt.Equal(got.CreatedAt))
+}
+
+func testAuditKeyCreateKeepsExisting(t *testing.T, repo repository.AuditKey) {
+	ctx := context.Background()
+	key := NewAuditKey()
+	_, err := repo.Create(ctx, key)
+	require.NoError(t, err)
+
+	other := NewAuditKey()
+	other.Subject = key.Subject
+	created, err := repo.Create(ctx, other)
+	require.NoError(t, err)
+	require.Equal(t, key.Key, created.Key)
+
+	got, err := repo.Get(ctx, key.Subject)
+	require.NoError(t, err)
+	require.Equal(t, key.Key, got.Key)
+}
+
+func testAuditKeyDelete(t *testing.T, repo repository.AuditKey) {
+	ctx := context.Background()
+	key, other := NewAuditKey(), NewAuditKey()
+	for _, k := range []domain.AuditKey{key, other} {
+		_, err := repo.Create(ctx, k)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, repo.Delete(ctx, key.Subject))
+	_, err := repo.Get(ctx, key.Subject)
+	require.ErrorIs(t, err, domain.ErrAuditKeyNotFound)
+	// deleting again is a no-op
+	require.NoError(t, repo.Delete(ctx, key.Subject))
+
+	_, err = repo.Get(ctx, other.Subject)
+	require.NoError(t, err)
+}
@@ -0,0 +1,247 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// GroupFactory returns a new and empty repository.Group for every call.
+type GroupFactory func(t *testing.T) repository.Group
+
+// RunGroupSuite runs the same scenarios against given repository.Group implementation.
+// Each scenario gets a fresh repository from newRepo.
+func RunGroupSuite(t *testing.T, newRepo GroupFactory) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, repo repository.Group)
+	}{
+		{"create and get", testGroupCreateAndGet},
+		{"list", testGroupList},
+		{"members", testGroupMembers},
+		{"delete", testGroupDelete},
+		{"tenant isolation", testGroupTenantIsolation},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+// NewGroup returns a group granting given roles, which is unique by id and name.
+func NewGroup(name string, roles ...domain.UserRole) domain.Group {
+	id := uuid.New()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return domain.Group{
+		ID:        id,
+		Name:      fmt.Sprintf("%s-%s", name, id),
+		Roles:     roles,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewGroupMember returns a member of group added now.
+func NewGroupMember(groupID uuid.UUID, kind domain.GroupMemberKind, memberID uuid.UUID) domain.GroupMember {
+	return domain.GroupMember{
+		GroupID:   groupID,
+		Kind:      kind,
+		MemberID:  memberID,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+func testGroupCreateAndGet(t *testing.T, repo repository.Group) {
+	ctx := context.Background()
+
+	group := NewGroup("admins", domain.UserRoleAdmin, domain.UserRoleNormal)
+	require.NoError(t, repo.Create(ctx, group))
+	got, err := repo.GetByID(ctx, group.ID)
+	require.NoError(t, err)
+	requireEqualGroup(t, group, got)
+
+	empty := NewGroup("empty")
+	require.NoError(t, repo.Create(ctx, empty))
+	got, err = repo.GetByID(ctx, empty.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.Roles)
+
+	duplicate := NewGroup("other")
+	duplicate.Name = group.Name
+	require.ErrorIs(t, repo.Create(ctx, duplicate), domain.ErrGroupAlreadyExists)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	require.ErrorIs(t, err, domain.ErrGroupNotFound)
+}
+
+func testGroupList(t *testing.T, repo repository.Group) {
+	ctx := context.Background()
+
+	var groups []domain.Group
+	for _, name := range []string{"b", "a", "c"} {
+		group := NewGroup(name)
+		require.NoError(t, repo.Create(ctx, group))
+		groups = append(groups, group)
+	}
+
+	p := &paginate.Pagination{Page: 1, PerPage: 2}
+	list, err := repo.List(ctx, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, p.TotalItems)
+	require.Len(t, list, 2)
+	require.Equal(t, groups[1].ID, list[0].ID, "ordered by name")
+	require.Equal(t, groups[0].ID, list[1].ID)
+
+	p = &paginate.Pagination{Page: 1, PerPage: 10,
+		Filters: []paginate.Filter{{Key: "name", Value: groups[2].Name, Condition: paginate.FilterEqual}},
+	}
+	list, err = repo.List(ctx, p)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, groups[2].ID, list[0].ID)
+}
+
+func testGroupMembers(t *testing.T, repo repository.Group) {
+	ctx := context.Background()
+
+	parent, child := NewGroup("b-parent"), NewGroup("a-child")
+	require.NoError(t, repo.Create(ctx, parent))
+	require.NoError(t, repo.Create(ctx, child))
+
+	userID := uuid.New()
+	user := NewGroupMember(parent.ID, domain.GroupMemberUser, userID)
+	nested := NewGroupMember(parent.ID, domain.GroupMemberGroup, child.ID)
+	nested.CreatedAt = user.CreatedAt.Add(time.Second)
+	require.NoError(t, repo.AddMember(ctx, user))
+	require.NoError(t, repo.AddMember(ctx, nested))
+	require.ErrorIs(t, repo.AddMember(ctx, user), domain.ErrGroupMemberAlreadyExists)
+	require.NoError(t, repo.AddMember(ctx, NewGroupMember(child.ID, domain.GroupMemberUser, userID)))
+
+	p := &paginate.Pagination{Page: 1, PerPage: 10}
+	members, err := repo.Members(ctx, parent.ID, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, p.TotalItems)
+	require.Len(t, members, 2)
+	requireEqualGroupMember(t, user, members[0])
+	requireEqualGroupMember(t, nested, members[1])
+
+	p = &paginate.Pagination{Page: 1, PerPage: 10,
+		Filters: []paginate.Filter{{Key: "kind", Value: string(domain.GroupMemberGroup), Condition: paginate.FilterEqual}},
+	}
+	members, err = repo.Members(ctx, parent.ID, p)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, child.ID, members[0].MemberID)
+
+	groups, err := repo.GroupsOf(ctx, domain.GroupMemberUser, userID)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	require.Equal(t, child.ID, groups[0].ID, "ordered by name")
+	require.Equal(t, parent.ID, groups[1].ID)
+
+	groups, err = repo.GroupsOf(ctx, domain.GroupMemberGroup, child.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, parent.ID, groups[0].ID)
+
+	groups, err = repo.GroupsOf(ctx, domain.GroupMemberGroup, userID)
+	require.NoError(t, err)
+	require.Empty(t, groups, "kind of member is part of its identity")
+
+	require.NoError(t, repo.RemoveMember(ctx, parent.ID, domain.GroupMemberUser, userID))
+	require.ErrorIs(t, repo.RemoveMember(ctx, parent.ID, domain.GroupMemberUser, userID), domain.ErrGroupMemberNotFound)
+	groups, err = repo.GroupsOf(ctx, domain.GroupMemberUser, userID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, child.ID, groups[0].ID)
+}
+
+func testGroupDelete(t *testing.T, repo repository.Group) {
+	ctx := context.Background()
+
+	parent, group, child := NewGroup("parent"), NewGroup("group"), NewGroup("child")
+	for _, g := range []domain.Group{parent, group, child} {
+		require.NoError(t, repo.Create(ctx, g))
+	}
+	userID := uuid.New()
+	require.NoError(t, repo.AddMember(ctx, NewGroupMember(parent.ID, domain.GroupMemberGroup, group.ID)))
+	require.NoError(t, repo.AddMember(ctx, NewGroupMember(group.ID, domain.GroupMemberGroup, child.ID)))
+	require.NoError(t, repo.AddMember(ctx, NewGroupMember(group.ID, domain.GroupMemberUser, userID)))
+
+	require.NoError(t, repo.Delete(ctx, group.ID))
+	require.ErrorIs(t, repo.Delete(ctx, group.ID), domain.ErrGroupNotFound)
+	_, err := repo.GetByID(ctx, group.ID)
+	require.ErrorIs(t, err, domain.ErrGroupNotFound)
+
+	members, err := repo.Members(ctx, parent.ID, &paginate.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Empty(t, members, "deleted group is not member of other groups")
+	groups, err := repo.GroupsOf(ctx, domain.GroupMemberGroup, child.ID)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+	groups, err = repo.GroupsOf(ctx, domain.GroupMemberUser, userID)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+}
+
+func testGroupTenantIsolation(t *testing.T, repo repository.Group) {
+	acme := tenant.ContextWithID(context.Background(), uuid.New())
+	other := tenant.ContextWithID(context.Background(), uuid.New())
+
+	group := NewGroup("admins", domain.UserRoleAdmin)
+	require.NoError(t, repo.Create(acme, group))
+	got, err := repo.GetByID(acme, group.ID)
+	require.NoError(t, err)
+	require.Equal(t, tenant.FromContext(acme), got.TenantID)
+
+	same := NewGroup("admins")
+	same.Name = group.Name
+	require.NoError(t, repo.Create(other, same), "names are unique per tenant")
+
+	userID := uuid.New()
+	require.NoError(t, repo.AddMember(acme, NewGroupMember(group.ID, domain.GroupMemberUser, userID)))
+
+	_, err = repo.GetByID(other, group.ID)
+	require.ErrorIs(t, err, domain.ErrGroupNotFound)
+	list, err := repo.List(other, &paginate.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, same.ID, list[0].ID)
+	members, err := repo.Members(other, group.ID, &paginate.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Empty(t, members)
+	groups, err := repo.GroupsOf(other, domain.GroupMemberUser, userID)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+	require.ErrorIs(t, repo.RemoveMember(other, group.ID, domain.GroupMemberUser, userID), domain.ErrGroupMemberNotFound)
+	require.ErrorIs(t, repo.Delete(other, group.ID), domain.ErrGroupNotFound)
+
+	groups, err = repo.GroupsOf(acme, domain.GroupMemberUser, userID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+}
+
+func requireEqualGroup(t *testing.T, expected, actual domain.Group) {
+	t.Helper()
+	require.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "expected %v, got %v", expected.CreatedAt, actual.CreatedAt)
+	require.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "expected %v, got %v", expected.UpdatedAt, actual.UpdatedAt)
+	expected.CreatedAt, actual.CreatedAt = time.Time{}, time.Time{}
+	expected.UpdatedAt, actual.UpdatedAt = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
+
+func requireEqualGroupMember(t *testing.T, expected, actual domain.GroupMember) {
+	t.Helper()
+	require.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "expected %v, got %v", expected.CreatedAt, actual.CreatedAt)
+	expected.CreatedAt, actual.CreatedAt = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
@@ -0,0 +1,239 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// InvitationFactory returns a new and empty repository.Invitation for every call.
+type InvitationFactory func(t *testing.T) repository.Invitation
+
+// RunInvitationSuite runs the same scenarios against given repository.Invitation implementation.
+// Each scenario gets a fresh repository from newRepo.
+func RunInvitationSuite(t *testing.T, newRepo InvitationFactory) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, repo repository.Invitation)
+	}{
+		{"create and get", testInvitationCreateAndGet},
+		{"update", testInvitationUpdate},
+		{"list", testInvitationList},
+		{"anonymize", testInvitationAnonymize},
+		{"tenant isolation", testInvitationTenantIsolation},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+// NewInvitation returns a pending invitation of a unique email, expiring in a day.
+func NewInvitation() domain.Invitation {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return domain.Invitation{
+		ID:        uuid.New(),
+		Email:     uuid.NewString() + "@example.com",
+		Role:      domain.UserRoleNormal,
+		Status:    domain.InvitationPending,
+		TokenHash: domain.HashInvitationToken(uuid.NewString()),
+		InvitedBy: uuid.New(),
+		ExpiresAt: now.Add(24 * time.Hour),
+		SentAt:    now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func testInvitationCreateAndGet(t *testing.T, repo repository.Invitation) {
+	ctx := context.Background()
+
+	invitation := NewInvitation()
+	require.NoError(t, repo.Create(ctx, invitation))
+	got, err := repo.GetByID(ctx, invitation.ID)
+	require.NoError(t, err)
+	requireEqualInvitation(t, invitation, got)
+
+	got, err = repo.GetByTokenHash(ctx, invitation.TokenHash)
+	require.NoError(t, err)
+	requireEqualInvitation(t, invitation, got)
+
+	system := NewInvitation()
+	system.InvitedBy = uuid.Nil
+	require.NoError(t, repo.Create(ctx, system))
+	got, err = repo.GetByID(ctx, system.ID)
+	require.NoError(t, err)
+	requireEqualInvitation(t, system, got)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	require.ErrorIs(t, err, domain.ErrInvitationNotFound)
+	_, err = repo.GetByTokenHash(ctx, domain.HashInvitationToken("unknown"))
+	require.ErrorIs(t, err, domain.ErrInvitationNotFound)
+}
+
+func testInvitationUpdate(t *testing.T, repo repository.Invitation) {
+	ctx := context.Background()
+	invitation := NewInvitation()
+	require.NoError(t, repo.Create(ctx, invitation))
+
+	// resend replaces token and extends expiry
+	oldTokenHash := invitation.TokenHash
+	invitation.TokenHash = domain.HashInvitationToken(uuid.NewString())
+	invitation.ExpiresAt = invitation.ExpiresAt.Add(time.Hour)
+	invitation.SentAt = invitation.SentAt.Add(time.Minute)
+	invitation.UpdatedAt = invitation.SentAt
+	require.NoError(t, repo.Update(ctx, invitation))
+	got, err := repo.GetByTokenHash(ctx, invitation.TokenHash)
+	require.NoError(t, err)
+	requireEqualInvitation(t, invitation, got)
+	_, err = repo.GetByTokenHash(ctx, oldTokenHash)
+	require.ErrorIs(t, err, domain.ErrInvitationNotFound)
+
+	invitation.Status = domain.InvitationAccepted
+	invitation.UserID = uuid.New()
+	require.NoError(t, repo.Update(ctx, invitation))
+	got, err = repo.GetByID(ctx, invitation.ID)
+	require.NoError(t, err)
+	requireEqualInvitation(t, invitation, got)
+
+	invitation.Status = domain.InvitationRevoked
+	require.ErrorIs(t, repo.Update(ctx, invitation), domain.ErrInvitationNotPending, "accepted invitation is final")
+	got, err = repo.GetByID(ctx, invitation.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.InvitationAccepted, got.Status)
+
+	require.ErrorIs(t, repo.Update(ctx, NewInvitation()), domain.ErrInvitationNotFound)
+}
+
+func testInvitationAnonymize(t *testing.T, repo repository.Invitation) {
+	ctx := context.Background()
+	user := domain.User{ID: uuid.New(), Email: uuid.NewString() + "@example.com"}
+	anonymized := user.Anonymized()
+	anonymized.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	// sent to email of user, accepted by user who changed its email later, and of someone else
+	sent, accepted, other := NewInvitation(), NewInvitation(), NewInvitation()
+	sent.Email = user.Email
+	accepted.CreatedAt = accepted.CreatedAt.Add(time.Second)
+	for _, invitation := range []domain.Invitation{sent, accepted, other} {
+		require.NoError(t, repo.Create(ctx, invitation))
+	}
+	accepted.Status = domain.InvitationAccepted
+	accepted.UserID = user.ID
+	require.NoError(t, repo.Update(ctx, accepted))
+
+	ids, err := repo.Anonymize(ctx, user, anonymized)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{sent.ID, accepted.ID}, ids)
+	for _, id := range ids {
+		got, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, anonymized.Email, got.Email)
+	}
+	got, err := repo.GetByID(ctx, other.ID)
+	require.NoError(t, err)
+	require.Equal(t, other.Email, got.Email)
+
+	ids, err = repo.Anonymize(ctx, user, anonymized)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{sent.ID, accepted.ID}, ids, "running again returns same invitations")
+}
+
+func testInvitationList(t *testing.T, repo repository.Invitation) {
+	ctx := context.Background()
+
+	inviter := uuid.New()
+	var invitations []domain.Invitation
+	for i := range 4 {
+		invitation := NewInvitation()
+		invitation.InvitedBy = inviter
+		if i%2 == 1 {
+			invitation.Role = domain.UserRoleAdmin
+		}
+		invitation.CreatedAt = invitation.CreatedAt.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.Create(ctx, invitation))
+		invitations = append(invitations, invitation)
+	}
+	require.NoError(t, repo.Create(ctx, NewInvitation()))
+
+	p := &paginate.Pagination{Page: 1, PerPage: 2,
+		Filters: []paginate.Filter{{Key: "invited_by", Value: inviter.String(), Condition: paginate.FilterEqual}},
+	}
+	list, err := repo.List(ctx, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, p.TotalItems)
+	require.Len(t, list, 2)
+	require.Equal(t, invitations[3].ID, list[0].ID, "newest first")
+	require.Equal(t, invitations[2].ID, list[1].ID)
+
+	p = &paginate.Pagination{Page: 1, PerPage: 10,
+		Sort: []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderAscending}},
+		Filters: []paginate.Filter{
+			{Key: "invited_by", Value: inviter.String(), Condition: paginate.FilterEqual},
+			{Key: "role", Value: string(domain.UserRoleAdmin), Condition: paginate.FilterEqual},
+		},
+	}
+	list, err = repo.List(ctx, p)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, invitations[1].ID, list[0].ID)
+	require.Equal(t, invitations[3].ID, list[1].ID)
+
+	p = &paginate.Pagination{Page: 1, PerPage: 10,
+		Filters: []paginate.Filter{{Key: "email", Value: invitations[0].Email, Condition: paginate.FilterEqual}},
+	}
+	list, err = repo.List(ctx, p)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, invitations[0].ID, list[0].ID)
+}
+
+func testInvitationTenantIsolation(t *testing.T, repo repository.Invitation) {
+	acme := tenant.ContextWithID(context.Background(), uuid.New())
+	other := tenant.ContextWithID(context.Background(), uuid.New())
+
+	invitation := NewInvitation()
+	require.NoError(t, repo.Create(acme, invitation))
+	got, err := repo.GetByID(acme, invitation.ID)
+	require.NoError(t, err)
+	require.Equal(t, tenant.FromContext(acme), got.TenantID)
+
+	_, err = repo.GetByID(other, invitation.ID)
+	require.ErrorIs(t, err, domain.ErrInvitationNotFound)
+	_, err = repo.GetByTokenHash(other, invitation.TokenHash)
+	require.ErrorIs(t, err, domain.ErrInvitationNotFound)
+	list, err := repo.List(other, &paginate.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Empty(t, list)
+	invitation.Status = domain.InvitationRevoked
+	require.ErrorIs(t, repo.Update(other, invitation), domain.ErrInvitationNotFound)
+
+	// invitees are not authenticated, so their token is looked up in all tenants
+	all := tenant.ContextWithAllTenants(context.Background())
+	got, err = repo.GetByTokenHash(all, invitation.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, tenant.FromContext(acme), got.TenantID)
+	require.NoError(t, repo.Update(all, invitation))
+}
+
+func requireEqualInvitation(t *testing.T, expected, actual domain.Invitation) {
+	t.Helper()
+	for _, times := range [][2]*time.Time{
+		{&expected.ExpiresAt, &actual.ExpiresAt},
+		{&expected.SentAt, &actual.SentAt},
+		{&expected.CreatedAt, &actual.CreatedAt},
+		{&expected.UpdatedAt, &actual.UpdatedAt},
+	} {
+		require.True(t, times[0].Equal(*times[1]), "expected %v, got %v", *times[0], *times[1])
+		*times[0], *times[1] = time.Time{}, time.Time{}
+	}
+	require.Equal(t, expected, actual)
+}
@@ -0,0 +1,177 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// OrganizationFactory returns a new repository.Organization, which has only the default organization, for every call.
+type OrganizationFactory func(t *testing.T) repository.Organization
+
+// RunOrganizationSuite runs the same scenarios against given repository.Organization implementation.
+// Each scenario gets a fresh repository from newRepo.
+func RunOrganizationSuite(t *testing.T, newRepo OrganizationFactory) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, repo repository.Organization)
+	}{
+		{"create and get", testOrganizationCreateAndGet},
+		{"default organization", testDefaultOrganization},
+		{"list", testOrganizationList},
+		{"members", testOrganizationMembers},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+// NewOrganization returns an organization which is unique by id and name.
+func NewOrganization(name string) domain.Organization {
+	id := uuid.New()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return domain.Organization{
+		ID:        id,
+		Name:      fmt.Sprintf("%s-%s", name, id),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func testOrganizationCreateAndGet(t *testing.T, repo repository.Organization) {
+	ctx := context.Background()
+
+	org := NewOrganization("acme")
+	require.NoError(t, repo.Create(ctx, org))
+	got, err := repo.GetByID(ctx, org.ID)
+	require.NoError(t, err)
+	requireEqualOrganization(t, org, got)
+
+	duplicate := NewOrganization("other")
+	duplicate.Name = org.Name
+	require.ErrorIs(t, repo.Create(ctx, duplicate), domain.ErrOrganizationAlreadyExists)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	require.ErrorIs(t, err, domain.ErrOrganizationNotFound)
+}
+
+func testDefaultOrganization(t *testing.T, repo repository.Organization) {
+	got, err := repo.GetByID(context.Background(), tenant.Default)
+	require.NoError(t, err)
+	require.Equal(t, tenant.Default, got.ID)
+	require.Equal(t, "default", got.Name)
+}
+
+func testOrganizationList(t *testing.T, repo repository.Organization) {
+	ctx := context.Background()
+
+	var orgs []domain.Organization
+	for _, name := range []string{"b", "a", "c"} {
+		org := NewOrganization("list")
+		org.Name = "list-" + name
+		require.NoError(t, repo.Create(ctx, org))
+		orgs = append(orgs, org)
+	}
+
+	p := &paginate.Pagination{Page: 1, PerPage: 10,
+		Filters: []paginate.Filter{
+			{Key: "id", Value: tenant.Default.String(), Condition: paginate.FilterNotEqual},
+			{Key: "name", Value: "list-a", Condition: paginate.FilterNotEqual},
+		},
+	}
+	list, err := repo.List(ctx, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, p.TotalItems)
+	require.Equal(t, "list-b", list[0].Name, "ordered by name")
+	require.Equal(t, "list-c", list[1].Name)
+
+	p = &paginate.Pagination{Page: 1, PerPage: 10,
+		Filters: []paginate.Filter{{Key: "id", Value: orgs[2].ID.String(), Condition: paginate.FilterEqual}},
+	}
+	list, err = repo.List(ctx, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, p.TotalItems)
+	requireEqualOrganization(t, orgs[2], list[0])
+}
+
+func testOrganizationMembers(t *testing.T, repo repository.Organization) {
+	ctx := context.Background()
+
+	org := NewOrganization("acme")
+	require.NoError(t, repo.Create(ctx, org))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	var members []domain.Membership
+	for i := range 3 {
+		m := domain.Membership{
+			OrganizationID: org.ID,
+			UserID:         uuid.New(),
+			Role:           domain.UserRoleNormal,
+			CreatedAt:      now.Add(time.Duration(i) * time.Second),
+		}
+		require.NoError(t, repo.SaveMember(ctx, m))
+		members = append(members, m)
+	}
+	require.NoError(t, repo.SaveMember(ctx, domain.Membership{
+		OrganizationID: tenant.Default, UserID: members[0].UserID, Role: domain.UserRoleAdmin, CreatedAt: now,
+	}))
+
+	// saving again changes only role
+	promoted := members[1]
+	promoted.Role = domain.UserRoleAdmin
+	promoted.CreatedAt = now.Add(time.Hour)
+	require.NoError(t, repo.SaveMember(ctx, promoted))
+	got, err := repo.Member(ctx, org.ID, promoted.UserID)
+	require.NoError(t, err)
+	members[1].Role = domain.UserRoleAdmin
+	requireEqualMembership(t, members[1], got)
+
+	p := &paginate.Pagination{Page: 1, PerPage: 10}
+	list, err := repo.Members(ctx, org.ID, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, p.TotalItems)
+	for i := range members {
+		requireEqualMembership(t, members[i], list[i])
+	}
+
+	p = &paginate.Pagination{Page: 1, PerPage: 10,
+		Filters: []paginate.Filter{{Key: "role", Value: string(domain.UserRoleAdmin), Condition: paginate.FilterEqual}},
+	}
+	list, err = repo.Members(ctx, org.ID, p)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, promoted.UserID, list[0].UserID)
+
+	require.NoError(t, repo.RemoveMember(ctx, org.ID, members[0].UserID))
+	_, err = repo.Member(ctx, org.ID, members[0].UserID)
+	require.ErrorIs(t, err, domain.ErrMembershipNotFound)
+	_, err = repo.Member(ctx, tenant.Default, members[0].UserID)
+	require.NoError(t, err, "memberships of other organizations are kept")
+	require.ErrorIs(t, repo.RemoveMember(ctx, org.ID, members[0].UserID), domain.ErrMembershipNotFound)
+}
+
+func requireEqualOrganization(t *testing.T, expected, actual domain.Organization) {
+	t.Helper()
+	require.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "expected %v, got %v", expected.CreatedAt, actual.CreatedAt)
+	require.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "expected %v, got %v", expected.UpdatedAt, actual.UpdatedAt)
+	expected.CreatedAt, actual.CreatedAt = time.Time{}, time.Time{}
+	expected.UpdatedAt, actual.UpdatedAt = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
+
+func requireEqualMembership(t *testing.T, expected, actual domain.Membership) {
+	t.Helper()
+	require.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "expected %v, got %v", expected.CreatedAt, actual.CreatedAt)
+	expected.CreatedAt, actual.CreatedAt = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
@@ -0,0 +1,229 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// PrivacyJobFactory returns a new and empty repository.PrivacyJob for every call.
+type PrivacyJobFactory func(t *testing.T) repository.PrivacyJob
+
+// RunPrivacyJobSuite runs the same scenarios against given repository.PrivacyJob implementation.
+// Each scenario gets a fresh repository from newRepo.
+func RunPrivacyJobSuite(t *testing.T, newRepo PrivacyJobFactory) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, repo repository.PrivacyJob)
+	}{
+		{"create and get", testPrivacyJobCreateAndGet},
+		{"claim", testPrivacyJobClaim},
+		{"finish", testPrivacyJobFinish},
+		{"list", testPrivacyJobList},
+		{"clear archives", testPrivacyJobClearArchives},
+		{"tenant isolation", testPrivacyJobTenantIsolation},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+// NewPrivacyJob returns a pending job of given kind.
+func NewPrivacyJob(kind domain.PrivacyJobKind) domain.PrivacyJob {
+	return domain.PrivacyJob{
+		ID:          uuid.New(),
+		Kind:        kind,
+		UserID:      uuid.New(),
+		Status:      domain.PrivacyJobPending,
+		RequestedBy: uuid.New(),
+		CreatedAt:   time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+func testPrivacyJobCreateAndGet(t *testing.T, repo repository.PrivacyJob) {
+	ctx := context.Background()
+
+	job := NewPrivacyJob(domain.PrivacyJobExport)
+	require.NoError(t, repo.Create(ctx, job))
+	got, err := repo.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	requireEqualPrivacyJob(t, job, got)
+
+	system := NewPrivacyJob(domain.PrivacyJobErasure)
+	system.RequestedBy = uuid.Nil
+	require.NoError(t, repo.Create(ctx, system))
+	got, err = repo.GetByID(ctx, system.ID)
+	require.NoError(t, err)
+	requireEqualPrivacyJob(t, system, got)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	require.ErrorIs(t, err, domain.ErrPrivacyJobNotFound)
+}
+
+func testPrivacyJobClaim(t *testing.T, repo repository.PrivacyJob) {
+	ctx := context.Background()
+	job := NewPrivacyJob(domain.PrivacyJobExport)
+	require.NoError(t, repo.Create(ctx, job))
+
+	startedAt := time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, repo.Claim(ctx, job.ID, startedAt, startedAt.Add(-time.Hour)))
+	got, err := repo.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.PrivacyJobRunning, got.Status)
+	require.True(t, startedAt.Equal(got.StartedAt))
+
+	require.ErrorIs(t, repo.Claim(ctx, job.ID, startedAt, startedAt.Add(-time.Hour)), domain.ErrPrivacyJobClaimed,
+		"running job is claimed")
+	require.NoError(t, repo.Claim(ctx, job.ID, startedAt.Add(time.Hour), startedAt.Add(time.Minute)),
+		"stale running job is claimable")
+
+	job.Status = domain.PrivacyJobCompleted
+	job.FinishedAt = startedAt.Add(time.Hour)
+	require.NoError(t, repo.Finish(ctx, job))
+	require.ErrorIs(t, repo.Claim(ctx, job.ID, time.Now(), time.Now().Add(time.Hour)), domain.ErrPrivacyJobClaimed,
+		"finished job is never claimable")
+
+	require.ErrorIs(t, repo.Claim(ctx, uuid.New(), time.Now(), time.Now()), domain.ErrPrivacyJobNotFound)
+}
+
+func testPrivacyJobFinish(t *testing.T, repo repository.PrivacyJob) {
+	ctx := context.Background()
+	job := NewPrivacyJob(domain.PrivacyJobExport)
+	require.NoError(t, repo.Create(ctx, job))
+
+	job.Status = domain.PrivacyJobCompleted
+	job.Archive = []byte("PK\x03\x04archive")
+	job.FinishedAt = time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, repo.Finish(ctx, job))
+	got, err := repo.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	requireEqualPrivacyJob(t, job, got)
+
+	failed := NewPrivacyJob(domain.PrivacyJobErasure)
+	require.NoError(t, repo.Create(ctx, failed))
+	failed.Status = domain.PrivacyJobFailed
+	failed.Error = "user not found"
+	failed.FinishedAt = time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, repo.Finish(ctx, failed))
+	got, err = repo.GetByID(ctx, failed.ID)
+	require.NoError(t, err)
+	requireEqualPrivacyJob(t, failed, got)
+
+	require.ErrorIs(t, repo.Finish(ctx, NewPrivacyJob(domain.PrivacyJobExport)), domain.ErrPrivacyJobNotFound)
+}
+
+func testPrivacyJobList(t *testing.T, repo repository.PrivacyJob) {
+	ctx := context.Background()
+
+	userID := uuid.New()
+	var jobs []domain.PrivacyJob
+	for i := range 4 {
+		kind := domain.PrivacyJobExport
+		if i%2 == 1 {
+			kind = domain.PrivacyJobErasure
+		}
+		job := NewPrivacyJob(kind)
+		job.UserID = userID
+		job.CreatedAt = job.CreatedAt.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.Create(ctx, job))
+		jobs = append(jobs, job)
+	}
+	require.NoError(t, repo.Create(ctx, NewPrivacyJob(domain.PrivacyJobExport)))
+
+	p := &paginate.Pagination{Page: 1, PerPage: 2,
+		Filters: []paginate.Filter{{Key: "user_id", Value: userID.String(), Condition: paginate.FilterEqual}},
+	}
+	list, err := repo.List(ctx, p)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, p.TotalItems)
+	require.Len(t, list, 2)
+	require.Equal(t, jobs[3].ID, list[0].ID, "newest first")
+	require.Equal(t, jobs[2].ID, list[1].ID)
+
+	p = &paginate.Pagination{Page: 1, PerPage: 10,
+		Sort: []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderAscending}},
+		Filters: []paginate.Filter{
+			{Key: "user_id", Value: userID.String(), Condition: paginate.FilterEqual},
+			{Key: "kind", Value: string(domain.PrivacyJobErasure), Condition: paginate.FilterEqual},
+		},
+	}
+	list, err = repo.List(ctx, p)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, jobs[1].ID, list[0].ID)
+	require.Equal(t, jobs[3].ID, list[1].ID)
+}
+
+func testPrivacyJobClearArchives(t *testing.T, repo repository.PrivacyJob) {
+	ctx := context.Background()
+
+	job := NewPrivacyJob(domain.PrivacyJobExport)
+	other := NewPrivacyJob(domain.PrivacyJobExport)
+	for _, j := range []domain.PrivacyJob{job, other} {
+		require.NoError(t, repo.Create(ctx, j))
+		j.Status = domain.PrivacyJobCompleted
+		j.Archive = []byte("archive")
+		j.FinishedAt = time.Now()
+		require.NoError(t, repo.Finish(ctx, j))
+	}
+
+	require.NoError(t, repo.ClearArchives(ctx, job.UserID))
+	got, err := repo.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.Archive)
+	require.Equal(t, domain.PrivacyJobCompleted, got.Status)
+
+	got, err = repo.GetByID(ctx, other.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("archive"), got.Archive, "archives of other users are kept")
+}
+
+func testPrivacyJobTenantIsolation(t *testing.T, repo repository.PrivacyJob) {
+	acme := tenant.ContextWithID(context.Background(), uuid.New())
+	other := tenant.ContextWithID(context.Background(), uuid.New())
+
+	job := NewPrivacyJob(domain.PrivacyJobExport)
+	require.NoError(t, repo.Create(acme, job))
+	got, err := repo.GetByID(acme, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, tenant.FromContext(acme), got.TenantID)
+
+	_, err = repo.GetByID(other, job.ID)
+	require.ErrorIs(t, err, domain.ErrPrivacyJobNotFound)
+	p := &paginate.Pagination{Page: 1, PerPage: 10}
+	list, err := repo.List(other, p)
+	require.NoError(t, err)
+	require.Empty(t, list)
+	require.ErrorIs(t, repo.Claim(other, job.ID, time.Now(), time.Now()), domain.ErrPrivacyJobNotFound)
+
+	all := tenant.ContextWithAllTenants(context.Background())
+	list, err = repo.List(all, &paginate.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NoError(t, repo.Claim(all, job.ID, time.Now(), time.Now()))
+}
+
+func requireEqualPrivacyJob(t *testing.T, expected, actual domain.PrivacyJob) {
+	t.Helper()
+	for _, times := range [][2]*time.Time{
+		{&expected.CreatedAt, &actual.CreatedAt},
+		{&expected.StartedAt, &actual.StartedAt},
+		{&expected.FinishedAt, &actual.FinishedAt},
+	} {
+		require.True(t, times[0].Equal(*times[1]), "expected %v, got %v", *times[0], *times[1])
+		*times[0], *times[1] = time.Time{}, time.Time{}
+	}
+	if len(expected.Archive) == 0 && len(actual.Archive) == 0 {
+		expected.Archive, actual.Archive = nil, nil
+	}
+	require.Equal(t, expected, actual)
+}
@@ -0,0 +1,1104 @@
+// Package repotest provides a conformance suite which every repository implementation must pass,
+// so all drivers(sql, mongodb, in-memory) behave the same way.
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/user"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// UserFactory returns a new and empty repository.User for every call.
+type UserFactory func(t *testing.T) repository.User
+
+// RunUserSuite runs the same scenarios against given repository.User implementation.
+// Each scenario gets a fresh repository from newRepo.
+func RunUserSuite(t *testing.T, newRepo UserFactory) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, repo repository.User)
+	}{
+		{"create and get", testCreateAndGet},
+		{"duplicate id", testDuplicateID},
+		{"duplicate email", testDuplicateEmail},
+		{"duplicate phone", testDuplicatePhone},
+		{"without phone", testWithoutPhone},
+		{"update conflict", testUpdateConflict},
+		{"not found", testNotFound},
+		{"update", testUpdate},
+		{"optimistic concurrency", testOptimisticConcurrency},
+		{"patch", testPatch},
+		{"patch conflict", testPatchConflict},
+		{"concurrent versioned updates", testConcurrentVersionedUpdates},
+		{"pagination", testPagination},
+		{"cursor pagination", testCursorPagination},
+		{"count", testCount},
+		{"filter", testFilter},
+		{"filter expression", testFilterExpression},
+		{"typed filter", testTypedFilter},
+		{"facets", testFacets},
+		{"search", testSearch},
+		{"projection", testProjection},
+		{"sort", testSort},
+		{"soft delete visibility", testSoftDelete},
+		{"restore", testRestore},
+		{"purge", testPurge},
+		{"purge deleted", testPurgeDeleted},
+		{"concurrent writes", testConcurrentWrites},
+		{"change status", testChangeStatus},
+		{"change status to deleted", testChangeStatusToDeleted},
+		{"status history", testStatusHistory},
+		{"anonymize", testAnonymize},
+		{"tenant isolation", testTenantIsolation},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+// NewUser returns a valid user which is unique by id and email.
+func NewUser(name string) domain.User {
+	id := uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+	return domain.User{
+		ID:          id,
+		Name:        name,
+		PhoneNumber: fmt.Sprintf("+98%010d", rand.Int64N(1e10)),
+		Email:       fmt.Sprintf("%s.%s@example.com", name, id),
+		Password:    "hashed_password",
+		Status:      domain.UsereStatusNew,
+		Role:        domain.UserRoleNormal,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Version:     1,
+	}
+}
+
+// RequireEqualUser asserts users are same, datetime compared at second precision
+// because some drivers do not keep fractional seconds.
+func RequireEqualUser(t *testing.T, expected, actual domain.User) {
+	t.Helper()
+	require.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Second)
+	require.WithinDuration(t, expected.UpdatedAt, actual.UpdatedAt, time.Second)
+	require.WithinDuration(t, expected.DeletedAt, actual.DeletedAt, time.Second)
+	expected.CreatedAt, actual.CreatedAt = time.Time{}, time.Time{}
+	expected.UpdatedAt, actual.UpdatedAt = time.Time{}, time.Time{}
+	expected.DeletedAt, actual.DeletedAt = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
+
+func createUsers(t *testing.T, repo repository.User, names ...string) []domain.User {
+	t.Helper()
+	users := make([]domain.User, 0, len(names))
+	for _, name := range names {
+		user := NewUser(name)
+		require.NoError(t, repo.Create(context.Background(), user))
+		users = append(users, user)
+	}
+	return users
+}
+
+// softDelete deletes user by ChangeStatus, same as user service.
+func softDelete(ctx context.Context, repo repository.User, id uuid.UUID) error {
+	user, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	transition, err := user.TransitionStatus(domain.UserStatusDeleted, "", uuid.Nil, time.Now())
+	if err != nil {
+		return err
+	}
+	return repo.ChangeStatus(ctx, transition, user.Version)
+}
+
+// restore restores deleted user by ChangeStatus, same as user service.
+func restore(ctx context.Context, repo repository.User, id uuid.UUID) error {
+	user, err := repo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	transition, err := user.TransitionStatus(domain.UserStatusActive, "", uuid.Nil, time.Now())
+	if err != nil {
+		return err
+	}
+	return repo.ChangeStatus(ctx, transition, user.Version)
+}
+
+func names(users []domain.User) []string {
+	n := make([]string, 0, len(users))
+	for _, user := range users {
+		n = append(n, user.Name)
+	}
+	return n
+}
+
+func testCreateAndGet(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	user := createUsers(t, repo, "amir")[0]
+
+	got, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	RequireEqualUser(t, user, got)
+
+	got, err = repo.GetByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	RequireEqualUser(t, user, got)
+}
+
+func testDuplicateID(t *testing.T, repo repository.User) {
+	user := createUsers(t, repo, "amir")[0]
+
+	duplicate := NewUser("other")
+	duplicate.ID = user.ID
+	err := repo.Create(context.Background(), duplicate)
+	require.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+}
+
+func testDuplicateEmail(t *testing.T, repo repository.User) {
+	user := createUsers(t, repo, "amir")[0]
+
+	duplicate := NewUser("other")
+	duplicate.Email = user.Email
+	err := repo.Create(context.Background(), duplicate)
+	require.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+}
+
+func testDuplicatePhone(t *testing.T, repo repository.User) {
+	user := createUsers(t, repo, "amir")[0]
+
+	duplicate := NewUser("other")
+	duplicate.PhoneNumber = user.PhoneNumber
+	err := repo.Create(context.Background(), duplicate)
+	require.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+}
+
+func testWithoutPhone(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	for _, name := range []string{"a", "b"} {
+		user := NewUser(name)
+		user.PhoneNumber = ""
+		require.NoError(t, repo.Create(ctx, user))
+
+		got, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		RequireEqualUser(t, user, got)
+	}
+}
+
+func testUpdateConflict(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b")
+
+	user := users[1]
+	user.Email = users[0].Email
+	require.ErrorIs(t, repo.Update(ctx, user), domain.ErrUserAlreadyExists)
+
+	user = users[1]
+	user.PhoneNumber = users[0].PhoneNumber
+	require.ErrorIs(t, repo.Update(ctx, user), domain.ErrUserAlreadyExists)
+}
+
+func testNotFound(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	createUsers(t, repo, "amir")
+	unknown := NewUser("unknown")
+
+	_, err := repo.GetByID(ctx, unknown.ID)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	_, err = repo.GetByEmail(ctx, unknown.Email)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	err = repo.Update(ctx, unknown)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	err = softDelete(ctx, repo, unknown.ID)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func testUpdate(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	user := createUsers(t, repo, "amir")[0]
+
+	user.Name = "amir mirzaei"
+	user.PhoneNumber = "+989107654321"
+	user.Email = "updated." + user.Email
+	user.Password = "new_hashed_password"
+	user.UpdatedAt = user.UpdatedAt.Add(time.Hour)
+	require.NoError(t, repo.Update(ctx, user))
+
+	got, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	user.Version++
+	RequireEqualUser(t, user, got)
+}
+
+func testOptimisticConcurrency(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	user := createUsers(t, repo, "amir")[0]
+
+	stale := user
+	user.Name = "first"
+	require.NoError(t, repo.Update(ctx, user))
+
+	stale.Name = "second"
+	require.ErrorIs(t, repo.Update(ctx, stale), domain.ErrConcurrentModification)
+
+	// zero version skips check
+	unconditional := stale
+	unconditional.Version = 0
+	require.NoError(t, repo.Update(ctx, unconditional))
+
+	got, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, "second", got.Name)
+	require.Equal(t, int64(3), got.Version)
+
+	require.NoError(t, softDelete(ctx, repo, user.ID))
+	got.Name = "deleted"
+	require.ErrorIs(t, repo.Update(ctx, got), domain.ErrUserNotFound)
+}
+
+func testPatch(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	user := createUsers(t, repo, "amir")[0]
+
+	name, phone := "amir mirzaei", ""
+	role := domain.UserRoleAdmin
+	patch := domain.UserPatch{
+		Name:        &name,
+		PhoneNumber: &phone,
+		Role:        &role,
+		UpdatedAt:   user.UpdatedAt.Add(time.Hour),
+		Version:     user.Version,
+	}
+	require.NoError(t, repo.Patch(ctx, user.ID, patch))
+
+	got, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	expected := patch.Apply(user)
+	expected.Version++
+	RequireEqualUser(t, expected, got)
+
+	// stale version
+	require.ErrorIs(t, repo.Patch(ctx, user.ID, patch), domain.ErrConcurrentModification)
+
+	patch = domain.UserPatch{UpdatedAt: got.UpdatedAt}
+	require.ErrorIs(t, repo.Patch(ctx, uuid.New(), patch), domain.ErrUserNotFound)
+
+	require.NoError(t, softDelete(ctx, repo, user.ID))
+	require.ErrorIs(t, repo.Patch(ctx, user.ID, patch), domain.ErrUserNotFound)
+}
+
+func testPatchConflict(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b")
+
+	require.ErrorIs(t, repo.Patch(ctx, users[1].ID, domain.UserPatch{Email: &users[0].Email}), domain.ErrUserAlreadyExists)
+	require.ErrorIs(t, repo.Patch(ctx, users[1].ID, domain.UserPatch{PhoneNumber: &users[0].PhoneNumber}), domain.ErrUserAlreadyExists)
+
+	// users without phone number do not conflict
+	empty := ""
+	require.NoError(t, repo.Patch(ctx, users[0].ID, domain.UserPatch{PhoneNumber: &empty}))
+	require.NoError(t, repo.Patch(ctx, users[1].ID, domain.UserPatch{PhoneNumber: &empty}))
+}
+
+func testConcurrentVersionedUpdates(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	const writers = 10
+
+	user := createUsers(t, repo, "amir")[0]
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			updated := user
+			updated.Name = fmt.Sprintf("amir%d", i)
+			errCh <- repo.Update(ctx, updated)
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	var succeeded int
+	for err := range errCh {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, domain.ErrConcurrentModification)
+	}
+	require.Equal(t, 1, succeeded)
+
+	got, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.Version+1, got.Version)
+}
+
+func testPagination(t *testing.T, repo repository.User) {
+	createUsers(t, repo, "a", "b", "c", "d", "e")
+
+	for _, tc := range []struct {
+		page, perPage int
+		expected      []string
+	}{
+		{1, 2, []string{"a", "b"}},
+		{2, 2, []string{"c", "d"}},
+		{3, 2, []string{"e"}},
+		{4, 2, []string{}},
+		{1, 10, []string{"a", "b", "c", "d", "e"}},
+	} {
+		t.Run(fmt.Sprintf("page %d per page %d", tc.page, tc.perPage), func(t *testing.T) {
+			pagination := &paginate.Pagination{
+				Page:    tc.page,
+				PerPage: tc.perPage,
+				Sort:    []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}},
+			}
+			users, err := repo.List(context.Background(), pagination)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, names(users))
+			require.Equal(t, int64(5), pagination.TotalItems)
+		})
+	}
+}
+
+func testCount(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	createUsers(t, repo, "a", "b", "c")
+
+	for _, tc := range []struct {
+		count   string
+		page    int
+		total   int64
+		hasMore bool
+	}{
+		{paginate.CountExact, 1, 3, true},
+		{paginate.CountExact, 2, 3, false},
+		{paginate.CountNone, 1, 0, true},
+		{paginate.CountNone, 2, 0, false},
+		// estimates of filtered lists, e.g. by tenant, are exact unless the database estimates them
+		{paginate.CountEstimated, 1, 3, true},
+	} {
+		t.Run(fmt.Sprintf("%s %d", tc.count, tc.page), func(t *testing.T) {
+			pagination := &paginate.Pagination{
+				Page:    tc.page,
+				PerPage: 2,
+				Sort:    []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}},
+				Count:   tc.count,
+			}
+			users, err := repo.List(ctx, pagination)
+			require.NoError(t, err)
+			require.Len(t, users, 2-tc.page+1)
+			require.Equal(t, tc.hasMore, pagination.HasMore)
+			if !pagination.Estimated {
+				require.Equal(t, tc.total, pagination.TotalItems)
+			}
+		})
+	}
+
+	_, err := repo.List(ctx, &paginate.Pagination{Page: 1, PerPage: 2, Count: "all"})
+	var fieldErr *paginate.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	require.Equal(t, "count", fieldErr.Param)
+}
+
+func testCursorPagination(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	// same names are ordered by id
+	createUsers(t, repo, "a", "b", "b", "b", "c")
+	sort := []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}}
+
+	var (
+		forward []domain.User
+		pages   []*paginate.Pagination
+	)
+	cursor := &paginate.Cursor{}
+	for {
+		pagination := &paginate.Pagination{PerPage: 2, Sort: sort, Cursor: cursor}
+		users, err := repo.List(ctx, pagination)
+		require.NoError(t, err)
+		require.NotEmpty(t, users)
+		forward = append(forward, users...)
+		pages = append(pages, pagination)
+		if pagination.NextCursor == "" {
+			break
+		}
+		cursor = &paginate.Cursor{Token: pagination.NextCursor}
+	}
+	require.Len(t, pages, 3)
+	require.Empty(t, pages[0].PrevCursor)
+	require.Equal(t, []string{"a", "b", "b", "b", "c"}, names(forward))
+	ids := map[uuid.UUID]bool{}
+	for _, user := range forward {
+		ids[user.ID] = true
+	}
+	require.Len(t, ids, 5)
+
+	// walking back from the last page lists same pages
+	var backward []domain.User
+	cursor = &paginate.Cursor{Token: pages[2].PrevCursor, Backward: true}
+	for {
+		pagination := &paginate.Pagination{PerPage: 2, Sort: sort, Cursor: cursor}
+		users, err := repo.List(ctx, pagination)
+		require.NoError(t, err)
+		require.NotEmpty(t, pagination.NextCursor)
+		backward = append(users, backward...)
+		if pagination.PrevCursor == "" {
+			break
+		}
+		cursor = &paginate.Cursor{Token: pagination.PrevCursor, Backward: true}
+	}
+	require.Equal(t, forward[:4], backward)
+
+	last := &paginate.Pagination{PerPage: 2, Sort: sort, Cursor: &paginate.Cursor{Backward: true}}
+	users, err := repo.List(ctx, last)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, names(users))
+	require.Empty(t, last.NextCursor)
+	require.NotEmpty(t, last.PrevCursor)
+
+	_, err = repo.List(ctx, &paginate.Pagination{PerPage: 2, Sort: sort, Cursor: &paginate.Cursor{Token: pages[0].NextCursor + "x"}})
+	require.ErrorIs(t, err, paginate.ErrInvalidCursor)
+
+	otherSort := []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderDescending}}
+	_, err = repo.List(ctx, &paginate.Pagination{PerPage: 2, Sort: otherSort, Cursor: &paginate.Cursor{Token: pages[0].NextCursor}})
+	require.ErrorIs(t, err, paginate.ErrInvalidCursor)
+}
+
+func testFilter(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b", "c")
+	require.NoError(t, softDelete(ctx, repo, users[1].ID))
+
+	deletedStatus := paginate.Filter{Key: "status", Value: strconv.Itoa(int(domain.UserStatusDeleted)), Condition: paginate.FilterEqual}
+	withDeleted := paginate.Filter{Key: user.FilterWithDeleted, Value: "true", Condition: paginate.FilterEqual}
+
+	for _, tc := range []struct {
+		name     string
+		filters  []paginate.Filter
+		expected []string
+	}{
+		{"equal", []paginate.Filter{{Key: "name", Value: "a", Condition: paginate.FilterEqual}}, []string{"a"}},
+		{"not equal", []paginate.Filter{{Key: "name", Value: "a", Condition: paginate.FilterNotEqual}}, []string{"c"}},
+		{"deleted are hidden", []paginate.Filter{deletedStatus}, []string{}},
+		{"with deleted", []paginate.Filter{withDeleted}, []string{"a", "b", "c"}},
+		{"only deleted", []paginate.Filter{withDeleted, deletedStatus}, []string{"b"}},
+		{"unknown field is ignored", []paginate.Filter{{Key: "unknown", Value: "a", Condition: paginate.FilterEqual}}, []string{"a", "c"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pagination := &paginate.Pagination{
+				Page:    1,
+				PerPage: 10,
+				Sort:    []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}},
+				Filters: tc.filters,
+			}
+			users, err := repo.List(ctx, pagination)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, names(users))
+			require.Equal(t, int64(len(tc.expected)), pagination.TotalItems)
+		})
+	}
+}
+
+func testFilterExpression(t *testing.T, repo repository.User) {
+	createUsers(t, repo, "a", "b", "c", "d")
+
+	for _, tc := range []struct {
+		expression string
+		filters    []paginate.Filter
+		expected   []string
+	}{
+		{"name==a,name==c", nil, []string{"a", "c"}},
+		{"(name==a,name==c);name!=c", nil, []string{"a"}},
+		{"name==d,name==b;name!=b", nil, []string{"d"}},
+		{"!(name==a,name==b)", nil, []string{"c", "d"}},
+		{"name=out=(a,b,c)", nil, []string{"d"}},
+		{"name==a,name==c", []paginate.Filter{{Key: "name", Value: "a", Condition: paginate.FilterNotEqual}}, []string{"c"}},
+	} {
+		t.Run(tc.expression, func(t *testing.T) {
+			pagination := &paginate.Pagination{
+				Page:       1,
+				PerPage:    10,
+				Sort:       []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}},
+				Filters:    tc.filters,
+				Expression: tc.expression,
+			}
+			users, err := repo.List(context.Background(), pagination)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, names(users))
+			require.Equal(t, int64(len(tc.expected)), pagination.TotalItems)
+		})
+	}
+
+	_, err := repo.List(context.Background(), &paginate.Pagination{Page: 1, PerPage: 10, Expression: "name==a;"})
+	var syntaxErr *paginate.SyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+}
+
+func testTypedFilter(t *testing.T, repo repository.User) {
+	users := createUsers(t, repo, "amir", "Ali", "b_c", "bxc")
+	status := strconv.Itoa(int(domain.UsereStatusNew))
+
+	for _, tc := range []struct {
+		name     string
+		filters  []paginate.Filter
+		expected []string
+	}{
+		{"uuid", []paginate.Filter{{Key: "id", Value: users[1].ID.String(), Condition: paginate.FilterEqual}}, []string{"Ali"}},
+		{"int", []paginate.Filter{{Key: "status", Value: status + ",9", Condition: paginate.FilterIn}}, []string{"Ali", "amir", "b_c", "bxc"}},
+		{"int greater", []paginate.Filter{{Key: "status", Value: status, Condition: paginate.FilterGreater}}, []string{}},
+		{"enum", []paginate.Filter{{Key: "role", Value: string(domain.UserRoleAdmin), Condition: paginate.FilterEqual}}, []string{}},
+		{"ilike", []paginate.Filter{{Key: "name", Value: "a%", Condition: paginate.FilterILike}}, []string{"Ali", "amir"}},
+		{"like escaped", []paginate.Filter{{Key: "name", Value: `b\_c`, Condition: paginate.FilterLike}}, []string{"b_c"}},
+		{"like single character", []paginate.Filter{{Key: "name", Value: "b_c", Condition: paginate.FilterLike}}, []string{"b_c", "bxc"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pagination := &paginate.Pagination{
+				Page:    1,
+				PerPage: 10,
+				Sort:    []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}},
+				Filters: tc.filters,
+			}
+			users, err := repo.List(context.Background(), pagination)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, names(users))
+		})
+	}
+
+	for _, filter := range []paginate.Filter{
+		{Key: "status", Value: "abc", Condition: paginate.FilterEqual},
+		{Key: "role", Value: "Root", Condition: paginate.FilterEqual},
+		{Key: "id", Value: "a%", Condition: paginate.FilterLike},
+	} {
+		_, err := repo.List(context.Background(), &paginate.Pagination{Page: 1, PerPage: 10, Filters: []paginate.Filter{filter}})
+		var filterErr *paginate.FilterError
+		require.ErrorAs(t, err, &filterErr)
+		require.Equal(t, filter.Key, filterErr.Field)
+	}
+	_, err := repo.List(context.Background(), &paginate.Pagination{Page: 1, PerPage: 10, Expression: "status=gt=new"})
+	var filterErr *paginate.FilterError
+	require.ErrorAs(t, err, &filterErr)
+}
+
+func testFacets(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b", "c")
+	transition, err := users[2].TransitionStatus(domain.UserStatusBanned, "spam", users[0].ID, time.Now().UTC())
+	require.NoError(t, err)
+	require.NoError(t, repo.ChangeStatus(ctx, transition, users[2].Version))
+
+	pagination := &paginate.Pagination{
+		Page:    1,
+		PerPage: 1,
+		Filters: []paginate.Filter{{Key: "name", Value: "a", Condition: paginate.FilterNotEqual}},
+		Aggregations: []paginate.Aggregation{
+			paginate.ParseAggregation("status"),
+			paginate.ParseAggregation("created_at:day,max(created_at),sum(status)"),
+		},
+	}
+	list, err := repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Len(t, pagination.Facets, 2)
+
+	// facets are of all filtered users, not only the page
+	status := pagination.Facets[0]
+	require.Equal(t, "status", status.Field)
+	require.Len(t, status.Groups, 2)
+	require.EqualValues(t, domain.UsereStatusNew, status.Groups[0].Key)
+	require.Equal(t, int64(1), status.Groups[0].Count)
+	require.EqualValues(t, domain.UserStatusBanned, status.Groups[1].Key)
+	require.Equal(t, int64(1), status.Groups[1].Count)
+
+	created := pagination.Facets[1]
+	require.Equal(t, "day", created.Bucket)
+	require.Len(t, created.Groups, 1)
+	require.Equal(t, paginate.BucketStart(users[0].CreatedAt, paginate.BucketDay).Format(time.RFC3339), created.Groups[0].Key)
+	require.Equal(t, int64(2), created.Groups[0].Count)
+	require.Contains(t, created.Groups[0].Metrics, "max(created_at)")
+	require.EqualValues(t, domain.UsereStatusNew+domain.UserStatusBanned, created.Groups[0].Metrics["sum(status)"])
+
+	for _, facet := range []string{"password", "name:day", "status,avg(status)", "name,sum(name)"} {
+		_, err = repo.List(ctx, &paginate.Pagination{Page: 1, PerPage: 10, Aggregations: []paginate.Aggregation{paginate.ParseAggregation(facet)}})
+		var fieldErr *paginate.FieldError
+		require.ErrorAs(t, err, &fieldErr, facet)
+		require.Equal(t, "facet", fieldErr.Param)
+	}
+}
+
+func testProjection(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b")
+
+	// fields are named same as fields of responses, cursor pages project their keyset fields too
+	for _, pagination := range []*paginate.Pagination{
+		{Page: 1, PerPage: 10, Fields: []string{"phone_number", "attributes.plan"}},
+		{PerPage: 10, Fields: []string{"phone_number"}, Cursor: &paginate.Cursor{}},
+	} {
+		pagination.Sort = []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}}
+		list, err := repo.List(ctx, pagination)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		for i, user := range list {
+			require.Equal(t, users[i].PhoneNumber, user.PhoneNumber)
+		}
+	}
+
+	// passwords and fields of other names are never projected
+	for _, field := range []string{"password", "phone", "deleted_at"} {
+		_, err := repo.List(ctx, &paginate.Pagination{Page: 1, PerPage: 10, Fields: []string{field}})
+		var fieldErr *paginate.FieldError
+		require.ErrorAs(t, err, &fieldErr, field)
+		require.Equal(t, "fields", fieldErr.Param)
+		require.NotContains(t, fieldErr.Allowed, "password")
+	}
+}
+
+func testSearch(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "Amir Zayi", "Amir Amiri", "Sara Sara", "Sara Zayi", "Ali")
+
+	byName := []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}}
+	for _, tc := range []struct {
+		q        string
+		expected []string
+	}{
+		{"amir", []string{"Amir Amiri", "Amir Zayi"}},
+		{"ZAYI amir", []string{"Amir Zayi"}},
+		{"zayi", []string{"Amir Zayi", "Sara Zayi"}},
+		// words of emails are searched too
+		{"example.com", []string{"Ali", "Amir Amiri", "Amir Zayi", "Sara Sara", "Sara Zayi"}},
+		// operators of databases are words of no user
+		{`amir -zayi OR "sara"`, []string{}},
+		{"amiri*", []string{"Amir Amiri"}},
+		{"nobody", []string{}},
+	} {
+		t.Run(tc.q, func(t *testing.T) {
+			pagination := &paginate.Pagination{Page: 1, PerPage: 10, Sort: byName, Search: tc.q}
+			list, err := repo.List(ctx, pagination)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, names(list))
+			require.Equal(t, int64(len(tc.expected)), pagination.TotalItems)
+		})
+	}
+
+	// searched users are sorted most relevant first unless sorted by pagination
+	pagination := &paginate.Pagination{Page: 1, PerPage: 10, Search: "sara"}
+	list, err := repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Sara Sara", "Sara Zayi"}, names(list))
+	require.Equal(t, []paginate.Sort{{Field: paginate.SortRelevance, Arrange: paginate.SortOrderDescending}}, pagination.Sort)
+	require.Equal(t, "<mark>Sara</mark> Zayi", pagination.Highlights[users[3].ID.String()]["name"])
+	require.Equal(t, "<mark>Sara</mark> <mark>Sara</mark>", pagination.Highlights[users[2].ID.String()]["name"])
+
+	_, err = repo.List(ctx, &paginate.Pagination{Page: 1, PerPage: 10, Sort: []paginate.Sort{{Field: paginate.SortRelevance, Arrange: paginate.SortOrderDescending}}})
+	var fieldErr *paginate.FieldError
+	require.ErrorAs(t, err, &fieldErr, "lists are sorted by relevance only if searched")
+
+	// index is kept up to date on writes
+	renamed := "Ali Zayi"
+	require.NoError(t, repo.Patch(ctx, users[4].ID, domain.UserPatch{Name: &renamed, UpdatedAt: time.Now().UTC()}))
+	anonymized := users[0].Anonymized()
+	anonymized.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, repo.Anonymize(ctx, anonymized))
+	require.NoError(t, softDelete(ctx, repo, users[3].ID))
+	require.NoError(t, repo.Purge(ctx, users[3].ID))
+
+	pagination = &paginate.Pagination{Page: 1, PerPage: 10, Sort: byName, Search: "zayi"}
+	list, err = repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Ali Zayi"}, names(list))
+	require.Equal(t, map[string]map[string]string{
+		users[4].ID.String(): {"name": "Ali <mark>Zayi</mark>"},
+	}, pagination.Highlights)
+}
+
+func testSort(t *testing.T, repo repository.User) {
+	createUsers(t, repo, "b", "c", "a")
+
+	for _, tc := range []struct {
+		arrange  string
+		expected []string
+	}{
+		{paginate.SortOrderAscending, []string{"a", "b", "c"}},
+		{paginate.SortOrderDescending, []string{"c", "b", "a"}},
+	} {
+		t.Run(tc.arrange, func(t *testing.T) {
+			users, err := repo.List(context.Background(), &paginate.Pagination{
+				Page:    1,
+				PerPage: 10,
+				Sort:    []paginate.Sort{{Field: "name", Arrange: tc.arrange}},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, names(users))
+		})
+	}
+}
+
+func testSoftDelete(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	deleted := createUsers(t, repo, "amir")[0]
+
+	require.NoError(t, softDelete(ctx, repo, deleted.ID))
+
+	_, err := repo.GetByID(ctx, deleted.ID)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	_, err = repo.GetByEmail(ctx, deleted.Email)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	require.ErrorIs(t, repo.Update(ctx, deleted), domain.ErrUserNotFound)
+	require.ErrorIs(t, softDelete(ctx, repo, deleted.ID), domain.ErrUserNotFound)
+
+	// deleted user still reserves its email until purge
+	duplicate := NewUser("other")
+	duplicate.Email = deleted.Email
+	require.ErrorIs(t, repo.Create(ctx, duplicate), domain.ErrUserAlreadyExists)
+
+	pagination := &paginate.Pagination{Page: 1, PerPage: 10}
+	users, err := repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Empty(t, users)
+	require.Zero(t, pagination.TotalItems)
+
+	pagination.Filters = []paginate.Filter{{Key: user.FilterWithDeleted, Value: "true", Condition: paginate.FilterEqual}}
+	users, err = repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, domain.UserStatusDeleted, users[0].Status)
+	require.WithinDuration(t, time.Now(), users[0].DeletedAt, time.Minute)
+}
+
+func testRestore(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	restored := createUsers(t, repo, "amir")[0]
+
+	require.ErrorIs(t, restore(ctx, repo, restored.ID), domain.ErrUserNotFound, "only deleted users are restorable")
+	_, err := repo.GetDeletedByID(ctx, restored.ID)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	require.NoError(t, softDelete(ctx, repo, restored.ID))
+	deleted, err := repo.GetDeletedByID(ctx, restored.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.UserStatusDeleted, deleted.Status)
+	require.Equal(t, restored.Version+1, deleted.Version)
+	require.NoError(t, restore(ctx, repo, restored.ID))
+
+	got, err := repo.GetByID(ctx, restored.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.UserStatusActive, got.Status)
+	require.True(t, got.DeletedAt.IsZero())
+}
+
+func testPurge(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b")
+
+	require.ErrorIs(t, repo.Purge(ctx, users[0].ID), domain.ErrUserNotFound, "only deleted users are purgeable")
+
+	require.NoError(t, softDelete(ctx, repo, users[0].ID))
+	require.NoError(t, repo.Purge(ctx, users[0].ID))
+	require.ErrorIs(t, restore(ctx, repo, users[0].ID), domain.ErrUserNotFound)
+
+	// purged user's email is free to use again
+	reused := NewUser("a")
+	reused.Email = users[0].Email
+	require.NoError(t, repo.Create(ctx, reused))
+
+	pagination := &paginate.Pagination{
+		Page:    1,
+		PerPage: 10,
+		Sort:    []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}},
+		Filters: []paginate.Filter{{Key: user.FilterWithDeleted, Value: "true", Condition: paginate.FilterEqual}},
+	}
+	list, err := repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, names(list))
+	require.Equal(t, reused.ID, list[0].ID)
+}
+
+func testPurgeDeleted(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b", "c")
+	require.NoError(t, softDelete(ctx, repo, users[0].ID))
+	require.NoError(t, softDelete(ctx, repo, users[1].ID))
+
+	count, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, count, "recently deleted users are kept")
+
+	count, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	pagination := &paginate.Pagination{
+		Page:    1,
+		PerPage: 10,
+		Filters: []paginate.Filter{{Key: user.FilterWithDeleted, Value: "true", Condition: paginate.FilterEqual}},
+	}
+	list, err := repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, names(list))
+}
+
+func testConcurrentWrites(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	const writers = 10
+
+	user := createUsers(t, repo, "amir")[0]
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, writers*2)
+	for i := range writers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errCh <- repo.Create(ctx, NewUser(fmt.Sprintf("user%d", i)))
+		}()
+		go func() {
+			defer wg.Done()
+			updated := user
+			updated.Name = fmt.Sprintf("amir%d", i)
+			updated.Version = 0
+			errCh <- repo.Update(ctx, updated)
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+
+	pagination := &paginate.Pagination{Page: 1, PerPage: writers * 2}
+	users, err := repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Len(t, users, writers+1)
+	require.Equal(t, int64(writers+1), pagination.TotalItems)
+
+	got, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Regexp(t, `^amir\d$`, got.Name)
+}
+
+func testChangeStatus(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	u := createUsers(t, repo, "amir")[0]
+
+	at := time.Now().UTC().Truncate(time.Second)
+	transition, err := u.TransitionStatus(domain.UserStatusBanned, "spam", uuid.New(), at)
+	require.NoError(t, err)
+	require.ErrorIs(t, repo.ChangeStatus(ctx, transition, u.Version+1), domain.ErrConcurrentModification, "stale version")
+	require.NoError(t, repo.ChangeStatus(ctx, transition, u.Version))
+
+	got, err := repo.GetByID(ctx, u.ID)
+	require.NoError(t, err)
+	u.Status, u.UpdatedAt, u.Version = domain.UserStatusBanned, at, u.Version+1
+	RequireEqualUser(t, u, got)
+
+	require.ErrorIs(t, repo.ChangeStatus(ctx, transition, 0), domain.ErrConcurrentModification, "status already changed")
+
+	transition.UserID = uuid.New()
+	require.ErrorIs(t, repo.ChangeStatus(ctx, transition, 0), domain.ErrUserNotFound)
+}
+
+func testChangeStatusToDeleted(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	u := createUsers(t, repo, "amir")[0]
+
+	deleted, err := u.TransitionStatus(domain.UserStatusDeleted, "", uuid.Nil, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.ChangeStatus(ctx, deleted, 0))
+
+	_, err = repo.GetByID(ctx, u.ID)
+	require.ErrorIs(t, err, domain.ErrUserNotFound, "deleted user is hidden")
+	require.ErrorIs(t, repo.ChangeStatus(ctx, deleted, 0), domain.ErrUserNotFound)
+
+	u.Status = domain.UserStatusDeleted
+	restored, err := u.TransitionStatus(domain.UserStatusActive, "", uuid.Nil, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.ChangeStatus(ctx, restored, 0))
+
+	got, err := repo.GetByID(ctx, u.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.UserStatusActive, got.Status)
+	require.True(t, got.DeletedAt.IsZero())
+
+	require.ErrorIs(t, repo.ChangeStatus(ctx, restored, 0), domain.ErrUserNotFound, "user is not deleted anymore")
+}
+
+func testStatusHistory(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b")
+	u := users[0]
+
+	actor := uuid.New()
+	var transitions []domain.UserStatusTransition
+	for i, to := range []domain.UserStatus{domain.UserStatusActive, domain.UserStatusBanned, domain.UserStatusActive} {
+		at := time.Now().UTC().Truncate(time.Second).Add(time.Duration(i) * time.Second)
+		transition, err := u.TransitionStatus(to, fmt.Sprintf("reason %d", i), actor, at)
+		require.NoError(t, err)
+		require.NoError(t, repo.ChangeStatus(ctx, transition, 0))
+		u.Status = to
+		transitions = append(transitions, transition)
+	}
+	system, err := users[1].TransitionStatus(domain.UserStatusActive, "", uuid.Nil, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.ChangeStatus(ctx, system, 0))
+
+	pagination := &paginate.Pagination{Page: 1, PerPage: 2}
+	history, err := repo.StatusHistory(ctx, u.ID, pagination)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), pagination.TotalItems)
+	require.Len(t, history, 2)
+	requireEqualTransition(t, transitions[2], history[0])
+	requireEqualTransition(t, transitions[1], history[1])
+
+	pagination = &paginate.Pagination{Page: 1, PerPage: 10, Sort: []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderAscending}}}
+	history, err = repo.StatusHistory(ctx, u.ID, pagination)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	requireEqualTransition(t, transitions[0], history[0])
+
+	history, err = repo.StatusHistory(ctx, users[1].ID, &paginate.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	requireEqualTransition(t, system, history[0])
+
+	// purge removes history
+	deleted, err := u.TransitionStatus(domain.UserStatusDeleted, "", uuid.Nil, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.ChangeStatus(ctx, deleted, 0))
+	require.NoError(t, repo.Purge(ctx, u.ID))
+	pagination = &paginate.Pagination{Page: 1, PerPage: 10}
+	history, err = repo.StatusHistory(ctx, u.ID, pagination)
+	require.NoError(t, err)
+	require.Empty(t, history)
+	require.Zero(t, pagination.TotalItems)
+}
+
+func requireEqualTransition(t *testing.T, expected, actual domain.UserStatusTransition) {
+	t.Helper()
+	require.WithinDuration(t, expected.At, actual.At, time.Second)
+	expected.At, actual.At = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
+
+func testAnonymize(t *testing.T, repo repository.User) {
+	ctx := context.Background()
+	users := createUsers(t, repo, "a", "b")
+	u := users[0]
+
+	transition, err := u.TransitionStatus(domain.UserStatusDeleted, "asked by a@example.com", uuid.New(), time.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.ChangeStatus(ctx, transition, 0))
+
+	anonymized := u.Anonymized()
+	anonymized.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, repo.Anonymize(ctx, anonymized), "deleted users are anonymizable")
+
+	pagination := &paginate.Pagination{
+		Page:    1,
+		PerPage: 10,
+		Filters: []paginate.Filter{
+			{Key: "id", Value: u.ID.String(), Condition: paginate.FilterEqual},
+			{Key: user.FilterWithDeleted, Value: "true", Condition: paginate.FilterEqual},
+		},
+	}
+	list, err := repo.List(ctx, pagination)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	got := list[0]
+	require.Empty(t, got.Name)
+	require.Empty(t, got.PhoneNumber)
+	require.Empty(t, got.Password)
+	require.Equal(t, anonymized.Email, got.Email)
+	require.Equal(t, domain.UserStatusDeleted, got.Status, "status is kept")
+	require.Equal(t, u.Version+2, got.Version)
+
+	history, err := repo.StatusHistory(ctx, u.ID, &paginate.Pagination{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Empty(t, history[0].Reason)
+
+	taken := users[1]
+	taken.Email = anonymized.Email
+	taken.UpdatedAt = time.Now()
+	require.ErrorIs(t, repo.Anonymize(ctx, taken), domain.ErrUserAlreadyExists)
+
+	require.ErrorIs(t, repo.Anonymize(ctx, NewUser("c").Anonymized()), domain.ErrUserNotFound)
+}
+
+func testTenantIsolation(t *testing.T, repo repository.User) {
+	acme := tenant.ContextWithID(context.Background(), uuid.New())
+	other := tenant.ContextWithID(context.Background(), uuid.New())
+
+	u := NewUser("a")
+	require.NoError(t, repo.Create(acme, u))
+	u.TenantID = tenant.FromContext(acme)
+	got, err := repo.GetByID(acme, u.ID)
+	require.NoError(t, err)
+	RequireEqualUser(t, u, got)
+
+	_, err = repo.GetByID(other, u.ID)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repo.GetByEmail(other, u.Email)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+	pagination := &paginate.Pagination{Page: 1, PerPage: 10}
+	list, err := repo.List(other, pagination)
+	require.NoError(t, err)
+	require.Empty(t, list)
+	require.Zero(t, pagination.TotalItems)
+
+	name := "changed"
+	require.ErrorIs(t, repo.Patch(other, u.ID, domain.UserPatch{Name: &name, UpdatedAt: time.Now()}), domain.ErrUserNotFound)
+	require.ErrorIs(t, repo.Update(other, u), domain.ErrUserNotFound)
+	transition, err := u.TransitionStatus(domain.UserStatusActive, "", uuid.Nil, time.Now())
+	require.NoError(t, err)
+	require.ErrorIs(t, repo.ChangeStatus(other, transition, 0), domain.ErrUserNotFound)
+	// deleted users are restored only in their tenant
+	require.NoError(t, softDelete(acme, repo, u.ID))
+	deleted, err := repo.GetDeletedByID(acme, u.ID)
+	require.NoError(t, err)
+	_, err = repo.GetDeletedByID(other, u.ID)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+	transition, err = deleted.TransitionStatus(domain.UserStatusActive, "", uuid.Nil, time.Now())
+	require.NoError(t, err)
+	require.ErrorIs(t, repo.ChangeStatus(other, transition, deleted.Version), domain.ErrUserNotFound)
+	require.NoError(t, repo.ChangeStatus(acme, transition, deleted.Version))
+	u.Status, u.UpdatedAt, u.Version = domain.UserStatusActive, transition.At, deleted.Version+1
+	got, err = repo.GetByID(acme, u.ID)
+	require.NoError(t, err)
+	RequireEqualUser(t, u, got)
+
+	// email and phone number are unique per tenant
+	same := NewUser("b")
+	same.Email, same.PhoneNumber = u.Email, u.PhoneNumber
+	require.NoError(t, repo.Create(other, same))
+	require.ErrorIs(t, repo.Create(acme, same), domain.ErrUserAlreadyExists)
+
+	all := tenant.ContextWithAllTenants(context.Background())
+	pagination = &paginate.Pagination{Page: 1, PerPage: 10,
+		Filters: []paginate.Filter{{Key: "email", Value: u.Email, Condition: paginate.FilterEqual}},
+	}
+	list, err = repo.List(all, pagination)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+}
@@ -0,0 +1,248 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// UserAttributeFactory returns new and empty repositories of users and their attributes,
+// sharing same storage, for every call.
+type UserAttributeFactory func(t *testing.T) (repository.User, repository.UserAttribute)
+
+// RunUserAttributeSuite runs the same scenarios against given repository.UserAttribute implementation
+// and custom attributes of users of its repository.User. Each scenario gets fresh repositories from newRepos.
+func RunUserAttributeSuite(t *testing.T, newRepos UserAttributeFactory) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, users repository.User, attributes repository.UserAttribute)
+	}{
+		{"definitions", testUserAttributeDefinitions},
+		{"values", testUserAttributeValues},
+		{"filter and sort", testUserAttributeFilterAndSort},
+		{"taken", testUserAttributeTaken},
+		{"delete removes values", testUserAttributeDelete},
+		{"tenant isolation", testUserAttributeTenantIsolation},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			users, attributes := newRepos(t)
+			tc.run(t, users, attributes)
+		})
+	}
+}
+
+// NewUserAttribute returns definition of an optional attribute created now.
+func NewUserAttribute(name string, typ domain.UserAttributeType, values ...string) domain.UserAttribute {
+	return domain.UserAttribute{
+		Name:      name,
+		Type:      typ,
+		Values:    values,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func defineUserAttributes(t *testing.T, ctx context.Context, repo repository.UserAttribute, attributes ...domain.UserAttribute) {
+	t.Helper()
+	for _, attribute := range attributes {
+		require.NoError(t, repo.Create(ctx, attribute))
+	}
+}
+
+func testUserAttributeDefinitions(t *testing.T, _ repository.User, repo repository.UserAttribute) {
+	ctx := context.Background()
+
+	plan := NewUserAttribute("plan", domain.UserAttributeEnum, "free", "gold")
+	plan.Required = true
+	code := NewUserAttribute("code", domain.UserAttributeString)
+	code.Unique = true
+	defineUserAttributes(t, ctx, repo, plan, code)
+	require.ErrorIs(t, repo.Create(ctx, NewUserAttribute("plan", domain.UserAttributeString)), domain.ErrUserAttributeAlreadyExists)
+
+	list, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	for i, expected := range []domain.UserAttribute{code, plan} {
+		require.WithinDuration(t, expected.CreatedAt, list[i].CreatedAt, time.Second)
+		list[i].CreatedAt = expected.CreatedAt
+		expected.TenantID = tenant.Default
+		if expected.Values == nil {
+			expected.Values = list[i].Values
+			require.Empty(t, list[i].Values)
+		}
+		require.Equal(t, expected, list[i])
+	}
+
+	require.NoError(t, repo.Delete(ctx, "code"))
+	require.ErrorIs(t, repo.Delete(ctx, "code"), domain.ErrUserAttributeNotFound)
+	list, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+}
+
+func testUserAttributeValues(t *testing.T, users repository.User, attributes repository.UserAttribute) {
+	ctx := context.Background()
+	defineUserAttributes(t, ctx, attributes,
+		NewUserAttribute("age", domain.UserAttributeNumber),
+		NewUserAttribute("vip", domain.UserAttributeBool),
+		NewUserAttribute("joined", domain.UserAttributeDate))
+
+	user := NewUser("a")
+	user.Attributes = map[string]any{"age": 30.5, "vip": true, "joined": "2024-02-29"}
+	require.NoError(t, users.Create(ctx, user))
+	got, err := users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.Attributes, got.Attributes)
+
+	user.Attributes = map[string]any{"age": 31.0}
+	require.NoError(t, users.Update(ctx, user))
+	got, err = users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.Attributes, got.Attributes)
+
+	require.NoError(t, users.Patch(ctx, user.ID, domain.UserPatch{Attributes: map[string]any{"vip": false}, UpdatedAt: time.Now()}))
+	got, err = users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"vip": false}, got.Attributes)
+
+	require.NoError(t, users.Patch(ctx, user.ID, domain.UserPatch{Attributes: map[string]any{}, UpdatedAt: time.Now()}))
+	got, err = users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.Attributes)
+
+	anonymous := NewUser("b")
+	anonymous.Attributes = map[string]any{"age": 20.0}
+	require.NoError(t, users.Create(ctx, anonymous))
+	anonymous.Name = "anonymous"
+	require.NoError(t, users.Anonymize(ctx, anonymous))
+	got, err = users.GetByID(ctx, anonymous.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.Attributes)
+}
+
+func testUserAttributeFilterAndSort(t *testing.T, users repository.User, attributes repository.UserAttribute) {
+	ctx := context.Background()
+	defineUserAttributes(t, ctx, attributes,
+		NewUserAttribute("age", domain.UserAttributeNumber),
+		NewUserAttribute("vip", domain.UserAttributeBool),
+		NewUserAttribute("plan", domain.UserAttributeEnum, "free", "gold"))
+
+	for name, values := range map[string]map[string]any{
+		"a": {"age": 9.0, "vip": true, "plan": "gold"},
+		"b": {"age": 100.0, "vip": false, "plan": "gold"},
+		"c": {"age": 30.0, "vip": true, "plan": "free"},
+	} {
+		user := NewUser(name)
+		user.Attributes = values
+		require.NoError(t, users.Create(ctx, user))
+	}
+
+	for _, tc := range []struct {
+		name     string
+		filters  []paginate.Filter
+		expected []string
+	}{
+		{"number", []paginate.Filter{{Key: "attributes.age", Value: "30", Condition: paginate.FilterEqual}}, []string{"c"}},
+		{"bool", []paginate.Filter{{Key: "attributes.vip", Value: "true", Condition: paginate.FilterEqual}}, []string{"a", "c"}},
+		{"enum", []paginate.Filter{{Key: "attributes.plan", Value: "free", Condition: paginate.FilterNotEqual}}, []string{"a", "b"}},
+		{"undefined attribute is ignored", []paginate.Filter{{Key: "attributes.unknown", Value: "x", Condition: paginate.FilterEqual}}, []string{"a", "b", "c"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pagination := &paginate.Pagination{Page: 1, PerPage: 10, Filters: tc.filters,
+				Sort: []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}}}
+			list, err := users.List(ctx, pagination)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, names(list))
+		})
+	}
+
+	// numbers are sorted numerically, not by their text
+	list, err := users.List(ctx, &paginate.Pagination{Page: 1, PerPage: 10,
+		Sort: []paginate.Sort{{Field: "attributes.age", Arrange: paginate.SortOrderDescending}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c", "a"}, names(list))
+}
+
+func testUserAttributeTaken(t *testing.T, users repository.User, attributes repository.UserAttribute) {
+	ctx := context.Background()
+	defineUserAttributes(t, ctx, attributes,
+		NewUserAttribute("code", domain.UserAttributeString),
+		NewUserAttribute("seat", domain.UserAttributeNumber))
+
+	user := NewUser("a")
+	user.Attributes = map[string]any{"code": "x1", "seat": 7.0}
+	require.NoError(t, users.Create(ctx, user))
+
+	taken, err := users.AttributeTaken(ctx, "code", "x1", uuid.New())
+	require.NoError(t, err)
+	require.True(t, taken)
+	taken, err = users.AttributeTaken(ctx, "seat", 7.0, uuid.New())
+	require.NoError(t, err)
+	require.True(t, taken)
+	taken, err = users.AttributeTaken(ctx, "code", "x1", user.ID)
+	require.NoError(t, err)
+	require.False(t, taken, "own value is not taken")
+	taken, err = users.AttributeTaken(ctx, "code", "x2", uuid.New())
+	require.NoError(t, err)
+	require.False(t, taken)
+
+	require.NoError(t, softDelete(ctx, users, user.ID))
+	taken, err = users.AttributeTaken(ctx, "code", "x1", uuid.New())
+	require.NoError(t, err)
+	require.True(t, taken, "values of deleted users are taken")
+}
+
+func testUserAttributeDelete(t *testing.T, users repository.User, attributes repository.UserAttribute) {
+	ctx := context.Background()
+	defineUserAttributes(t, ctx, attributes,
+		NewUserAttribute("age", domain.UserAttributeNumber),
+		NewUserAttribute("vip", domain.UserAttributeBool))
+
+	user := NewUser("a")
+	user.Attributes = map[string]any{"age": 9.0, "vip": true}
+	require.NoError(t, users.Create(ctx, user))
+
+	require.NoError(t, attributes.Delete(ctx, "vip"))
+	got, err := users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"age": 9.0}, got.Attributes)
+
+	require.NoError(t, attributes.Delete(ctx, "age"))
+	got, err = users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.Attributes)
+}
+
+func testUserAttributeTenantIsolation(t *testing.T, users repository.User, attributes repository.UserAttribute) {
+	acme := tenant.ContextWithID(context.Background(), uuid.New())
+	other := tenant.ContextWithID(context.Background(), uuid.New())
+
+	defineUserAttributes(t, acme, attributes, NewUserAttribute("age", domain.UserAttributeNumber))
+	require.NoError(t, attributes.Create(other, NewUserAttribute("age", domain.UserAttributeString)), "names are unique per tenant")
+
+	list, err := attributes.List(acme)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, domain.UserAttributeNumber, list[0].Type)
+	require.Equal(t, tenant.FromContext(acme), list[0].TenantID)
+
+	user := NewUser("a")
+	user.Attributes = map[string]any{"age": 9.0}
+	require.NoError(t, users.Create(acme, user))
+	taken, err := users.AttributeTaken(other, "age", 9.0, uuid.New())
+	require.NoError(t, err)
+	require.False(t, taken)
+
+	require.NoError(t, attributes.Delete(other, "age"))
+	require.ErrorIs(t, attributes.Delete(other, "age"), domain.ErrUserAttributeNotFound)
+	got, err := users.GetByID(acme, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"age": 9.0}, got.Attributes, "values of other tenant are kept")
+}
@@ -0,0 +1,72 @@
+package user
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+type userAttributeInMemoryRepo struct {
+	mu    sync.RWMutex
+	store []domain.UserAttribute
+	users *userInMemoryRepo
+}
+
+// NewUserAttributeInMemoryRepo returns attribute repository of given users, which removes values of deleted attributes
+// from them and lets them be filtered by defined attributes.
+func NewUserAttributeInMemoryRepo(users *userInMemoryRepo) *userAttributeInMemoryRepo {
+	r := &userAttributeInMemoryRepo{users: users}
+	users.attributes = r
+	return r
+}
+
+func (r *userAttributeInMemoryRepo) Create(ctx context.Context, attribute domain.UserAttribute) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attribute.TenantID = tenant.FromContext(ctx)
+	if slices.ContainsFunc(r.store, func(a domain.UserAttribute) bool {
+		return a.TenantID == attribute.TenantID && a.Name == attribute.Name
+	}) {
+		return domain.ErrUserAttributeAlreadyExists
+	}
+	attribute.Values = slices.Clone(attribute.Values)
+	r.store = append(r.store, attribute)
+	return nil
+}
+
+func (r *userAttributeInMemoryRepo) List(ctx context.Context) ([]domain.UserAttribute, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scope := tenant.ScopeOf(ctx)
+	attributes := make([]domain.UserAttribute, 0, len(r.store))
+	for _, a := range r.store {
+		if scope.Includes(a.TenantID) {
+			attributes = append(attributes, a)
+		}
+	}
+	slices.SortFunc(attributes, func(a, b domain.UserAttribute) int { return cmp.Compare(a.Name, b.Name) })
+	return attributes, nil
+}
+
+func (r *userAttributeInMemoryRepo) Delete(ctx context.Context, name string) error {
+	r.mu.Lock()
+	scope := tenant.ScopeOf(ctx)
+	n := len(r.store)
+	r.store = slices.DeleteFunc(r.store, func(a domain.UserAttribute) bool {
+		return a.Name == name && scope.Includes(a.TenantID)
+	})
+	deleted := len(r.store) < n
+	r.mu.Unlock()
+
+	if !deleted {
+		return domain.ErrUserAttributeNotFound
+	}
+	r.users.removeAttribute(ctx, name)
+	return nil
+}
@@ -0,0 +1,109 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const userAttributeCollectionName = "user_attribute"
+
+type userAttributeMongoRepo struct {
+	db    *mongo.Collection
+	users *mongo.Collection
+}
+
+func NewUserAttributeMongoRepository(db *mongo.Database) *userAttributeMongoRepo {
+	return &userAttributeMongoRepo{
+		db:    db.Collection(userAttributeCollectionName),
+		users: db.Collection(userCollectionName),
+	}
+}
+
+// userAttributeDocument keeps field names same as sql columns.
+type userAttributeDocument struct {
+	TenantID  uuid.UUID `bson:"tenant_id"`
+	Name      string    `bson:"name"`
+	Type      string    `bson:"type"`
+	Required  bool      `bson:"is_required"`
+	Unique    bool      `bson:"is_unique"`
+	Values    []string  `bson:"enum_values"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// Create inserts attribute only if its name is not taken in tenant, so it is atomic without a unique index.
+func (r *userAttributeMongoRepo) Create(ctx context.Context, attribute domain.UserAttribute) error {
+	tenantID := tenant.FromContext(ctx)
+	res, err := r.db.UpdateOne(ctx,
+		bson.M{"tenant_id": tenantID, "name": attribute.Name},
+		bson.M{"$setOnInsert": userAttributeDocument{
+			TenantID:  tenantID,
+			Name:      attribute.Name,
+			Type:      string(attribute.Type),
+			Required:  attribute.Required,
+			Unique:    attribute.Unique,
+			Values:    attribute.Values,
+			CreatedAt: attribute.CreatedAt,
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	if res.UpsertedCount == 0 {
+		return domain.ErrUserAttributeAlreadyExists
+	}
+	return nil
+}
+
+func (r *userAttributeMongoRepo) List(ctx context.Context) ([]domain.UserAttribute, error) {
+	return listUserAttributeDocuments(ctx, r.db)
+}
+
+// listUserAttributeDocuments returns attributes of tenant scope of ctx ordered by name,
+// shared with user repository which looks them up to filter by them.
+func listUserAttributeDocuments(ctx context.Context, col *mongo.Collection) ([]domain.UserAttribute, error) {
+	cursor, err := col.Find(ctx, scoped(ctx, bson.M{}), options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []userAttributeDocument
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	attributes := make([]domain.UserAttribute, 0, len(docs))
+	for _, doc := range docs {
+		attributes = append(attributes, domain.UserAttribute{
+			TenantID:  doc.TenantID,
+			Name:      doc.Name,
+			Type:      domain.UserAttributeType(doc.Type),
+			Required:  doc.Required,
+			Unique:    doc.Unique,
+			Values:    doc.Values,
+			CreatedAt: doc.CreatedAt,
+		})
+	}
+	return attributes, nil
+}
+
+// Delete removes attribute and then its values, they are not atomic same as user ChangeStatus.
+func (r *userAttributeMongoRepo) Delete(ctx context.Context, name string) error {
+	res, err := r.db.DeleteOne(ctx, scoped(ctx, bson.M{"name": name}))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrUserAttributeNotFound
+	}
+	field := domain.UserAttributePrefix + name
+	_, err = r.users.UpdateMany(ctx, scoped(ctx, bson.M{field: bson.M{"$exists": true}}), bson.M{"$unset": bson.M{field: ""}})
+	return err
+}
@@ -0,0 +1,92 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/amirzayi/clean_architect/infra/migrations/model"
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/sqlutil"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const userAttributeTableName = "user_attribute"
+
+type userAttributeSQLRepo struct {
+	db        *sqlx.DB
+	table     string
+	userTable string
+}
+
+func NewUserAttributeSQLRepository(db *sqlx.DB) *userAttributeSQLRepo {
+	return &userAttributeSQLRepo{
+		db:        db,
+		table:     sqlutil.QuoteIdentifier(db.DriverName(), userAttributeTableName),
+		userTable: sqlutil.QuoteIdentifier(db.DriverName(), userTableName),
+	}
+}
+
+func (r *userAttributeSQLRepo) Create(ctx context.Context, attribute domain.UserAttribute) error {
+	attribute.TenantID = tenant.FromContext(ctx)
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(tenant_id,name,type,is_required,is_unique,enum_values,created_at)
+	VALUES(:tenant_id,:name,:type,:is_required,:is_unique,:enum_values,:created_at)`, r.table),
+		model.ConvertUserAttributeFromDomain(attribute))
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrUserAttributeAlreadyExists
+	}
+	return err
+}
+
+func (r *userAttributeSQLRepo) List(ctx context.Context) ([]domain.UserAttribute, error) {
+	return listUserAttributes(ctx, r.db, r.table)
+}
+
+// listUserAttributes returns attributes of tenant scope of ctx ordered by name, shared with user repository
+// which looks them up to filter and sort by them.
+func listUserAttributes(ctx context.Context, db *sqlx.DB, table string) ([]domain.UserAttribute, error) {
+	query := "SELECT * FROM " + table
+	var args []any
+	if scope := tenant.ScopeOf(ctx); !scope.All {
+		query += " WHERE " + sqlutil.TenantColumn + "=?"
+		args = append(args, scope.ID)
+	}
+
+	var attributes []model.UserAttribute
+	if err := db.SelectContext(ctx, &attributes, db.Rebind(query+" ORDER BY name"), args...); err != nil {
+		return nil, err
+	}
+	return model.ConvertUserAttributesToDomains(attributes), nil
+}
+
+func (r *userAttributeSQLRepo) Delete(ctx context.Context, name string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	cond, args := tenantCondition(ctx)
+	res, err := tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf("DELETE FROM %s WHERE name=?%s", r.table, cond)),
+		append([]any{name}, args...)...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrUserAttributeNotFound
+	}
+
+	// name is validated by domain.UserAttribute.Validate on creation, so it is safe in json path
+	_, err = tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf("UPDATE %s SET attributes=%s WHERE attributes IS NOT NULL%s",
+		r.userTable, sqlutil.JSONRemove(r.db.DriverName(), "attributes", name), cond)), args...)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -2,42 +2,88 @@ package user
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/amirzayi/clean_architect/internal/domain"
 	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/search"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 )
 
 type userInMemoryRepo struct {
-	mu    sync.RWMutex
-	store map[uuid.UUID]domain.User
+	mu            sync.RWMutex
+	store         map[uuid.UUID]domain.User
+	statusHistory map[uuid.UUID][]domain.UserStatusTransition
+	// attributes is set by NewUserAttributeInMemoryRepo, filters of undefined attributes are ignored.
+	attributes *userAttributeInMemoryRepo
+	// index is the full-text index of names and emails of stored users, see indexUser.
+	index *search.Index
 }
 
 func NewUserInMemoryRepo() *userInMemoryRepo {
 	return &userInMemoryRepo{
-		store: make(map[uuid.UUID]domain.User),
+		store:         make(map[uuid.UUID]domain.User),
+		statusHistory: make(map[uuid.UUID][]domain.UserStatusTransition),
+		index:         search.NewIndex(),
 	}
 }
 
+// userInMemoryFields are queryable fields of users, same keys as sql queryable fields.
+var userInMemoryFields = paginate.Accessors[domain.User]{
+	"id":         func(u domain.User) any { return u.ID },
+	"name":       func(u domain.User) any { return u.Name },
+	"phone":      func(u domain.User) any { return u.PhoneNumber },
+	"email":      func(u domain.User) any { return u.Email },
+	"status":     func(u domain.User) any { return u.Status },
+	"role":       func(u domain.User) any { return u.Role },
+	"created_at": func(u domain.User) any { return u.CreatedAt },
+	"updated_at": func(u domain.User) any { return u.UpdatedAt },
+	"deleted_at": func(u domain.User) any { return u.DeletedAt },
+}
+
 func (r *userInMemoryRepo) Create(ctx context.Context, user domain.User) error {
-	user, err := r.GetByID(ctx, user.ID)
-	if err == nil {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.store[user.ID]; ok {
+		return domain.ErrUserAlreadyExists
+	}
+	user.TenantID = tenant.FromContext(ctx)
+	if r.hasConflict(user) {
 		return domain.ErrUserAlreadyExists
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	user.Attributes = cloneAttributes(user.Attributes)
 	r.store[user.ID] = user
+	r.indexUser(user)
 	return nil
 }
 
-func (r *userInMemoryRepo) GetByID(_ context.Context, id uuid.UUID) (domain.User, error) {
+// indexUser indexes searched fields of user, same fields as sql and mongo indexes.
+func (r *userInMemoryRepo) indexUser(user domain.User) {
+	r.index.Add(user.ID.String(), user.Name, user.Email)
+}
+
+func (r *userInMemoryRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	user, ok := r.store[id]
-	if !ok {
+	user, ok := r.get(ctx, id)
+	if !ok || isDeleted(user) {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *userInMemoryRepo) GetDeletedByID(ctx context.Context, id uuid.UUID) (domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.get(ctx, id)
+	if !ok || !isDeleted(user) {
 		return domain.User{}, domain.ErrUserNotFound
 	}
 	return user, nil
@@ -47,8 +93,9 @@ func (r *userInMemoryRepo) GetByEmail(ctx context.Context, email string) (domain
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	scope := tenant.ScopeOf(ctx)
 	for _, u := range r.store {
-		if u.Email == email {
+		if u.Email == email && !isDeleted(u) && scope.Includes(u.TenantID) {
 			return u, nil
 		}
 	}
@@ -56,40 +103,286 @@ func (r *userInMemoryRepo) GetByEmail(ctx context.Context, email string) (domain
 	return domain.User{}, domain.ErrUserNotFound
 }
 
-func (r *userInMemoryRepo) List(_ context.Context, pagination *paginate.Pagination) ([]domain.User, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *userInMemoryRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.User, error) {
+	withDeleted := withDeleted(pagination)
+	scope := tenant.ScopeOf(ctx)
+	fields, accessors := userInMemoryFields.Fields(userTypes), userInMemoryFields
+	if usesAttributes(pagination) {
+		fields, accessors = r.attributeFields(ctx)
+	}
+	fields.Select = userSelectFields
+	terms := search.Terms(pagination.Search)
+	var ranks map[string]float64
+	if len(terms) > 0 {
+		ranks = r.index.Search(terms)
+		accessors = maps.Clone(accessors)
+		accessors[paginate.SortRelevance] = func(u domain.User) any { return ranks[u.ID.String()] }
+		fields = withRelevance(fields, paginate.SortRelevance)
+		searchSort(pagination)
+	}
 
+	r.mu.RLock()
 	users := make([]domain.User, 0, len(r.store))
 	for _, user := range r.store {
-		users = append(users, user)
+		if _, ok := ranks[user.ID.String()]; len(terms) > 0 && !ok {
+			continue
+		}
+		if (withDeleted || !isDeleted(user)) && scope.Includes(user.TenantID) {
+			users = append(users, user)
+		}
+	}
+	r.mu.RUnlock()
+
+	users, err := paginate.List(users, pagination, fields, accessors)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) > 0 {
+		highlight(pagination, users, terms)
 	}
 	return users, nil
 }
 
-func (r *userInMemoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	user, err := r.GetByID(ctx, id)
-	if err != nil {
-		return err
+// attributeFields returns user fields and their accessors with defined custom attributes, filters of undefined
+// attributes are ignored same as other repositories.
+func (r *userInMemoryRepo) attributeFields(ctx context.Context) (paginate.Fields, paginate.Accessors[domain.User]) {
+	var attributes []domain.UserAttribute
+	if r.attributes != nil {
+		attributes, _ = r.attributes.List(ctx)
 	}
+	accessors := maps.Clone(userInMemoryFields)
+	types := maps.Clone(userTypes)
+	for _, attribute := range attributes {
+		accessors[domain.UserAttributePrefix+attribute.Name] = func(u domain.User) any { return u.Attributes[attribute.Name] }
+		types[domain.UserAttributePrefix+attribute.Name] = attributeType(attribute)
+	}
+	return accessors.Fields(types), accessors
+}
+
+func (r *userInMemoryRepo) Purge(ctx context.Context, id uuid.UUID) error {
 	r.mu.Lock()
-	user.Status = domain.UserStatusDeleted
-	r.store[id] = user
-	r.mu.Unlock()
+	defer r.mu.Unlock()
+
+	user, ok := r.get(ctx, id)
+	if !ok || !isDeleted(user) {
+		return domain.ErrUserNotFound
+	}
+	delete(r.store, id)
+	delete(r.statusHistory, id)
+	r.index.Remove(id.String())
 	return nil
 }
 
+func (r *userInMemoryRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope := tenant.ScopeOf(ctx)
+	var count int64
+	for id, user := range r.store {
+		if isDeleted(user) && user.DeletedAt.Before(before) && scope.Includes(user.TenantID) {
+			delete(r.store, id)
+			delete(r.statusHistory, id)
+			r.index.Remove(id.String())
+			count++
+		}
+	}
+	return count, nil
+}
+
+func isDeleted(user domain.User) bool {
+	return !user.DeletedAt.IsZero()
+}
+
+// get returns user of given id if it is in tenant scope of ctx, caller must hold the lock.
+func (r *userInMemoryRepo) get(ctx context.Context, id uuid.UUID) (domain.User, bool) {
+	user, ok := r.store[id]
+	if !ok || !tenant.ScopeOf(ctx).Includes(user.TenantID) {
+		return domain.User{}, false
+	}
+	return user, true
+}
+
 func (r *userInMemoryRepo) Update(ctx context.Context, user domain.User) error {
-	u, err := r.GetByID(ctx, user.ID)
-	if err != nil {
-		return err
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.get(ctx, user.ID)
+	if !ok || isDeleted(u) {
+		return domain.ErrUserNotFound
+	}
+	if user.Version != 0 && u.Version != user.Version {
+		return domain.ErrConcurrentModification
+	}
+	user.TenantID = u.TenantID
+	if r.hasConflict(user) {
+		return domain.ErrUserAlreadyExists
+	}
+	u.Name = user.Name
+	u.PhoneNumber = user.PhoneNumber
+	u.Email = user.Email
+	u.Password = user.Password
+	u.Attributes = cloneAttributes(user.Attributes)
+	u.UpdatedAt = user.UpdatedAt
+	u.Version++
+	r.store[user.ID] = u
+	r.indexUser(u)
+	return nil
+}
+
+func (r *userInMemoryRepo) Patch(ctx context.Context, id uuid.UUID, patch domain.UserPatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.get(ctx, id)
+	if !ok || isDeleted(u) {
+		return domain.ErrUserNotFound
+	}
+	if patch.Version != 0 && u.Version != patch.Version {
+		return domain.ErrConcurrentModification
+	}
+	u = patch.Apply(u)
+	u.Attributes = cloneAttributes(u.Attributes)
+	if r.hasConflict(u) {
+		return domain.ErrUserAlreadyExists
+	}
+	u.Version++
+	r.store[id] = u
+	r.indexUser(u)
+	return nil
+}
+
+func (r *userInMemoryRepo) ChangeStatus(ctx context.Context, transition domain.UserStatusTransition, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.get(ctx, transition.UserID)
+	if !ok || isDeleted(u) != (transition.From == domain.UserStatusDeleted) {
+		return domain.ErrUserNotFound
 	}
+	if u.Status != transition.From || (version != 0 && u.Version != version) {
+		return domain.ErrConcurrentModification
+	}
+
+	switch {
+	case transition.To == domain.UserStatusDeleted:
+		u.DeletedAt = transition.At
+	case transition.From == domain.UserStatusDeleted:
+		u.DeletedAt = time.Time{}
+	}
+	u.Status = transition.To
+	u.UpdatedAt = transition.At
+	u.Version++
+	r.store[u.ID] = u
+	r.statusHistory[u.ID] = append(r.statusHistory[u.ID], transition)
+	return nil
+}
+
+func (r *userInMemoryRepo) StatusHistory(ctx context.Context, userID uuid.UUID, pagination *paginate.Pagination) ([]domain.UserStatusTransition, error) {
+	sortStatusHistory(pagination)
+
+	r.mu.RLock()
+	var history []domain.UserStatusTransition
+	if _, ok := r.get(ctx, userID); ok {
+		history = slices.Clone(r.statusHistory[userID])
+	}
+	r.mu.RUnlock()
+
+	// history is kept oldest first, so transitions of the same time keep their order when sorted newest first
+	if pagination.Sort[0].Arrange == paginate.SortOrderDescending {
+		slices.Reverse(history)
+	}
+	return paginate.List(history, pagination, statusHistoryFields, statusHistoryInMemoryFields)
+}
+
+// statusHistoryInMemoryFields are accessors of statusHistoryFields.
+var statusHistoryInMemoryFields = paginate.Accessors[domain.UserStatusTransition]{
+	"id":          func(t domain.UserStatusTransition) any { return t.ID },
+	"from_status": func(t domain.UserStatusTransition) any { return t.From },
+	"to_status":   func(t domain.UserStatusTransition) any { return t.To },
+	"actor_id":    func(t domain.UserStatusTransition) any { return t.ActorID },
+	"created_at":  func(t domain.UserStatusTransition) any { return t.At },
+}
+
+func (r *userInMemoryRepo) Anonymize(ctx context.Context, user domain.User) error {
 	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.get(ctx, user.ID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.TenantID = u.TenantID
+	if r.hasConflict(user) {
+		return domain.ErrUserAlreadyExists
+	}
 	u.Name = user.Name
 	u.PhoneNumber = user.PhoneNumber
 	u.Email = user.Email
 	u.Password = user.Password
+	u.Attributes = nil
+	u.UpdatedAt = user.UpdatedAt
+	u.Version++
 	r.store[user.ID] = u
-	r.mu.Unlock()
+	r.indexUser(u)
+
+	for i := range r.statusHistory[user.ID] {
+		r.statusHistory[user.ID][i].Reason = ""
+	}
 	return nil
 }
+
+// hasConflict reports whether another user of same tenant has same email or phone number,
+// same as sql unique indexes. caller must hold the lock.
+func (r *userInMemoryRepo) hasConflict(user domain.User) bool {
+	for _, u := range r.store {
+		if u.ID == user.ID || u.TenantID != user.TenantID {
+			continue
+		}
+		if u.Email == user.Email || (user.PhoneNumber != "" && u.PhoneNumber == user.PhoneNumber) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *userInMemoryRepo) AttributeTaken(ctx context.Context, name string, value any, exceptID uuid.UUID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scope := tenant.ScopeOf(ctx)
+	for _, u := range r.store {
+		if v, ok := u.Attributes[name]; ok && v == value && u.ID != exceptID && scope.Includes(u.TenantID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// removeAttribute removes value of attribute from users of tenant scope of ctx.
+func (r *userInMemoryRepo) removeAttribute(ctx context.Context, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope := tenant.ScopeOf(ctx)
+	for id, u := range r.store {
+		if _, ok := u.Attributes[name]; !ok || !scope.Includes(u.TenantID) {
+			continue
+		}
+		u.Attributes = cloneAttributes(u.Attributes)
+		delete(u.Attributes, name)
+		if len(u.Attributes) == 0 {
+			u.Attributes = nil
+		}
+		r.store[id] = u
+	}
+}
+
+// cloneAttributes copies attributes so stored users are not changed through maps of callers,
+// empty attributes are kept as nil same as other repositories.
+func cloneAttributes(attributes map[string]any) map[string]any {
+	if len(attributes) == 0 {
+		return nil
+	}
+	return maps.Clone(attributes)
+}
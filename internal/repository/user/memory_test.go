@@ -0,0 +1,22 @@
+package user_test
+
+import (
+	"testing"
+
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+	"github.com/amirzayi/clean_architect/internal/repository/user"
+)
+
+func TestUserInMemoryRepo(t *testing.T) {
+	repotest.RunUserSuite(t, func(t *testing.T) repository.User {
+		return user.NewUserInMemoryRepo()
+	})
+}
+
+func TestUserAttributeInMemoryRepo(t *testing.T) {
+	repotest.RunUserAttributeSuite(t, func(t *testing.T) (repository.User, repository.UserAttribute) {
+		users := user.NewUserInMemoryRepo()
+		return users, user.NewUserAttributeInMemoryRepo(users)
+	})
+}
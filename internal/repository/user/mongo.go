@@ -3,33 +3,89 @@ package user
 import (
 	"context"
 	"errors"
+	"maps"
+	"sync/atomic"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/mongoutil"
 	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/search"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 	"github.com/google/uuid"
 )
 
-const userCollectionName = "user"
+const (
+	userCollectionName              = "user"
+	userStatusHistoryCollectionName = "user_status_history"
+)
+
+var notDeleted = bson.M{"$ne": domain.UserStatusDeleted}
 
 type userMongoRepo struct {
-	db *mongo.Collection
+	db            *mongo.Collection
+	statusHistory *mongo.Collection
+	attributes    *mongo.Collection
+	// searchIndexed tells text index of users is created, see ensureSearchIndex.
+	searchIndexed atomic.Bool
 }
 
 func NewUserMongoRepository(db *mongo.Database) *userMongoRepo {
-	return &userMongoRepo{db: db.Collection(userCollectionName)}
+	return &userMongoRepo{
+		db:            db.Collection(userCollectionName),
+		statusHistory: db.Collection(userStatusHistoryCollectionName),
+		attributes:    db.Collection(userAttributeCollectionName),
+	}
+}
+
+// userStatusHistoryDocument keeps field names same as sql columns so status history fields need no mapping.
+type userStatusHistoryDocument struct {
+	ID         uuid.UUID         `bson:"id"`
+	TenantID   uuid.UUID         `bson:"tenant_id"`
+	UserID     uuid.UUID         `bson:"user_id"`
+	FromStatus domain.UserStatus `bson:"from_status"`
+	ToStatus   domain.UserStatus `bson:"to_status"`
+	Reason     string            `bson:"reason"`
+	ActorID    uuid.UUID         `bson:"actor_id"`
+	CreatedAt  time.Time         `bson:"created_at"`
 }
 
 func (r *userMongoRepo) Create(ctx context.Context, user domain.User) error {
-	_, err := r.db.InsertOne(ctx, user)
+	count, err := r.db.CountDocuments(ctx, bson.M{"id": user.ID})
+	if err != nil {
+		return err
+	}
+	exists, err := r.hasConflict(ctx, user.ID, user.Email, user.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	if count > 0 || exists {
+		return domain.ErrUserAlreadyExists
+	}
+
+	user.TenantID = tenant.FromContext(ctx)
+	_, err = r.db.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrUserAlreadyExists
+	}
 	return err
 }
 
 func (r *userMongoRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.User, error) {
 	var user domain.User
-	err := r.db.FindOne(ctx, bson.M{"id": id}).Decode(&user)
+	err := r.db.FindOne(ctx, scoped(ctx, bson.M{"id": id, "status": notDeleted})).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return user, domain.ErrUserNotFound
+	}
+	return user, err
+}
+
+func (r *userMongoRepo) GetDeletedByID(ctx context.Context, id uuid.UUID) (domain.User, error) {
+	var user domain.User
+	err := r.db.FindOne(ctx, scoped(ctx, bson.M{"id": id, "status": domain.UserStatusDeleted})).Decode(&user)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return user, domain.ErrUserNotFound
 	}
@@ -38,7 +94,7 @@ func (r *userMongoRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.User,
 
 func (r *userMongoRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	var user domain.User
-	err := r.db.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.db.FindOne(ctx, scoped(ctx, bson.M{"email": email, "status": notDeleted})).Decode(&user)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return user, domain.ErrUserNotFound
 	}
@@ -46,35 +102,202 @@ func (r *userMongoRepo) GetByEmail(ctx context.Context, email string) (domain.Us
 }
 
 func (r *userMongoRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.User, error) {
-	var users []domain.User
-	cursor, err := r.db.Find(ctx, nil)
+	var predicates []bson.E
+	if !withDeleted(pagination) {
+		predicates = append(predicates, bson.E{Key: "status", Value: notDeleted})
+	}
+
+	fields := paginate.Fields{Filter: userMongoFields, Select: userMongoSelect, Types: userTypes}
+	if usesAttributes(pagination) {
+		attributes, err := listUserAttributeDocuments(ctx, r.attributes)
+		if err != nil {
+			return nil, err
+		}
+		fields = attributeFields(attributes)
+	}
+	terms := search.Terms(pagination.Search)
+	if len(terms) > 0 {
+		if err := r.ensureSearchIndex(ctx); err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, mongoutil.TextSearch(terms))
+		fields = withRelevance(fields, mongoutil.TextScore)
+		searchSort(pagination)
+	}
+
+	users, err := mongoutil.PaginatedList[domain.User](ctx, r.db, tenant.ScopeOf(ctx), pagination, fields, predicates...)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-	if err = cursor.All(ctx, &users); err != nil {
-		return nil, err
+	if len(terms) > 0 {
+		highlight(pagination, users, terms)
 	}
 	return users, nil
 }
 
-func (r *userMongoRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	res, err := r.db.UpdateOne(ctx,
-		bson.M{"id": id},
-		bson.M{"$set": bson.M{"status": domain.UserStatusDeleted}})
+// ensureSearchIndex creates text index of names and emails of users once, mongo keeps it up to date on every write.
+func (r *userMongoRepo) ensureSearchIndex(ctx context.Context) error {
+	if r.searchIndexed.Load() {
+		return nil
+	}
+	if _, err := r.db.Indexes().CreateOne(ctx, mongoutil.TextIndex("user_search", "name", "email")); err != nil {
+		return err
+	}
+	r.searchIndexed.Store(true)
+	return nil
+}
+
+var userMongoFields = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"phone":      "phonenumber",
+	"email":      "email",
+	"status":     "status",
+	"role":       "role",
+	"created_at": "createdat",
+	"updated_at": "updatedat",
+	"deleted_at": "deletedat",
+}
+
+// userMongoSelect are document fields of projected fields of users, see userSelectFields.
+var userMongoSelect = map[string]string{
+	"id":           "id",
+	"name":         "name",
+	"phone_number": "phonenumber",
+	"email":        "email",
+	"status":       "status",
+	"role":         "role",
+	"created_at":   "createdat",
+	"updated_at":   "updatedat",
+	"attributes":   "attributes",
+	"version":      "version",
+}
+
+// attributeFields returns user fields with custom attributes whose filter values are parsed by type of attribute,
+// filters of undefined attributes are ignored same as unknown fields. attributes are kept in attributes field
+// of user documents, so their keys are their document fields.
+func attributeFields(attributes []domain.UserAttribute) paginate.Fields {
+	fields := maps.Clone(userMongoFields)
+	types := maps.Clone(userTypes)
+	for _, attribute := range attributes {
+		fields[domain.UserAttributePrefix+attribute.Name] = domain.UserAttributePrefix + attribute.Name
+		types[domain.UserAttributePrefix+attribute.Name] = attributeType(attribute)
+	}
+	return paginate.Fields{Filter: fields, Select: userMongoSelect, Types: types}
+}
+
+func (r *userMongoRepo) AttributeTaken(ctx context.Context, name string, value any, exceptID uuid.UUID) (bool, error) {
+	count, err := r.db.CountDocuments(ctx, scoped(ctx, bson.M{
+		domain.UserAttributePrefix + name: value,
+		"id":                              bson.M{"$ne": exceptID},
+	}))
+	return count > 0, err
+}
+
+func (r *userMongoRepo) Purge(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.DeleteOne(ctx, scoped(ctx, bson.M{"id": id, "status": domain.UserStatusDeleted}))
 	if err != nil {
 		return err
 	}
-	if res.MatchedCount == 0 {
+	if res.DeletedCount == 0 {
 		return domain.ErrUserNotFound
 	}
-	return nil
+	_, err = r.statusHistory.DeleteMany(ctx, bson.M{"user_id": id})
+	return err
 }
 
-func (r *userMongoRepo) Update(ctx context.Context, user domain.User) error {
-	res, err := r.db.UpdateOne(ctx,
-		bson.M{"id": user.ID},
-		bson.M{"$set": bson.M{"name": user.Name, "phone_number": user.PhoneNumber, "email": user.Email, "password": user.Password}})
+func (r *userMongoRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	filter := scoped(ctx, bson.M{"status": domain.UserStatusDeleted, "deletedat": bson.M{"$lt": before}})
+	ids, err := r.db.Distinct(ctx, "id", filter)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if _, err = r.statusHistory.DeleteMany(ctx, bson.M{"user_id": bson.M{"$in": ids}}); err != nil {
+		return 0, err
+	}
+	res, err := r.db.DeleteMany(ctx, bson.M{"id": bson.M{"$in": ids}, "status": domain.UserStatusDeleted})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// ChangeStatus updates user and then inserts status history,
+// they are not atomic since mongodb transactions need a replica set.
+func (r *userMongoRepo) ChangeStatus(ctx context.Context, transition domain.UserStatusTransition, version int64) error {
+	set := bson.M{"status": transition.To, "updatedat": transition.At}
+	switch {
+	case transition.To == domain.UserStatusDeleted:
+		set["deletedat"] = transition.At
+	case transition.From == domain.UserStatusDeleted:
+		set["deletedat"] = time.Time{}
+	}
+
+	filter := scoped(ctx, bson.M{"id": transition.UserID, "status": transition.From})
+	if version != 0 {
+		filter["version"] = version
+	}
+	err := r.updateOne(ctx, filter, bson.M{"$set": set, "$inc": bson.M{"version": 1}})
+	if errors.Is(err, domain.ErrUserNotFound) {
+		// distinguish changed status or version from missing user
+		status := notDeleted
+		if transition.From == domain.UserStatusDeleted {
+			status = bson.M{"$eq": domain.UserStatusDeleted}
+		}
+		count, cerr := r.db.CountDocuments(ctx, scoped(ctx, bson.M{"id": transition.UserID, "status": status}))
+		if cerr != nil {
+			return cerr
+		}
+		if count > 0 {
+			return domain.ErrConcurrentModification
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.statusHistory.InsertOne(ctx, userStatusHistoryDocument{
+		ID:         transition.ID,
+		TenantID:   tenant.FromContext(ctx),
+		UserID:     transition.UserID,
+		FromStatus: transition.From,
+		ToStatus:   transition.To,
+		Reason:     transition.Reason,
+		ActorID:    transition.ActorID,
+		CreatedAt:  transition.At,
+	})
+	return err
+}
+
+func (r *userMongoRepo) StatusHistory(ctx context.Context, userID uuid.UUID, pagination *paginate.Pagination) ([]domain.UserStatusTransition, error) {
+	sortStatusHistory(pagination)
+	docs, err := mongoutil.PaginatedList[userStatusHistoryDocument](ctx, r.statusHistory, tenant.ScopeOf(ctx), pagination,
+		statusHistoryFields, bson.E{Key: "user_id", Value: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]domain.UserStatusTransition, 0, len(docs))
+	for _, doc := range docs {
+		history = append(history, domain.UserStatusTransition{
+			ID:      doc.ID,
+			UserID:  doc.UserID,
+			From:    doc.FromStatus,
+			To:      doc.ToStatus,
+			Reason:  doc.Reason,
+			ActorID: doc.ActorID,
+			At:      doc.CreatedAt,
+		})
+	}
+	return history, nil
+}
+
+func (r *userMongoRepo) updateOne(ctx context.Context, filter, update bson.M) error {
+	res, err := r.db.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
@@ -83,3 +306,126 @@ func (r *userMongoRepo) Update(ctx context.Context, user domain.User) error {
 	}
 	return nil
 }
+
+func (r *userMongoRepo) Update(ctx context.Context, user domain.User) error {
+	return r.update(ctx, user.ID, user.Version, user.Email, user.PhoneNumber, bson.M{
+		"name":        user.Name,
+		"phonenumber": user.PhoneNumber,
+		"email":       user.Email,
+		"password":    user.Password,
+		"attributes":  user.Attributes,
+		"updatedat":   user.UpdatedAt,
+	})
+}
+
+func (r *userMongoRepo) Patch(ctx context.Context, id uuid.UUID, patch domain.UserPatch) error {
+	set := bson.M{"updatedat": patch.UpdatedAt}
+	var email, phone string
+	if patch.Name != nil {
+		set["name"] = *patch.Name
+	}
+	if patch.PhoneNumber != nil {
+		phone = *patch.PhoneNumber
+		set["phonenumber"] = phone
+	}
+	if patch.Email != nil {
+		email = *patch.Email
+		set["email"] = email
+	}
+	if patch.Password != nil {
+		set["password"] = *patch.Password
+	}
+	if patch.Role != nil {
+		set["role"] = *patch.Role
+	}
+	if patch.Attributes != nil {
+		// empty attributes are saved as null same as sql
+		set["attributes"] = nil
+		if len(patch.Attributes) > 0 {
+			set["attributes"] = patch.Attributes
+		}
+	}
+	return r.update(ctx, id, patch.Version, email, phone, set)
+}
+
+// update sets given fields of user and increments its version,
+// non-empty email and phone are checked against other users same as sql unique indexes.
+func (r *userMongoRepo) update(ctx context.Context, id uuid.UUID, version int64, email, phone string, set bson.M) error {
+	exists, err := r.hasConflict(ctx, id, email, phone)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrUserAlreadyExists
+	}
+
+	filter := scoped(ctx, bson.M{"id": id, "status": notDeleted})
+	if version != 0 {
+		filter["version"] = version
+	}
+	err = r.updateOne(ctx, filter, bson.M{"$set": set, "$inc": bson.M{"version": 1}})
+	if errors.Is(err, domain.ErrUserNotFound) && version != 0 {
+		// distinguish stale version from missing user
+		count, cerr := r.db.CountDocuments(ctx, scoped(ctx, bson.M{"id": id, "status": notDeleted}))
+		if cerr != nil {
+			return cerr
+		}
+		if count > 0 {
+			return domain.ErrConcurrentModification
+		}
+	}
+	return err
+}
+
+// Anonymize updates user and then status history, they are not atomic same as ChangeStatus.
+func (r *userMongoRepo) Anonymize(ctx context.Context, user domain.User) error {
+	exists, err := r.hasConflict(ctx, user.ID, user.Email, user.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrUserAlreadyExists
+	}
+
+	err = r.updateOne(ctx, scoped(ctx, bson.M{"id": user.ID}), bson.M{
+		"$set": bson.M{
+			"name":        user.Name,
+			"phonenumber": user.PhoneNumber,
+			"email":       user.Email,
+			"password":    user.Password,
+			"updatedat":   user.UpdatedAt,
+		},
+		"$unset": bson.M{"attributes": ""},
+		"$inc":   bson.M{"version": 1},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.statusHistory.UpdateMany(ctx, bson.M{"user_id": user.ID}, bson.M{"$set": bson.M{"reason": ""}})
+	return err
+}
+
+// hasConflict reports whether another user of tenant has same non-empty email or phone number, same as sql unique indexes.
+func (r *userMongoRepo) hasConflict(ctx context.Context, id uuid.UUID, email, phone string) (bool, error) {
+	var conditions bson.A
+	if email != "" {
+		conditions = append(conditions, bson.M{"email": email})
+	}
+	if phone != "" {
+		conditions = append(conditions, bson.M{"phonenumber": phone})
+	}
+	if len(conditions) == 0 {
+		return false, nil
+	}
+
+	count, err := r.db.CountDocuments(ctx, scoped(ctx, bson.M{
+		"id":  bson.M{"$ne": id},
+		"$or": conditions,
+	}))
+	return count > 0, err
+}
+
+// scoped restricts filter to users of tenant scope of ctx.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	return mongoutil.WithTenant(tenant.ScopeOf(ctx), filter)
+}
@@ -4,39 +4,71 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/amirzayi/clean_architect/infra/migrations/model"
 	"github.com/amirzayi/clean_architect/internal/domain"
 	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/search"
 	"github.com/amirzayi/clean_architect/pkg/sqlutil"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
+const (
+	userTableName              = "user"
+	userStatusHistoryTableName = "user_status_history"
+)
+
 type userSQLRepo struct {
-	db *sqlx.DB
+	db                 *sqlx.DB
+	table              string
+	statusHistoryTable string
+	attributeTable     string
 }
 
 func NewUserSQLRepository(db *sqlx.DB) *userSQLRepo {
-	return &userSQLRepo{db: db}
+	return &userSQLRepo{
+		db:                 db,
+		table:              sqlutil.QuoteIdentifier(db.DriverName(), userTableName),
+		statusHistoryTable: sqlutil.QuoteIdentifier(db.DriverName(), userStatusHistoryTableName),
+		attributeTable:     sqlutil.QuoteIdentifier(db.DriverName(), userAttributeTableName),
+	}
 }
 
 func (r *userSQLRepo) Create(ctx context.Context, user domain.User) error {
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO user
-	(id,name,phone,email,password,status,role,created_at)
-	VALUES(?,?,?,?,?,?,?,?)`,
-		user.ID, user.Name, user.PhoneNumber, user.Email, user.Password, user.Status, user.Role, user.CreatedAt.Format(time.RFC3339))
-	if err != nil {
-		return err
+	user.TenantID = tenant.FromContext(ctx)
+	_, err := r.db.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(id,tenant_id,name,phone,email,password,status,role,created_at,updated_at,attributes,version)
+	VALUES(:id,:tenant_id,:name,:phone,:email,:password,:status,:role,:created_at,:updated_at,:attributes,:version)`, r.table),
+		model.ConvertUserFromDomain(user))
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrUserAlreadyExists
 	}
-	return nil
+	return err
 }
 
 func (r *userSQLRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.User, error) {
 	var user model.User
-	err := r.db.GetContext(ctx, &user, "SELECT * FROM user WHERE id=? LIMIT 1", id)
+	cond, args := tenantCondition(ctx)
+	err := r.db.GetContext(ctx, &user, r.db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE id=? AND deleted_at IS NULL%s LIMIT 1", r.table, cond)),
+		append([]any{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+	return model.ConvertUserToDomain(user), err
+}
+
+func (r *userSQLRepo) GetDeletedByID(ctx context.Context, id uuid.UUID) (domain.User, error) {
+	var user model.User
+	cond, args := tenantCondition(ctx)
+	err := r.db.GetContext(ctx, &user, r.db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE id=? AND deleted_at IS NOT NULL%s LIMIT 1", r.table, cond)),
+		append([]any{id}, args...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return domain.User{}, domain.ErrUserNotFound
 	}
@@ -45,7 +77,9 @@ func (r *userSQLRepo) GetByID(ctx context.Context, id uuid.UUID) (domain.User, e
 
 func (r *userSQLRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	var user model.User
-	err := r.db.GetContext(ctx, &user, "SELECT * FROM user WHERE email=? LIMIT 1", email)
+	cond, args := tenantCondition(ctx)
+	err := r.db.GetContext(ctx, &user, r.db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE email=? AND deleted_at IS NULL%s LIMIT 1", r.table, cond)),
+		append([]any{email}, args...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return domain.User{}, domain.ErrUserNotFound
 	}
@@ -53,20 +87,285 @@ func (r *userSQLRepo) GetByEmail(ctx context.Context, email string) (domain.User
 }
 
 func (r *userSQLRepo) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.User, error) {
-	users, err := sqlutil.PaginatedList[model.User](ctx, r.db, "user", pagination, map[string]string{
-		"id":         "id",
-		"name":       "name",
-		"phone":      "phone",
-		"email":      "email",
-		"status":     "status",
-		"role":       "role",
-		"created_at": "created_at",
+	var predicates []sqlutil.Predicate
+	if !withDeleted(pagination) {
+		predicates = append(predicates, sqlutil.Predicate{Query: "deleted_at IS NULL"})
+	}
+
+	fields, err := r.queryableFields(ctx, pagination)
+	if err != nil {
+		return nil, err
+	}
+	terms := search.Terms(pagination.Search)
+	if len(terms) > 0 {
+		predicate, relevance := userSearchIndex.Search(r.db.DriverName(), r.table, terms)
+		predicates = append(predicates, predicate)
+		fields = withRelevance(fields, relevance.Query, relevance.Args...)
+		searchSort(pagination)
+	}
+	rows, err := sqlutil.PaginatedList[model.User](ctx, r.db, r.table, tenant.ScopeOf(ctx), pagination, fields, predicates...)
+	if err != nil {
+		return nil, err
+	}
+	users := model.ConvertUsersToDomains(rows)
+	if len(terms) > 0 {
+		highlight(pagination, users, terms)
+	}
+	return users, nil
+}
+
+// userSearchIndex is the full-text index of users made by their migrations.
+var userSearchIndex = sqlutil.SearchIndex{Table: "user_search", Columns: []string{"name", "email"}}
+
+var userSQLFields = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"phone":      "phone",
+	"email":      "email",
+	"status":     "status",
+	"role":       "role",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"deleted_at": "deleted_at",
+}
+
+// userSQLSelect are columns of projected fields of users, see userSelectFields.
+var userSQLSelect = map[string]string{
+	"id":           "id",
+	"name":         "name",
+	"phone_number": "phone",
+	"email":        "email",
+	"status":       "status",
+	"role":         "role",
+	"created_at":   "created_at",
+	"updated_at":   "updated_at",
+	"attributes":   "attributes",
+	"version":      "version",
+}
+
+// queryableFields returns user fields with custom attributes if pagination filters or sorts by them,
+// attributes are looked up so their values are compared by their type, they are selectable only as a whole.
+func (r *userSQLRepo) queryableFields(ctx context.Context, pagination *paginate.Pagination) (paginate.Fields, error) {
+	if !usesAttributes(pagination) {
+		return paginate.Fields{Filter: userSQLFields, Select: userSQLSelect, Types: userTypes}, nil
+	}
+	attributes, err := listUserAttributes(ctx, r.db, r.attributeTable)
+	if err != nil {
+		return paginate.Fields{}, err
+	}
+	fields := maps.Clone(userSQLFields)
+	types := maps.Clone(userTypes)
+	for _, attribute := range attributes {
+		fields[domain.UserAttributePrefix+attribute.Name] = sqlutil.JSONField(r.db.DriverName(), "attributes", attribute.Name, jsonType(attribute.Type))
+		types[domain.UserAttributePrefix+attribute.Name] = attributeType(attribute)
+		if attribute.Type == domain.UserAttributeBool {
+			// booleans are extracted as text, see sqlutil.JSONBool
+			types[domain.UserAttributePrefix+attribute.Name] = paginate.Field{Type: paginate.TypeEnum, Values: []string{"true", "false"}}
+		}
+	}
+	return paginate.Fields{Filter: fields, Select: userSQLSelect, Types: types}, nil
+}
+
+func jsonType(t domain.UserAttributeType) sqlutil.JSONType {
+	switch t {
+	case domain.UserAttributeNumber:
+		return sqlutil.JSONNumber
+	case domain.UserAttributeBool:
+		return sqlutil.JSONBool
+	}
+	return sqlutil.JSONText
+}
+
+// jsonValue returns normalized attribute value as compared to its sqlutil.JSONField.
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return value
+}
+
+func (r *userSQLRepo) AttributeTaken(ctx context.Context, name string, value any, exceptID uuid.UUID) (bool, error) {
+	typ := sqlutil.JSONText
+	switch value.(type) {
+	case float64:
+		typ = sqlutil.JSONNumber
+	case bool:
+		typ = sqlutil.JSONBool
+	}
+
+	var taken bool
+	cond, args := tenantCondition(ctx)
+	// name is a defined attribute, which is validated by domain.UserAttribute.Validate, so it is safe in json path
+	err := r.db.GetContext(ctx, &taken, r.db.Rebind(fmt.Sprintf("SELECT COUNT(*) > 0 FROM %s WHERE %s=? AND id<>?%s",
+		r.table, sqlutil.JSONField(r.db.DriverName(), "attributes", name, typ), cond)),
+		append([]any{jsonValue(value), exceptID}, args...)...)
+	return taken, err
+}
+
+// tenantCondition returns condition of tenant scope of ctx, to be appended to where clause.
+func tenantCondition(ctx context.Context) (string, []any) {
+	return sqlutil.TenantCondition(tenant.ScopeOf(ctx))
+}
+
+func (r *userSQLRepo) Purge(ctx context.Context, id uuid.UUID) error {
+	cond, args := tenantCondition(ctx)
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf("DELETE FROM %s WHERE id=? AND deleted_at IS NOT NULL%s", r.table, cond)),
+			append([]any{id}, args...)...)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		_, err = tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf("DELETE FROM %s WHERE user_id=?", r.statusHistoryTable)), id)
+		return err
+	})
+}
+
+func (r *userSQLRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	cond, condArgs := tenantCondition(ctx)
+	args := append([]any{before.UTC()}, condArgs...)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf(
+			"DELETE FROM %s WHERE user_id IN (SELECT id FROM %s WHERE deleted_at IS NOT NULL AND deleted_at < ?%s)",
+			r.statusHistoryTable, r.table, cond)), args...)
+		if err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf("DELETE FROM %s WHERE deleted_at IS NOT NULL AND deleted_at < ?%s", r.table, cond)), args...)
+		if err != nil {
+			return err
+		}
+		count, err = res.RowsAffected()
+		return err
 	})
-	return model.ConvertUsersToDomains(users), err
+	return count, err
 }
 
-func (r *userSQLRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	res, err := r.db.ExecContext(ctx, "UPDATE USER SET status=? WHERE id=?", domain.UserStatusDeleted, id)
+func (r *userSQLRepo) ChangeStatus(ctx context.Context, transition domain.UserStatusTransition, version int64) error {
+	sets := []string{"status=?", "updated_at=?", "version=version+1"}
+	args := []any{int(transition.To), transition.At.UTC()}
+	switch {
+	case transition.To == domain.UserStatusDeleted:
+		sets = append(sets, "deleted_at=?")
+		args = append(args, transition.At.UTC())
+	case transition.From == domain.UserStatusDeleted:
+		sets = append(sets, "deleted_at=NULL")
+	}
+
+	cond, condArgs := tenantCondition(ctx)
+	where := "id=? AND " + deletedCondition(transition.From) + cond
+	whereArgs := append([]any{transition.UserID}, condArgs...)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s AND status=?", r.table, strings.Join(sets, ", "), where)
+	args = append(append(args, whereArgs...), int(transition.From))
+	if version != 0 {
+		query += " AND version=?"
+		args = append(args, version)
+	}
+
+	var changed bool
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, tx.Rebind(query), args...)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil || rowsAffected == 0 {
+			return err
+		}
+		changed = true
+
+		history := model.ConvertUserStatusHistoryFromDomain(transition)
+		history.TenantID = tenant.FromContext(ctx)
+		_, err = tx.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+		(id,tenant_id,user_id,from_status,to_status,reason,actor_id,created_at)
+		VALUES(:id,:tenant_id,:user_id,:from_status,:to_status,:reason,:actor_id,:created_at)`, r.statusHistoryTable),
+			history)
+		return err
+	})
+	if err != nil || changed {
+		return err
+	}
+
+	// distinguish changed status or version from missing user
+	var exists bool
+	err = r.db.GetContext(ctx, &exists, r.db.Rebind(fmt.Sprintf("SELECT COUNT(*) > 0 FROM %s WHERE %s", r.table, where)), whereArgs...)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrConcurrentModification
+	}
+	return domain.ErrUserNotFound
+}
+
+func deletedCondition(status domain.UserStatus) string {
+	if status == domain.UserStatusDeleted {
+		return "deleted_at IS NOT NULL"
+	}
+	return "deleted_at IS NULL"
+}
+
+func (r *userSQLRepo) StatusHistory(ctx context.Context, userID uuid.UUID, pagination *paginate.Pagination) ([]domain.UserStatusTransition, error) {
+	sortStatusHistory(pagination)
+	history, err := sqlutil.PaginatedList[model.UserStatusHistory](ctx, r.db, r.statusHistoryTable, tenant.ScopeOf(ctx), pagination,
+		statusHistoryFields, sqlutil.Predicate{Query: "user_id=?", Args: []any{userID}})
+	return model.ConvertUserStatusHistoriesToDomains(history), err
+}
+
+func (r *userSQLRepo) Anonymize(ctx context.Context, user domain.User) error {
+	cond, args := tenantCondition(ctx)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf(
+			"UPDATE %s SET name=?, phone=?, email=?, password=?, attributes=NULL, updated_at=?, version=version+1 WHERE id=?%s", r.table, cond)),
+			append([]any{user.Name, sql.NullString{String: user.PhoneNumber, Valid: user.PhoneNumber != ""}, user.Email, user.Password,
+				user.UpdatedAt.UTC(), user.ID}, args...)...)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		_, err = tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf("UPDATE %s SET reason='' WHERE user_id=?", r.statusHistoryTable)), user.ID)
+		return err
+	})
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrUserAlreadyExists
+	}
+	return err
+}
+
+// withTx runs fn in a transaction which is committed if fn succeeds.
+func (r *userSQLRepo) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// exec runs given query on user table, scoped by tenant of ctx,
+// and returns domain.ErrUserNotFound if no rows affected.
+func (r *userSQLRepo) exec(ctx context.Context, query string, args ...any) error {
+	cond, condArgs := tenantCondition(ctx)
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(fmt.Sprintf(query, r.table)+cond), append(args, condArgs...)...)
 	if err != nil {
 		return err
 	}
@@ -81,12 +380,22 @@ func (r *userSQLRepo) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *userSQLRepo) Update(ctx context.Context, user domain.User) error {
-	res, err := r.db.ExecContext(ctx, `
-	UPDATE USER
-	SET name=?, phone=?, email=?, password=?
-	WHERE id=?`,
-		user.Name, user.PhoneNumber, user.Email, user.Password,
-		user.ID)
+	query := `
+	UPDATE %s
+	SET name=:name, phone=:phone, email=:email, password=:password, attributes=:attributes, updated_at=:updated_at, version=version+1
+	WHERE id=:id AND deleted_at IS NULL`
+	if user.Version != 0 {
+		query += " AND version=:version"
+	}
+	scope := tenant.ScopeOf(ctx)
+	if !scope.All {
+		query += " AND tenant_id=:tenant_id"
+		user.TenantID = scope.ID
+	}
+	res, err := r.db.NamedExecContext(ctx, fmt.Sprintf(query, r.table), model.ConvertUserFromDomain(user))
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrUserAlreadyExists
+	}
 	if err != nil {
 		return err
 	}
@@ -95,7 +404,81 @@ func (r *userSQLRepo) Update(ctx context.Context, user domain.User) error {
 		return err
 	}
 	if rowsAffected == 0 {
-		return domain.ErrUserNotFound
+		return r.notUpdated(ctx, user.ID, user.Version)
 	}
 	return nil
 }
+
+func (r *userSQLRepo) Patch(ctx context.Context, id uuid.UUID, patch domain.UserPatch) error {
+	sets := []string{"updated_at=?", "version=version+1"}
+	args := []any{patch.UpdatedAt.UTC()}
+	if patch.Name != nil {
+		sets = append(sets, "name=?")
+		args = append(args, *patch.Name)
+	}
+	if patch.PhoneNumber != nil {
+		// empty phone number saved as null, same as model.ConvertUserFromDomain
+		sets = append(sets, "phone=?")
+		args = append(args, sql.NullString{String: *patch.PhoneNumber, Valid: *patch.PhoneNumber != ""})
+	}
+	if patch.Email != nil {
+		sets = append(sets, "email=?")
+		args = append(args, *patch.Email)
+	}
+	if patch.Password != nil {
+		sets = append(sets, "password=?")
+		args = append(args, *patch.Password)
+	}
+	if patch.Role != nil {
+		sets = append(sets, "role=?")
+		args = append(args, string(*patch.Role))
+	}
+	if patch.Attributes != nil {
+		sets = append(sets, "attributes=?")
+		args = append(args, model.ConvertAttributeValuesFromDomain(patch.Attributes))
+	}
+
+	cond, condArgs := tenantCondition(ctx)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id=? AND deleted_at IS NULL%s", r.table, strings.Join(sets, ", "), cond)
+	args = append(append(args, id), condArgs...)
+	if patch.Version != 0 {
+		query += " AND version=?"
+		args = append(args, patch.Version)
+	}
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if sqlutil.IsUniqueViolation(err) {
+		return domain.ErrUserAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return r.notUpdated(ctx, id, patch.Version)
+	}
+	return nil
+}
+
+// notUpdated returns error of an update which affected no rows,
+// it distinguishes stale version from missing user.
+func (r *userSQLRepo) notUpdated(ctx context.Context, id uuid.UUID, version int64) error {
+	if version == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	var exists bool
+	cond, args := tenantCondition(ctx)
+	err := r.db.GetContext(ctx, &exists, r.db.Rebind(fmt.Sprintf("SELECT COUNT(*) > 0 FROM %s WHERE id=? AND deleted_at IS NULL%s", r.table, cond)),
+		append([]any{id}, args...)...)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrConcurrentModification
+	}
+	return domain.ErrUserNotFound
+}
@@ -0,0 +1,46 @@
+package user_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/amirzayi/clean_architect/infra/migrations"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/internal/repository/repotest"
+	"github.com/amirzayi/clean_architect/internal/repository/user"
+)
+
+func TestUserSQLiteRepo(t *testing.T) {
+	repotest.RunUserSuite(t, func(t *testing.T) repository.User {
+		return user.NewUserSQLRepository(newSQLiteDB(t))
+	})
+}
+
+func newSQLiteDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	db, err := sqlx.Connect("sqlite", filepath.Join(t.TempDir(), "test.sqlite")+"?_time_format=sqlite")
+	require.NoError(t, err)
+	// sqlite allows only one writer at a time
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db.DB, "sqlite")
+	require.NoError(t, err)
+	if err = migrator.Up(); err != nil && err != migrate.ErrNoChange {
+		require.NoError(t, err)
+	}
+	return db
+}
+
+func TestUserAttributeSQLiteRepo(t *testing.T) {
+	repotest.RunUserAttributeSuite(t, func(t *testing.T) (repository.User, repository.UserAttribute) {
+		db := newSQLiteDB(t)
+		return user.NewUserSQLRepository(db), user.NewUserAttributeSQLRepository(db)
+	})
+}
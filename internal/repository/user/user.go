@@ -0,0 +1,123 @@
+package user
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/search"
+)
+
+// FilterWithDeleted is the list filter key to include deleted users, e.g. ?with_deleted=true
+const FilterWithDeleted = "with_deleted"
+
+func withDeleted(pagination *paginate.Pagination) bool {
+	return slices.ContainsFunc(pagination.Filters, func(filter paginate.Filter) bool {
+		return filter.Key == FilterWithDeleted && filter.Value == "true"
+	})
+}
+
+// usesAttributes reports whether pagination filters, by filters or filter expression, or sorts by custom attributes,
+// see domain.UserAttributePrefix. invalid expressions are left to be rejected by listing.
+func usesAttributes(pagination *paginate.Pagination) bool {
+	expr, _ := pagination.Where()
+	return slices.ContainsFunc(pagination.Filters, func(filter paginate.Filter) bool {
+		return strings.HasPrefix(filter.Key, domain.UserAttributePrefix)
+	}) || slices.ContainsFunc(paginate.Comparisons(expr), func(comparison *paginate.Comparison) bool {
+		return strings.HasPrefix(comparison.Key, domain.UserAttributePrefix)
+	}) || slices.ContainsFunc(pagination.Sort, func(sort paginate.Sort) bool {
+		return strings.HasPrefix(sort.Field, domain.UserAttributePrefix)
+	})
+}
+
+// userSelectFields are fields of users projected by fields parameter, named same as fields of their responses, e.g.
+// phone_number, so sparse fieldsets of lists are projected by repositories and their responses alike. custom
+// attributes are projected as a whole, and passwords are never projected. memory repository returns users whole.
+var userSelectFields = map[string]string{
+	"id":           "id",
+	"name":         "name",
+	"phone_number": "phone_number",
+	"email":        "email",
+	"status":       "status",
+	"role":         "role",
+	"created_at":   "created_at",
+	"updated_at":   "updated_at",
+	"attributes":   "attributes",
+	"version":      "version",
+}
+
+// attributeType returns filter type of custom attribute by its type, dates are compared as their text.
+func attributeType(attribute domain.UserAttribute) paginate.Field {
+	switch attribute.Type {
+	case domain.UserAttributeNumber:
+		return paginate.Field{Type: paginate.TypeFloat}
+	case domain.UserAttributeBool:
+		return paginate.Field{Type: paginate.TypeBool}
+	case domain.UserAttributeEnum:
+		return paginate.Field{Type: paginate.TypeEnum, Values: attribute.Values}
+	}
+	return paginate.Field{Type: paginate.TypeString}
+}
+
+// searchSort sorts searched users most relevant first if pagination does not sort them, cursor pages are sorted
+// by relevance only if sorted explicitly, since sql and mongo could not page by cursor of computed relevance.
+func searchSort(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 && pagination.Cursor == nil {
+		pagination.Sort = []paginate.Sort{{Field: paginate.SortRelevance, Arrange: paginate.SortOrderDescending}}
+	}
+}
+
+// withRelevance returns fields sortable by given relevance of searched users besides their filter fields,
+// args are bound to placeholders of relevance, see paginate.Fields.SortArgs.
+func withRelevance(fields paginate.Fields, relevance string, args ...any) paginate.Fields {
+	fields.Sort = maps.Clone(fields.Filter)
+	fields.Sort[paginate.SortRelevance] = relevance
+	if len(args) > 0 {
+		fields.SortArgs = map[string][]any{paginate.SortRelevance: args}
+	}
+	return fields
+}
+
+// highlight sets highlights of searched fields of users having terms, see paginate.Pagination.Highlights.
+func highlight(pagination *paginate.Pagination, users []domain.User, terms []string) {
+	pagination.Highlights = make(map[string]map[string]string)
+	for _, user := range users {
+		if highlights := search.Highlights(map[string]string{"name": user.Name, "email": user.Email}, terms); highlights != nil {
+			pagination.Highlights[user.ID.String()] = highlights
+		}
+	}
+}
+
+// statusHistoryFields are queryable fields of status history, same keys in all repositories.
+var statusHistoryFields = paginate.Fields{Filter: map[string]string{
+	"id":          "id",
+	"from_status": "from_status",
+	"to_status":   "to_status",
+	"actor_id":    "actor_id",
+	"created_at":  "created_at",
+}, Types: map[string]paginate.Field{
+	"id":          {Type: paginate.TypeUUID},
+	"from_status": {Type: paginate.TypeInt},
+	"to_status":   {Type: paginate.TypeInt},
+	"actor_id":    {Type: paginate.TypeUUID},
+	"created_at":  {Type: paginate.TypeTime},
+}}
+
+// userTypes are types of user fields, same in all repositories.
+var userTypes = map[string]paginate.Field{
+	"id":         {Type: paginate.TypeUUID},
+	"status":     {Type: paginate.TypeInt},
+	"role":       {Type: paginate.TypeEnum, Values: []string{string(domain.UserRoleNormal), string(domain.UserRoleAdmin)}},
+	"created_at": {Type: paginate.TypeTime},
+	"updated_at": {Type: paginate.TypeTime},
+	"deleted_at": {Type: paginate.TypeTime},
+}
+
+// sortStatusHistory sorts history newest first if pagination does not sort it.
+func sortStatusHistory(pagination *paginate.Pagination) {
+	if len(pagination.Sort) == 0 {
+		pagination.Sort = []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderDescending}}
+	}
+}
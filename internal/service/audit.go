@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/requestinfo"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const (
+	// maxAuditAppendAttempts limits retries of appending an entry while other instances append concurrently.
+	maxAuditAppendAttempts = 3
+	auditPageSize          = 500
+)
+
+var ErrAuditHashChainDisabled = errors.New("audit hash chain is disabled")
+
+type Audit interface {
+	// Record appends an entry of action on target, actor and request info are taken from ctx.
+	// failures are only logged since the action is already done.
+	Record(ctx context.Context, action domain.AuditAction, targetType, targetID string, changes []domain.AuditChange)
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.AuditEntry, error)
+	// Export calls fn for every entry matching filters of pagination in order of sequence,
+	// page and sort of pagination are ignored.
+	Export(ctx context.Context, pagination *paginate.Pagination, fn func(domain.AuditEntry) error) error
+	// Verify checks hash chain of whole log, returned error wraps domain.ErrAuditChainBroken if it is tampered.
+	Verify(ctx context.Context) error
+	// Pseudonymizer returns pseudonymizer of personal values of subject, see domain.AuditSubject.
+	// key of subject is created on its first personal value, values are redacted if it could not be loaded.
+	Pseudonymizer(ctx context.Context, subject string) domain.AuditPseudonymizer
+	// Shred deletes key of subject, so pseudonyms of its personal values can no longer be linked to them
+	// while entries and their hash chain are kept untouched.
+	Shred(ctx context.Context, subject string) error
+}
+
+type audit struct {
+	db        repository.Audit
+	keys      repository.AuditKey
+	hashChain bool
+	logger    *slog.Logger
+	// mu serializes appends of this instance, so only concurrent instances conflict on sequence
+	mu sync.Mutex
+}
+
+// NewAuditService returns audit service, hashChain links every entry to previous one by its hash.
+// keys are keys of audit subjects which personal values in changes are pseudonymized by.
+func NewAuditService(db repository.Audit, keys repository.AuditKey, hashChain bool, logger *slog.Logger) Audit {
+	return &audit{
+		db:        db,
+		keys:      keys,
+		hashChain: hashChain,
+		logger:    logger,
+	}
+}
+
+func (a *audit) Record(ctx context.Context, action domain.AuditAction, targetType, targetID string, changes []domain.AuditChange) {
+	info := requestinfo.InfoFromContext(ctx)
+	entry := domain.AuditEntry{
+		ID:         uuid.New(),
+		TenantID:   tenant.FromContext(ctx),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    normalizeAuditChanges(changes),
+		IP:         info.IP,
+		RequestID:  info.RequestID,
+		// some drivers keep only milliseconds, hash must not change after storing
+		At: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		entry.ActorID = claims.UserID
+		entry.ActorRole = claims.UserRole
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// entry is recorded even if the request is canceled after its action is done
+	ctx = context.WithoutCancel(ctx)
+	var err error
+	for range maxAuditAppendAttempts {
+		if err = a.append(ctx, entry); !errors.Is(err, domain.ErrAuditSequenceConflict) {
+			break
+		}
+	}
+	if err != nil {
+		a.logger.Error("failed to record audit entry",
+			slog.String("action", string(action)),
+			slog.String("target_id", targetID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// normalizeAuditChanges returns changes as they are read back from storage, e.g. typed nil slices are absent,
+// so hash of an entry does not change after it is stored.
+func normalizeAuditChanges(changes []domain.AuditChange) []domain.AuditChange {
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return changes
+	}
+	var normalized []domain.AuditChange
+	if err = json.Unmarshal(b, &normalized); err != nil {
+		return changes
+	}
+	return normalized
+}
+
+func (a *audit) append(ctx context.Context, entry domain.AuditEntry) error {
+	last, err := a.db.Last(ctx)
+	if err != nil {
+		return err
+	}
+	entry.Sequence = last.Sequence + 1
+	if a.hashChain {
+		entry.PrevHash = last.Hash
+		entry.Hash = entry.ComputeHash()
+	}
+	return a.db.Append(ctx, entry)
+}
+
+func (a *audit) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.AuditEntry, error) {
+	entries, err := a.db.List(ctx, pagination)
+	if isPaginationError(err) {
+		return nil, paginationError(err)
+	}
+	if err != nil {
+		a.logger.Error("failed to list audit entries", slog.Any("error", err))
+		return nil, errs.New(err, errs.CodeInternal)
+	}
+	return entries, nil
+}
+
+func (a *audit) Export(ctx context.Context, pagination *paginate.Pagination, fn func(domain.AuditEntry) error) error {
+	err := a.each(ctx, pagination.Filters, func(entries []domain.AuditEntry) error {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		a.logger.Error("failed to export audit entries", slog.Any("error", err))
+		return errs.New(err, errs.CodeInternal)
+	}
+	return nil
+}
+
+func (a *audit) Verify(ctx context.Context) error {
+	if !a.hashChain {
+		return errs.New(ErrAuditHashChainDisabled, errs.CodeInvalidArgument)
+	}
+
+	// chain is shared by all tenants
+	ctx = tenant.ContextWithAllTenants(ctx)
+	var prev domain.AuditEntry
+	err := a.each(ctx, nil, func(entries []domain.AuditEntry) error {
+		if err := domain.VerifyAuditChain(prev, entries); err != nil {
+			return err
+		}
+		prev = entries[len(entries)-1]
+		return nil
+	})
+	if err != nil && !errors.Is(err, domain.ErrAuditChainBroken) {
+		a.logger.Error("failed to verify audit chain", slog.Any("error", err))
+		return errs.New(err, errs.CodeInternal)
+	}
+	return err
+}
+
+// each calls fn for every page of entries matching filters in order of sequence.
+func (a *audit) each(ctx context.Context, filters []paginate.Filter, fn func([]domain.AuditEntry) error) error {
+	for page := 1; ; page++ {
+		entries, err := a.db.List(ctx, &paginate.Pagination{
+			Page:    page,
+			PerPage: auditPageSize,
+			Sort:    []paginate.Sort{{Field: "sequence", Arrange: paginate.SortOrderAscending}},
+			Filters: filters,
+		})
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			if err = fn(entries); err != nil {
+				return err
+			}
+		}
+		if len(entries) < auditPageSize {
+			return nil
+		}
+	}
+}
+
+func (a *audit) Pseudonymizer(ctx context.Context, subject string) domain.AuditPseudonymizer {
+	return domain.NewAuditPseudonymizer(func() []byte {
+		key := make([]byte, domain.AuditKeySize)
+		if _, err := rand.Read(key); err != nil {
+			a.logger.Error("failed to generate audit key", slog.String("subject", subject), slog.Any("error", err))
+			return nil
+		}
+		stored, err := a.keys.Create(context.WithoutCancel(ctx), domain.AuditKey{
+			Subject:   subject,
+			Key:       key,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			a.logger.Error("failed to create audit key", slog.String("subject", subject), slog.Any("error", err))
+			return nil
+		}
+		return stored.Key
+	})
+}
+
+func (a *audit) Shred(ctx context.Context, subject string) error {
+	if err := a.keys.Delete(ctx, subject); err != nil {
+		a.logger.Error("failed to shred audit key", slog.String("subject", subject), slog.Any("error", err))
+		return errs.New(err, errs.CodeInternal)
+	}
+	return nil
+}
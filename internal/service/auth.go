@@ -5,52 +5,66 @@ import (
 	"errors"
 	"log/slog"
 
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
 	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
 	"github.com/amirzayi/clean_architect/pkg/auth"
 	"github.com/amirzayi/clean_architect/pkg/errs"
 	"github.com/amirzayi/clean_architect/pkg/hash"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 )
 
+// Auth registers and logs in users of the organization of context.
 type Auth interface {
 	Register(ctx context.Context, auth domain.Auth) error
 	Login(ctx context.Context, auth domain.Auth) (token string, err error)
+	// SwitchOrganization returns token of authenticated user for given organization,
+	// user must be owned by or member of the organization.
+	SwitchOrganization(ctx context.Context, organizationID uuid.UUID) (token string, err error)
 }
 
 type authService struct {
-	userService User
-	hasher      hash.PasswordHasher
-	authManager auth.Manager
-	logger      *slog.Logger
+	userService   User
+	users         repository.User
+	organizations repository.Organization
+	hasher        hash.PasswordHasher
+	authManager   auth.Manager
+	logger        *slog.Logger
 }
 
-func NewAuthService(userService User, hasher hash.PasswordHasher, authManager auth.Manager) Auth {
+func NewAuthService(userService User, users repository.User, organizations repository.Organization,
+	hasher hash.PasswordHasher, authManager auth.Manager, logger *slog.Logger,
+) Auth {
 	return &authService{
-		userService: userService,
-		hasher:      hasher,
-		authManager: authManager,
+		userService:   userService,
+		users:         users,
+		organizations: organizations,
+		hasher:        hasher,
+		authManager:   authManager,
+		logger:        logger,
 	}
 }
 
 func (a *authService) Register(ctx context.Context, auth domain.Auth) error {
-	pwd, err := a.hasher.Hash(auth.Password)
-	if err != nil {
-		a.logger.Error("failed to create hashed password", slog.Any("error", err))
+	if _, err := a.organizations.GetByID(ctx, tenant.FromContext(ctx)); err != nil {
+		if errors.Is(err, domain.ErrOrganizationNotFound) {
+			return errs.NotFound("organization")
+		}
+		a.logger.Error("failed to get organization", slog.Any("error", err))
 		return errs.New(err, errs.CodeInternal)
 	}
 
 	user := domain.User{
 		Email:       auth.Email,
 		PhoneNumber: auth.PhoneNumber,
-		Password:    pwd,
+		Password:    auth.Password,
 		Role:        domain.UserRoleNormal,
 	}
 
-	_, err = a.userService.Create(ctx, user)
-	if err != nil {
-		return err
-	}
-	return nil
+	_, err := a.userService.Create(withSelfService(ctx), user)
+	return err
 }
 func (a *authService) Login(ctx context.Context, auth domain.Auth) (string, error) {
 	// todo: add login via phone no
@@ -59,8 +73,8 @@ func (a *authService) Login(ctx context.Context, auth domain.Auth) (string, erro
 		return "", err
 	}
 
-	if user.Status == domain.UserStatusBanned {
-		return "", errs.New(errors.New("user banned"), errs.CodeForbiddenAccess)
+	if err = mustBeAbleToLogin(user); err != nil {
+		return "", err
 	}
 
 	if err = a.hasher.Compare(user.Password, auth.Password); err != nil {
@@ -71,10 +85,56 @@ func (a *authService) Login(ctx context.Context, auth domain.Auth) (string, erro
 		return "", errs.New(err, errs.CodeInternal)
 	}
 
-	token, err := a.authManager.CreateToken(user.ID, string(user.Role))
+	return a.createToken(user.ID, user.Role, user.TenantID)
+}
+
+func (a *authService) SwitchOrganization(ctx context.Context, organizationID uuid.UUID) (string, error) {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return "", errs.New(errors.New("unauthenticated"), errs.CodeUnauthorized)
+	}
+	// user is looked up by repository, cached users are keyed by tenant of context
+	user, err := a.users.GetByID(tenant.ContextWithAllTenants(ctx), claims.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return "", errs.NotFound("user")
+		}
+		a.logger.Error("failed to get user", slog.Any("error", err))
+		return "", errs.New(err, errs.CodeInternal)
+	}
+	if err = mustBeAbleToLogin(user); err != nil {
+		return "", err
+	}
+
+	if user.TenantID == organizationID {
+		return a.createToken(user.ID, user.Role, organizationID)
+	}
+	membership, err := a.organizations.Member(ctx, organizationID, user.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMembershipNotFound) {
+			return "", errs.New(errors.New("user is not member of organization"), errs.CodeForbiddenAccess)
+		}
+		a.logger.Error("failed to get membership", slog.Any("error", err))
+		return "", errs.New(err, errs.CodeInternal)
+	}
+	return a.createToken(user.ID, membership.Role, organizationID)
+}
+
+func (a *authService) createToken(userID uuid.UUID, role domain.UserRole, tenantID uuid.UUID) (string, error) {
+	token, err := a.authManager.CreateToken(userID, string(role), tenantID)
 	if err != nil {
 		a.logger.Error("failed to create token", slog.Any("error", err))
 		return "", errs.New(err, errs.CodeInternal)
 	}
 	return token, nil
 }
+
+func mustBeAbleToLogin(user domain.User) error {
+	if user.Status == domain.UserStatusBanned {
+		return errs.New(errors.New("user banned"), errs.CodeForbiddenAccess)
+	}
+	if user.Status == domain.UserStatusInactive {
+		return errs.New(errors.New("user deactivated"), errs.CodeForbiddenAccess)
+	}
+	return nil
+}
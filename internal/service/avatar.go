@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/imageutil"
+	"github.com/amirzayi/clean_architect/pkg/storage"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+const (
+	// defaultAvatarMaxSize is used when no maximum size of avatars is configured.
+	defaultAvatarMaxSize = 2 << 20
+	// defaultAvatarThumbnailSize is used when no size of thumbnails is configured.
+	defaultAvatarThumbnailSize = 128
+	// avatarMaxDimension limits width and height of avatars, so small files of huge images are not decoded.
+	avatarMaxDimension = 4096
+)
+
+// Avatar keeps avatars of users of the organization of context and their thumbnails in storage,
+// they are downloaded by signed urls of File service.
+type Avatar interface {
+	// Upload replaces avatar of user by image of r and returns its urls, images are accepted by their sniffed
+	// content type, see domain.AvatarContentTypes. Only admins could change avatars of other users.
+	Upload(ctx context.Context, userID uuid.UUID, r io.Reader) (domain.AvatarURLs, error)
+	URLs(ctx context.Context, userID uuid.UUID) (domain.AvatarURLs, error)
+	// Delete removes avatar of user, only admins could remove avatars of other users.
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+type avatar struct {
+	users         User
+	store         storage.Driver
+	files         File
+	maxSize       int64
+	thumbnailSize int
+	audit         Audit
+	logger        *slog.Logger
+}
+
+func NewAvatarService(users User, store storage.Driver, files File, maxSize int64, thumbnailSize int,
+	audit Audit, logger *slog.Logger) Avatar {
+	if maxSize <= 0 {
+		maxSize = defaultAvatarMaxSize
+	}
+	if thumbnailSize <= 0 {
+		thumbnailSize = defaultAvatarThumbnailSize
+	}
+	return &avatar{
+		users:         users,
+		store:         store,
+		files:         files,
+		maxSize:       maxSize,
+		thumbnailSize: thumbnailSize,
+		audit:         audit,
+		logger:        logger,
+	}
+}
+
+func (a *avatar) Upload(ctx context.Context, userID uuid.UUID, r io.Reader) (domain.AvatarURLs, error) {
+	if err := mustBeSelfOrAdmin(ctx, userID); err != nil {
+		return domain.AvatarURLs{}, err
+	}
+	if _, err := a.users.GetByID(ctx, userID); err != nil {
+		return domain.AvatarURLs{}, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, a.maxSize+1))
+	if err != nil {
+		return domain.AvatarURLs{}, errs.New(err, errs.CodeInvalidArgument)
+	}
+	if int64(len(data)) > a.maxSize {
+		return domain.AvatarURLs{}, errs.New(
+			fmt.Errorf("%w: larger than %d bytes", domain.ErrInvalidAvatar, a.maxSize), errs.CodePayloadTooLarge)
+	}
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(domain.AvatarContentTypes, contentType) {
+		return domain.AvatarURLs{}, errs.New(
+			fmt.Errorf("%w: %s is not one of %v", domain.ErrInvalidAvatar, contentType, domain.AvatarContentTypes),
+			errs.CodeInvalidArgument)
+	}
+	img, format, err := imageutil.Decode(data, avatarMaxDimension)
+	if err != nil {
+		return domain.AvatarURLs{}, errs.New(fmt.Errorf("%w: %w", domain.ErrInvalidAvatar, err), errs.CodeInvalidArgument)
+	}
+
+	var thumbnail bytes.Buffer
+	if err = imageutil.Encode(&thumbnail, imageutil.Thumbnail(img, a.thumbnailSize), format); err != nil {
+		return domain.AvatarURLs{}, a.error(err)
+	}
+	thumbnailType := "image/png"
+	if format == "jpeg" {
+		thumbnailType = "image/jpeg"
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	if err = a.store.Put(ctx, domain.AvatarKey(tenantID, userID, domain.AvatarOriginal), bytes.NewReader(data), contentType); err != nil {
+		return domain.AvatarURLs{}, a.error(err)
+	}
+	if err = a.store.Put(ctx, domain.AvatarKey(tenantID, userID, domain.AvatarThumbnail), &thumbnail, thumbnailType); err != nil {
+		return domain.AvatarURLs{}, a.error(err)
+	}
+	a.audit.Record(ctx, domain.AuditActionUserAvatarUpdate, domain.AuditTargetUser, userID.String(),
+		[]domain.AuditChange{{Field: "avatar", After: fmt.Sprintf("%s, %d bytes", contentType, len(data))}})
+	return a.urls(tenantID, userID), nil
+}
+
+func (a *avatar) URLs(ctx context.Context, userID uuid.UUID) (domain.AvatarURLs, error) {
+	tenantID := tenant.FromContext(ctx)
+	if _, err := a.store.Stat(ctx, domain.AvatarKey(tenantID, userID, domain.AvatarOriginal)); err != nil {
+		return domain.AvatarURLs{}, a.error(err)
+	}
+	return a.urls(tenantID, userID), nil
+}
+
+func (a *avatar) urls(tenantID, userID uuid.UUID) domain.AvatarURLs {
+	original, expiresAt := a.files.URL(domain.AvatarKey(tenantID, userID, domain.AvatarOriginal))
+	thumbnail, _ := a.files.URL(domain.AvatarKey(tenantID, userID, domain.AvatarThumbnail))
+	return domain.AvatarURLs{Original: original, Thumbnail: thumbnail, ExpiresAt: expiresAt}
+}
+
+func (a *avatar) Delete(ctx context.Context, userID uuid.UUID) error {
+	if err := mustBeSelfOrAdmin(ctx, userID); err != nil {
+		return err
+	}
+	tenantID := tenant.FromContext(ctx)
+	if _, err := a.store.Stat(ctx, domain.AvatarKey(tenantID, userID, domain.AvatarOriginal)); err != nil {
+		return a.error(err)
+	}
+	if err := deleteAvatar(ctx, a.store, tenantID, userID); err != nil {
+		return a.error(err)
+	}
+	a.audit.Record(ctx, domain.AuditActionUserAvatarDelete, domain.AuditTargetUser, userID.String(), nil)
+	return nil
+}
+
+// deleteAvatar removes avatar of user in all sizes, removing a missing avatar is not an error.
+func deleteAvatar(ctx context.Context, store storage.Driver, tenantID, userID uuid.UUID) error {
+	for _, size := range []domain.AvatarSize{domain.AvatarThumbnail, domain.AvatarOriginal} {
+		if err := store.Delete(ctx, domain.AvatarKey(tenantID, userID, size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mustBeSelfOrAdmin prevents users from changing avatars of others, calls without claims are trusted.
+func mustBeSelfOrAdmin(ctx context.Context, id uuid.UUID) error {
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.UserID != id && !claims.HasRole(string(domain.UserRoleAdmin)) {
+		return errs.New(errors.New("users could only change their own avatar"), errs.CodeForbiddenAccess)
+	}
+	return nil
+}
+
+func (a *avatar) error(err error) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return errs.NotFound("avatar")
+	}
+	a.logger.Error("failed to access avatars", slog.Any("error", err))
+	return errs.New(err, errs.CodeInternal)
+}
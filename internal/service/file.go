@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/storage"
+)
+
+const (
+	// defaultFileURLLifeTime is used when no life time of signed urls is configured.
+	defaultFileURLLifeTime = 15 * time.Minute
+	// defaultFileDownloadURL is the endpoint serving signed urls, see v2.FileRoutes.
+	defaultFileDownloadURL = "/v2/files"
+)
+
+// File serves blobs of storage by signed time-limited urls, so they are downloaded without authentication.
+type File interface {
+	// URL returns signed download url of blob of key and its expiry.
+	URL(key string) (string, time.Time)
+	// Open verifies a signed url by its query and returns its blob, caller must close it.
+	Open(ctx context.Context, query url.Values) (io.ReadCloser, storage.Object, error)
+}
+
+type file struct {
+	store       storage.Driver
+	signer      storage.Signer
+	downloadURL string
+	lifeTime    time.Duration
+	logger      *slog.Logger
+}
+
+func NewFileService(store storage.Driver, signer storage.Signer, downloadURL string, lifeTime time.Duration,
+	logger *slog.Logger) File {
+	if downloadURL == "" {
+		downloadURL = defaultFileDownloadURL
+	}
+	if lifeTime <= 0 {
+		lifeTime = defaultFileURLLifeTime
+	}
+	return &file{
+		store:       store,
+		signer:      signer,
+		downloadURL: downloadURL,
+		lifeTime:    lifeTime,
+		logger:      logger,
+	}
+}
+
+func (f *file) URL(key string) (string, time.Time) {
+	expiresAt := time.Now().Add(f.lifeTime).Truncate(time.Second)
+	return f.signer.SignURL(f.downloadURL, key, expiresAt), expiresAt
+}
+
+func (f *file) Open(ctx context.Context, query url.Values) (io.ReadCloser, storage.Object, error) {
+	key, err := f.signer.Verify(query, time.Now())
+	if err != nil {
+		return nil, storage.Object{}, errs.New(err, errs.CodeForbiddenAccess)
+	}
+	r, object, err := f.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, storage.Object{}, errs.NotFound("file")
+		}
+		f.logger.Error("failed to open file", slog.String("key", key), slog.Any("error", err))
+		return nil, storage.Object{}, errs.New(err, errs.CodeInternal)
+	}
+	return r, object, nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/cache"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+var errGroupNameRequired = errors.New("group name is required")
+
+const (
+	// groupRolesCachePrefix is the prefix of cached roles of users, they are cached under key of their tenant
+	// along with generation of its groups, see group.generation.
+	groupRolesCachePrefix = "group_roles"
+	// groupGenerationCachePrefix is the prefix of cached generations of groups of tenants.
+	groupGenerationCachePrefix = "group_generation"
+	groupRolesCacheLifeTime    = 5 * time.Minute
+)
+
+// Group manages groups of the organization of context, groups contain users and other groups
+// and grant their roles to all users in them, directly or through nested groups.
+type Group interface {
+	Create(ctx context.Context, group domain.Group) (domain.Group, error)
+	Get(ctx context.Context, id uuid.UUID) (domain.Group, error)
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Group, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// AddMember adds user or group as a direct member of group, groups containing group itself could not be added.
+	AddMember(ctx context.Context, member domain.GroupMember) (domain.GroupMember, error)
+	RemoveMember(ctx context.Context, groupID uuid.UUID, kind domain.GroupMemberKind, memberID uuid.UUID) error
+	// Members lists direct members of group.
+	Members(ctx context.Context, groupID uuid.UUID, pagination *paginate.Pagination) ([]domain.GroupMember, error)
+	// UserGroups returns groups user belongs to, directly or through nested groups, ordered by name.
+	UserGroups(ctx context.Context, userID uuid.UUID) ([]domain.Group, error)
+	// Roles returns roles granted to user by all of its groups, sorted and without duplicates.
+	// roles are cached until groups of the organization change.
+	Roles(ctx context.Context, userID uuid.UUID) ([]domain.UserRole, error)
+}
+
+type group struct {
+	db          repository.Group
+	users       repository.User
+	audit       Audit
+	roles       cache.Cache[[]domain.UserRole]
+	generations cache.Cache[string]
+	logger      *slog.Logger
+}
+
+func NewGroupService(db repository.Group, users repository.User, audit Audit, cacheDriver cache.Driver,
+	logger *slog.Logger) Group {
+	return &group{
+		db:          db,
+		users:       users,
+		audit:       audit,
+		roles:       cache.New[[]domain.UserRole](cacheDriver, groupRolesCachePrefix, groupRolesCacheLifeTime),
+		generations: cache.New[string](cacheDriver, groupGenerationCachePrefix, groupRolesCacheLifeTime),
+		logger:      logger,
+	}
+}
+
+func (g *group) Create(ctx context.Context, group domain.Group) (domain.Group, error) {
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return domain.Group{}, errs.New(errGroupNameRequired, errs.CodeInvalidArgument)
+	}
+	for _, role := range group.Roles {
+		if !role.IsValid() {
+			return domain.Group{}, errs.New(fmt.Errorf("invalid role %q", role), errs.CodeInvalidArgument)
+		}
+	}
+	group.Roles = slices.Compact(slices.Sorted(slices.Values(group.Roles)))
+
+	group.ID = uuid.New()
+	group.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	group.UpdatedAt = group.CreatedAt
+	if err := g.db.Create(ctx, group); err != nil {
+		return domain.Group{}, g.error(err)
+	}
+	g.audit.Record(ctx, domain.AuditActionGroupCreate, domain.AuditTargetGroup, group.ID.String(),
+		[]domain.AuditChange{{Field: "name", After: group.Name}, {Field: "roles", After: group.Roles}})
+	return group, nil
+}
+
+func (g *group) Get(ctx context.Context, id uuid.UUID) (domain.Group, error) {
+	group, err := g.db.GetByID(ctx, id)
+	if err != nil {
+		return domain.Group{}, g.error(err)
+	}
+	return group, nil
+}
+
+func (g *group) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Group, error) {
+	groups, err := g.db.List(ctx, pagination)
+	if err != nil {
+		return nil, g.error(err)
+	}
+	return groups, nil
+}
+
+func (g *group) Delete(ctx context.Context, id uuid.UUID) error {
+	group, err := g.db.GetByID(ctx, id)
+	if err == nil {
+		err = g.db.Delete(ctx, id)
+	}
+	if err != nil {
+		return g.error(err)
+	}
+	g.invalidateRoles(ctx)
+	g.audit.Record(ctx, domain.AuditActionGroupDelete, domain.AuditTargetGroup, id.String(),
+		[]domain.AuditChange{{Field: "name", Before: group.Name}, {Field: "roles", Before: group.Roles}})
+	return nil
+}
+
+func (g *group) AddMember(ctx context.Context, member domain.GroupMember) (domain.GroupMember, error) {
+	if !member.Kind.IsValid() {
+		return domain.GroupMember{}, errs.New(fmt.Errorf("invalid member kind %q", member.Kind), errs.CodeInvalidArgument)
+	}
+	if _, err := g.db.GetByID(ctx, member.GroupID); err != nil {
+		return domain.GroupMember{}, g.error(err)
+	}
+	switch member.Kind {
+	case domain.GroupMemberUser:
+		if _, err := g.users.GetByID(ctx, member.MemberID); err != nil {
+			return domain.GroupMember{}, g.error(err)
+		}
+	case domain.GroupMemberGroup:
+		if err := g.mustNotCycle(ctx, member.GroupID, member.MemberID); err != nil {
+			return domain.GroupMember{}, err
+		}
+	}
+
+	member.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := g.db.AddMember(ctx, member); err != nil {
+		return domain.GroupMember{}, g.error(err)
+	}
+	g.invalidateRoles(ctx)
+	g.audit.Record(ctx, domain.AuditActionGroupMemberAdd, domain.AuditTargetGroup, member.GroupID.String(),
+		[]domain.AuditChange{{Field: string(member.Kind), After: member.MemberID.String()}})
+	return member, nil
+}
+
+// mustNotCycle checks that child group exists and that parent is neither child nor nested in it,
+// otherwise adding child to parent would make parent a member of itself.
+// it is not atomic, but traversals never loop since they skip visited groups.
+func (g *group) mustNotCycle(ctx context.Context, parentID, childID uuid.UUID) error {
+	if _, err := g.db.GetByID(ctx, childID); err != nil {
+		return g.error(err)
+	}
+	if parentID == childID {
+		return errs.New(domain.ErrGroupCycle, errs.CodeConflict)
+	}
+	ancestors, err := g.ancestors(ctx, domain.GroupMemberGroup, parentID)
+	if err != nil {
+		return g.error(err)
+	}
+	if slices.ContainsFunc(ancestors, func(ancestor domain.Group) bool { return ancestor.ID == childID }) {
+		return errs.New(domain.ErrGroupCycle, errs.CodeConflict)
+	}
+	return nil
+}
+
+func (g *group) RemoveMember(ctx context.Context, groupID uuid.UUID, kind domain.GroupMemberKind, memberID uuid.UUID) error {
+	if !kind.IsValid() {
+		return errs.New(fmt.Errorf("invalid member kind %q", kind), errs.CodeInvalidArgument)
+	}
+	if err := g.db.RemoveMember(ctx, groupID, kind, memberID); err != nil {
+		return g.error(err)
+	}
+	g.invalidateRoles(ctx)
+	g.audit.Record(ctx, domain.AuditActionGroupMemberRemove, domain.AuditTargetGroup, groupID.String(),
+		[]domain.AuditChange{{Field: string(kind), Before: memberID.String()}})
+	return nil
+}
+
+func (g *group) Members(ctx context.Context, groupID uuid.UUID, pagination *paginate.Pagination) ([]domain.GroupMember, error) {
+	if _, err := g.db.GetByID(ctx, groupID); err != nil {
+		return nil, g.error(err)
+	}
+	members, err := g.db.Members(ctx, groupID, pagination)
+	if err != nil {
+		return nil, g.error(err)
+	}
+	return members, nil
+}
+
+func (g *group) UserGroups(ctx context.Context, userID uuid.UUID) ([]domain.Group, error) {
+	groups, err := g.ancestors(ctx, domain.GroupMemberUser, userID)
+	if err != nil {
+		return nil, g.error(err)
+	}
+	slices.SortFunc(groups, func(a, b domain.Group) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID.String(), b.ID.String()))
+	})
+	return groups, nil
+}
+
+func (g *group) Roles(ctx context.Context, userID uuid.UUID) ([]domain.UserRole, error) {
+	generation, cached := g.generation(ctx)
+	key := tenant.Key(ctx, userID.String()+":"+generation)
+	if cached {
+		if roles, err := g.roles.Get(ctx, key); err == nil {
+			return roles, nil
+		}
+	}
+
+	groups, err := g.ancestors(ctx, domain.GroupMemberUser, userID)
+	if err != nil {
+		return nil, g.error(err)
+	}
+	var roles []domain.UserRole
+	for _, group := range groups {
+		roles = append(roles, group.Roles...)
+	}
+	slices.Sort(roles)
+	roles = slices.Compact(roles)
+
+	if cached {
+		if err = g.roles.Set(ctx, key, roles); err != nil {
+			g.logger.Error("failed to cache group roles", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+	return roles, nil
+}
+
+// generation returns current generation of groups of tenant of ctx, roles of users are cached by it so they are
+// never stale, a new generation is started if there is none. cached is false if cache is not available.
+func (g *group) generation(ctx context.Context) (generation string, cached bool) {
+	key := tenant.FromContext(ctx).String()
+	generation, err := g.generations.Get(ctx, key)
+	if err == nil {
+		return generation, true
+	}
+	generation = uuid.NewString()
+	if err = g.generations.Set(ctx, key, generation); err != nil {
+		g.logger.Error("failed to cache group generation", slog.String("key", key), slog.Any("error", err))
+		return "", false
+	}
+	return generation, true
+}
+
+// invalidateRoles starts a new generation of groups of tenant of ctx, so cached roles of its users are not used.
+func (g *group) invalidateRoles(ctx context.Context) {
+	key := tenant.FromContext(ctx).String()
+	if err := g.generations.Set(ctx, key, uuid.NewString()); err != nil {
+		g.logger.Error("failed to cache group generation", slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+// ancestors returns all groups containing given member, directly or through nested groups, in breadth first order.
+func (g *group) ancestors(ctx context.Context, kind domain.GroupMemberKind, memberID uuid.UUID) ([]domain.Group, error) {
+	parents, err := g.db.GroupsOf(ctx, kind, memberID)
+	if err != nil {
+		return nil, err
+	}
+	visited := make(map[uuid.UUID]bool)
+	var ancestors []domain.Group
+	for len(parents) > 0 {
+		group := parents[0]
+		parents = parents[1:]
+		if visited[group.ID] {
+			continue
+		}
+		visited[group.ID] = true
+		ancestors = append(ancestors, group)
+
+		grandparents, err := g.db.GroupsOf(ctx, domain.GroupMemberGroup, group.ID)
+		if err != nil {
+			return nil, err
+		}
+		parents = append(parents, grandparents...)
+	}
+	return ancestors, nil
+}
+
+func (g *group) error(err error) error {
+	switch {
+	case isPaginationError(err):
+		return paginationError(err)
+	case errors.Is(err, domain.ErrGroupNotFound):
+		return errs.NotFound("group")
+	case errors.Is(err, domain.ErrGroupMemberNotFound):
+		return errs.NotFound("group member")
+	case errors.Is(err, domain.ErrUserNotFound):
+		return errs.NotFound("user")
+	case errors.Is(err, domain.ErrGroupAlreadyExists), errors.Is(err, domain.ErrGroupMemberAlreadyExists):
+		return errs.New(err, errs.CodeExisted)
+	}
+	g.logger.Error("failed to access groups", slog.Any("error", err))
+	return errs.New(err, errs.CodeInternal)
+}
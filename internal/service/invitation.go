@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/notify"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// defaultInvitationLifeTime is used when no life time of invitations is configured.
+const defaultInvitationLifeTime = 72 * time.Hour
+
+var (
+	errInvitationExpired    = errors.New("invitation expired")
+	errInvitationPending    = errors.New("email has a pending invitation")
+	errInvitationEmailTaken = errors.New("email is taken by a user")
+)
+
+// Invitation invites emails into a role of the organization of context, invitees accept by the token
+// sent to them and set their own password.
+type Invitation interface {
+	// Invite sends a token to email which expires at given time, zero time means default life time.
+	Invite(ctx context.Context, email string, role domain.UserRole, expiresAt time.Time) (domain.Invitation, error)
+	Get(ctx context.Context, id uuid.UUID) (domain.Invitation, error)
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Invitation, error)
+	// Resend sends a new token of a pending invitation, even an expired one, and extends its expiry by default life time.
+	// previous token is not valid anymore.
+	Resend(ctx context.Context, id uuid.UUID) (domain.Invitation, error)
+	Revoke(ctx context.Context, id uuid.UUID) (domain.Invitation, error)
+	// Accept creates user of invitation of token with given name, phone number and password.
+	Accept(ctx context.Context, token string, user domain.User) (domain.User, error)
+}
+
+type invitation struct {
+	db          repository.Invitation
+	userService User
+	notifier    notify.Driver
+	audit       Audit
+	lifeTime    time.Duration
+	acceptURL   string
+	logger      *slog.Logger
+}
+
+func NewInvitationService(db repository.Invitation, userService User, notifier notify.Driver,
+	audit Audit, lifeTime time.Duration, acceptURL string, logger *slog.Logger) Invitation {
+	if lifeTime <= 0 {
+		lifeTime = defaultInvitationLifeTime
+	}
+	return &invitation{
+		db:          db,
+		userService: userService,
+		notifier:    notifier,
+		audit:       audit,
+		lifeTime:    lifeTime,
+		acceptURL:   acceptURL,
+		logger:      logger,
+	}
+}
+
+func (i *invitation) Invite(ctx context.Context, email string, role domain.UserRole, expiresAt time.Time) (domain.Invitation, error) {
+	if !role.IsValid() {
+		return domain.Invitation{}, errs.New(fmt.Errorf("invalid role %q", role), errs.CodeInvalidArgument)
+	}
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(i.lifeTime)
+	}
+	if !expiresAt.After(now) {
+		return domain.Invitation{}, errs.New(errors.New("expiry must be in the future"), errs.CodeInvalidArgument)
+	}
+	email = strings.TrimSpace(email)
+	if err := i.mustBeInvitable(ctx, email, now); err != nil {
+		return domain.Invitation{}, err
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return domain.Invitation{}, i.error(err)
+	}
+	invitation := domain.Invitation{
+		ID:        uuid.New(),
+		TenantID:  tenant.FromContext(ctx),
+		Email:     email,
+		Role:      role,
+		Status:    domain.InvitationPending,
+		TokenHash: domain.HashInvitationToken(token),
+		ExpiresAt: expiresAt.UTC().Truncate(time.Millisecond),
+		SentAt:    now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		invitation.InvitedBy = claims.UserID
+	}
+	if err = i.db.Create(ctx, invitation); err != nil {
+		return domain.Invitation{}, i.error(err)
+	}
+	// email of invitee is personal, it is shredded by erasure of the user it is sent to
+	pseudonymize := i.audit.Pseudonymizer(ctx, domain.AuditSubject(domain.AuditTargetInvitation, invitation.ID))
+	i.audit.Record(ctx, domain.AuditActionInvitationCreate, domain.AuditTargetInvitation, invitation.ID.String(),
+		[]domain.AuditChange{{Field: "email", After: pseudonymize(email)}, {Field: "role", After: string(role)}})
+	i.send(ctx, invitation, token)
+	return invitation, nil
+}
+
+// mustBeInvitable checks that email is neither taken by a user nor has a pending invitation.
+func (i *invitation) mustBeInvitable(ctx context.Context, email string, now time.Time) error {
+	_, err := i.userService.GetByEmail(ctx, email)
+	if err == nil {
+		return errs.New(errInvitationEmailTaken, errs.CodeExisted)
+	}
+	var appErr *errs.Error
+	if !errors.As(err, &appErr) || appErr.Code != errs.CodeNotFound {
+		return err
+	}
+
+	pending, err := i.db.List(ctx, &paginate.Pagination{Page: 1, PerPage: 10, Filters: []paginate.Filter{
+		{Key: "email", Value: email, Condition: paginate.FilterEqual},
+		{Key: "status", Value: string(domain.InvitationPending), Condition: paginate.FilterEqual},
+	}})
+	if err != nil {
+		return i.error(err)
+	}
+	for _, invitation := range pending {
+		if invitation.StatusAt(now) == domain.InvitationPending {
+			return errs.New(errInvitationPending, errs.CodeExisted)
+		}
+	}
+	return nil
+}
+
+func (i *invitation) Get(ctx context.Context, id uuid.UUID) (domain.Invitation, error) {
+	invitation, err := i.db.GetByID(ctx, id)
+	if err != nil {
+		return domain.Invitation{}, i.error(err)
+	}
+	return invitation, nil
+}
+
+func (i *invitation) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Invitation, error) {
+	invitations, err := i.db.List(ctx, pagination)
+	if err != nil {
+		return nil, i.error(err)
+	}
+	return invitations, nil
+}
+
+func (i *invitation) Resend(ctx context.Context, id uuid.UUID) (domain.Invitation, error) {
+	invitation, err := i.db.GetByID(ctx, id)
+	if err != nil {
+		return domain.Invitation{}, i.error(err)
+	}
+	token, err := newInvitationToken()
+	if err != nil {
+		return domain.Invitation{}, i.error(err)
+	}
+
+	before := invitation.ExpiresAt
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	invitation.TokenHash = domain.HashInvitationToken(token)
+	invitation.ExpiresAt = now.Add(i.lifeTime)
+	invitation.SentAt = now
+	invitation.UpdatedAt = now
+	if err = i.db.Update(ctx, invitation); err != nil {
+		return domain.Invitation{}, i.error(err)
+	}
+	i.audit.Record(ctx, domain.AuditActionInvitationResend, domain.AuditTargetInvitation, id.String(),
+		[]domain.AuditChange{{Field: "expires_at", Before: before, After: invitation.ExpiresAt}})
+	i.send(ctx, invitation, token)
+	return invitation, nil
+}
+
+func (i *invitation) Revoke(ctx context.Context, id uuid.UUID) (domain.Invitation, error) {
+	invitation, err := i.db.GetByID(ctx, id)
+	if err != nil {
+		return domain.Invitation{}, i.error(err)
+	}
+	invitation.Status = domain.InvitationRevoked
+	invitation.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err = i.db.Update(ctx, invitation); err != nil {
+		return domain.Invitation{}, i.error(err)
+	}
+	i.audit.Record(ctx, domain.AuditActionInvitationRevoke, domain.AuditTargetInvitation, id.String(),
+		[]domain.AuditChange{{Field: "status", Before: string(domain.InvitationPending), After: string(invitation.Status)}})
+	return invitation, nil
+}
+
+func (i *invitation) Accept(ctx context.Context, token string, user domain.User) (domain.User, error) {
+	// invitees are not authenticated, tenant of invitation is found by its token
+	invitation, err := i.db.GetByTokenHash(tenant.ContextWithAllTenants(ctx), domain.HashInvitationToken(token))
+	if err != nil {
+		return domain.User{}, i.error(err)
+	}
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	switch invitation.StatusAt(now) {
+	case domain.InvitationPending:
+	case domain.InvitationExpired:
+		return domain.User{}, errs.New(errInvitationExpired, errs.CodeConflict)
+	default:
+		return domain.User{}, i.error(domain.ErrInvitationNotPending)
+	}
+	ctx = tenant.ContextWithID(ctx, invitation.TenantID)
+
+	user.Email = invitation.Email
+	user.Role = invitation.Role
+	// unique email of users prevents accepting twice, even concurrently
+	user, err = i.userService.Create(withSelfService(ctx), user)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	invitation.Status = domain.InvitationAccepted
+	invitation.UserID = user.ID
+	invitation.UpdatedAt = now
+	if err = i.db.Update(ctx, invitation); err != nil {
+		i.logger.Error("failed to mark invitation accepted", slog.String("invitation_id", invitation.ID.String()),
+			slog.Any("error", err))
+	}
+	i.audit.Record(ctx, domain.AuditActionInvitationAccept, domain.AuditTargetInvitation, invitation.ID.String(),
+		[]domain.AuditChange{
+			{Field: "status", Before: string(domain.InvitationPending), After: string(invitation.Status)},
+			{Field: "user_id", After: user.ID.String()},
+		})
+	return user, nil
+}
+
+// send sends token of invitation to its email, failures are only logged since invitation could be resent.
+func (i *invitation) send(ctx context.Context, invitation domain.Invitation, token string) {
+	link := token
+	if i.acceptURL != "" {
+		link = i.acceptURL + "?token=" + url.QueryEscape(token)
+	}
+	err := i.notifier.Send(ctx, notify.Message{
+		To:      invitation.Email,
+		Subject: "You are invited",
+		Body: fmt.Sprintf("You are invited as %s, accept the invitation by %s before %s.",
+			invitation.Role, link, invitation.ExpiresAt.Format(time.RFC1123)),
+	})
+	if err != nil {
+		i.logger.Warn("failed to send invitation", slog.String("invitation_id", invitation.ID.String()),
+			slog.Any("error", err))
+	}
+}
+
+func (i *invitation) error(err error) error {
+	switch {
+	case isPaginationError(err):
+		return paginationError(err)
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		return errs.NotFound("invitation")
+	case errors.Is(err, domain.ErrInvitationNotPending):
+		return errs.New(err, errs.CodeConflict)
+	}
+	i.logger.Error("failed to access invitations", slog.Any("error", err))
+	return errs.New(err, errs.CodeInternal)
+}
+
+// newInvitationToken returns a random url safe token.
+func newInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
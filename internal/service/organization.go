@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+var (
+	errOrganizationNameRequired = errors.New("organization name is required")
+	errOwnOrganizationMember    = errors.New("users are members of their own organization by their user role")
+)
+
+// Organization manages organizations and their memberships. organizations are created and listed
+// by the default organization only, members of an organization are managed by itself or the default organization.
+type Organization interface {
+	Create(ctx context.Context, organization domain.Organization) (domain.Organization, error)
+	Get(ctx context.Context, id uuid.UUID) (domain.Organization, error)
+	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Organization, error)
+	// SaveMember grants user of another organization given role, or changes its role if it is member already.
+	SaveMember(ctx context.Context, membership domain.Membership) (domain.Membership, error)
+	RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error
+	Members(ctx context.Context, organizationID uuid.UUID, pagination *paginate.Pagination) ([]domain.Membership, error)
+}
+
+type organization struct {
+	db     repository.Organization
+	users  repository.User
+	audit  Audit
+	logger *slog.Logger
+}
+
+func NewOrganizationService(db repository.Organization, users repository.User, audit Audit, logger *slog.Logger) Organization {
+	return &organization{
+		db:     db,
+		users:  users,
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+func (o *organization) Create(ctx context.Context, organization domain.Organization) (domain.Organization, error) {
+	if err := mustBeDefaultTenant(ctx); err != nil {
+		return domain.Organization{}, err
+	}
+	organization.Name = strings.TrimSpace(organization.Name)
+	if organization.Name == "" {
+		return domain.Organization{}, errs.New(errOrganizationNameRequired, errs.CodeInvalidArgument)
+	}
+
+	organization.ID = uuid.New()
+	organization.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	organization.UpdatedAt = organization.CreatedAt
+	if err := o.db.Create(ctx, organization); err != nil {
+		return domain.Organization{}, o.error(err)
+	}
+	o.audit.Record(ctx, domain.AuditActionOrganizationCreate, domain.AuditTargetOrganization, organization.ID.String(),
+		[]domain.AuditChange{{Field: "name", After: organization.Name}})
+	return organization, nil
+}
+
+func (o *organization) Get(ctx context.Context, id uuid.UUID) (domain.Organization, error) {
+	if err := mustManageOrganization(ctx, id); err != nil {
+		return domain.Organization{}, err
+	}
+	organization, err := o.db.GetByID(ctx, id)
+	if err != nil {
+		return domain.Organization{}, o.error(err)
+	}
+	return organization, nil
+}
+
+func (o *organization) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.Organization, error) {
+	if err := mustBeDefaultTenant(ctx); err != nil {
+		return nil, err
+	}
+	organizations, err := o.db.List(ctx, pagination)
+	if err != nil {
+		return nil, o.error(err)
+	}
+	return organizations, nil
+}
+
+func (o *organization) SaveMember(ctx context.Context, membership domain.Membership) (domain.Membership, error) {
+	if err := mustManageOrganization(ctx, membership.OrganizationID); err != nil {
+		return domain.Membership{}, err
+	}
+	if !membership.Role.IsValid() {
+		return domain.Membership{}, errs.New(fmt.Errorf("invalid role %q", membership.Role), errs.CodeInvalidArgument)
+	}
+	if err := mustNotBeActor(ctx, membership.UserID); err != nil {
+		return domain.Membership{}, err
+	}
+	if _, err := o.db.GetByID(ctx, membership.OrganizationID); err != nil {
+		return domain.Membership{}, o.error(err)
+	}
+	// members come from other organizations, so user is looked up in all of them
+	user, err := o.users.GetByID(tenant.ContextWithAllTenants(ctx), membership.UserID)
+	if err != nil {
+		return domain.Membership{}, o.error(err)
+	}
+	if user.TenantID == membership.OrganizationID {
+		return domain.Membership{}, errs.New(errOwnOrganizationMember, errs.CodeConflict)
+	}
+
+	var before domain.UserRole
+	current, err := o.db.Member(ctx, membership.OrganizationID, membership.UserID)
+	switch {
+	case err == nil:
+		before = current.Role
+	case !errors.Is(err, domain.ErrMembershipNotFound):
+		return domain.Membership{}, o.error(err)
+	}
+
+	membership.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err = o.db.SaveMember(ctx, membership); err != nil {
+		return domain.Membership{}, o.error(err)
+	}
+	if before != "" {
+		membership.CreatedAt = current.CreatedAt
+	}
+	o.audit.Record(ctx, domain.AuditActionOrganizationMemberSave, domain.AuditTargetOrganization,
+		membership.OrganizationID.String(), memberAuditChanges(membership.UserID, before, membership.Role))
+	return membership, nil
+}
+
+func (o *organization) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	if err := mustManageOrganization(ctx, organizationID); err != nil {
+		return err
+	}
+	if err := mustNotBeActor(ctx, userID); err != nil {
+		return err
+	}
+	current, err := o.db.Member(ctx, organizationID, userID)
+	if err == nil {
+		err = o.db.RemoveMember(ctx, organizationID, userID)
+	}
+	if err != nil {
+		return o.error(err)
+	}
+	o.audit.Record(ctx, domain.AuditActionOrganizationMemberRemove, domain.AuditTargetOrganization,
+		organizationID.String(), memberAuditChanges(userID, current.Role, ""))
+	return nil
+}
+
+func (o *organization) Members(ctx context.Context, organizationID uuid.UUID, pagination *paginate.Pagination) ([]domain.Membership, error) {
+	if err := mustManageOrganization(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	members, err := o.db.Members(ctx, organizationID, pagination)
+	if err != nil {
+		return nil, o.error(err)
+	}
+	return members, nil
+}
+
+func (o *organization) error(err error) error {
+	switch {
+	case isPaginationError(err):
+		return paginationError(err)
+	case errors.Is(err, domain.ErrOrganizationNotFound):
+		return errs.NotFound("organization")
+	case errors.Is(err, domain.ErrMembershipNotFound):
+		return errs.NotFound("membership")
+	case errors.Is(err, domain.ErrUserNotFound):
+		return errs.NotFound("user")
+	case errors.Is(err, domain.ErrOrganizationAlreadyExists):
+		return errs.New(err, errs.CodeExisted)
+	}
+	o.logger.Error("failed to access organizations", slog.Any("error", err))
+	return errs.New(err, errs.CodeInternal)
+}
+
+// memberAuditChanges returns changes of membership of user, empty role means absent.
+func memberAuditChanges(userID uuid.UUID, before, after domain.UserRole) []domain.AuditChange {
+	role := domain.AuditChange{Field: "role"}
+	if before != "" {
+		role.Before = string(before)
+	}
+	if after != "" {
+		role.After = string(after)
+	}
+	return []domain.AuditChange{{Field: "user_id", After: userID.String()}, role}
+}
+
+// mustBeDefaultTenant allows only the default organization, which hosts the platform, to manage organizations.
+func mustBeDefaultTenant(ctx context.Context) error {
+	if tenant.FromContext(ctx) != tenant.Default {
+		return errs.New(errors.New("only the default organization could manage organizations"), errs.CodeForbiddenAccess)
+	}
+	return nil
+}
+
+// mustManageOrganization allows organization itself and the default organization to manage it.
+func mustManageOrganization(ctx context.Context, id uuid.UUID) error {
+	if current := tenant.FromContext(ctx); current != id && current != tenant.Default {
+		return errs.New(errors.New("organizations could not manage other organizations"), errs.CodeForbiddenAccess)
+	}
+	return nil
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	userrepo "github.com/amirzayi/clean_architect/internal/repository/user"
+	"github.com/amirzayi/clean_architect/pkg/auth"
+	"github.com/amirzayi/clean_architect/pkg/bus"
+	"github.com/amirzayi/clean_architect/pkg/cache"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/storage"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
+)
+
+// UserErasedEvent is the subject of domain.UserErasure events published when personal data of a user is erased.
+const UserErasedEvent = "user.erased"
+
+const (
+	// privacyPollInterval is the interval of looking for jobs queued by other instances.
+	privacyPollInterval = time.Minute
+	// privacyJobTimeout limits run time of a job, jobs running longer are considered abandoned and run again.
+	privacyJobTimeout = 30 * time.Minute
+	privacyPageSize   = 500
+)
+
+type Privacy interface {
+	// RequestExport queues a job which gathers profile, status history, audit entries and cached copies
+	// of user into a zip archive.
+	RequestExport(ctx context.Context, userID uuid.UUID) (domain.PrivacyJob, error)
+	// RequestErasure queues a job which deletes user, anonymises its personal data, status history reasons and
+	// emails of its invitations, removes its avatar, export archives and cached copies and publishes UserErasedEvent.
+	// audit log is append-only and kept as the record of changes, its personal values are shredded.
+	RequestErasure(ctx context.Context, userID uuid.UUID) (domain.PrivacyJob, error)
+	Job(ctx context.Context, id uuid.UUID) (domain.PrivacyJob, error)
+	Jobs(ctx context.Context, pagination *paginate.Pagination) ([]domain.PrivacyJob, error)
+	// Run runs queued jobs until ctx is done, jobs abandoned by stopped instances are run again.
+	Run(ctx context.Context)
+}
+
+type privacy struct {
+	jobs          repository.PrivacyJob
+	users         repository.User
+	invitations   repository.Invitation
+	userService   User
+	audit         Audit
+	cache         cache.Cache[domain.User]
+	store         storage.Driver
+	erasureEvents bus.EventBus[domain.UserErasure]
+	logger        *slog.Logger
+	// queued wakes Run up when a job is requested on this instance
+	queued chan struct{}
+}
+
+func NewPrivacyService(jobs repository.PrivacyJob, users repository.User, invitations repository.Invitation,
+	userService User, audit Audit, cacheDriver cache.Driver, store storage.Driver, eventDriver bus.Driver,
+	logger *slog.Logger) Privacy {
+	return &privacy{
+		jobs:          jobs,
+		users:         users,
+		invitations:   invitations,
+		userService:   userService,
+		audit:         audit,
+		cache:         cache.New[domain.User](cacheDriver, userCachePrefix, time.Hour),
+		store:         store,
+		erasureEvents: bus.New[domain.UserErasure](eventDriver),
+		logger:        logger,
+		queued:        make(chan struct{}, 1),
+	}
+}
+
+func (p *privacy) RequestExport(ctx context.Context, userID uuid.UUID) (domain.PrivacyJob, error) {
+	return p.request(ctx, domain.PrivacyJobExport, userID, domain.AuditActionUserExport)
+}
+
+func (p *privacy) RequestErasure(ctx context.Context, userID uuid.UUID) (domain.PrivacyJob, error) {
+	if err := mustNotBeActor(ctx, userID); err != nil {
+		return domain.PrivacyJob{}, err
+	}
+	return p.request(ctx, domain.PrivacyJobErasure, userID, domain.AuditActionUserErase)
+}
+
+func (p *privacy) request(ctx context.Context, kind domain.PrivacyJobKind, userID uuid.UUID,
+	action domain.AuditAction) (domain.PrivacyJob, error) {
+	if _, err := p.user(ctx, userID); err != nil {
+		return domain.PrivacyJob{}, p.jobError(err)
+	}
+
+	job := domain.PrivacyJob{
+		ID:        uuid.New(),
+		Kind:      kind,
+		UserID:    userID,
+		Status:    domain.PrivacyJobPending,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		job.RequestedBy = claims.UserID
+	}
+	if err := p.jobs.Create(ctx, job); err != nil {
+		return domain.PrivacyJob{}, p.jobError(err)
+	}
+	p.audit.Record(ctx, action, domain.AuditTargetUser, userID.String(), nil)
+
+	select {
+	case p.queued <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (p *privacy) Job(ctx context.Context, id uuid.UUID) (domain.PrivacyJob, error) {
+	job, err := p.jobs.GetByID(ctx, id)
+	if err != nil {
+		return domain.PrivacyJob{}, p.jobError(err)
+	}
+	return job, nil
+}
+
+func (p *privacy) Jobs(ctx context.Context, pagination *paginate.Pagination) ([]domain.PrivacyJob, error) {
+	jobs, err := p.jobs.List(ctx, pagination)
+	if err != nil {
+		return nil, p.jobError(err)
+	}
+	return jobs, nil
+}
+
+func (p *privacy) jobError(err error) error {
+	if isPaginationError(err) {
+		return paginationError(err)
+	}
+	if errors.Is(err, domain.ErrPrivacyJobNotFound) {
+		return errs.NotFound("privacy job")
+	}
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return errs.NotFound("user")
+	}
+	p.logger.Error("failed to access privacy jobs", slog.Any("error", err))
+	return errs.New(err, errs.CodeInternal)
+}
+
+func (p *privacy) Run(ctx context.Context) {
+	// jobs of all tenants are run, each job runs in scope of its own tenant
+	ctx = tenant.ContextWithAllTenants(ctx)
+	ticker := time.NewTicker(privacyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.runQueued(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.queued:
+		}
+	}
+}
+
+// runQueued runs pending jobs and abandoned running jobs, oldest first.
+func (p *privacy) runQueued(ctx context.Context) {
+	for _, status := range []domain.PrivacyJobStatus{domain.PrivacyJobPending, domain.PrivacyJobRunning} {
+		jobs, err := p.jobs.List(ctx, &paginate.Pagination{
+			Page:    1,
+			PerPage: privacyPageSize,
+			Sort:    []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderAscending}},
+			Filters: []paginate.Filter{{Key: "status", Value: string(status), Condition: paginate.FilterEqual}},
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				p.logger.Error("failed to list queued privacy jobs", slog.Any("error", err))
+			}
+			return
+		}
+		for _, job := range jobs {
+			if ctx.Err() != nil {
+				return
+			}
+			p.run(ctx, job)
+		}
+	}
+}
+
+// run claims and runs job, job interrupted by canceling ctx is left running to be run again once abandoned.
+func (p *privacy) run(ctx context.Context, job domain.PrivacyJob) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	err := p.jobs.Claim(ctx, job.ID, now, now.Add(-privacyJobTimeout))
+	if errors.Is(err, domain.ErrPrivacyJobClaimed) {
+		return
+	}
+	if err != nil {
+		p.logger.Error("failed to claim privacy job", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+		return
+	}
+
+	jobCtx, cancel := context.WithTimeout(tenant.ContextWithID(ctx, job.TenantID), privacyJobTimeout)
+	defer cancel()
+	switch job.Kind {
+	case domain.PrivacyJobExport:
+		job.Archive, err = p.export(jobCtx, job.UserID)
+	case domain.PrivacyJobErasure:
+		err = p.erase(jobCtx, job)
+	}
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	job.Status = domain.PrivacyJobCompleted
+	job.FinishedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err != nil {
+		p.logger.Error("privacy job failed",
+			slog.String("job_id", job.ID.String()),
+			slog.String("kind", string(job.Kind)),
+			slog.Any("error", err),
+		)
+		job.Status = domain.PrivacyJobFailed
+		job.Error = "internal error"
+		if errors.Is(err, domain.ErrUserNotFound) {
+			job.Error = domain.ErrUserNotFound.Error()
+		}
+	}
+	if err = p.jobs.Finish(ctx, job); err != nil {
+		p.logger.Error("failed to finish privacy job", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+	}
+}
+
+// user returns user of id even if it is deleted.
+func (p *privacy) user(ctx context.Context, id uuid.UUID) (domain.User, error) {
+	users, err := p.users.List(ctx, &paginate.Pagination{
+		Page:    1,
+		PerPage: 1,
+		Filters: []paginate.Filter{
+			{Key: "id", Value: id.String(), Condition: paginate.FilterEqual},
+			{Key: userrepo.FilterWithDeleted, Value: "true", Condition: paginate.FilterEqual},
+		},
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	if len(users) == 0 {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+	return users[0], nil
+}
+
+// export returns zip archive of all data stored about user, password hashes are redacted.
+func (p *privacy) export(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	u, err := p.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := p.statusHistory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := p.auditEntries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := p.cachedCopies(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	u.Password = domain.AuditRedacted
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name    string
+		content any
+	}{
+		{"user.json", u},
+		{"status_history.json", history},
+		{"audit_log.json", entries},
+		{"cache.json", cached},
+	} {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *privacy) statusHistory(ctx context.Context, userID uuid.UUID) ([]domain.UserStatusTransition, error) {
+	history := []domain.UserStatusTransition{}
+	for page := 1; ; page++ {
+		transitions, err := p.users.StatusHistory(ctx, userID, &paginate.Pagination{
+			Page:    page,
+			PerPage: privacyPageSize,
+			Sort:    []paginate.Sort{{Field: "created_at", Arrange: paginate.SortOrderAscending}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, transitions...)
+		if len(transitions) < privacyPageSize {
+			return history, nil
+		}
+	}
+}
+
+// auditEntries returns entries targeting user or acted by user in order of sequence.
+func (p *privacy) auditEntries(ctx context.Context, userID uuid.UUID) ([]domain.AuditEntry, error) {
+	entries := []domain.AuditEntry{}
+	seen := make(map[uuid.UUID]bool)
+	for _, filters := range [][]paginate.Filter{
+		{
+			{Key: "target_type", Value: domain.AuditTargetUser, Condition: paginate.FilterEqual},
+			{Key: "target_id", Value: userID.String(), Condition: paginate.FilterEqual},
+		},
+		{{Key: "actor_id", Value: userID.String(), Condition: paginate.FilterEqual}},
+	} {
+		err := p.audit.Export(ctx, &paginate.Pagination{Filters: filters}, func(entry domain.AuditEntry) error {
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	slices.SortFunc(entries, func(a, b domain.AuditEntry) int { return cmp.Compare(a.Sequence, b.Sequence) })
+	return entries, nil
+}
+
+// cachedCopies returns users cached by id and email of u, password hashes are redacted.
+func (p *privacy) cachedCopies(ctx context.Context, u domain.User) (map[string]domain.User, error) {
+	cached := make(map[string]domain.User)
+	for _, key := range []string{tenant.Key(ctx, u.ID.String()), tenant.Key(ctx, u.Email)} {
+		copied, err := p.cache.Get(ctx, key)
+		if errors.Is(err, cache.ErrCacheMissed) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		copied.Password = domain.AuditRedacted
+		cached[userCachePrefix+":"+key] = copied
+	}
+	return cached, nil
+}
+
+// erase deletes user if it is not deleted yet and anonymises it, it is safe to run again after a failure.
+func (p *privacy) erase(ctx context.Context, job domain.PrivacyJob) error {
+	u, err := p.user(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+	if u.Status != domain.UserStatusDeleted {
+		// erasure applies on any version of user
+		if err = p.userService.Delete(ctx, u.ID, 0); err != nil {
+			return err
+		}
+	}
+
+	anonymized := u.Anonymized()
+	anonymized.UpdatedAt = time.Now()
+	invitations, err := p.invitations.Anonymize(ctx, u, anonymized)
+	if err != nil {
+		return err
+	}
+	if err = p.users.Anonymize(ctx, anonymized); err != nil {
+		return err
+	}
+	// pseudonyms of personal values in audit entries are shredded, entries and their hash chain are untouched
+	subjects := []string{domain.AuditSubject(domain.AuditTargetUser, u.ID)}
+	for _, id := range invitations {
+		subjects = append(subjects, domain.AuditSubject(domain.AuditTargetInvitation, id))
+	}
+	for _, subject := range subjects {
+		if err = p.audit.Shred(ctx, subject); err != nil {
+			return err
+		}
+	}
+	if err = p.jobs.ClearArchives(ctx, u.ID); err != nil {
+		return err
+	}
+	if err = deleteAvatar(ctx, p.store, u.TenantID, u.ID); err != nil {
+		return err
+	}
+	for _, key := range []string{tenant.Key(ctx, u.ID.String()), tenant.Key(ctx, u.Email)} {
+		if err = p.cache.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	erasure := domain.UserErasure{TenantID: job.TenantID, UserID: u.ID, JobID: job.ID, At: anonymized.UpdatedAt}
+	if err = p.erasureEvents.Publish(ctx, UserErasedEvent, erasure); err != nil {
+		p.logger.Warn("failed to publish user erased event", slog.Any("error", err))
+	}
+	return nil
+}
@@ -1,13 +1,20 @@
 package service
 
 import (
+	"crypto/rand"
+	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/amirzayi/clean_architect/internal/repository"
 	"github.com/amirzayi/clean_architect/pkg/auth"
 	"github.com/amirzayi/clean_architect/pkg/bus"
 	"github.com/amirzayi/clean_architect/pkg/cache"
+	"github.com/amirzayi/clean_architect/pkg/errs"
 	"github.com/amirzayi/clean_architect/pkg/hash"
+	"github.com/amirzayi/clean_architect/pkg/notify"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/storage"
 )
 
 type Dependencies struct {
@@ -17,17 +24,120 @@ type Dependencies struct {
 	Cache        cache.Driver
 	Event        bus.Driver
 	Logger       *slog.Logger
+	// AuditHashChain links every audit entry to previous one by its hash.
+	AuditHashChain bool
+	// Notifier sends invitations, logs them if nil.
+	Notifier notify.Driver
+	// InvitationLifeTime is the default life time of invitations.
+	InvitationLifeTime time.Duration
+	// InvitationAcceptURL is the page of client accepting invitation tokens.
+	InvitationAcceptURL string
+	// Storage keeps uploaded files, e.g. avatars, keeps them in memory if nil.
+	Storage storage.Driver
+	// FileURLSecret signs download urls of files, a random secret is used if empty
+	// which makes urls invalid after restart and on other instances.
+	FileURLSecret []byte
+	// FileURLLifeTime is the life time of signed download urls.
+	FileURLLifeTime time.Duration
+	// FileDownloadURL is the address of endpoint serving signed urls, /v2/files if empty.
+	FileDownloadURL string
+	// AvatarMaxSize is the maximum size of avatars in bytes.
+	AvatarMaxSize int64
+	// AvatarThumbnailSize is the width and height of square bounding thumbnails of avatars.
+	AvatarThumbnailSize int
 }
 
 type Services struct {
-	Auth Auth
-	User User
+	Auth          Auth
+	User          User
+	Audit         Audit
+	Privacy       Privacy
+	Organization  Organization
+	Invitation    Invitation
+	Group         Group
+	UserAttribute UserAttribute
+	File          File
+	Avatar        Avatar
 }
 
 func NewServices(deps *Dependencies) *Services {
-	userService := NewUserService(deps.Repositories.User, deps.Cache, deps.Event, deps.Logger)
+	auditService := NewAuditService(deps.Repositories.Audit, deps.Repositories.AuditKey, deps.AuditHashChain, deps.Logger)
+	userService := NewUserService(deps.Repositories.User, deps.Repositories.UserAttribute, deps.Hasher, deps.Cache, deps.Event,
+		auditService, deps.Logger)
+	store := deps.Storage
+	if store == nil {
+		store = storage.NewInMemoryDriver()
+	}
+	urlSecret := deps.FileURLSecret
+	if len(urlSecret) == 0 {
+		urlSecret = make([]byte, 32)
+		rand.Read(urlSecret)
+	}
+	fileService := NewFileService(store, storage.NewSigner(urlSecret), deps.FileDownloadURL, deps.FileURLLifeTime, deps.Logger)
+	privacyService := NewPrivacyService(deps.Repositories.PrivacyJob, deps.Repositories.User, deps.Repositories.Invitation,
+		userService, auditService, deps.Cache, store, deps.Event, deps.Logger)
+	groupService := NewGroupService(deps.Repositories.Group, deps.Repositories.User, auditService, deps.Cache, deps.Logger)
+	notifier := deps.Notifier
+	if notifier == nil {
+		notifier = notify.NewLogDriver(deps.Logger)
+	}
 	return &Services{
 		User: userService,
-		Auth: NewAuthService(userService, deps.Hasher, deps.AuthManager),
+		Auth: NewAuthService(userService, deps.Repositories.User, deps.Repositories.Organization,
+			deps.Hasher, deps.AuthManager, deps.Logger),
+		Audit:        auditService,
+		Privacy:      privacyService,
+		Organization: NewOrganizationService(deps.Repositories.Organization, deps.Repositories.User, auditService, deps.Logger),
+		Invitation: NewInvitationService(deps.Repositories.Invitation, userService, notifier, auditService,
+			deps.InvitationLifeTime, deps.InvitationAcceptURL, deps.Logger),
+		Group:         groupService,
+		UserAttribute: NewUserAttributeService(deps.Repositories.UserAttribute, auditService, deps.Logger),
+		File:          fileService,
+		Avatar: NewAvatarService(userService, store, fileService, deps.AvatarMaxSize, deps.AvatarThumbnailSize,
+			auditService, deps.Logger),
+	}
+}
+
+// isPaginationError reports whether err is of pagination given by client, e.g. tampered cursors, unknown sort fields
+// or invalid filter expressions and values.
+func isPaginationError(err error) bool {
+	var (
+		fieldErr  *paginate.FieldError
+		syntaxErr *paginate.SyntaxError
+		filterErr *paginate.FilterError
+	)
+	return errors.Is(err, paginate.ErrInvalidCursor) || errors.Is(err, paginate.ErrCursorNotSupported) ||
+		errors.As(err, &fieldErr) || errors.As(err, &syntaxErr) || errors.As(err, &filterErr)
+}
+
+// paginationError returns pagination error as invalid argument, fields which are not allowed are detailed by allowed ones
+// invalid filter expressions by position of error and invalid filters by conditions or values allowed on their field.
+func paginationError(err error) error {
+	var syntaxErr *paginate.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return errs.New(err, errs.CodeInvalidArgument, map[string]any{
+			"parameter": "filter",
+			"position":  syntaxErr.Pos,
+			"message":   syntaxErr.Msg,
+		})
+	}
+	var filterErr *paginate.FilterError
+	if errors.As(err, &filterErr) {
+		return errs.New(err, errs.CodeInvalidArgument, map[string]any{
+			"field":     filterErr.Field,
+			"condition": filterErr.Condition,
+			"value":     filterErr.Value,
+			"allowed":   filterErr.Allowed,
+			"message":   filterErr.Msg,
+		})
+	}
+	var fieldErr *paginate.FieldError
+	if errors.As(err, &fieldErr) {
+		return errs.New(err, errs.CodeInvalidArgument, map[string]any{
+			"parameter": fieldErr.Param,
+			"field":     fieldErr.Field,
+			"allowed":   fieldErr.Allowed,
+		})
 	}
+	return errs.New(err, errs.CodeInvalidArgument)
 }
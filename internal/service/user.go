@@ -3,45 +3,91 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"iter"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/amirzayi/clean_architect/internal/domain"
 	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/auth"
 	"github.com/amirzayi/clean_architect/pkg/bus"
 	"github.com/amirzayi/clean_architect/pkg/cache"
 	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/hash"
 	"github.com/amirzayi/clean_architect/pkg/paginate"
 	"github.com/amirzayi/clean_architect/pkg/synq"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 )
 
 type User interface {
+	// Create creates a new user of given plain password, which is hashed before saving.
 	Create(ctx context.Context, user domain.User) (domain.User, error)
 	GetByID(ctx context.Context, id uuid.UUID) (domain.User, error)
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	List(ctx context.Context, pagination *paginate.Pagination) ([]domain.User, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete soft deletes user, version is the expected version of user which zero means no version check.
+	Delete(ctx context.Context, id uuid.UUID, version int64) error
 	Update(ctx context.Context, user domain.User) error
+	// Patch changes given fields of user, role must be changed via ChangeRole
+	// and status via status operations.
+	Patch(ctx context.Context, id uuid.UUID, patch domain.UserPatch) (domain.User, error)
+	ChangeRole(ctx context.Context, id uuid.UUID, role domain.UserRole, version int64) (domain.User, error)
+	// Ban, Unban, Activate and Deactivate move user through status state machine,
+	// version is the expected version of user which zero means no version check.
+	Ban(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error)
+	Unban(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error)
+	Activate(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error)
+	Deactivate(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error)
+	StatusHistory(ctx context.Context, id uuid.UUID, pagination *paginate.Pagination) ([]domain.UserStatusTransition, error)
+	// Import creates users of rows and reports result of each row, failed rows do not stop importing.
+	// dryRun validates rows against stored users without creating them.
+	Import(ctx context.Context, rows iter.Seq2[UserImportRow, error], dryRun bool, report func(UserImportResult)) (UserImportSummary, error)
+	// Export calls fn for every user matching filters of pagination, oldest first unless sorted by pagination.
+	// page of pagination is ignored.
+	Export(ctx context.Context, pagination *paginate.Pagination, fn func(domain.User) error) error
+	// Restore moves deleted user to active status, version is checked same as Delete.
+	Restore(ctx context.Context, id uuid.UUID, version int64) error
+	Purge(ctx context.Context, id uuid.UUID) error
+	PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error)
 }
 
+// UserStatusChangedEvent is the subject of domain.UserStatusTransition events published on every status change.
+const UserStatusChangedEvent = "user.status.changed"
+
+// userCachePrefix is the prefix of cached users, they are cached by both id and email
+// under key of their tenant, see tenant.Key.
+const userCachePrefix = "user"
+
 type user struct {
-	db       repository.User
-	cache    cache.Cache[domain.User]
-	eventBus bus.EventBus[domain.User]
-	logger   *slog.Logger
-	dbcache  synq.CacheSync[domain.User]
+	db           repository.User
+	attributes   repository.UserAttribute
+	hasher       hash.PasswordHasher
+	cache        cache.Cache[domain.User]
+	eventBus     bus.EventBus[domain.User]
+	statusEvents bus.EventBus[domain.UserStatusTransition]
+	audit        Audit
+	logger       *slog.Logger
+	dbcache      synq.CacheSync[domain.User]
 }
 
-func NewUserService(db repository.User, cacheDriver cache.Driver, eventDriver bus.Driver, logger *slog.Logger) User {
-	cache := cache.New[domain.User](cacheDriver, "user", time.Hour)
+func NewUserService(db repository.User, attributes repository.UserAttribute, hasher hash.PasswordHasher,
+	cacheDriver cache.Driver, eventDriver bus.Driver, audit Audit, logger *slog.Logger) User {
+	cache := cache.New[domain.User](cacheDriver, userCachePrefix, time.Hour)
 	return &user{
-		db:       db,
-		cache:    cache,
-		eventBus: bus.New[domain.User](eventDriver),
-		logger:   logger,
-		dbcache:  synq.New(cache, logger),
+		db:           db,
+		attributes:   attributes,
+		hasher:       hasher,
+		cache:        cache,
+		eventBus:     bus.New[domain.User](eventDriver),
+		statusEvents: bus.New[domain.UserStatusTransition](eventDriver),
+		audit:        audit,
+		logger:       logger,
+		dbcache:      synq.New(cache, logger),
 	}
 }
 
@@ -49,8 +95,21 @@ func (u *user) Create(ctx context.Context, user domain.User) (domain.User, error
 	user.ID = uuid.New()
 	user.Status = domain.UsereStatusNew
 	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	user.Version = 1
+	user.TenantID = tenant.FromContext(ctx)
+
+	attributes, err := u.validateAttributes(ctx, user.ID, user.Attributes, !isSelfService(ctx))
+	if err != nil {
+		return domain.User{}, err
+	}
+	user.Attributes = attributes
+	if user.Password, err = u.hasher.Hash(user.Password); err != nil {
+		u.logger.Error("failed to create hashed password", slog.Any("error", err))
+		return domain.User{}, errs.New(err, errs.CodeInternal)
+	}
 
-	err := u.dbcache.SetAsync(user.ID.String(), user, func() error {
+	err = u.dbcache.SetAsync(tenant.Key(ctx, user.ID.String()), user, func() error {
 		return u.db.Create(ctx, user)
 	})
 	if err != nil {
@@ -60,11 +119,13 @@ func (u *user) Create(ctx context.Context, user domain.User) (domain.User, error
 		u.logger.Error("failed to create user", slog.Any("error", err))
 		return domain.User{}, errs.New(err, errs.CodeInternal)
 	}
+	u.audit.Record(ctx, domain.AuditActionUserCreate, domain.AuditTargetUser, user.ID.String(),
+		domain.UserAuditChanges(domain.User{}, user, u.pseudonymizer(ctx, user.ID)))
 	return user, nil
 }
 
 func (u *user) GetByEmail(ctx context.Context, email string) (domain.User, error) {
-	user, err := u.dbcache.GetAsync(ctx, email, func() (domain.User, error) {
+	user, err := u.dbcache.GetAsync(ctx, tenant.Key(ctx, email), func() (domain.User, error) {
 		return u.db.GetByEmail(ctx, email)
 	})
 	if err != nil {
@@ -78,7 +139,13 @@ func (u *user) GetByEmail(ctx context.Context, email string) (domain.User, error
 }
 
 func (u *user) List(ctx context.Context, pagination *paginate.Pagination) ([]domain.User, error) {
+	if err := u.normalizeAttributeQuery(ctx, pagination); err != nil {
+		return nil, err
+	}
 	users, err := u.db.List(ctx, pagination)
+	if isPaginationError(err) {
+		return nil, paginationError(err)
+	}
 	if err != nil {
 		u.logger.Error("failed to list users", slog.Any("error", err))
 		return nil, errs.New(err, errs.CodeInternal)
@@ -86,36 +153,408 @@ func (u *user) List(ctx context.Context, pagination *paginate.Pagination) ([]dom
 	return users, nil
 }
 
-func (u *user) Delete(ctx context.Context, id uuid.UUID) error {
-	err := u.dbcache.DeleteAsync(id.String(), func() error {
-		return u.db.Delete(ctx, id)
+func (u *user) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	user, err := u.db.GetByID(ctx, id)
+	if err != nil {
+		return u.updateError(err)
+	}
+	if version != 0 && version != user.Version {
+		return errs.New(domain.ErrConcurrentModification, errs.CodeConflict)
+	}
+	// deleted user must not login via cached user by email, changeStatus drops it
+	_, err = u.changeStatus(ctx, user, domain.UserStatusDeleted, "", domain.AuditActionUserDelete)
+	return err
+}
+
+// Restore applies only on the loaded version of deleted user, which must be the expected version if it is given.
+func (u *user) Restore(ctx context.Context, id uuid.UUID, version int64) error {
+	user, err := u.db.GetDeletedByID(ctx, id)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return errs.NotFound("deleted user")
+	}
+	if err != nil {
+		return u.updateError(err)
+	}
+	if version != 0 && version != user.Version {
+		return errs.New(domain.ErrConcurrentModification, errs.CodeConflict)
+	}
+	_, err = u.changeStatus(ctx, user, domain.UserStatusActive, "", domain.AuditActionUserRestore)
+	return err
+}
+
+func (u *user) Purge(ctx context.Context, id uuid.UUID) error {
+	err := u.dbcache.DeleteAsync(tenant.Key(ctx, id.String()), func() error {
+		return u.db.Purge(ctx, id)
 	})
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
-			return errs.NotFound("user")
+			return errs.NotFound("deleted user")
 		}
-		u.logger.Error("failed to delete user", slog.Any("error", err))
+		u.logger.Error("failed to purge user", slog.Any("error", err))
 		return errs.New(err, errs.CodeInternal)
 	}
+	u.audit.Record(ctx, domain.AuditActionUserPurge, domain.AuditTargetUser, id.String(), nil)
 	return nil
 }
 
+func (u *user) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	count, err := u.db.PurgeDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		u.logger.Error("failed to purge deleted users", slog.Any("error", err))
+		return 0, errs.New(err, errs.CodeInternal)
+	}
+	return count, nil
+}
+
+func (u *user) deleteCacheAsync(key string) {
+	go func() {
+		if err := u.cache.Delete(context.Background(), key); err != nil {
+			u.logger.Error("failed to delete cache", slog.String("key", key), slog.Any("error", err))
+		}
+	}()
+}
+
+// pseudonymizer returns pseudonymizer of personal values of user in audit changes.
+func (u *user) pseudonymizer(ctx context.Context, id uuid.UUID) domain.AuditPseudonymizer {
+	return u.audit.Pseudonymizer(ctx, domain.AuditSubject(domain.AuditTargetUser, id))
+}
+
+// Update replaces user fields, empty password keeps current password and nil attributes keep current attributes.
 func (u *user) Update(ctx context.Context, user domain.User) error {
-	err := u.dbcache.SetAsync(user.ID.String(), user, func() error {
-		return u.db.Update(ctx, user)
-	})
+	current, err := u.db.GetByID(ctx, user.ID)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			return errs.NotFound("user")
+		return u.updateError(err)
+	}
+	if user.Attributes == nil {
+		user.Attributes = current.Attributes
+	} else if user.Attributes, err = u.validateAttributes(ctx, user.ID, user.Attributes, true); err != nil {
+		return err
+	}
+	if user.Version == 0 {
+		// kept fields are of loaded user, so it applies only on its version
+		user.Version = current.Version
+	}
+	user.Password, err = u.hashOrKeepPassword(user.Password, current.Password)
+	if err == nil {
+		user.UpdatedAt = time.Now()
+		// given user is partial and its version changes, so cached user invalidated instead of replaced
+		err = u.dbcache.DeleteAsync(tenant.Key(ctx, user.ID.String()), func() error {
+			return u.db.Update(ctx, user)
+		})
+	}
+	if err != nil {
+		return u.updateError(err)
+	}
+	u.deleteCacheAsync(tenant.Key(ctx, current.Email))
+
+	updated := current
+	updated.Name, updated.PhoneNumber, updated.Email, updated.Password = user.Name, user.PhoneNumber, user.Email, user.Password
+	updated.Attributes = user.Attributes
+	u.audit.Record(ctx, domain.AuditActionUserUpdate, domain.AuditTargetUser, user.ID.String(),
+		domain.UserAuditChanges(current, updated, u.pseudonymizer(ctx, current.ID)))
+	return nil
+}
+
+func (u *user) hashOrKeepPassword(password, current string) (string, error) {
+	if password == "" {
+		return current, nil
+	}
+	return u.hasher.Hash(password)
+}
+
+func (u *user) Patch(ctx context.Context, id uuid.UUID, patch domain.UserPatch) (domain.User, error) {
+	if patch.Role != nil {
+		return domain.User{}, errs.New(errors.New("role could not be patched"), errs.CodeInvalidArgument)
+	}
+	if patch.Password != nil {
+		hashed, err := u.hasher.Hash(*patch.Password)
+		if err != nil {
+			u.logger.Error("failed to create hashed password", slog.Any("error", err))
+			return domain.User{}, errs.New(err, errs.CodeInternal)
+		}
+		patch.Password = &hashed
+	}
+	if patch.Attributes != nil {
+		attributes, err := u.validateAttributes(ctx, id, patch.Attributes, true)
+		if err != nil {
+			return domain.User{}, err
+		}
+		if attributes == nil {
+			// patch replaces all attributes, so an empty one removes them
+			attributes = map[string]any{}
+		}
+		patch.Attributes = attributes
+	}
+	return u.patch(ctx, id, patch, domain.AuditActionUserUpdate)
+}
+
+type selfServiceKey struct{}
+
+// withSelfService marks ctx of users creating themselves, e.g. by registering or accepting invitations,
+// who could not give custom attributes, so required attributes are not enforced on them.
+func withSelfService(ctx context.Context) context.Context {
+	return context.WithValue(ctx, selfServiceKey{}, true)
+}
+
+func isSelfService(ctx context.Context) bool {
+	selfService, _ := ctx.Value(selfServiceKey{}).(bool)
+	return selfService
+}
+
+// validateAttributes validates custom attributes of user against definitions of its tenant and returns them normalized,
+// values of unique attributes must not belong to other users.
+func (u *user) validateAttributes(ctx context.Context, id uuid.UUID, attributes map[string]any, required bool) (map[string]any, error) {
+	definitions, err := u.attributes.List(ctx)
+	if err != nil {
+		u.logger.Error("failed to list user attributes", slog.Any("error", err))
+		return nil, errs.New(err, errs.CodeInternal)
+	}
+	if !required {
+		for i := range definitions {
+			definitions[i].Required = false
+		}
+	}
+	normalized, err := domain.ValidateUserAttributes(definitions, attributes)
+	if err != nil {
+		return nil, errs.New(err, errs.CodeInvalidArgument)
+	}
+
+	for _, definition := range definitions {
+		value, ok := normalized[definition.Name]
+		if !definition.Unique || !ok {
+			continue
+		}
+		taken, err := u.db.AttributeTaken(ctx, definition.Name, value, id)
+		if err != nil {
+			u.logger.Error("failed to check user attribute", slog.Any("error", err))
+			return nil, errs.New(err, errs.CodeInternal)
+		}
+		if taken {
+			return nil, errs.New(fmt.Errorf("%w: %q", domain.ErrUserAttributeTaken, definition.Name), errs.CodeExisted)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeAttributeQuery validates filters, filter expression and sorts of pagination on custom attributes and rewrites filter values
+// in their normalized form, so repositories compare them as stored. filters of undefined attributes are left
+// to be ignored same as unknown fields, but sorting by them is rejected.
+func (u *user) normalizeAttributeQuery(ctx context.Context, pagination *paginate.Pagination) error {
+	var definitions []domain.UserAttribute
+	definition := func(key string) (domain.UserAttribute, bool, error) {
+		name, ok := strings.CutPrefix(key, domain.UserAttributePrefix)
+		if !ok {
+			return domain.UserAttribute{}, false, nil
+		}
+		if definitions == nil {
+			var err error
+			if definitions, err = u.attributes.List(ctx); err != nil {
+				u.logger.Error("failed to list user attributes", slog.Any("error", err))
+				return domain.UserAttribute{}, false, errs.New(err, errs.CodeInternal)
+			}
+		}
+		i := slices.IndexFunc(definitions, func(d domain.UserAttribute) bool { return d.Name == name })
+		if i < 0 {
+			return domain.UserAttribute{}, false, nil
+		}
+		return definitions[i], true, nil
+	}
+
+	for _, sort := range pagination.Sort {
+		_, ok, err := definition(sort.Field)
+		if err != nil {
+			return err
+		}
+		if !ok && strings.HasPrefix(sort.Field, domain.UserAttributePrefix) {
+			return errs.New(fmt.Errorf("%w: %q is not defined", domain.ErrInvalidUserAttribute, sort.Field), errs.CodeInvalidArgument)
 		}
-		u.logger.Error("failed to update user", slog.Any("error", err))
-		return errs.New(err, errs.CodeInternal)
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return paginationError(err)
+	}
+	// comparisons of expression are kept by pagination, so they are normalized in place same as filters
+	filters := make([]*paginate.Filter, 0, len(pagination.Filters))
+	for i := range pagination.Filters {
+		filters = append(filters, &pagination.Filters[i])
+	}
+	for _, comparison := range paginate.Comparisons(expr) {
+		filters = append(filters, &comparison.Filter)
+	}
+	for _, filter := range filters {
+		attribute, ok, err := definition(filter.Key)
+		if err != nil {
+			return err
+		}
+		if !ok || filter.Condition == paginate.FilterLike {
+			continue
+		}
+		values := strings.Split(filter.Value, ",")
+		for j, value := range values {
+			v, err := attribute.ParseValue(value)
+			if err != nil {
+				return errs.New(err, errs.CodeInvalidArgument)
+			}
+			values[j] = domain.FormatUserAttributeValue(v)
+		}
+		filter.Value = strings.Join(values, ",")
 	}
 	return nil
 }
 
+func (u *user) ChangeRole(ctx context.Context, id uuid.UUID, role domain.UserRole, version int64) (domain.User, error) {
+	if !role.IsValid() {
+		return domain.User{}, errs.New(fmt.Errorf("invalid role %q", role), errs.CodeInvalidArgument)
+	}
+	if err := mustNotBeActor(ctx, id); err != nil {
+		return domain.User{}, err
+	}
+	return u.patch(ctx, id, domain.UserPatch{Role: &role, Version: version}, domain.AuditActionUserRoleChange)
+}
+
+func (u *user) Ban(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error) {
+	return u.statusOperation(ctx, id, domain.UserStatusBanned, reason, version)
+}
+
+func (u *user) Unban(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error) {
+	return u.statusOperation(ctx, id, domain.UserStatusActive, reason, version, domain.UserStatusBanned)
+}
+
+// Activate activates new or deactivated users, banned users must be unbanned instead.
+func (u *user) Activate(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error) {
+	return u.statusOperation(ctx, id, domain.UserStatusActive, reason, version, domain.UsereStatusNew, domain.UserStatusInactive)
+}
+
+func (u *user) Deactivate(ctx context.Context, id uuid.UUID, reason string, version int64) (domain.User, error) {
+	return u.statusOperation(ctx, id, domain.UserStatusInactive, reason, version)
+}
+
+func (u *user) StatusHistory(ctx context.Context, id uuid.UUID, pagination *paginate.Pagination) ([]domain.UserStatusTransition, error) {
+	history, err := u.db.StatusHistory(ctx, id, pagination)
+	if isPaginationError(err) {
+		return nil, paginationError(err)
+	}
+	if err != nil {
+		u.logger.Error("failed to list user status history", slog.Any("error", err))
+		return nil, errs.New(err, errs.CodeInternal)
+	}
+	return history, nil
+}
+
+// statusOperation moves user to given status, from limits source statuses more than status state machine.
+func (u *user) statusOperation(ctx context.Context, id uuid.UUID, to domain.UserStatus, reason string, version int64,
+	from ...domain.UserStatus) (domain.User, error) {
+	if err := mustNotBeActor(ctx, id); err != nil {
+		return domain.User{}, err
+	}
+	current, err := u.db.GetByID(ctx, id)
+	if err != nil {
+		return domain.User{}, u.updateError(err)
+	}
+	if version != 0 && version != current.Version {
+		return domain.User{}, errs.New(domain.ErrConcurrentModification, errs.CodeConflict)
+	}
+	if len(from) > 0 && !slices.Contains(from, current.Status) {
+		err = fmt.Errorf("%w from %s to %s", domain.ErrInvalidStatusTransition, current.Status, to)
+		return domain.User{}, errs.New(err, errs.CodeConflict)
+	}
+	return u.changeStatus(ctx, current, to, reason, domain.AuditActionUserStatusChange)
+}
+
+// changeStatus moves user to given status through status state machine, keeps it in status history,
+// publishes UserStatusChangedEvent and records it as given audit action.
+func (u *user) changeStatus(ctx context.Context, current domain.User, to domain.UserStatus, reason string,
+	action domain.AuditAction) (domain.User, error) {
+	var actorID uuid.UUID
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		actorID = claims.UserID
+	}
+	transition, err := current.TransitionStatus(to, reason, actorID, time.Now())
+	if err != nil {
+		return domain.User{}, errs.New(err, errs.CodeConflict)
+	}
+
+	err = u.dbcache.DeleteAsync(tenant.Key(ctx, current.ID.String()), func() error {
+		return u.db.ChangeStatus(ctx, transition, current.Version)
+	})
+	if err != nil {
+		return domain.User{}, u.updateError(err)
+	}
+	if current.Email != "" {
+		u.deleteCacheAsync(tenant.Key(ctx, current.Email))
+	}
+	if err = u.statusEvents.Publish(ctx, UserStatusChangedEvent, transition); err != nil {
+		u.logger.Warn("failed to publish user status changed event", slog.Any("error", err))
+	}
+
+	user := current
+	user.Status = to
+	user.UpdatedAt = transition.At
+	user.Version++
+	switch {
+	case to == domain.UserStatusDeleted:
+		user.DeletedAt = transition.At
+	case current.Status == domain.UserStatusDeleted:
+		user.DeletedAt = time.Time{}
+	}
+	u.audit.Record(ctx, action, domain.AuditTargetUser, user.ID.String(),
+		domain.UserAuditChanges(current, user, u.pseudonymizer(ctx, current.ID)))
+	return user, nil
+}
+
+// mustNotBeActor prevents admins from changing their own role or status and locking themselves out.
+func mustNotBeActor(ctx context.Context, id uuid.UUID) error {
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.UserID == id {
+		return errs.New(errors.New("users could not change their own role or status"), errs.CodeForbiddenAccess)
+	}
+	return nil
+}
+
+// patch applies patch, records it as given audit action and returns patched user.
+func (u *user) patch(ctx context.Context, id uuid.UUID, patch domain.UserPatch, action domain.AuditAction) (domain.User, error) {
+	current, err := u.db.GetByID(ctx, id)
+	if err != nil {
+		return domain.User{}, u.updateError(err)
+	}
+	if patch.IsEmpty() {
+		return current, nil
+	}
+
+	if patch.Version == 0 {
+		patch.Version = current.Version
+	}
+	patch.UpdatedAt = time.Now()
+	err = u.dbcache.DeleteAsync(tenant.Key(ctx, id.String()), func() error {
+		return u.db.Patch(ctx, id, patch)
+	})
+	if err != nil {
+		return domain.User{}, u.updateError(err)
+	}
+	u.deleteCacheAsync(tenant.Key(ctx, current.Email))
+
+	user := patch.Apply(current)
+	user.Version++
+	u.audit.Record(ctx, action, domain.AuditTargetUser, id.String(),
+		domain.UserAuditChanges(current, user, u.pseudonymizer(ctx, current.ID)))
+	return user, nil
+}
+
+func (u *user) updateError(err error) error {
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return errs.NotFound("user")
+	}
+	if errors.Is(err, domain.ErrUserAlreadyExists) {
+		return errs.New(err, errs.CodeExisted)
+	}
+	if errors.Is(err, domain.ErrConcurrentModification) {
+		return errs.New(err, errs.CodeConflict)
+	}
+	u.logger.Error("failed to update user", slog.Any("error", err))
+	return errs.New(err, errs.CodeInternal)
+}
+
 func (u *user) GetByID(ctx context.Context, id uuid.UUID) (domain.User, error) {
-	user, err := u.dbcache.GetAsync(ctx, id.String(), func() (domain.User, error) {
+	user, err := u.dbcache.GetAsync(ctx, tenant.Key(ctx, id.String()), func() (domain.User, error) {
 		return u.db.GetByID(ctx, id)
 	})
 	if err != nil {
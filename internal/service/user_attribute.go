@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/internal/repository"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+)
+
+// UserAttribute manages custom attributes which admins define for users of the organization of context,
+// values of users are validated against them, see domain.ValidateUserAttributes.
+type UserAttribute interface {
+	Create(ctx context.Context, attribute domain.UserAttribute) (domain.UserAttribute, error)
+	// List returns all attributes, ordered by name.
+	List(ctx context.Context) ([]domain.UserAttribute, error)
+	// Delete removes attribute and its values of all users.
+	Delete(ctx context.Context, name string) error
+}
+
+type userAttribute struct {
+	db     repository.UserAttribute
+	audit  Audit
+	logger *slog.Logger
+}
+
+func NewUserAttributeService(db repository.UserAttribute, audit Audit, logger *slog.Logger) UserAttribute {
+	return &userAttribute{
+		db:     db,
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+func (a *userAttribute) Create(ctx context.Context, attribute domain.UserAttribute) (domain.UserAttribute, error) {
+	if err := attribute.Validate(); err != nil {
+		return domain.UserAttribute{}, errs.New(err, errs.CodeInvalidArgument)
+	}
+	attribute.Values = slices.Compact(slices.Sorted(slices.Values(attribute.Values)))
+	attribute.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := a.db.Create(ctx, attribute); err != nil {
+		return domain.UserAttribute{}, a.error(err)
+	}
+	a.audit.Record(ctx, domain.AuditActionUserAttributeCreate, domain.AuditTargetUserAttribute, attribute.Name,
+		[]domain.AuditChange{{Field: "type", After: attribute.Type}, {Field: "required", After: attribute.Required},
+			{Field: "unique", After: attribute.Unique}, {Field: "values", After: attribute.Values}})
+	return attribute, nil
+}
+
+func (a *userAttribute) List(ctx context.Context) ([]domain.UserAttribute, error) {
+	attributes, err := a.db.List(ctx)
+	if err != nil {
+		return nil, a.error(err)
+	}
+	return attributes, nil
+}
+
+func (a *userAttribute) Delete(ctx context.Context, name string) error {
+	if err := a.db.Delete(ctx, name); err != nil {
+		return a.error(err)
+	}
+	a.audit.Record(ctx, domain.AuditActionUserAttributeDelete, domain.AuditTargetUserAttribute, name, nil)
+	return nil
+}
+
+func (a *userAttribute) error(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUserAttributeNotFound):
+		return errs.NotFound("user attribute")
+	case errors.Is(err, domain.ErrUserAttributeAlreadyExists):
+		return errs.New(err, errs.CodeExisted)
+	}
+	a.logger.Error("failed to access user attributes", slog.Any("error", err))
+	return errs.New(err, errs.CodeInternal)
+}
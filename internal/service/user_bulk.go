@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/amirzayi/clean_architect/internal/domain"
+	"github.com/amirzayi/clean_architect/pkg/errs"
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+const userExportPageSize = 500
+
+// UserImportRow is a row of bulk import with plain password, Err is set for rows which are malformed
+// before reaching service.
+type UserImportRow struct {
+	Line int
+	User domain.User
+	Err  error
+}
+
+// UserImportResult is the result of importing a row, Err is nil for imported rows.
+type UserImportResult struct {
+	Line int
+	ID   uuid.UUID
+	Err  error
+}
+
+type UserImportSummary struct {
+	Total    int
+	Imported int
+	Failed   int
+	DryRun   bool
+}
+
+func (u *user) Import(ctx context.Context, rows iter.Seq2[UserImportRow, error], dryRun bool,
+	report func(UserImportResult)) (UserImportSummary, error) {
+	summary := UserImportSummary{DryRun: dryRun}
+	// users of batch are checked against each other too, dry run stores nothing to conflict with
+	seen := make(map[string]int)
+
+	for row, err := range rows {
+		if err != nil {
+			return summary, err
+		}
+		summary.Total++
+
+		result := UserImportResult{Line: row.Line, Err: row.Err}
+		if result.Err == nil {
+			var user domain.User
+			user, result.Err = u.importUser(ctx, row.User, dryRun, seen)
+			result.ID = user.ID
+		}
+		if result.Err != nil {
+			summary.Failed++
+		} else {
+			summary.Imported++
+			seen[row.User.Email] = row.Line
+			if row.User.PhoneNumber != "" {
+				seen[row.User.PhoneNumber] = row.Line
+			}
+		}
+		if report != nil {
+			report(result)
+		}
+	}
+	return summary, nil
+}
+
+func (u *user) importUser(ctx context.Context, user domain.User, dryRun bool, seen map[string]int) (domain.User, error) {
+	if user.Role == "" {
+		user.Role = domain.UserRoleNormal
+	}
+	if !user.Role.IsValid() {
+		return domain.User{}, errs.New(fmt.Errorf("invalid role %q", user.Role), errs.CodeInvalidArgument)
+	}
+	for _, key := range []string{user.Email, user.PhoneNumber} {
+		if line, ok := seen[key]; ok && key != "" {
+			return domain.User{}, errs.New(fmt.Errorf("%w, same as line %d", domain.ErrUserAlreadyExists, line), errs.CodeExisted)
+		}
+	}
+
+	if dryRun {
+		if _, err := u.validateAttributes(ctx, uuid.Nil, user.Attributes, true); err != nil {
+			return domain.User{}, err
+		}
+		// phone numbers are checked only by creating users
+		_, err := u.db.GetByEmail(ctx, user.Email)
+		if err == nil {
+			return domain.User{}, errs.New(domain.ErrUserAlreadyExists, errs.CodeExisted)
+		}
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			u.logger.Error("failed to get user by email", slog.Any("error", err))
+			return domain.User{}, errs.New(err, errs.CodeInternal)
+		}
+		return user, nil
+	}
+	return u.Create(ctx, user)
+}
+
+func (u *user) Export(ctx context.Context, pagination *paginate.Pagination, fn func(domain.User) error) error {
+	if err := u.normalizeAttributeQuery(ctx, pagination); err != nil {
+		return err
+	}
+	sort := pagination.Sort
+	if len(sort) == 0 {
+		sort = []paginate.Sort{
+			{Field: "created_at", Arrange: paginate.SortOrderAscending},
+			{Field: "id", Arrange: paginate.SortOrderAscending},
+		}
+	}
+
+	for page := 1; ; page++ {
+		users, err := u.db.List(ctx, &paginate.Pagination{
+			Page:    page,
+			PerPage: userExportPageSize,
+			Sort:    sort,
+			Filters: pagination.Filters,
+		})
+		if isPaginationError(err) {
+			return paginationError(err)
+		}
+		if err != nil {
+			u.logger.Error("failed to export users", slog.Any("error", err))
+			return errs.New(err, errs.CodeInternal)
+		}
+		for _, user := range users {
+			if err = fn(user); err != nil {
+				return err
+			}
+		}
+		if len(users) < userExportPageSize {
+			return nil
+		}
+	}
+}
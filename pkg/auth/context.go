@@ -0,0 +1,16 @@
+package auth
+
+import "context"
+
+type claimsKey struct{}
+
+// ContextWithClaims returns a copy of ctx which carries claims of authenticated user.
+func ContextWithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns claims of authenticated user, ok is false for unauthenticated requests.
+func ClaimsFromContext(ctx context.Context) (claims Claims, ok bool) {
+	claims, ok = ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
@@ -27,7 +27,7 @@ func NewJWT(signingMethod jwt.SigningMethod, key []byte, duration time.Duration)
 	}
 }
 
-func (j *jwtManager) CreateToken(userID uuid.UUID, userRole string) (string, error) {
+func (j *jwtManager) CreateToken(userID uuid.UUID, userRole string, tenantID uuid.UUID) (string, error) {
 	token, err := jwt.NewWithClaims(j.signingMethod, jwtClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.duration)),
@@ -35,6 +35,7 @@ func (j *jwtManager) CreateToken(userID uuid.UUID, userRole string) (string, err
 		Claims: Claims{
 			UserID:   userID,
 			UserRole: userRole,
+			TenantID: tenantID,
 		},
 	}).SignedString(j.key)
 	if err != nil {
@@ -15,7 +15,8 @@ func TestJWT(t *testing.T) {
 
 	id := uuid.New()
 	role := "Admin"
-	token, err := m.CreateToken(id, role)
+	tenantID := uuid.New()
+	token, err := m.CreateToken(id, role, tenantID)
 	require.NoError(t, err)
 
 	claims, err := m.VerifyToken(token)
@@ -23,6 +24,7 @@ func TestJWT(t *testing.T) {
 
 	require.Equal(t, id, claims.UserID)
 	require.Equal(t, role, claims.UserRole)
+	require.Equal(t, tenantID, claims.TenantID)
 }
 
 func TestJWTValidation(t *testing.T) {
@@ -30,7 +32,8 @@ func TestJWTValidation(t *testing.T) {
 
 	id := uuid.New()
 	role := "Admin"
-	token, err := m.CreateToken(id, role)
+	tenantID := uuid.New()
+	token, err := m.CreateToken(id, role, tenantID)
 	require.NoError(t, err)
 
 	claims, err := m.VerifyToken(token)
@@ -44,12 +47,13 @@ func TestJWTValidation_DifferentSignMethod(t *testing.T) {
 
 	id := uuid.New()
 	role := "Admin"
-	token, err := m.CreateToken(id, role)
+	tenantID := uuid.New()
+	token, err := m.CreateToken(id, role, tenantID)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
 	newM := auth.NewJWT(jwt.SigningMethodHS256, []byte("sample_key"), time.Hour)
-	newToken, err := newM.CreateToken(id, role)
+	newToken, err := newM.CreateToken(id, role, tenantID)
 	require.NoError(t, err)
 
 	claims, err := m.VerifyToken(newToken)
@@ -1,15 +1,27 @@
 package auth
 
 import (
+	"slices"
+
 	"github.com/google/uuid"
 )
 
+// Claims of authenticated user, TenantID is the organization token is issued for,
+// tokens without it belong to the default tenant. Roles are granted to user by its groups besides UserRole,
+// they are resolved for every request and never kept in tokens, so changes of groups take effect at once.
 type Claims struct {
 	UserID   uuid.UUID `json:"uid"`
 	UserRole string    `json:"role"`
+	TenantID uuid.UUID `json:"tid"`
+	Roles    []string  `json:"-"`
+}
+
+// HasRole reports whether role is the role of user or one of roles granted by its groups.
+func (c Claims) HasRole(role string) bool {
+	return c.UserRole == role || slices.Contains(c.Roles, role)
 }
 
 type Manager interface {
-	CreateToken(userID uuid.UUID, userRole string) (token string, err error)
+	CreateToken(userID uuid.UUID, userRole string, tenantID uuid.UUID) (token string, err error)
 	VerifyToken(token string) (claims Claims, err error)
 }
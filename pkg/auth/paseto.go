@@ -19,13 +19,14 @@ func NewPaseto(key []byte, duration time.Duration) Manager {
 	}
 }
 
-func (p *pasetoManager) CreateToken(userID uuid.UUID, userRole string) (string, error) {
+func (p *pasetoManager) CreateToken(userID uuid.UUID, userRole string, tenantID uuid.UUID) (string, error) {
 	jsonToken := paseto.JSONToken{
 		Expiration: time.Now().Add(p.duration),
 	}
 	claims := Claims{
 		UserID:   userID,
 		UserRole: userRole,
+		TenantID: tenantID,
 	}
 	pasetoMaker := paseto.NewV2()
 	token, err := pasetoMaker.Encrypt(p.key, jsonToken, claims)
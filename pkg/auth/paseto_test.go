@@ -15,8 +15,9 @@ func TestPaseto(t *testing.T) {
 
 	id := uuid.New()
 	role := "Admin"
+	tenantID := uuid.New()
 
-	token, err := p.CreateToken(id, role)
+	token, err := p.CreateToken(id, role, tenantID)
 	require.NoError(t, err)
 
 	claims, err := p.VerifyToken(token)
@@ -24,6 +25,7 @@ func TestPaseto(t *testing.T) {
 
 	require.Equal(t, id, claims.UserID)
 	require.Equal(t, role, claims.UserRole)
+	require.Equal(t, tenantID, claims.TenantID)
 }
 
 func TestPasetoValidation(t *testing.T) {
@@ -31,8 +33,9 @@ func TestPasetoValidation(t *testing.T) {
 
 	id := uuid.New()
 	role := "Admin"
+	tenantID := uuid.New()
 
-	token, err := p.CreateToken(id, role)
+	token, err := p.CreateToken(id, role, tenantID)
 	require.NoError(t, err)
 
 	claims, err := p.VerifyToken(token)
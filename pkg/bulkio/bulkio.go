@@ -0,0 +1,226 @@
+// Package bulkio streams flat records, maps of column to value, in csv or ndjson formats.
+package bulkio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+
+	CSVMediaType    = "text/csv"
+	NDJSONMediaType = "application/x-ndjson"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format, must be csv or ndjson")
+
+// ParseFormat returns format of given name or media type.
+func ParseFormat(s string) (Format, error) {
+	if mediaType, _, err := mime.ParseMediaType(s); err == nil {
+		s = mediaType
+	}
+	switch strings.ToLower(s) {
+	case "csv", CSVMediaType:
+		return FormatCSV, nil
+	case "ndjson", "jsonl", NDJSONMediaType, "application/jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+func (f Format) MediaType() string {
+	if f == FormatCSV {
+		return CSVMediaType
+	}
+	return NDJSONMediaType
+}
+
+// RecordError is the error of a malformed record, reading could continue after it.
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+type Reader interface {
+	// Read returns next record and its line number, io.EOF at the end of input.
+	// A *RecordError is returned for malformed records which skips them.
+	Read() (record map[string]string, line int, err error)
+}
+
+func NewReader(format Format, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		return &csvReader{r: cr}, nil
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &ndjsonReader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// maxLineSize is the maximum size of a ndjson line.
+const maxLineSize = 1 << 20
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+// Read uses first row as header, header names are lower cased.
+func (c *csvReader) Read() (map[string]string, int, error) {
+	if c.header == nil {
+		header, err := c.r.Read()
+		if err != nil {
+			return nil, 0, err
+		}
+		for i, column := range header {
+			// excel prepends byte order mark to utf-8 files
+			header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		}
+		c.header = header
+	}
+
+	values, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.Line, &RecordError{Line: parseErr.Line, Err: parseErr.Err}
+		}
+		return nil, 0, err
+	}
+	line, _ := c.r.FieldPos(0)
+	if len(values) != len(c.header) {
+		return nil, line, &RecordError{Line: line, Err: fmt.Errorf("expected %d fields, got %d", len(c.header), len(values))}
+	}
+
+	record := make(map[string]string, len(values))
+	for i, value := range values {
+		record[c.header[i]] = value
+	}
+	return record, line, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// Read skips empty lines, values of each line must be json scalars which are converted to string.
+func (n *ndjsonReader) Read() (map[string]string, int, error) {
+	for n.scanner.Scan() {
+		n.line++
+		b := n.scanner.Bytes()
+		if len(strings.TrimSpace(string(b))) == 0 {
+			continue
+		}
+
+		var object map[string]any
+		if err := json.Unmarshal(b, &object); err != nil {
+			return nil, n.line, &RecordError{Line: n.line, Err: fmt.Errorf("invalid json object: %w", err)}
+		}
+		record := make(map[string]string, len(object))
+		for key, value := range object {
+			switch v := value.(type) {
+			case nil:
+			case string:
+				record[key] = v
+			case map[string]any, []any:
+				return nil, n.line, &RecordError{Line: n.line, Err: fmt.Errorf("field %q must not be an object or array", key)}
+			default:
+				record[key] = fmt.Sprint(v)
+			}
+		}
+		return record, n.line, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return nil, n.line, err
+	}
+	return nil, n.line, io.EOF
+}
+
+type Writer interface {
+	// Write writes values of columns of record, missing columns are written empty.
+	Write(record map[string]string) error
+	// Flush writes buffered records to underlying writer.
+	Flush() error
+}
+
+// NewWriter returns a writer of records with given columns, csv header is written by first record.
+func NewWriter(format Format, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w), columns: columns}, nil
+	case FormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonWriter{w: bw, encoder: json.NewEncoder(bw), columns: columns}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	columns       []string
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(record map[string]string) error {
+	if !c.headerWritten {
+		if err := c.w.Write(c.columns); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	values := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		values[i] = record[column]
+	}
+	return c.w.Write(values)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	encoder *json.Encoder
+	columns []string
+}
+
+func (n *ndjsonWriter) Write(record map[string]string) error {
+	object := make(map[string]string, len(n.columns))
+	for _, column := range n.columns {
+		object[column] = record[column]
+	}
+	return n.encoder.Encode(object)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.w.Flush()
+}
@@ -0,0 +1,93 @@
+package bulkio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/pkg/bulkio"
+)
+
+func TestParseFormat(t *testing.T) {
+	for input, expected := range map[string]bulkio.Format{
+		"csv":                              bulkio.FormatCSV,
+		"text/csv; charset=utf-8":          bulkio.FormatCSV,
+		"NDJSON":                           bulkio.FormatNDJSON,
+		"application/x-ndjson":             bulkio.FormatNDJSON,
+		"application/jsonl; charset=utf-8": bulkio.FormatNDJSON,
+	} {
+		format, err := bulkio.ParseFormat(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, format, input)
+	}
+	_, err := bulkio.ParseFormat("application/json")
+	require.ErrorIs(t, err, bulkio.ErrUnsupportedFormat)
+}
+
+type row struct {
+	record map[string]string
+	line   int
+	err    bool
+}
+
+func readAll(t *testing.T, r bulkio.Reader) []row {
+	var rows []row
+	for {
+		record, line, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		var recordErr *bulkio.RecordError
+		if err != nil && !errors.As(err, &recordErr) {
+			require.NoError(t, err)
+		}
+		rows = append(rows, row{record, line, err != nil})
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "\ufeffName, Email\namir,a@b.c\nali\n\"reza,r@b.c\nmina,m@b.c\n"
+	r, err := bulkio.NewReader(bulkio.FormatCSV, strings.NewReader(input))
+	require.NoError(t, err)
+
+	rows := readAll(t, r)
+	require.Equal(t, row{map[string]string{"name": "amir", "email": "a@b.c"}, 2, false}, rows[0])
+	require.Equal(t, row{nil, 3, true}, rows[1], "missing field")
+	require.True(t, rows[len(rows)-1].err, "unterminated quote")
+}
+
+func TestNDJSONReader(t *testing.T) {
+	input := `{"name":"amir","age":30,"admin":true,"phone":null}` + "\n\n" + `{"name":` + "\n" + `{"tags":["a"]}` + "\n" + `{"name":"ali"}`
+	r, err := bulkio.NewReader(bulkio.FormatNDJSON, strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Equal(t, []row{
+		{map[string]string{"name": "amir", "age": "30", "admin": "true"}, 1, false},
+		{nil, 3, true},
+		{nil, 4, true},
+		{map[string]string{"name": "ali"}, 5, false},
+	}, readAll(t, r))
+}
+
+func TestWriter(t *testing.T) {
+	columns := []string{"name", "email"}
+	records := []map[string]string{{"name": "amir", "email": "a@b.c", "ignored": "x"}, {"name": "ali, jr"}}
+
+	for format, expected := range map[bulkio.Format]string{
+		bulkio.FormatCSV:    "name,email\namir,a@b.c\n\"ali, jr\",\n",
+		bulkio.FormatNDJSON: `{"email":"a@b.c","name":"amir"}` + "\n" + `{"email":"","name":"ali, jr"}` + "\n",
+	} {
+		var buf bytes.Buffer
+		w, err := bulkio.NewWriter(format, &buf, columns)
+		require.NoError(t, err)
+		for _, record := range records {
+			require.NoError(t, w.Write(record))
+		}
+		require.NoError(t, w.Flush())
+		require.Equal(t, expected, buf.String(), format)
+	}
+}
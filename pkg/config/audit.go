@@ -0,0 +1,10 @@
+package config
+
+type audit struct {
+	hashChain bool
+}
+
+// HashChain reports whether every audit entry is linked to previous one by its hash for tamper evidence.
+func (a audit) HashChain() bool {
+	return a.hashChain
+}
@@ -0,0 +1,16 @@
+package config
+
+type avatar struct {
+	maxSizeInKB   uint
+	thumbnailSize uint
+}
+
+// MaxSize is the maximum size of uploaded avatars in bytes.
+func (a avatar) MaxSize() int64 {
+	return int64(a.maxSizeInKB) << 10
+}
+
+// ThumbnailSize is the width and height of square bounding thumbnails of avatars in pixels.
+func (a avatar) ThumbnailSize() int {
+	return int(a.thumbnailSize)
+}
@@ -18,13 +18,18 @@ import (
 // db, web server, and grpc server configurations.
 // It has no Exported fields to encapsulate configurations.
 type AppConfig struct {
-	db     db
-	web    web
-	grpc   grpc
-	logger logger
-	auth   auth
-	cache  cache
-	event  event
+	db         db
+	web        web
+	grpc       grpc
+	logger     logger
+	auth       auth
+	cache      cache
+	event      event
+	audit      audit
+	invitation invitation
+	notifier   notifier
+	storage    storage
+	avatar     avatar
 }
 
 func (app AppConfig) DB() db {
@@ -55,18 +60,40 @@ func (app AppConfig) Event() event {
 	return app.event
 }
 
+func (app AppConfig) Audit() audit {
+	return app.audit
+}
+
+func (app AppConfig) Invitation() invitation {
+	return app.invitation
+}
+
+func (app AppConfig) Notifier() notifier {
+	return app.notifier
+}
+
+func (app AppConfig) Storage() storage {
+	return app.storage
+}
+
+func (app AppConfig) Avatar() avatar {
+	return app.avatar
+}
+
 // tmpConfig holds the configurations for the entire application, including
 // db, web server, and grpc server configurations.
 // It should have Exported fields to work with tags.
 type tmpConfig struct {
 	DB struct {
-		Driver   string `default:"sqlite" json:"driver" yaml:"driver" toml:"driver"`
-		IP       string `default:"127.0.0.1" json:"ip" yaml:"ip" toml:"ip"`
-		Port     uint   `default:"3306" json:"port" yaml:"port" toml:"port"`
-		UserName string `default:"amir" json:"userName" yaml:"userName" toml:"userName"`
-		Password string `default:"mirzaei" json:"password" yaml:"password" toml:"password"`
-		Name     string `default:"clean-architect" json:"name" yaml:"name" toml:"name"`
-		Path     string `default:"." json:"path" yaml:"path" toml:"path"`
+		Driver                 string `default:"sqlite" json:"driver" yaml:"driver" toml:"driver"`
+		IP                     string `default:"127.0.0.1" json:"ip" yaml:"ip" toml:"ip"`
+		Port                   uint   `default:"3306" json:"port" yaml:"port" toml:"port"`
+		UserName               string `default:"amir" json:"userName" yaml:"userName" toml:"userName"`
+		Password               string `default:"mirzaei" json:"password" yaml:"password" toml:"password"`
+		Name                   string `default:"clean-architect" json:"name" yaml:"name" toml:"name"`
+		Path                   string `default:"." json:"path" yaml:"path" toml:"path"`
+		AutoMigrate            bool   `default:"false" json:"autoMigrate" yaml:"autoMigrate" toml:"autoMigrate"`
+		DeletedRetentionInDays uint   `default:"30" json:"deletedRetentionInDays" yaml:"deletedRetentionInDays" toml:"deletedRetentionInDays"`
 	} `json:"db" yaml:"db" toml:"db"`
 	Web struct {
 		BindingIPAddress       string `default:"0.0.0.0" json:"bindingIpAddress" yaml:"bindingIpAddress" toml:"bindingIpAddress"`
@@ -111,18 +138,46 @@ type tmpConfig struct {
 		UserName string `default:"" json:"userName" yaml:"userName" toml:"userName"`
 		Password string `default:"" json:"password" yaml:"password" toml:"password"`
 	} `json:"event" yaml:"event" toml:"event"`
+	Audit struct {
+		HashChain bool `default:"true" json:"hashChain" yaml:"hashChain" toml:"hashChain"`
+	} `json:"audit" yaml:"audit" toml:"audit"`
+	Invitation struct {
+		LifeTimeInHours uint   `default:"72" json:"lifeTimeInHours" yaml:"lifeTimeInHours" toml:"lifeTimeInHours"`
+		AcceptURL       string `default:"" json:"acceptURL" yaml:"acceptURL" toml:"acceptURL"`
+	} `json:"invitation" yaml:"invitation" toml:"invitation"`
+	Notifier struct {
+		Driver string `default:"log" json:"driver" yaml:"driver" toml:"driver"`
+	} `json:"notifier" yaml:"notifier" toml:"notifier"`
+	Storage struct {
+		Driver               string `default:"" json:"driver" yaml:"driver" toml:"driver"`
+		Path                 string `default:"storage" json:"path" yaml:"path" toml:"path"`
+		Endpoint             string `default:"" json:"endpoint" yaml:"endpoint" toml:"endpoint"`
+		Region               string `default:"us-east-1" json:"region" yaml:"region" toml:"region"`
+		Bucket               string `default:"" json:"bucket" yaml:"bucket" toml:"bucket"`
+		AccessKey            string `default:"" json:"accessKey" yaml:"accessKey" toml:"accessKey"`
+		SecretKey            string `default:"" json:"secretKey" yaml:"secretKey" toml:"secretKey"`
+		URLSecret            string `default:"" json:"urlSecret" yaml:"urlSecret" toml:"urlSecret"`
+		URLLifeTimeInMinutes uint   `default:"15" json:"urlLifeTimeInMinutes" yaml:"urlLifeTimeInMinutes" toml:"urlLifeTimeInMinutes"`
+		DownloadURL          string `default:"/v2/files" json:"downloadURL" yaml:"downloadURL" toml:"downloadURL"`
+	} `json:"storage" yaml:"storage" toml:"storage"`
+	Avatar struct {
+		MaxSizeInKB   uint `default:"2048" json:"maxSizeInKB" yaml:"maxSizeInKB" toml:"maxSizeInKB"`
+		ThumbnailSize uint `default:"128" json:"thumbnailSize" yaml:"thumbnailSize" toml:"thumbnailSize"`
+	} `json:"avatar" yaml:"avatar" toml:"avatar"`
 }
 
 func (cfg tmpConfig) ToAppConfig() AppConfig {
 	return AppConfig{
 		db: db{
-			driver:   cfg.DB.Driver,
-			ip:       cfg.DB.IP,
-			port:     cfg.DB.Port,
-			userName: cfg.DB.UserName,
-			password: cfg.DB.Password,
-			name:     cfg.DB.Name,
-			path:     cfg.DB.Path,
+			driver:                 cfg.DB.Driver,
+			ip:                     cfg.DB.IP,
+			port:                   cfg.DB.Port,
+			userName:               cfg.DB.UserName,
+			password:               cfg.DB.Password,
+			name:                   cfg.DB.Name,
+			path:                   cfg.DB.Path,
+			autoMigrate:            cfg.DB.AutoMigrate,
+			deletedRetentionInDays: cfg.DB.DeletedRetentionInDays,
 		},
 		web: web{
 			bindingIpAddress:       cfg.Web.BindingIPAddress,
@@ -167,6 +222,32 @@ func (cfg tmpConfig) ToAppConfig() AppConfig {
 			user:     cfg.Event.UserName,
 			password: cfg.Event.Password,
 		},
+		audit: audit{
+			hashChain: cfg.Audit.HashChain,
+		},
+		invitation: invitation{
+			lifeTimeInHours: cfg.Invitation.LifeTimeInHours,
+			acceptURL:       cfg.Invitation.AcceptURL,
+		},
+		notifier: notifier{
+			driver: cfg.Notifier.Driver,
+		},
+		storage: storage{
+			driver:               cfg.Storage.Driver,
+			path:                 cfg.Storage.Path,
+			endpoint:             cfg.Storage.Endpoint,
+			region:               cfg.Storage.Region,
+			bucket:               cfg.Storage.Bucket,
+			accessKey:            cfg.Storage.AccessKey,
+			secretKey:            cfg.Storage.SecretKey,
+			urlSecret:            cfg.Storage.URLSecret,
+			urlLifeTimeInMinutes: cfg.Storage.URLLifeTimeInMinutes,
+			downloadURL:          cfg.Storage.DownloadURL,
+		},
+		avatar: avatar{
+			maxSizeInKB:   cfg.Avatar.MaxSizeInKB,
+			thumbnailSize: cfg.Avatar.ThumbnailSize,
+		},
 	}
 }
 
@@ -3,22 +3,34 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 )
 
 type db struct {
-	driver   string
-	ip       string
-	port     uint
-	userName string
-	password string
-	name     string
-	path     string
+	driver                 string
+	ip                     string
+	port                   uint
+	userName               string
+	password               string
+	name                   string
+	path                   string
+	autoMigrate            bool
+	deletedRetentionInDays uint
 }
 
 func (db db) Driver() string {
 	return db.driver
 }
 
+func (db db) AutoMigrate() bool {
+	return db.autoMigrate
+}
+
+// DeletedRetention returns how long soft deleted rows are kept, zero means forever.
+func (db db) DeletedRetention() time.Duration {
+	return time.Duration(db.deletedRetentionInDays) * 24 * time.Hour
+}
+
 func (db db) ConnectionString() string {
 	// todo: add mongodb connection string
 	switch db.driver {
@@ -31,7 +43,7 @@ func (db db) ConnectionString() string {
 			db.name,
 		)
 	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s",
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&multiStatements=true&clientFoundRows=true",
 			db.userName,
 			db.password,
 			db.ip,
@@ -39,7 +51,7 @@ func (db db) ConnectionString() string {
 			db.name,
 		)
 	case "sqlite":
-		return fmt.Sprintf("%s.sqlite", filepath.Join(db.path, db.name))
+		return fmt.Sprintf("%s.sqlite?_time_format=sqlite", filepath.Join(db.path, db.name))
 	default:
 		return ""
 	}
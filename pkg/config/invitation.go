@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+type invitation struct {
+	lifeTimeInHours uint
+	acceptURL       string
+}
+
+// LifeTime is the default time to expiry of invitations.
+func (i invitation) LifeTime() time.Duration {
+	return time.Duration(i.lifeTimeInHours) * time.Hour
+}
+
+// AcceptURL is the page of client accepting invitations, token is appended to it as query parameter.
+func (i invitation) AcceptURL() string {
+	return i.acceptURL
+}
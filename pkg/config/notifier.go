@@ -0,0 +1,9 @@
+package config
+
+type notifier struct {
+	driver string
+}
+
+func (n notifier) Driver() string {
+	return n.driver
+}
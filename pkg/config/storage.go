@@ -0,0 +1,62 @@
+package config
+
+import "time"
+
+type storage struct {
+	driver               string
+	path                 string
+	endpoint             string
+	region               string
+	bucket               string
+	accessKey            string
+	secretKey            string
+	urlSecret            string
+	urlLifeTimeInMinutes uint
+	downloadURL          string
+}
+
+// Driver is "local", "s3" or empty to keep files in memory.
+func (s storage) Driver() string {
+	return s.driver
+}
+
+// Path is the root directory of local driver.
+func (s storage) Path() string {
+	return s.path
+}
+
+// Endpoint is the base url of S3-compatible service, e.g. http://127.0.0.1:9000 of MinIO.
+func (s storage) Endpoint() string {
+	return s.endpoint
+}
+
+func (s storage) Region() string {
+	return s.region
+}
+
+func (s storage) Bucket() string {
+	return s.bucket
+}
+
+func (s storage) AccessKey() string {
+	return s.accessKey
+}
+
+func (s storage) SecretKey() string {
+	return s.secretKey
+}
+
+// URLSecret signs download urls of files, instances serving same files must share it.
+func (s storage) URLSecret() string {
+	return s.urlSecret
+}
+
+// URLLifeTime is the time to expiry of signed download urls.
+func (s storage) URLLifeTime() time.Duration {
+	return time.Duration(s.urlLifeTimeInMinutes) * time.Minute
+}
+
+// DownloadURL is the address of endpoint serving signed urls, e.g. https://api.example.com/v2/files
+func (s storage) DownloadURL() string {
+	return s.downloadURL
+}
@@ -15,6 +15,10 @@ const (
 	CodeExisted
 	CodeInvalidArgument
 	CodeInternal
+	CodeConflict
+	CodePreconditionFailed
+	CodePayloadTooLarge
+	CodePreconditionRequired
 )
 
 func (e ErrorCode) HttpStatus() int {
@@ -34,6 +38,18 @@ func (e ErrorCode) HttpStatus() int {
 	case CodeInvalidArgument:
 		return http.StatusBadRequest
 
+	case CodeConflict:
+		return http.StatusConflict
+
+	case CodePreconditionFailed:
+		return http.StatusPreconditionFailed
+
+	case CodePayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+
+	case CodePreconditionRequired:
+		return http.StatusPreconditionRequired
+
 	default:
 		return http.StatusInternalServerError
 	}
@@ -56,6 +72,18 @@ func (e ErrorCode) GRPCStatus() codes.Code {
 	case CodeInvalidArgument:
 		return codes.InvalidArgument
 
+	case CodeConflict:
+		return codes.Aborted
+
+	case CodePreconditionFailed:
+		return codes.FailedPrecondition
+
+	case CodePayloadTooLarge:
+		return codes.InvalidArgument
+
+	case CodePreconditionRequired:
+		return codes.FailedPrecondition
+
 	default:
 		return codes.Internal
 	}
@@ -1,7 +1,6 @@
 package errs
 
 import (
-	"errors"
 	"fmt"
 	"runtime"
 )
@@ -11,24 +10,28 @@ type Error struct {
 	Code       ErrorCode
 	StackTrace string
 	Details    []any
+	// err is the original error, kept for errors.Is and errors.As
+	err error
 }
 
 func New(err error, code ErrorCode, details ...any) error {
+	msg := err.Error()
 	// hide internal errors from end users
 	if code == CodeInternal {
-		err = errors.New("internal error")
+		msg = "internal error"
 	}
 	return &Error{
-		Msg:        err.Error(),
+		Msg:        msg,
 		Code:       code,
 		StackTrace: caller(),
 		Details:    details,
+		err:        err,
 	}
 }
 
 func NotFound(entity string) error {
 	msg := fmt.Sprintf("%s not found", entity)
-	return Error{
+	return &Error{
 		Msg:        msg,
 		Code:       CodeNotFound,
 		StackTrace: caller(),
@@ -39,6 +42,10 @@ func (e Error) Error() string {
 	return e.Msg
 }
 
+func (e Error) Unwrap() error {
+	return e.err
+}
+
 // caller uses log.Lshortfile to format the caller
 func caller() string {
 	_, file, line, ok := runtime.Caller(2)
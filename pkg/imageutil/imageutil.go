@@ -0,0 +1,95 @@
+// Package imageutil decodes uploaded images and resizes them, e.g. into thumbnails.
+package imageutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	// registers gif decoder, animated images are decoded by their first frame
+	_ "image/gif"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions are too large")
+)
+
+// jpegQuality is the quality of encoded jpeg images.
+const jpegQuality = 85
+
+// Decode decodes a png, jpeg or gif image, dimensions are checked before decoding pixels
+// so images wider or taller than maxDimension are rejected cheaply.
+func Decode(data []byte, maxDimension int) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+	if config.Width > maxDimension || config.Height > maxDimension {
+		return nil, "", fmt.Errorf("%w: %dx%d is larger than %dx%d", ErrTooLarge, config.Width, config.Height, maxDimension, maxDimension)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+	return img, format, nil
+}
+
+// Encode writes img as jpeg if format is "jpeg", otherwise as png.
+func Encode(w io.Writer, img image.Image, format string) error {
+	if format == "jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	return png.Encode(w, img)
+}
+
+// Thumbnail scales src down to fit in a size×size square keeping its aspect ratio, by averaging pixels
+// of source area of every pixel. Images fitting in the square are not scaled up.
+func Thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	dw, dh := sw, sh
+	if sw > size || sh > size {
+		if sw >= sh {
+			dw, dh = size, max(1, sh*size/sw)
+		} else {
+			dw, dh = max(1, sw*size/sh), size
+		}
+	}
+
+	// premultiplied pixels are averaged, so transparent pixels do not darken edges
+	rgba := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	if dw == sw && dh == sh {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := range dw {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride+x0*4 : sy*rgba.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			offset := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[offset+i] = uint8((sum[i] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
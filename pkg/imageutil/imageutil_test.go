@@ -0,0 +1,67 @@
+package imageutil_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/pkg/imageutil"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	img, format, err := imageutil.Decode(data, 40)
+	require.NoError(t, err)
+	require.Equal(t, "png", format)
+	require.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+
+	_, _, err = imageutil.Decode(data, 39)
+	require.ErrorIs(t, err, imageutil.ErrTooLarge)
+
+	_, _, err = imageutil.Decode([]byte("<svg></svg>"), 40)
+	require.ErrorIs(t, err, imageutil.ErrUnsupportedFormat)
+}
+
+func TestThumbnail(t *testing.T) {
+	// left half is red and right half is transparent
+	src := image.NewNRGBA(image.Rect(10, 10, 410, 210))
+	for y := 10; y < 210; y++ {
+		for x := 10; x < 210; x++ {
+			src.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	thumbnail := imageutil.Thumbnail(src, 100)
+	require.Equal(t, image.Rect(0, 0, 100, 50), thumbnail.Bounds())
+	require.Equal(t, color.RGBA{R: 255, A: 255}, thumbnail.At(0, 0))
+	require.Equal(t, color.RGBA{}, thumbnail.At(99, 49))
+
+	tall := imageutil.Thumbnail(image.NewRGBA(image.Rect(0, 0, 3, 900)), 90)
+	require.Equal(t, image.Rect(0, 0, 1, 90), tall.Bounds(), "thin images keep at least a pixel")
+
+	small := imageutil.Thumbnail(src, 1000)
+	require.Equal(t, image.Rect(0, 0, 400, 200), small.Bounds(), "small images are not scaled up")
+}
+
+func TestEncode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for _, format := range []string{"jpeg", "png"} {
+		var buf bytes.Buffer
+		require.NoError(t, imageutil.Encode(&buf, img, format))
+		_, decoded, err := image.DecodeConfig(&buf)
+		require.NoError(t, err)
+		require.Equal(t, format, decoded)
+	}
+}
@@ -26,8 +26,12 @@ func DecodeAndValidate[T any](r *http.Request) (T, error) {
 	if err != nil {
 		return v, err
 	}
+	return v, Validate(v)
+}
 
-	if err = validate.Struct(v); err != nil {
+// Validate checks validate tags of given struct and returns invalid fields as error details.
+func Validate(v any) error {
+	if err := validate.Struct(v); err != nil {
 		if validationError, ok := err.(validator.ValidationErrors); ok {
 			errFields := make(map[string][]string)
 			for _, err := range validationError {
@@ -46,14 +50,17 @@ func DecodeAndValidate[T any](r *http.Request) (T, error) {
 				case "max":
 					errField = fmt.Sprintf("the %s may not be greater than %s characters.", fieldName, err.Param())
 
+				case "oneof":
+					errField = fmt.Sprintf("the %s must be one of %s.", fieldName, strings.ReplaceAll(err.Param(), " ", ", "))
+
 				default:
 					errField = fmt.Sprintf("the %s is invalid.", fieldName)
 				}
 				errFields[fieldName] = append(errFields[fieldName], errField)
 			}
-			return v, errs.New(errors.New("given body is not valid"), errs.CodeInvalidArgument, errFields)
+			return errs.New(errors.New("given body is not valid"), errs.CodeInvalidArgument, errFields)
 		}
-		return v, errs.New(fmt.Errorf("failed to validate json: %w", err), errs.CodeInvalidArgument)
+		return errs.New(fmt.Errorf("failed to validate json: %w", err), errs.CodeInvalidArgument)
 	}
-	return v, nil
+	return nil
 }
@@ -0,0 +1,264 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchMediaType = "application/merge-patch+json"
+	JSONPatchMediaType  = "application/json-patch+json"
+)
+
+var (
+	// ErrPatchTestFailed returned when a test operation of json patch does not match.
+	ErrPatchTestFailed = errors.New("json patch test operation failed")
+	errPathNotFound    = errors.New("path not found")
+)
+
+// MergePatch applies RFC 7396 json merge patch on given document.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any, len(p))
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergePatch(t[key], value)
+	}
+	return t
+}
+
+// PatchOperation is an operation of RFC 6902 json patch.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch applies RFC 6902 json patch on given document, operations applied atomically
+// so document is untouched if any of operations fails.
+func JSONPatch(doc, patch []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	var operations []PatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("invalid json patch: %w", err)
+	}
+
+	for i, op := range operations {
+		var err error
+		if target, err = applyOperation(target, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+func applyOperation(doc any, op PatchOperation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("value is required")
+		}
+		var value any
+		if err = json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		switch op.Op {
+		case "add":
+			return addValue(doc, path, value)
+		case "replace":
+			if doc, err = removeValue(doc, path); err != nil {
+				return nil, err
+			}
+			return addValue(doc, path, value)
+		default:
+			current, err := getValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrPatchTestFailed
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		return removeValue(doc, path)
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, errors.New("can not move a value into one of its children")
+			}
+			if doc, err = removeValue(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			// copied value must not share containers with source
+			b, _ := json.Marshal(value)
+			_ = json.Unmarshal(b, &value)
+		}
+		return addValue(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// parsePointer splits RFC 6901 json pointer to its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func getValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, errPathNotFound
+			}
+			doc = value
+		case []any:
+			i, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, errPathNotFound
+		}
+	}
+	return doc, nil
+}
+
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modifyParent(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			if token == "-" {
+				return append(node, value), nil
+			}
+			i, err := arrayIndex(token, len(node)+1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], append([]any{value}, node[i:]...)...), nil
+		default:
+			return nil, errPathNotFound
+		}
+	})
+}
+
+func removeValue(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errors.New("can not remove whole document")
+	}
+	return modifyParent(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, errPathNotFound
+			}
+			delete(node, token)
+			return node, nil
+		case []any:
+			i, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		default:
+			return nil, errPathNotFound
+		}
+	})
+}
+
+// modifyParent replaces parent container of path by result of fn.
+func modifyParent(doc any, path []string, fn func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := getValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = modifyParent(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]any:
+		node[path[0]] = child
+	case []any:
+		i, _ := arrayIndex(path[0], len(node))
+		node[i] = child
+	}
+	return doc, nil
+}
+
+func arrayIndex(token string, length int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= length || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return i, nil
+}
@@ -0,0 +1,76 @@
+package jsonutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+)
+
+func TestMergePatch(t *testing.T) {
+	for _, tc := range []struct {
+		name, doc, patch, expected string
+	}{
+		{"replace", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"nested", `{"a":{"b":"c","d":"e"}}`, `{"a":{"b":null,"f":1}}`, `{"a":{"d":"e","f":1}}`},
+		{"array replaced", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		{"non object patch", `{"a":"b"}`, `["c"]`, `["c"]`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := jsonutil.MergePatch([]byte(tc.doc), []byte(tc.patch))
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(got))
+		})
+	}
+
+	_, err := jsonutil.MergePatch([]byte(`{}`), []byte(`{`))
+	require.Error(t, err)
+}
+
+func TestJSONPatch(t *testing.T) {
+	doc := `{"name":"amir","tags":["a","b"],"a/b":{"c~d":1}}`
+
+	for _, tc := range []struct {
+		name, patch, expected string
+	}{
+		{"add", `[{"op":"add","path":"/email","value":"a@b.c"}]`, `{"name":"amir","email":"a@b.c","tags":["a","b"],"a/b":{"c~d":1}}`},
+		{"add to array", `[{"op":"add","path":"/tags/1","value":"x"}]`, `{"name":"amir","tags":["a","x","b"],"a/b":{"c~d":1}}`},
+		{"append to array", `[{"op":"add","path":"/tags/-","value":"x"}]`, `{"name":"amir","tags":["a","b","x"],"a/b":{"c~d":1}}`},
+		{"remove", `[{"op":"remove","path":"/tags/0"}]`, `{"name":"amir","tags":["b"],"a/b":{"c~d":1}}`},
+		{"replace escaped", `[{"op":"replace","path":"/a~1b/c~0d","value":2}]`, `{"name":"amir","tags":["a","b"],"a/b":{"c~d":2}}`},
+		{"move", `[{"op":"move","from":"/name","path":"/full_name"}]`, `{"full_name":"amir","tags":["a","b"],"a/b":{"c~d":1}}`},
+		{"copy", `[{"op":"copy","from":"/tags","path":"/labels"}]`, `{"name":"amir","tags":["a","b"],"labels":["a","b"],"a/b":{"c~d":1}}`},
+		{"test then replace", `[{"op":"test","path":"/name","value":"amir"},{"op":"replace","path":"/name","value":"ali"}]`, `{"name":"ali","tags":["a","b"],"a/b":{"c~d":1}}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := jsonutil.JSONPatch([]byte(doc), []byte(tc.patch))
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(got))
+		})
+	}
+
+	for _, tc := range []struct {
+		name, patch string
+	}{
+		{"replace missing", `[{"op":"replace","path":"/missing","value":1}]`},
+		{"remove missing", `[{"op":"remove","path":"/missing"}]`},
+		{"invalid index", `[{"op":"add","path":"/tags/5","value":1}]`},
+		{"leading zero index", `[{"op":"remove","path":"/tags/01"}]`},
+		{"invalid pointer", `[{"op":"add","path":"name","value":1}]`},
+		{"missing value", `[{"op":"add","path":"/name"}]`},
+		{"unknown operation", `[{"op":"merge","path":"/name","value":1}]`},
+		{"move into child", `[{"op":"move","from":"/tags","path":"/tags/0"}]`},
+		{"not an array", `{"op":"add","path":"/name","value":1}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := jsonutil.JSONPatch([]byte(doc), []byte(tc.patch))
+			require.Error(t, err)
+		})
+	}
+
+	_, err := jsonutil.JSONPatch([]byte(doc), []byte(`[{"op":"test","path":"/name","value":"ali"}]`))
+	require.ErrorIs(t, err, jsonutil.ErrPatchTestFailed)
+}
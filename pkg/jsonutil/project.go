@@ -0,0 +1,116 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Project returns json of v having only given fields of its objects, fields are dotted paths of json names, e.g.
+// attributes.plan, arrays are projected element by element and fields missing in v are left out, so sparse fieldsets
+// of responses never have zero values of fields which are not listed. v is returned whole if fields is empty.
+// hidden fields, dotted paths as well, are never returned even if v has them or they are requested, e.g. password.
+func Project(v any, fields []string, hidden ...string) (json.RawMessage, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 && len(hidden) == 0 {
+		return b, nil
+	}
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	// numbers are kept as they are written, e.g. large integers
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		doc, _ = project(doc, newPathTree(fields))
+	}
+	doc = hide(doc, newPathTree(hidden))
+	return json.Marshal(doc)
+}
+
+// pathTree is a tree of dotted paths keyed by their fields, nil trees are leaves, which are whole values.
+type pathTree map[string]pathTree
+
+func newPathTree(paths []string) pathTree {
+	tree := pathTree{}
+	for _, path := range paths {
+		node := tree
+		fields := strings.Split(path, ".")
+		for i, field := range fields {
+			child, ok := node[field]
+			switch {
+			case ok && child == nil:
+				// a parent path is already whole
+			case i == len(fields)-1:
+				node[field] = nil
+			case !ok:
+				child = pathTree{}
+				node[field] = child
+			}
+			if child == nil {
+				break
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// project returns value having only fields of tree, ok is false if value has none of them.
+func project(value any, tree pathTree) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		projected := make(map[string]any, len(tree))
+		for field, child := range tree {
+			fieldValue, ok := v[field]
+			if !ok {
+				continue
+			}
+			if child != nil {
+				if fieldValue, ok = project(fieldValue, child); !ok {
+					continue
+				}
+			}
+			projected[field] = fieldValue
+		}
+		return projected, true
+
+	case []any:
+		projected := make([]any, 0, len(v))
+		for _, item := range v {
+			if item, ok := project(item, tree); ok {
+				projected = append(projected, item)
+			}
+		}
+		return projected, true
+
+	case nil:
+		// null objects have no fields but are written as they are, same as empty objects
+		return nil, true
+	}
+	// scalars have no fields
+	return nil, false
+}
+
+// hide removes fields of tree from value.
+func hide(value any, tree pathTree) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for field, child := range tree {
+			if child == nil {
+				delete(v, field)
+			} else if fieldValue, ok := v[field]; ok {
+				v[field] = hide(fieldValue, child)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = hide(item, tree)
+		}
+	}
+	return value
+}
@@ -0,0 +1,41 @@
+package jsonutil_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/pkg/jsonutil"
+)
+
+func TestProject(t *testing.T) {
+	doc := `{"id":12345678901234567890,"name":"amir","password":"secret","attributes":{"plan":"pro","age":30,"password":"x"},"tags":[{"a":1,"b":2},{"b":3}],"empty":null}`
+
+	for _, tc := range []struct {
+		name     string
+		fields   []string
+		hidden   []string
+		expected string
+	}{
+		{"whole", nil, nil, doc},
+		{"fields", []string{"id", "name"}, nil, `{"id":12345678901234567890,"name":"amir"}`},
+		{"nested", []string{"attributes.plan"}, nil, `{"attributes":{"plan":"pro"}}`},
+		{"parent wins", []string{"attributes.plan", "attributes"}, nil, `{"attributes":{"plan":"pro","age":30,"password":"x"}}`},
+		{"arrays", []string{"tags.a"}, nil, `{"tags":[{"a":1},{}]}`},
+		{"missing", []string{"phone", "name.first", "attributes.missing"}, nil, `{"attributes":{}}`},
+		{"null", []string{"empty.a"}, nil, `{"empty":null}`},
+		{"hidden", nil, []string{"password", "attributes.password"}, `{"id":12345678901234567890,"name":"amir","attributes":{"plan":"pro","age":30},"tags":[{"a":1,"b":2},{"b":3}],"empty":null}`},
+		{"hidden requested", []string{"name", "password"}, []string{"password"}, `{"name":"amir"}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := jsonutil.Project(json.RawMessage(doc), tc.fields, tc.hidden...)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(got))
+		})
+	}
+
+	got, err := jsonutil.Project([]map[string]any{{"a": 1, "b": 2}, {"a": 3}}, []string{"a"})
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"a":1},{"a":3}]`, string(got))
+}
@@ -0,0 +1,124 @@
+package mongoutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// bucketFormats format dates of buckets as their start in UTC, see paginate.Group.
+var bucketFormats = map[string]string{
+	paginate.BucketHour:  "%Y-%m-%dT%H:00:00Z",
+	paginate.BucketDay:   "%Y-%m-%dT00:00:00Z",
+	paginate.BucketMonth: "%Y-%m-01T00:00:00Z",
+	paginate.BucketYear:  "%Y-01-01T00:00:00Z",
+}
+
+// AggregationPipeline returns pipeline of facets of aggregations of documents matching filter, its only document
+// has groups of each aggregation in field of its index, e.g. {"0": [{_id: key, count: n, m0: metric}]}.
+// aggregations must be allowed by fields, see paginate.Fields.Aggregations.
+func AggregationPipeline(aggregations []paginate.Aggregation, fields paginate.Fields, filter bson.D) (mongo.Pipeline, error) {
+	aggregations, err := fields.Aggregations(aggregations)
+	if err != nil {
+		return nil, err
+	}
+	facets := bson.D{}
+	for i, aggregation := range aggregations {
+		var key any = "$" + aggregation.Field
+		if aggregation.Bucket != "" {
+			key = bson.D{{Key: "$dateToString", Value: bson.D{
+				{Key: "format", Value: bucketFormats[aggregation.Bucket]},
+				{Key: "date", Value: "$" + aggregation.Field},
+			}}}
+		}
+		group := bson.D{{Key: "_id", Value: key}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}
+		for j, metric := range aggregation.Metrics {
+			group = append(group, bson.E{Key: fmt.Sprintf("m%d", j), Value: bson.D{{Key: "$" + metric.Func, Value: "$" + metric.Field}}})
+		}
+		facets = append(facets, bson.E{Key: fmt.Sprint(i), Value: bson.A{
+			bson.D{{Key: "$group", Value: group}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+			bson.D{{Key: "$limit", Value: paginate.MaxFacetGroups}},
+		}})
+	}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: facets}},
+	}, nil
+}
+
+// listFacets sets facets of aggregations of pagination on documents of collection matching filter.
+func listFacets(ctx context.Context, col *mongo.Collection, pagination *paginate.Pagination, fields paginate.Fields, filter bson.D) error {
+	if len(pagination.Aggregations) == 0 {
+		return nil
+	}
+	pipeline, err := AggregationPipeline(pagination.Aggregations, fields, filter)
+	if err != nil {
+		return err
+	}
+	cursor, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var results []map[string][]bson.Raw
+	if err = cursor.All(ctx, &results); err != nil {
+		return err
+	}
+	facets := make([]paginate.Facet, 0, len(pagination.Aggregations))
+	for i, aggregation := range pagination.Aggregations {
+		groups := []paginate.Group{}
+		if len(results) > 0 {
+			for _, document := range results[0][fmt.Sprint(i)] {
+				group, err := facetGroup(document, aggregation)
+				if err != nil {
+					return err
+				}
+				groups = append(groups, group)
+			}
+		}
+		facets = append(facets, paginate.Facet{Field: aggregation.Field, Bucket: aggregation.Bucket, Groups: groups})
+	}
+	pagination.Facets = facets
+	return nil
+}
+
+func facetGroup(document bson.Raw, aggregation paginate.Aggregation) (paginate.Group, error) {
+	var group paginate.Group
+	if err := document.Lookup("count").Unmarshal(&group.Count); err != nil {
+		return group, err
+	}
+	key, err := documentValue(document.Lookup("_id"))
+	if err != nil {
+		return group, err
+	}
+	group.Key = key
+	if len(aggregation.Metrics) > 0 {
+		group.Metrics = make(map[string]any, len(aggregation.Metrics))
+		for i, metric := range aggregation.Metrics {
+			value, err := documentValue(document.Lookup(fmt.Sprintf("m%d", i)))
+			if err != nil {
+				return group, err
+			}
+			group.Metrics[metric.String()] = value
+		}
+	}
+	return group, nil
+}
+
+// documentValue returns value of document field, dates are returned as times so they are encoded as rfc 3339.
+func documentValue(raw bson.RawValue) (any, error) {
+	var value any
+	if err := raw.Unmarshal(&value); err != nil {
+		return nil, err
+	}
+	if date, ok := value.(primitive.DateTime); ok {
+		return date.Time().UTC(), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,124 @@
+package mongoutil
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IDField is the tiebreaker of cursor pages, collections without it can not be listed by cursor.
+const IDField = "id"
+
+// keysetValue wraps values of cursors, values are kept as canonical extended json to keep their bson types.
+type keysetValue struct {
+	V bson.RawValue `bson:"v"`
+}
+
+// keysetList finds cursor page of collection ordered by sort of pagination and IDField, see paginate.Keyset.
+// documents are not counted.
+func keysetList[T any](ctx context.Context, col *mongo.Collection,
+	pagination *paginate.Pagination, fields paginate.Fields, filter bson.D) ([]T, error) {
+	if _, err := fields.Sorts(pagination.Sort); err != nil {
+		return nil, err
+	}
+	projection, err := fields.Projection(pagination.Fields)
+	if err != nil {
+		return nil, err
+	}
+	keyset, err := pagination.Keyset(IDField)
+	if err != nil {
+		return nil, err
+	}
+	// sort of client is allowed, so only the tiebreaker is missing
+	sorts, err := fields.Sorts(keyset.Sort)
+	if err != nil || slices.ContainsFunc(sorts, func(sort paginate.Sort) bool { return sort.Field == TextScore }) {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	if keyset.Values != nil {
+		match, err := keysetFilter(sorts, keyset.Values)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, match}}}
+	}
+
+	if len(projection) > 0 {
+		for _, sort := range sorts {
+			if !slices.Contains(projection, sort.Field) {
+				projection = append(projection, sort.Field)
+			}
+		}
+	}
+
+	// one more document tells whether there is a next page
+	options := options.Find().
+		SetLimit(int64(pagination.PerPage + 1)).
+		SetSort(sortAggregate(sorts)).
+		SetProjection(projectionAggregate(projection))
+	cursor, err := col.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []T
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	return paginate.Page(pagination, keyset, rows, func(row T) ([]json.RawMessage, error) {
+		return keysetValues(row, sorts)
+	})
+}
+
+// keysetFilter returns match of documents after document of values,
+// e.g. {$or: [{a: {$gt: x}}, {a: x, b: {$gt: y}}]} for ascending sort of a and b.
+func keysetFilter(sorts []paginate.Sort, values []json.RawMessage) (bson.D, error) {
+	parsed := make([]bson.RawValue, len(values))
+	for i, value := range values {
+		var v keysetValue
+		if err := bson.UnmarshalExtJSON(value, true, &v); err != nil || v.V.Type == bson.TypeNull || v.V.Type == 0 {
+			return nil, paginate.ErrInvalidCursor
+		}
+		parsed[i] = v.V
+	}
+
+	or := bson.A{}
+	for i, sort := range sorts {
+		match := bson.D{}
+		for j := range i {
+			match = append(match, bson.E{Key: sorts[j].Field, Value: parsed[j]})
+		}
+		operator := "$gt"
+		if sort.Arrange == paginate.SortOrderDescending {
+			operator = "$lt"
+		}
+		match = append(match, bson.E{Key: sort.Field, Value: bson.D{{Key: operator, Value: parsed[i]}}})
+		or = append(or, match)
+	}
+	return bson.D{{Key: "$or", Value: or}}, nil
+}
+
+// keysetValues returns extended json of sort fields of row, documents missing them or having nulls can not be a cursor.
+func keysetValues[T any](row T, sorts []paginate.Sort) ([]json.RawMessage, error) {
+	doc, err := bson.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]json.RawMessage, len(sorts))
+	for i, sort := range sorts {
+		value, err := bson.Raw(doc).LookupErr(strings.Split(sort.Field, ".")...)
+		if err != nil || value.Type == bson.TypeNull {
+			return nil, paginate.ErrCursorNotSupported
+		}
+		if values[i], err = bson.MarshalExtJSON(keysetValue{V: value}, true, false); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
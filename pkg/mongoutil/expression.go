@@ -0,0 +1,46 @@
+package mongoutil
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// expressionMatch returns match of filter expression, e.g. {$and: [{$or: [{status: 1}, {status: 2}]}, {$nor: [{role: x}]}]}.
+// comparisons of fields out of filterable fields are rejected by paginate.FieldError unlike filters which are ignored.
+func expressionMatch(expr paginate.Expr, fields paginate.Fields) (bson.D, error) {
+	switch e := expr.(type) {
+	case paginate.AndExpr:
+		return joinExpressions("$and", []paginate.Expr(e), fields)
+	case paginate.OrExpr:
+		return joinExpressions("$or", []paginate.Expr(e), fields)
+	case paginate.NotExpr:
+		return joinExpressions("$nor", []paginate.Expr{e.Expr}, fields)
+	case *paginate.Comparison:
+		field, ok := fields.Filter[e.Key]
+		if !ok {
+			return nil, &paginate.FieldError{Param: "filter", Field: e.Key, Allowed: slices.Sorted(maps.Keys(fields.Filter))}
+		}
+		values, err := fields.Values(e.Filter)
+		if err != nil {
+			return nil, err
+		}
+		return filterMatch(field, e.Condition, values), nil
+	}
+	return nil, fmt.Errorf("unknown expression %T", expr)
+}
+
+func joinExpressions(operator string, exprs []paginate.Expr, fields paginate.Fields) (bson.D, error) {
+	matches := bson.A{}
+	for _, expr := range exprs {
+		match, err := expressionMatch(expr, fields)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return bson.D{{Key: operator, Value: matches}}, nil
+}
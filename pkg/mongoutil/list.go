@@ -2,50 +2,154 @@ package mongoutil
 
 import (
 	"context"
-	"strconv"
-	"strings"
-	"time"
+	"errors"
+	"sync"
 
 	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func PaginatedList[T any](ctx context.Context, col *mongo.Collection,
-	pagination *paginate.Pagination, queryableFields map[string]string) ([]T, error) {
+// TenantField holds tenant of documents in tenant-scoped collections.
+const TenantField = "tenant_id"
 
-	options := options.Find().
-		SetLimit(int64(pagination.PerPage)).
-		SetSkip(int64((pagination.Page - 1) * pagination.PerPage)).
-		SetSort(sortAggregate(pagination.Sort)).
-		SetProjection(projectionAggregate(pagination.Fields))
+// TenantFilter returns filter of scope to be added to raw queries, it is empty for tenant.AllTenants.
+// documents saved before tenants have no tenant field and belong to the default tenant.
+func TenantFilter(scope tenant.Scope) bson.D {
+	if scope.All {
+		return bson.D{}
+	}
+	if scope.ID == tenant.Default {
+		return bson.D{{Key: TenantField, Value: bson.M{"$in": bson.A{scope.ID, nil}}}}
+	}
+	return bson.D{{Key: TenantField, Value: scope.ID}}
+}
+
+// WithTenant returns filter restricted to documents of scope.
+func WithTenant(scope tenant.Scope, filter bson.M) bson.M {
+	for _, e := range TenantFilter(scope) {
+		filter[e.Key] = e.Value
+	}
+	return filter
+}
 
-	filterAggregate := filterAggregate(pagination.Filters, queryableFields)
-	cursor, err := col.Find(ctx, filterAggregate, options)
+// PaginatedList finds documents of given collection by pagination,
+// predicates always apply besides pagination filters and documents out of tenant scope are never listed.
+// pages of cursor paginations are found by keyset of sort and IDField instead of skip, see paginate.Keyset.
+// sorts and projections out of fields are rejected by paginate.FieldError, filter expression of pagination is anded with its filters.
+// facets of aggregations of pagination are listed over all documents of its filters by one pipeline, see paginate.Aggregation.
+// pages are found with one more document which tells whether documents follow them, documents are counted by count mode
+// of pagination concurrently with the page, estimates are of whole collection, so only lists without filters are estimated.
+// counts are not in the snapshot of page as snapshot sessions need replica sets and could not run concurrent operations.
+func PaginatedList[T any](ctx context.Context, col *mongo.Collection, scope tenant.Scope,
+	pagination *paginate.Pagination, fields paginate.Fields, predicates ...bson.E) ([]T, error) {
+	mode, err := pagination.CountMode()
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
+	predicates = append(TenantFilter(scope), predicates...)
 
-	var data []T
-	if err = cursor.All(ctx, &data); err != nil {
+	filterAggregate, err := filterAggregate(pagination.Filters, fields)
+	if err != nil {
+		return nil, err
+	}
+	filterAggregate = append(filterAggregate, predicates...)
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+	if expr != nil {
+		match, err := expressionMatch(expr, fields)
+		if err != nil {
+			return nil, err
+		}
+		// wrapped so fields of expression do not override same fields of filters
+		filterAggregate = append(filterAggregate, bson.E{Key: "$and", Value: bson.A{match}})
+	}
+	if err := listFacets(ctx, col, pagination, fields, filterAggregate); err != nil {
+		return nil, err
+	}
+	if pagination.Cursor != nil {
+		return keysetList[T](ctx, col, pagination, fields, filterAggregate)
+	}
+
+	sorts, err := fields.Sorts(pagination.Sort)
+	if err != nil {
+		return nil, err
+	}
+	projection, err := fields.Projection(pagination.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	// one more document tells whether documents follow the page
+	options := options.Find().
+		SetLimit(int64(pagination.PerPage + 1)).
+		SetSkip(int64((pagination.Page - 1) * pagination.PerPage)).
+		SetSort(sortAggregate(sorts)).
+		SetProjection(projectionAggregate(projection))
+
+	if mode == paginate.CountEstimated && len(filterAggregate) > 0 {
+		mode = paginate.CountExact
+	}
+	var (
+		count    int64
+		countErr error
+		wg       sync.WaitGroup
+	)
+	if mode != paginate.CountNone {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if mode == paginate.CountEstimated {
+				count, countErr = col.EstimatedDocumentCount(ctx)
+				return
+			}
+			count, countErr = col.CountDocuments(ctx, filterAggregate)
+		}()
+	}
+
+	data, err := findAll[T](ctx, col, filterAggregate, options)
+	wg.Wait()
+	if err = errors.Join(err, countErr); err != nil {
 		return nil, err
 	}
 
-	count, err := col.CountDocuments(ctx, filterAggregate)
+	data = paginate.PageRows(pagination, data)
+	switch mode {
+	case paginate.CountExact:
+		pagination.SetTotalItems(count)
+	case paginate.CountEstimated:
+		pagination.SetEstimatedTotalItems(count, len(data))
+	}
+	return data, nil
+}
+
+func findAll[T any](ctx context.Context, col *mongo.Collection, filter bson.D, options *options.FindOptions) ([]T, error) {
+	cursor, err := col.Find(ctx, filter, options)
 	if err != nil {
 		return nil, err
 	}
-	pagination.SetTotalItems(count)
+	defer cursor.Close(ctx)
 
+	var data []T
+	if err = cursor.All(ctx, &data); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
-func sortAggregate(paginationSort []paginate.Sort) bson.D {
+// sortAggregate sorts by given sorts, their fields are mapped document fields or TextScore.
+func sortAggregate(sorts []paginate.Sort) bson.D {
 	sortAggregate := bson.D{}
-	for _, sort := range paginationSort {
+	for _, sort := range sorts {
+		if sort.Field == TextScore {
+			sortAggregate = append(sortAggregate, bson.E{Key: TextScore, Value: bson.D{{Key: "$meta", Value: "textScore"}}})
+			continue
+		}
 		msort := -1
 		if sort.Arrange == paginate.SortOrderAscending {
 			msort = 1
@@ -63,50 +167,42 @@ func projectionAggregate(fields []string) bson.D {
 	return projection
 }
 
-func filterAggregate(filters []paginate.Filter, queryableFields map[string]string) bson.D {
-	if len(filters) == 0 {
-		return bson.D{}
-	}
-
+// filterAggregate returns match of filters of filterable fields, values of filters are parsed by types of their fields
+// and filters not fitting them are returned as paginate.FilterError.
+func filterAggregate(filters []paginate.Filter, fields paginate.Fields) (bson.D, error) {
 	filterAggregate := bson.D{}
-	match := bson.D{}
-
 	for _, filter := range filters {
-		field, ok := queryableFields[filter.Key]
+		field, ok := fields.Filter[filter.Key]
 		if !ok {
 			continue
 		}
+		values, err := fields.Values(filter)
+		if err != nil {
+			return nil, err
+		}
+		filterAggregate = append(filterAggregate, filterMatch(field, filter.Condition, values)...)
+	}
+	return filterAggregate, nil
+}
 
-		switch filter.Condition {
-		case paginate.FilterLike:
-			match = bson.D{{Key: field, Value: primitive.Regex{Pattern: filter.Value, Options: "i"}}} // "i" for case insensitive
-
-		case paginate.FilterIn:
-			values := strings.Split(filter.Value, ",")
-			if len(values) < 2 {
-				continue
-			}
+// filterMatch returns match of condition on given field, values are parsed by paginate.Fields.Values.
+func filterMatch(field, condition string, values []any) bson.D {
+	switch condition {
+	case paginate.FilterLike:
+		return bson.D{{Key: field, Value: primitive.Regex{Pattern: paginate.LikeRegexp(values[0].(string)), Options: "s"}}}
 
-			arr := bson.A{}
-			for _, v := range values {
-				arr = append(arr, sanitize(v))
-			}
-			match = bson.D{{Key: field, Value: bson.D{{Key: "$in", Value: arr}}}}
+	case paginate.FilterILike:
+		return bson.D{{Key: field, Value: primitive.Regex{Pattern: paginate.LikeRegexp(values[0].(string)), Options: "si"}}} // "i" for case insensitive
 
-		case paginate.FilterBetween:
-			values := strings.Split(filter.Value, ",")
-			if len(values) < 2 {
-				continue
-			}
-			match = bson.D{{Key: field, Value: bson.D{{Key: "$gte", Value: sanitize(values[0])}, {Key: "$lte", Value: sanitize(values[1])}}}}
+	case paginate.FilterIn:
+		return bson.D{{Key: field, Value: bson.D{{Key: "$in", Value: bson.A(values)}}}}
 
-		default:
-			match = bson.D{{Key: field, Value: bson.D{{Key: conditionToNosql(filter.Condition), Value: sanitize(filter.Value)}}}}
-		}
+	case paginate.FilterBetween:
+		return bson.D{{Key: field, Value: bson.D{{Key: "$gte", Value: values[0]}, {Key: "$lte", Value: values[1]}}}}
 
-		filterAggregate = append(filterAggregate, match...)
+	default:
+		return bson.D{{Key: field, Value: bson.D{{Key: conditionToNosql(condition), Value: values[0]}}}}
 	}
-	return filterAggregate
 }
 
 func conditionToNosql(condition string) string {
@@ -114,7 +210,7 @@ func conditionToNosql(condition string) string {
 	case paginate.FilterEqual:
 		return "$eq"
 	case paginate.FilterNotEqual:
-		return "$neq"
+		return "$ne"
 	case paginate.FilterGreater:
 		return "$gt"
 	case paginate.FilterGreaterEqual:
@@ -128,13 +224,3 @@ func conditionToNosql(condition string) string {
 		return ""
 	}
 }
-func sanitize(v string) any {
-	if digit, err := strconv.ParseFloat(v, 64); err == nil {
-		return digit
-	}
-	t, err := time.Parse(time.RFC3339, v)
-	if err == nil {
-		return primitive.NewDateTimeFromTime(t)
-	}
-	return v
-}
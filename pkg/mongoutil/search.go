@@ -0,0 +1,30 @@
+package mongoutil
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TextScore is the sort field of relevance of documents matched by TextSearch, map sort fields to it, e.g.
+// paginate.SortRelevance. documents are sorted most relevant first whatever the arrange, mongo sorts scores only so.
+// cursor pages could not be sorted by it.
+const TextScore = "textScore"
+
+// TextSearch returns predicate of documents having all terms in fields of text index of collection, see TextIndex.
+// terms are quoted as phrases, so they are never negated and all of them must be matched.
+func TextSearch(terms []string) bson.E {
+	return bson.E{Key: "$text", Value: bson.D{{Key: "$search", Value: `"` + strings.Join(terms, `" "`) + `"`}}}
+}
+
+// TextIndex returns text index of given fields, collections have one text index at most. words are neither stemmed
+// nor stopwords, same as words of search.Terms.
+func TextIndex(name string, fields ...string) mongo.IndexModel {
+	keys := bson.D{}
+	for _, field := range fields {
+		keys = append(keys, bson.E{Key: field, Value: "text"})
+	}
+	return mongo.IndexModel{Keys: keys, Options: options.Index().SetName(name).SetDefaultLanguage("none")}
+}
@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+)
+
+type logDriver struct {
+	logger *slog.Logger
+}
+
+// NewLogDriver returns a driver which only logs messages, it is meant for development
+// since message bodies, e.g. secret links, are written to logs.
+func NewLogDriver(logger *slog.Logger) Driver {
+	return logDriver{logger: logger}
+}
+
+func (d logDriver) Send(ctx context.Context, message Message) error {
+	d.logger.InfoContext(ctx, "notification",
+		slog.String("to", message.To),
+		slog.String("subject", message.Subject),
+		slog.String("body", message.Body),
+	)
+	return nil
+}
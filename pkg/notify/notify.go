@@ -0,0 +1,15 @@
+// Package notify sends notifications, e.g. emails, to users through pluggable drivers.
+package notify
+
+import "context"
+
+// Message is a notification sent to a recipient, To is the address of recipient, e.g. an email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Driver interface {
+	Send(ctx context.Context, message Message) error
+}
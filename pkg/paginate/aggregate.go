@@ -0,0 +1,154 @@
+package paginate
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	facetParamName = "facet"
+
+	AggregateCount = "count"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+	AggregateSum   = "sum"
+
+	BucketHour  = "hour"
+	BucketDay   = "day"
+	BucketMonth = "month"
+	BucketYear  = "year"
+
+	// MaxFacetGroups is the most groups of a facet, groups are ordered by their keys.
+	MaxFacetGroups = 100
+)
+
+var (
+	metricFuncs = []string{AggregateMin, AggregateMax, AggregateSum}
+	buckets     = []string{BucketHour, BucketDay, BucketMonth, BucketYear}
+)
+
+// Aggregation requests a facet of list, counts of rows grouped by a field and metrics of each group.
+// facets are computed over all rows matching filters, not only the listed page.
+type Aggregation struct {
+	// Field is the field rows are grouped by.
+	Field string
+	// Bucket groups rows of time fields by their hour, day, month or year instead of their values.
+	Bucket string
+	// Metrics are computed on fields of rows of each group besides their count.
+	Metrics []Metric
+}
+
+// Metric is an aggregate function on a field, e.g. max(created_at).
+type Metric struct {
+	Func  string
+	Field string
+}
+
+func (m Metric) String() string {
+	return fmt.Sprintf("%s(%s)", m.Func, m.Field)
+}
+
+// Facet is the result of an Aggregation.
+type Facet struct {
+	Field  string  `json:"field"`
+	Bucket string  `json:"bucket,omitempty"`
+	Groups []Group `json:"groups"`
+}
+
+// Group is a group of rows of a facet, keys of buckets are their start time in UTC, e.g. 2024-01-02T00:00:00Z.
+type Group struct {
+	Key   any   `json:"key"`
+	Count int64 `json:"count"`
+	// Metrics are values of metrics of aggregation keyed by their string, e.g. max(created_at).
+	Metrics map[string]any `json:"metrics,omitempty"`
+}
+
+// ParseAggregation parses facet parameter of field, optionally followed by its bucket, and metrics separated by comma,
+// e.g. created_at:day or role,min(created_at),sum(score). count of groups is always given, so count is no metric.
+// spec is not validated, see Fields.Aggregations.
+func ParseAggregation(spec string) Aggregation {
+	parts := strings.Split(spec, ",")
+	field, bucket, _ := strings.Cut(parts[0], ":")
+	aggregation := Aggregation{Field: field, Bucket: bucket}
+	for _, part := range parts[1:] {
+		if part == AggregateCount {
+			continue
+		}
+		name, rest, ok := strings.Cut(part, "(")
+		field, closed := strings.CutSuffix(rest, ")")
+		if !ok || !closed {
+			// kept to be rejected as unknown function
+			aggregation.Metrics = append(aggregation.Metrics, Metric{Func: part})
+			continue
+		}
+		aggregation.Metrics = append(aggregation.Metrics, Metric{Func: name, Field: field})
+	}
+	return aggregation
+}
+
+// Aggregations returns aggregations with fields mapped to their columns, aggregations are on filter fields.
+// unknown fields and functions, buckets of non-time fields and sums of non-numeric fields are returned as FieldError.
+func (f Fields) Aggregations(aggregations []Aggregation) ([]Aggregation, error) {
+	mapped := make([]Aggregation, 0, len(aggregations))
+	for _, aggregation := range aggregations {
+		column, ok := f.Filter[aggregation.Field]
+		if !ok {
+			return nil, &FieldError{Param: facetParamName, Field: aggregation.Field, Allowed: slices.Sorted(maps.Keys(f.Filter))}
+		}
+		if aggregation.Bucket != "" {
+			if !slices.Contains(buckets, aggregation.Bucket) {
+				return nil, &FieldError{Param: facetParamName, Field: aggregation.Bucket, Allowed: buckets}
+			}
+			if f.Types[aggregation.Field].Type != TypeTime {
+				return nil, &FieldError{Param: facetParamName, Field: aggregation.Field + ":" + aggregation.Bucket, Allowed: f.typed(TypeTime)}
+			}
+		}
+		metrics := make([]Metric, 0, len(aggregation.Metrics))
+		for _, metric := range aggregation.Metrics {
+			if !slices.Contains(metricFuncs, metric.Func) {
+				return nil, &FieldError{Param: facetParamName, Field: metric.String(), Allowed: metricFuncs}
+			}
+			column, ok := f.Filter[metric.Field]
+			if !ok {
+				return nil, &FieldError{Param: facetParamName, Field: metric.Field, Allowed: slices.Sorted(maps.Keys(f.Filter))}
+			}
+			if metric.Func == AggregateSum && !slices.Contains([]Type{TypeInt, TypeFloat}, f.Types[metric.Field].Type) {
+				return nil, &FieldError{Param: facetParamName, Field: metric.String(), Allowed: f.typed(TypeInt, TypeFloat)}
+			}
+			metrics = append(metrics, Metric{Func: metric.Func, Field: column})
+		}
+		mapped = append(mapped, Aggregation{Field: column, Bucket: aggregation.Bucket, Metrics: metrics})
+	}
+	return mapped, nil
+}
+
+// typed returns sorted filter fields of given types.
+func (f Fields) typed(types ...Type) []string {
+	var fields []string
+	for field := range f.Filter {
+		if slices.Contains(types, f.Types[field].Type) {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// BucketStart returns start of bucket of t in UTC, see Group.
+func BucketStart(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	switch bucket {
+	case BucketHour:
+		return t.Truncate(time.Hour)
+	case BucketDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case BucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case BucketYear:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
@@ -0,0 +1,76 @@
+package paginate_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAggregation(t *testing.T) {
+	require.Equal(t, paginate.Aggregation{Field: "created_at", Bucket: "day"}, paginate.ParseAggregation("created_at:day"))
+	require.Equal(t, paginate.Aggregation{Field: "role", Metrics: []paginate.Metric{
+		{Func: "min", Field: "created_at"}, {Func: "sum", Field: "score"}, {Func: "max("},
+	}}, paginate.ParseAggregation("role,count,min(created_at),sum(score),max("))
+
+	r, err := http.NewRequest(http.MethodGet, "/somewhere?facet=role&facet=created_at:month&role=admin", http.NoBody)
+	require.NoError(t, err)
+	pagination := paginate.ParseFromRequest(r)
+	require.Equal(t, []paginate.Aggregation{{Field: "role"}, {Field: "created_at", Bucket: "month"}}, pagination.Aggregations)
+	require.Equal(t, []paginate.Filter{{Key: "role", Value: "admin", Condition: paginate.FilterEqual}}, pagination.Filters)
+}
+
+func TestFieldsAggregations(t *testing.T) {
+	fields := paginate.Fields{
+		Filter: map[string]string{"role": "role", "score": "points", "created_at": "created"},
+		Types:  map[string]paginate.Field{"score": {Type: paginate.TypeFloat}, "created_at": {Type: paginate.TypeTime}},
+	}
+	mapped, err := fields.Aggregations([]paginate.Aggregation{paginate.ParseAggregation("created_at:day,sum(score),max(role)")})
+	require.NoError(t, err)
+	require.Equal(t, []paginate.Aggregation{{Field: "created", Bucket: "day", Metrics: []paginate.Metric{
+		{Func: "sum", Field: "points"}, {Func: "max", Field: "role"},
+	}}}, mapped)
+
+	for _, tc := range []struct {
+		spec     string
+		expected paginate.FieldError
+	}{
+		{"name", paginate.FieldError{Param: "facet", Field: "name", Allowed: []string{"created_at", "role", "score"}}},
+		{"created_at:week", paginate.FieldError{Param: "facet", Field: "week", Allowed: []string{"hour", "day", "month", "year"}}},
+		{"role:day", paginate.FieldError{Param: "facet", Field: "role:day", Allowed: []string{"created_at"}}},
+		{"role,avg(score)", paginate.FieldError{Param: "facet", Field: "avg(score)", Allowed: []string{"min", "max", "sum"}}},
+		{"role,min(name)", paginate.FieldError{Param: "facet", Field: "name", Allowed: []string{"created_at", "role", "score"}}},
+		{"role,sum(role)", paginate.FieldError{Param: "facet", Field: "sum(role)", Allowed: []string{"score"}}},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			_, err := fields.Aggregations([]paginate.Aggregation{paginate.ParseAggregation(tc.spec)})
+			require.Equal(t, &tc.expected, err)
+		})
+	}
+}
+
+func TestListFacets(t *testing.T) {
+	pagination := &paginate.Pagination{
+		Page:    1,
+		PerPage: 1,
+		Filters: []paginate.Filter{{Key: "id", Value: "4", Condition: paginate.FilterNotEqual}},
+		Aggregations: []paginate.Aggregation{
+			paginate.ParseAggregation("active,sum(score),max(created_at)"),
+			paginate.ParseAggregation("created_at:month"),
+		},
+	}
+	got, err := paginate.List(items(), pagination, itemFields, paginate.TagAccessors[item]("db"))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, []paginate.Facet{
+		{Field: "active", Groups: []paginate.Group{
+			{Key: false, Count: 1, Metrics: map[string]any{"sum(score)": 10.0, "max(created_at)": day.AddDate(0, 0, 1)}},
+			{Key: true, Count: 2, Metrics: map[string]any{"sum(score)": 19.0, "max(created_at)": day.AddDate(0, 0, 2)}},
+		}},
+		{Field: "created_at", Bucket: "month", Groups: []paginate.Group{{Key: "2024-01-01T00:00:00Z", Count: 3}}},
+	}, pagination.Facets)
+}
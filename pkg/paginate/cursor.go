@@ -0,0 +1,201 @@
+package paginate
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	afterParamName  = "after"
+	beforeParamName = "before"
+
+	// cursorSignatureSize is the size of truncated hmac of tokens in bytes.
+	cursorSignatureSize = 16
+)
+
+var (
+	// ErrInvalidCursor returned for tampered tokens and tokens of another sort.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrCursorNotSupported returned by lists which can not page by cursor,
+	// e.g. sorted by computed expressions or having rows without tiebreaker.
+	ErrCursorNotSupported = errors.New("cursor pagination is not supported by this list")
+)
+
+var (
+	cursorSecretMu sync.RWMutex
+	cursorSecret   = randomSecret()
+)
+
+// SetCursorSecret sets secret of signing cursor tokens, tokens of a random secret are valid until restart otherwise.
+func SetCursorSecret(secret []byte) {
+	cursorSecretMu.Lock()
+	defer cursorSecretMu.Unlock()
+	cursorSecret = slices.Clone(secret)
+}
+
+func randomSecret() []byte {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+}
+
+// Cursor requests keyset pagination instead of page numbers, rows are listed after or before the row of token.
+type Cursor struct {
+	// Token is next_cursor or prev_cursor of a previous page, the first page, or the last page of backward cursors, if empty.
+	Token string
+	// Backward lists rows before row of token, it is set by before parameter.
+	Backward bool
+}
+
+// Keyset is the plan of listing a cursor page.
+type Keyset struct {
+	// Sort is sort of pagination followed by tiebreaker, arranges are reversed for backward cursors,
+	// rows are fetched in this order.
+	Sort []Sort
+	// Values are sort values of row of token in order of Sort, they are empty for the first page.
+	Values   []json.RawMessage
+	Backward bool
+
+	signature string
+}
+
+type cursorPayload struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// Keyset returns plan of listing cursor page of p, rows having same sort values are ordered by tiebreaker field,
+// which must be unique, e.g. id. it returns ErrInvalidCursor if token is not issued for same sort.
+func (p *Pagination) Keyset(tiebreaker string) (Keyset, error) {
+	if p.Cursor == nil {
+		return Keyset{}, ErrCursorNotSupported
+	}
+
+	sorts := slices.Clone(p.Sort)
+	arrange := SortOrderAscending
+	if len(sorts) > 0 {
+		arrange = sorts[len(sorts)-1].Arrange
+	}
+	if !slices.ContainsFunc(sorts, func(sort Sort) bool { return sort.Field == tiebreaker }) {
+		sorts = append(sorts, Sort{Field: tiebreaker, Arrange: arrange})
+	}
+
+	keyset := Keyset{Sort: sorts, Backward: p.Cursor.Backward, signature: sortSignature(sorts)}
+	if p.Cursor.Token != "" {
+		payload, err := decodeCursor(p.Cursor.Token)
+		if err != nil {
+			return Keyset{}, err
+		}
+		if payload.Sort != keyset.signature || len(payload.Values) != len(sorts) {
+			return Keyset{}, ErrInvalidCursor
+		}
+		keyset.Values = payload.Values
+	}
+
+	if keyset.Backward {
+		for i := range keyset.Sort {
+			keyset.Sort[i].Arrange = reverseArrange(keyset.Sort[i].Arrange)
+		}
+	}
+	return keyset, nil
+}
+
+// Page trims rows fetched in order of keyset, at most PerPage+1 rows to know whether more rows are left,
+// into page of p in order of its sort and sets NextCursor and PrevCursor of p by sort values of rows returned by values.
+func Page[T any](p *Pagination, keyset Keyset, rows []T, values func(T) ([]json.RawMessage, error)) ([]T, error) {
+	hasMore := len(rows) > p.PerPage
+	rows = rows[:min(len(rows), p.PerPage)]
+	if keyset.Backward {
+		slices.Reverse(rows)
+	}
+
+	// pages of cursors have no number and are not counted
+	p.Page, p.NextCursor, p.PrevCursor, p.HasMore = 0, "", "", hasMore
+	if len(rows) == 0 {
+		return rows, nil
+	}
+
+	hasNext, hasPrev := hasMore, keyset.Values != nil
+	if keyset.Backward {
+		hasNext, hasPrev = keyset.Values != nil, hasMore
+	}
+	if hasNext {
+		last, err := values(rows[len(rows)-1])
+		if err != nil {
+			return nil, err
+		}
+		if p.NextCursor, err = encodeCursor(cursorPayload{Sort: keyset.signature, Values: last}); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		first, err := values(rows[0])
+		if err != nil {
+			return nil, err
+		}
+		if p.PrevCursor, err = encodeCursor(cursorPayload{Sort: keyset.signature, Values: first}); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func sortSignature(sorts []Sort) string {
+	parts := make([]string, len(sorts))
+	for i, sort := range sorts {
+		parts[i] = sort.Field + " " + sort.Arrange
+	}
+	return strings.Join(parts, ",")
+}
+
+func reverseArrange(arrange string) string {
+	if arrange == SortOrderDescending {
+		return SortOrderAscending
+	}
+	return SortOrderDescending
+}
+
+// encodeCursor returns base64 of payload and its signature separated by a dot.
+func encodeCursor(payload cursorPayload) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signCursor(encoded)), nil
+}
+
+func decodeCursor(token string) (cursorPayload, error) {
+	var payload cursorPayload
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return payload, ErrInvalidCursor
+	}
+	given, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(given, signCursor(encoded)) {
+		return payload, ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return payload, ErrInvalidCursor
+	}
+	if err = json.Unmarshal(data, &payload); err != nil {
+		return payload, ErrInvalidCursor
+	}
+	return payload, nil
+}
+
+func signCursor(encoded string) []byte {
+	cursorSecretMu.RLock()
+	h := hmac.New(sha256.New, cursorSecret)
+	cursorSecretMu.RUnlock()
+	h.Write([]byte(encoded))
+	return h.Sum(nil)[:cursorSignatureSize]
+}
@@ -0,0 +1,100 @@
+package paginate_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCursorFromRequest(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/somewhere?after=abc&per_page=5&name=smith", http.NoBody)
+	require.NoError(t, err)
+	pagination := paginate.ParseFromRequest(r)
+	require.Equal(t, &paginate.Cursor{Token: "abc"}, pagination.Cursor)
+	require.Equal(t, []paginate.Filter{{Key: "name", Value: "smith", Condition: paginate.FilterEqual}}, pagination.Filters)
+
+	r, err = http.NewRequest(http.MethodGet, "/somewhere?before=", http.NoBody)
+	require.NoError(t, err)
+	require.Equal(t, &paginate.Cursor{Backward: true}, paginate.ParseFromRequest(r).Cursor)
+
+	r, err = http.NewRequest(http.MethodGet, "/somewhere?page=2", http.NoBody)
+	require.NoError(t, err)
+	require.Nil(t, paginate.ParseFromRequest(r).Cursor)
+}
+
+func TestKeyset(t *testing.T) {
+	pagination := &paginate.Pagination{
+		PerPage: 2,
+		Sort:    []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderDescending}},
+		Cursor:  &paginate.Cursor{},
+	}
+	keyset, err := pagination.Keyset("id")
+	require.NoError(t, err)
+	require.Equal(t, []paginate.Sort{
+		{Field: "name", Arrange: paginate.SortOrderDescending},
+		{Field: "id", Arrange: paginate.SortOrderDescending},
+	}, keyset.Sort)
+	require.Nil(t, keyset.Values)
+
+	rows := []string{"c", "b", "a"}
+	page, err := paginate.Page(pagination, keyset, rows, values)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b"}, page)
+	require.NotEmpty(t, pagination.NextCursor)
+	require.Empty(t, pagination.PrevCursor)
+
+	next := &paginate.Pagination{PerPage: 2, Sort: pagination.Sort, Cursor: &paginate.Cursor{Token: pagination.NextCursor}}
+	keyset, err = next.Keyset("id")
+	require.NoError(t, err)
+	require.Equal(t, []json.RawMessage{json.RawMessage(`"b"`), json.RawMessage(`"b"`)}, keyset.Values)
+
+	page, err = paginate.Page(next, keyset, []string{"a"}, values)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, page)
+	require.Empty(t, next.NextCursor)
+	require.NotEmpty(t, next.PrevCursor)
+
+	// backward pages are fetched in reverse order
+	prev := &paginate.Pagination{PerPage: 2, Sort: pagination.Sort, Cursor: &paginate.Cursor{Token: next.PrevCursor, Backward: true}}
+	keyset, err = prev.Keyset("id")
+	require.NoError(t, err)
+	require.Equal(t, paginate.SortOrderAscending, keyset.Sort[0].Arrange)
+	page, err = paginate.Page(prev, keyset, []string{"b", "c"}, values)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b"}, page)
+	require.NotEmpty(t, prev.NextCursor)
+	require.Empty(t, prev.PrevCursor)
+}
+
+func TestKeysetInvalidCursor(t *testing.T) {
+	pagination := &paginate.Pagination{PerPage: 1, Cursor: &paginate.Cursor{}}
+	keyset, err := pagination.Keyset("id")
+	require.NoError(t, err)
+	_, err = paginate.Page(pagination, keyset, []string{"a", "b"}, values)
+	require.NoError(t, err)
+	token := pagination.NextCursor
+
+	for name, tc := range map[string]*paginate.Pagination{
+		"tampered":     {Cursor: &paginate.Cursor{Token: "x" + token}},
+		"malformed":    {Cursor: &paginate.Cursor{Token: "abc"}},
+		"another sort": {Sort: []paginate.Sort{{Field: "name", Arrange: paginate.SortOrderAscending}}, Cursor: &paginate.Cursor{Token: token}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.Keyset("id")
+			require.ErrorIs(t, err, paginate.ErrInvalidCursor)
+		})
+	}
+
+	paginate.SetCursorSecret([]byte("another secret"))
+	_, err = (&paginate.Pagination{Cursor: &paginate.Cursor{Token: token}}).Keyset("id")
+	require.ErrorIs(t, err, paginate.ErrInvalidCursor)
+}
+
+// values returns row as both sort and tiebreaker value.
+func values(row string) ([]json.RawMessage, error) {
+	v, err := json.Marshal(row)
+	return []json.RawMessage{v, v}, err
+}
@@ -0,0 +1,379 @@
+package paginate
+
+import (
+	"fmt"
+	"strings"
+)
+
+const filterParamName = "filter"
+
+// SyntaxError is returned for invalid filter expressions, Pos is the byte offset of error in expression.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.Pos, e.Msg)
+}
+
+// Expr is a boolean expression of filters, see ParseExpression.
+type Expr interface {
+	// Match evaluates expression by results of match for its comparisons.
+	Match(match func(Filter) bool) bool
+}
+
+// AndExpr matches if all of its expressions match.
+type AndExpr []Expr
+
+// OrExpr matches if any of its expressions matches.
+type OrExpr []Expr
+
+// NotExpr matches if its expression does not match.
+type NotExpr struct {
+	Expr Expr
+}
+
+// Comparison is a filter of expression, values of in and between conditions are joined by comma same as filters.
+type Comparison struct {
+	Filter
+	// Pos is the byte offset of comparison in expression.
+	Pos int
+}
+
+func (e AndExpr) Match(match func(Filter) bool) bool {
+	for _, expr := range e {
+		if !expr.Match(match) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e OrExpr) Match(match func(Filter) bool) bool {
+	for _, expr := range e {
+		if expr.Match(match) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e NotExpr) Match(match func(Filter) bool) bool {
+	return !e.Expr.Match(match)
+}
+
+func (c *Comparison) Match(match func(Filter) bool) bool {
+	return match(c.Filter)
+}
+
+// Match evaluates expr by match, nil expressions match all.
+func Match(expr Expr, match func(Filter) bool) bool {
+	return expr == nil || expr.Match(match)
+}
+
+// Comparisons returns comparisons of expr in order of expression, they could be modified in place.
+func Comparisons(expr Expr) []*Comparison {
+	var comparisons []*Comparison
+	var walk func(Expr)
+	walk = func(expr Expr) {
+		switch e := expr.(type) {
+		case AndExpr:
+			for _, expr := range e {
+				walk(expr)
+			}
+		case OrExpr:
+			for _, expr := range e {
+				walk(expr)
+			}
+		case NotExpr:
+			walk(e.Expr)
+		case *Comparison:
+			comparisons = append(comparisons, e)
+		}
+	}
+	walk(expr)
+	return comparisons
+}
+
+// Where returns expression of filter parameter, it is nil if not given. expression is parsed once,
+// so comparisons returned by Comparisons of it could be normalized before listing.
+func (p *Pagination) Where() (Expr, error) {
+	if p.where == nil && p.whereErr == nil && p.Expression != "" {
+		p.where, p.whereErr = ParseExpression(p.Expression)
+	}
+	return p.where, p.whereErr
+}
+
+// operators maps operators of expressions to filter conditions, =out= is negated in.
+var operators = map[string]string{
+	"==":        FilterEqual,
+	"!=":        FilterNotEqual,
+	"=gt=":      FilterGreater,
+	">":         FilterGreater,
+	"=ge=":      FilterGreaterEqual,
+	"=gte=":     FilterGreaterEqual,
+	">=":        FilterGreaterEqual,
+	"=lt=":      FilterLess,
+	"<":         FilterLess,
+	"=le=":      FilterLessEqual,
+	"=lte=":     FilterLessEqual,
+	"<=":        FilterLessEqual,
+	"=in=":      FilterIn,
+	"=out=":     FilterIn,
+	"=between=": FilterBetween,
+	"=like=":    FilterLike,
+	"=ilike=":   FilterILike,
+}
+
+// ParseExpression parses RSQL/FIQL-style expression of filters, e.g. (status==1,status==2);created_at=gt=2024-01-01
+//   - ";" is and, "," is or which binds weaker than and, "!" negates its following comparison or group.
+//   - comparisons are field, operator and value: == != =gt= =ge= =lt= =le= (or > >= < <=), =like=, =ilike=,
+//     =in=(a,b) and =out=(a,b) having one or more values, =between=(a,b) having two values.
+//   - values having reserved characters, e.g. spaces, are quoted by ' or " and escaped by \, no value may contain comma.
+func ParseExpression(expression string) (Expr, error) {
+	p := &expressionParser{input: expression}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+	return expr, nil
+}
+
+type expressionParser struct {
+	input string
+	pos   int
+}
+
+func (p *expressionParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// consume skips spaces and the given character if it is next.
+func (p *expressionParser) consume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *expressionParser) or() (Expr, error) {
+	var or OrExpr
+	for {
+		expr, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, expr)
+		if !p.consume(',') {
+			break
+		}
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *expressionParser) and() (Expr, error) {
+	var and AndExpr
+	for {
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+		if !p.consume(';') {
+			break
+		}
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *expressionParser) unary() (Expr, error) {
+	p.skipSpaces()
+	// "!=" is an operator, so "!" is a negation only at start of terms
+	if p.consume('!') {
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Expr: expr}, nil
+	}
+	if p.consume('(') {
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, p.errorf("missing closing parenthesis")
+		}
+		return expr, nil
+	}
+	return p.comparison()
+}
+
+func (p *expressionParser) comparison() (Expr, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && isSelectorChar(p.input[p.pos], p.pos == start) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.pos == len(p.input) {
+			return nil, p.errorf("missing field")
+		}
+		return nil, p.errorf("unexpected %q, expected field", p.input[p.pos])
+	}
+	key := p.input[start:p.pos]
+
+	p.skipSpaces()
+	opStart := p.pos
+	operator := p.operator()
+	condition, ok := operators[operator]
+	if !ok {
+		p.pos = opStart
+		return nil, p.errorf("unknown operator %q", operator)
+	}
+
+	p.skipSpaces()
+	valuesStart := p.pos
+	values, err := p.values()
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &Comparison{Filter: Filter{Key: key, Condition: condition, Value: strings.Join(values, ",")}, Pos: start}
+	switch {
+	case condition == FilterBetween && len(values) != 2:
+		return nil, &SyntaxError{Pos: valuesStart, Msg: fmt.Sprintf("%s needs two values", operator)}
+	case condition == FilterIn && len(values) == 1:
+		// in filters need several values
+		comparison.Condition = FilterEqual
+		if operator == "=out=" {
+			comparison.Condition = FilterNotEqual
+		}
+		return comparison, nil
+	case condition != FilterIn && condition != FilterBetween && len(values) != 1:
+		return nil, &SyntaxError{Pos: valuesStart, Msg: fmt.Sprintf("%s needs one value", operator)}
+	}
+	if operator == "=out=" {
+		return NotExpr{Expr: comparison}, nil
+	}
+	return comparison, nil
+}
+
+// operator reads ==, !=, <, <=, >, >= or =name=.
+func (p *expressionParser) operator() string {
+	rest := p.input[p.pos:]
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(rest, op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	if !strings.HasPrefix(rest, "=") {
+		return ""
+	}
+	end := strings.IndexByte(rest[1:], '=')
+	if end < 0 {
+		return rest
+	}
+	p.pos += end + 2
+	return rest[:end+2]
+}
+
+// values reads a value or a parenthesized list of values.
+func (p *expressionParser) values() ([]string, error) {
+	if !p.consume('(') {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return []string{value}, nil
+	}
+	var values []string
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if p.consume(')') {
+			return values, nil
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("missing closing parenthesis of values")
+		}
+	}
+}
+
+func (p *expressionParser) value() (string, error) {
+	p.skipSpaces()
+	if p.pos == len(p.input) {
+		return "", p.errorf("missing value")
+	}
+	start := p.pos
+	var value string
+	if quote := p.input[p.pos]; quote == '\'' || quote == '"' {
+		var b strings.Builder
+		p.pos++
+		for {
+			if p.pos == len(p.input) {
+				return "", &SyntaxError{Pos: start, Msg: "unterminated quoted value"}
+			}
+			c := p.input[p.pos]
+			p.pos++
+			if c == quote {
+				break
+			}
+			if c == '\\' && p.pos < len(p.input) {
+				c = p.input[p.pos]
+				p.pos++
+			}
+			b.WriteByte(c)
+		}
+		value = b.String()
+	} else {
+		for p.pos < len(p.input) && !isReserved(p.input[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", p.errorf("unexpected %q, expected value", p.input[p.pos])
+		}
+		value = p.input[start:p.pos]
+	}
+	if strings.Contains(value, ",") {
+		return "", &SyntaxError{Pos: start, Msg: "values may not contain comma"}
+	}
+	return value, nil
+}
+
+func isSelectorChar(c byte, first bool) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', c == '_':
+		return true
+	case '0' <= c && c <= '9', c == '.':
+		return !first
+	}
+	return false
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte("\"'();,=!<> \t", c) >= 0
+}
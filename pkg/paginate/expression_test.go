@@ -0,0 +1,119 @@
+package paginate_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/stretchr/testify/require"
+)
+
+func comparison(key, condition, value string, pos int) *paginate.Comparison {
+	return &paginate.Comparison{Filter: paginate.Filter{Key: key, Condition: condition, Value: value}, Pos: pos}
+}
+
+func TestParseExpression(t *testing.T) {
+	for _, tc := range []struct {
+		expression string
+		expected   paginate.Expr
+	}{
+		{"name==amir", comparison("name", paginate.FilterEqual, "amir", 0)},
+		{"(status==1,status==2);created_at=gt=2024-01-01", paginate.AndExpr{
+			paginate.OrExpr{comparison("status", paginate.FilterEqual, "1", 1), comparison("status", paginate.FilterEqual, "2", 11)},
+			comparison("created_at", paginate.FilterGreater, "2024-01-01", 22),
+		}},
+		// and binds stronger than or
+		{"a==1,b==2;c==3", paginate.OrExpr{
+			comparison("a", paginate.FilterEqual, "1", 0),
+			paginate.AndExpr{comparison("b", paginate.FilterEqual, "2", 5), comparison("c", paginate.FilterEqual, "3", 10)},
+		}},
+		{"!(role==admin) ; age >= 18", paginate.AndExpr{
+			paginate.NotExpr{Expr: comparison("role", paginate.FilterEqual, "admin", 2)},
+			comparison("age", paginate.FilterGreaterEqual, "18", 17),
+		}},
+		{"status=in=(1,2,3)", comparison("status", paginate.FilterIn, "1,2,3", 0)},
+		{"status=in=(1)", comparison("status", paginate.FilterEqual, "1", 0)},
+		{"status=out=(1,2)", paginate.NotExpr{Expr: comparison("status", paginate.FilterIn, "1,2", 0)}},
+		{"age=between=(18,30)", comparison("age", paginate.FilterBetween, "18,30", 0)},
+		{`name=='amir zayi';attributes.city!="te\"hran"`, paginate.AndExpr{
+			comparison("name", paginate.FilterEqual, "amir zayi", 0),
+			comparison("attributes.city", paginate.FilterNotEqual, `te"hran`, 18),
+		}},
+	} {
+		t.Run(tc.expression, func(t *testing.T) {
+			expr, err := paginate.ParseExpression(tc.expression)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, expr)
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expression string
+		expected   paginate.SyntaxError
+	}{
+		{"", paginate.SyntaxError{Pos: 0, Msg: "missing field"}},
+		{"name", paginate.SyntaxError{Pos: 4, Msg: `unknown operator ""`}},
+		{"name=~amir", paginate.SyntaxError{Pos: 4, Msg: `unknown operator "=~amir"`}},
+		{"name==", paginate.SyntaxError{Pos: 6, Msg: "missing value"}},
+		{"(name==a", paginate.SyntaxError{Pos: 8, Msg: "missing closing parenthesis"}},
+		{"name==a)", paginate.SyntaxError{Pos: 7, Msg: `unexpected ')'`}},
+		{"name==a;;", paginate.SyntaxError{Pos: 8, Msg: `unexpected ';', expected field`}},
+		{"age=between=(1)", paginate.SyntaxError{Pos: 12, Msg: "=between= needs two values"}},
+		{"age=gt=(1,2)", paginate.SyntaxError{Pos: 7, Msg: "=gt= needs one value"}},
+		{"name=='a,b'", paginate.SyntaxError{Pos: 6, Msg: "values may not contain comma"}},
+		{"name=='amir", paginate.SyntaxError{Pos: 6, Msg: "unterminated quoted value"}},
+	} {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, err := paginate.ParseExpression(tc.expression)
+			require.Equal(t, &tc.expected, err)
+		})
+	}
+}
+
+func TestExpressionMatch(t *testing.T) {
+	values := map[string]string{"name": "amir", "status": "2"}
+	match := func(filter paginate.Filter) bool {
+		return values[filter.Key] == filter.Value
+	}
+
+	for expression, expected := range map[string]bool{
+		"name==amir;status==2":              true,
+		"name==ali,status==2":               true,
+		"name==ali;status==2":               false,
+		"!(name==ali,status==1)":            true,
+		"!name==amir":                       false,
+		"(name==ali,name==amir);!status==1": true,
+	} {
+		expr, err := paginate.ParseExpression(expression)
+		require.NoError(t, err)
+		require.Equal(t, expected, paginate.Match(expr, match), expression)
+	}
+	require.True(t, paginate.Match(nil, match))
+}
+
+func TestWhere(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?filter="+url.QueryEscape("name==amir,name==ali")+"&status=1", nil)
+	pagination := paginate.ParseFromRequest(r)
+	require.Equal(t, "name==amir,name==ali", pagination.Expression)
+	require.Equal(t, []paginate.Filter{{Key: "status", Value: "1", Condition: paginate.FilterEqual}}, pagination.Filters)
+
+	expr, err := pagination.Where()
+	require.NoError(t, err)
+
+	// comparisons are kept, so they could be normalized
+	paginate.Comparisons(expr)[1].Value = "ALI"
+	expr, err = pagination.Where()
+	require.NoError(t, err)
+	require.Equal(t, paginate.OrExpr{
+		comparison("name", paginate.FilterEqual, "amir", 0),
+		comparison("name", paginate.FilterEqual, "ALI", 11),
+	}, expr)
+
+	pagination = &paginate.Pagination{Expression: "name=="}
+	_, err = pagination.Where()
+	var syntaxErr *paginate.SyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+}
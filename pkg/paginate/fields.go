@@ -0,0 +1,82 @@
+package paginate
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Fields whitelists fields of a list, they map fields given by clients to columns, expressions or document fields of drivers.
+type Fields struct {
+	// Filter are fields filtered by, filters of other fields are ignored.
+	Filter map[string]string
+	// Sort are fields sorted by, Filter is used if nil.
+	Sort map[string]string
+	// SortArgs are args of placeholders of Sort expressions keyed by their fields, e.g. search terms of relevance,
+	// sql drivers bind them wherever their fields are sorted by.
+	SortArgs map[string][]any
+	// Select are fields projected by fields parameter, they must map to columns of rows, Filter is used if nil.
+	Select map[string]string
+	// Types define types of filter fields and conditions allowed on them, fields without definition are strings.
+	Types map[string]Field
+}
+
+// FieldError is returned for fields of sort or fields parameters which are not allowed by list.
+type FieldError struct {
+	// Param is the query parameter of field, sort or fields.
+	Param string
+	Field string
+	// Allowed are fields, or arranges for invalid arranges of sort, allowed in Param.
+	Allowed []string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s by %q is not allowed, allowed values are: %s", e.Param, e.Field, strings.Join(e.Allowed, ", "))
+}
+
+// Sorts returns sorts with fields mapped to their columns, unknown fields and invalid arranges are returned as FieldError.
+func (f Fields) Sorts(sorts []Sort) ([]Sort, error) {
+	allowed := f.Sort
+	if allowed == nil {
+		allowed = f.Filter
+	}
+	mapped := make([]Sort, 0, len(sorts))
+	for _, sort := range sorts {
+		column, ok := allowed[sort.Field]
+		if !ok {
+			return nil, &FieldError{Param: sortParamName, Field: sort.Field, Allowed: slices.Sorted(maps.Keys(allowed))}
+		}
+		if !isValidSortArrange(sort.Arrange) {
+			return nil, &FieldError{Param: sortParamName, Field: sort.Arrange, Allowed: []string{SortOrderAscending, SortOrderDescending}}
+		}
+		mapped = append(mapped, Sort{Field: column, Arrange: sort.Arrange})
+	}
+	return mapped, nil
+}
+
+// Projection returns columns of fields, unknown fields are returned as FieldError. fields could be dotted paths into
+// their values, e.g. attributes.plan, which project the column of their first field, see ProjectionRoot.
+func (f Fields) Projection(fields []string) ([]string, error) {
+	allowed := f.Select
+	if allowed == nil {
+		allowed = f.Filter
+	}
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := allowed[ProjectionRoot(field)]
+		if !ok {
+			return nil, &FieldError{Param: fieldsParamName, Field: field, Allowed: slices.Sorted(maps.Keys(allowed))}
+		}
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+// ProjectionRoot returns the first field of dotted path of projected field, e.g. attributes of attributes.plan.
+func ProjectionRoot(field string) string {
+	root, _, _ := strings.Cut(field, ".")
+	return root
+}
@@ -0,0 +1,39 @@
+package paginate_test
+
+import (
+	"testing"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFields(t *testing.T) {
+	fields := paginate.Fields{
+		Filter: map[string]string{"name": "name", "phone": "phone_number", "age": "CAST(age AS REAL)"},
+		Select: map[string]string{"name": "name", "phone": "phone_number"},
+	}
+
+	// sort falls back to filter fields
+	sorts, err := fields.Sorts([]paginate.Sort{{Field: "age", Arrange: paginate.SortOrderDescending}})
+	require.NoError(t, err)
+	require.Equal(t, []paginate.Sort{{Field: "CAST(age AS REAL)", Arrange: paginate.SortOrderDescending}}, sorts)
+
+	columns, err := fields.Projection([]string{"phone", "name", "phone"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"phone_number", "name"}, columns)
+
+	_, err = fields.Projection([]string{"age"})
+	require.Equal(t, &paginate.FieldError{Param: "fields", Field: "age", Allowed: []string{"name", "phone"}}, err)
+
+	// nested paths project their first field
+	columns, err = fields.Projection([]string{"name.first", "name"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"name"}, columns)
+
+	_, err = fields.Projection([]string{"age.years"})
+	require.Equal(t, &paginate.FieldError{Param: "fields", Field: "age.years", Allowed: []string{"name", "phone"}}, err)
+
+	_, err = fields.Sorts([]paginate.Sort{{Field: "email", Arrange: paginate.SortOrderAscending}})
+	require.Equal(t, &paginate.FieldError{Param: "sort", Field: "email", Allowed: []string{"age", "name", "phone"}}, err)
+	require.EqualError(t, err, `sort by "email" is not allowed, allowed values are: age, name, phone`)
+}
@@ -0,0 +1,83 @@
+package paginate
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Links are urls of pages of a list relative to its request, they keep all parameters of request, e.g. filters,
+// sorts and fields, but its page or cursor. links of pages which do not exist or are not known are empty.
+type Links struct {
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// NewLinks returns links of pages of listed pagination requested by u.
+// offset pages link to page 1, the adjacent pages and the last page if rows are counted exactly, cursor pages link
+// to the first page, their cursors and the last page, which is listed by an empty before cursor.
+func NewLinks(u *url.URL, pagination *Pagination) Links {
+	query := u.Query()
+	query.Del(pageParamName)
+	query.Del(afterParamName)
+	query.Del(beforeParamName)
+	link := func(param, value string) string {
+		q := maps.Clone(query)
+		q.Set(param, value)
+		return (&url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: q.Encode()}).String()
+	}
+	page := func(n int) string {
+		return link(pageParamName, strconv.Itoa(n))
+	}
+
+	if pagination.Cursor != nil {
+		links := Links{First: link(afterParamName, ""), Last: link(beforeParamName, "")}
+		if pagination.NextCursor != "" {
+			links.Next = link(afterParamName, pagination.NextCursor)
+		}
+		if pagination.PrevCursor != "" {
+			links.Prev = link(beforeParamName, pagination.PrevCursor)
+		}
+		return links
+	}
+
+	links := Links{First: page(1)}
+	if pagination.Page > 1 {
+		links.Prev = page(pagination.Page - 1)
+	}
+	if pagination.HasMore {
+		links.Next = page(pagination.Page + 1)
+	}
+	if mode, _ := pagination.CountMode(); mode != CountNone && !pagination.Estimated && pagination.PerPage > 0 {
+		links.Last = page(max(1, int((pagination.TotalItems+int64(pagination.PerPage)-1)/int64(pagination.PerPage))))
+	}
+	return links
+}
+
+// Header returns value of RFC 8288 Link header of links, e.g. </users?page=2>; rel="next".
+func (l Links) Header() string {
+	var header []string
+	for _, link := range []struct{ rel, url string }{
+		{"first", l.First}, {"prev", l.Prev}, {"next", l.Next}, {"last", l.Last},
+	} {
+		if link.url != "" {
+			header = append(header, fmt.Sprintf("<%s>; rel=%q", link.url, link.rel))
+		}
+	}
+	return strings.Join(header, ", ")
+}
+
+// SetLinks sets links of listed pagination requested by r to Link header of w and to pagination, so they are given
+// by NewListResponse too. it must be called before the header of w is written.
+func SetLinks(w http.ResponseWriter, r *http.Request, pagination *Pagination) {
+	links := NewLinks(r.URL, pagination)
+	pagination.Links = &links
+	if header := links.Header(); header != "" {
+		w.Header().Set("Link", header)
+	}
+}
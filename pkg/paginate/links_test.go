@@ -0,0 +1,52 @@
+package paginate_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLinks(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users?page=2&per_page=2&sort=name&sort=desc&status=1&status=gt&fields=id", http.NoBody)
+	pagination := paginate.ParseFromRequest(r)
+	pagination.SetTotalItems(7)
+
+	links := paginate.NewLinks(r.URL, pagination)
+	require.Equal(t, paginate.Links{
+		First: "/users?fields=id&page=1&per_page=2&sort=name&sort=desc&status=1&status=gt",
+		Prev:  "/users?fields=id&page=1&per_page=2&sort=name&sort=desc&status=1&status=gt",
+		Next:  "/users?fields=id&page=3&per_page=2&sort=name&sort=desc&status=1&status=gt",
+		Last:  "/users?fields=id&page=4&per_page=2&sort=name&sort=desc&status=1&status=gt",
+	}, links)
+
+	// last page is not known without exact counts
+	pagination.Page, pagination.Count, pagination.HasMore = 1, paginate.CountNone, false
+	require.Equal(t, paginate.Links{First: "/users?fields=id&page=1&per_page=2&sort=name&sort=desc&status=1&status=gt"},
+		paginate.NewLinks(r.URL, pagination))
+
+	r = httptest.NewRequest(http.MethodGet, "/users?before=token&per_page=2", http.NoBody)
+	pagination = paginate.ParseFromRequest(r)
+	pagination.NextCursor, pagination.PrevCursor = "next", "prev"
+	require.Equal(t, paginate.Links{
+		First: "/users?after=&per_page=2",
+		Prev:  "/users?before=prev&per_page=2",
+		Next:  "/users?after=next&per_page=2",
+		Last:  "/users?before=&per_page=2",
+	}, paginate.NewLinks(r.URL, pagination))
+}
+
+func TestSetLinks(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users?per_page=1", http.NoBody)
+	pagination := paginate.ParseFromRequest(r)
+	pagination.SetTotalItems(2)
+
+	w := httptest.NewRecorder()
+	paginate.SetLinks(w, r, pagination)
+	require.Equal(t, `</users?page=1&per_page=1>; rel="first", </users?page=2&per_page=1>; rel="next", </users?page=2&per_page=1>; rel="last"`,
+		w.Header().Get("Link"))
+	require.Equal(t, pagination.Links, paginate.NewListResponse(nil, pagination).Links)
+	require.Equal(t, "/users?page=2&per_page=1", pagination.Links.Next)
+}
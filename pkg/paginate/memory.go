@@ -0,0 +1,453 @@
+package paginate
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Accessors maps fields of lists to values of items, they are the in-memory counterpart of Fields.
+// values are compared by their types, e.g. numerically for numbers and chronologically for times.
+type Accessors[T any] map[string]func(T) any
+
+// TagAccessors returns accessors of exported fields of struct T by their names in given tag, e.g. `db:"created_at"`,
+// fields without the tag or tagged by "-" are not accessible.
+func TagAccessors[T any](tag string) Accessors[T] {
+	accessors := Accessors[T]{}
+	for _, field := range reflect.VisibleFields(reflect.TypeFor[T]()) {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		index := field.Index
+		accessors[name] = func(item T) any {
+			return reflect.ValueOf(item).FieldByIndex(index).Interface()
+		}
+	}
+	return accessors
+}
+
+// Fields returns fields of accessors mapped to themselves with given types, for in-memory lists whose fields
+// have no columns.
+func (a Accessors[T]) Fields(types map[string]Field) Fields {
+	filter := make(map[string]string, len(a))
+	for field := range a {
+		filter[field] = field
+	}
+	return Fields{Filter: filter, Types: types}
+}
+
+// List filters, sorts and pages items same as database drivers list their rows, so in-memory repositories behave
+// the same way. fields are the same fields of drivers, their columns are not used but accessors must have all of them.
+// filters of unknown fields are ignored, while sorts, projections and filter expressions of them are rejected by
+// FieldError, and filter values are parsed by Fields.Values. pages of cursor paginations are found by keyset of sort
+// and "id" field, see Keyset. items are not projected, they are returned whole. facets of aggregations of pagination
+// are listed over all filtered items, and items are counted unless pagination asks for no count.
+func List[T any](items []T, pagination *Pagination, fields Fields, accessors Accessors[T]) ([]T, error) {
+	mode, err := pagination.CountMode()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fields.Sorts(pagination.Sort); err != nil {
+		return nil, err
+	}
+	if _, err := fields.Projection(pagination.Fields); err != nil {
+		return nil, err
+	}
+	expr, err := pagination.Where()
+	if err != nil {
+		return nil, err
+	}
+
+	// values of filters are parsed once, comparisons are kept by pagination so they are keyed by their address
+	values := make(map[*Filter][]any)
+	parse := func(filter *Filter, strict bool) error {
+		if _, ok := fields.Filter[filter.Key]; !ok {
+			if strict {
+				return &FieldError{Param: filterParamName, Field: filter.Key, Allowed: slices.Sorted(maps.Keys(fields.Filter))}
+			}
+			return nil
+		}
+		v, err := fields.Values(*filter)
+		if err != nil {
+			return err
+		}
+		if filter.Condition == FilterLike || filter.Condition == FilterILike {
+			// patterns are compiled once instead of once per item
+			v = []any{likeMatcher(v[0].(string), filter.Condition == FilterILike)}
+		}
+		values[filter] = v
+		return nil
+	}
+	filters := make([]*Filter, len(pagination.Filters))
+	for i := range pagination.Filters {
+		filters[i] = &pagination.Filters[i]
+		if err := parse(filters[i], false); err != nil {
+			return nil, err
+		}
+	}
+	comparisons := Comparisons(expr)
+	for _, comparison := range comparisons {
+		if err := parse(&comparison.Filter, true); err != nil {
+			return nil, err
+		}
+	}
+
+	items = slices.DeleteFunc(slices.Clone(items), func(item T) bool {
+		match := func(filter *Filter) bool {
+			v, ok := values[filter]
+			return !ok || matchFilter(accessors[filter.Key](item), filter.Condition, v)
+		}
+		for _, filter := range filters {
+			if !match(filter) {
+				return true
+			}
+		}
+		return !matchExpression(expr, match)
+	})
+	if pagination.Facets, err = listFacets(items, pagination.Aggregations, fields, accessors); err != nil {
+		return nil, err
+	}
+
+	sorts := pagination.Sort
+	var keyset Keyset
+	if pagination.Cursor != nil {
+		if _, ok := accessors["id"]; !ok {
+			return nil, ErrCursorNotSupported
+		}
+		if keyset, err = pagination.Keyset("id"); err != nil {
+			return nil, err
+		}
+		sorts = keyset.Sort
+	}
+	slices.SortStableFunc(items, func(a, b T) int {
+		for _, sort := range sorts {
+			accessor := accessors[sort.Field]
+			c := compareValues(accessor(a), accessor(b))
+			if sort.Arrange == SortOrderDescending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	if pagination.Cursor != nil {
+		return keysetPage(pagination, keyset, items, accessors)
+	}
+
+	start := min(max(pagination.Page-1, 0)*pagination.PerPage, len(items))
+	end := min(start+pagination.PerPage+1, len(items))
+	page := PageRows(pagination, items[start:end])
+	// items are counted exactly even if estimates are asked
+	if mode != CountNone {
+		pagination.SetTotalItems(int64(len(items)))
+	}
+	return page, nil
+}
+
+// listFacets returns facets of aggregations on items, groups are ordered by their keys same as sorts.
+func listFacets[T any](items []T, aggregations []Aggregation, fields Fields, accessors Accessors[T]) ([]Facet, error) {
+	if len(aggregations) == 0 {
+		return nil, nil
+	}
+	if _, err := fields.Aggregations(aggregations); err != nil {
+		return nil, err
+	}
+	facets := make([]Facet, 0, len(aggregations))
+	for _, aggregation := range aggregations {
+		var (
+			groups  []Group
+			indexes = make(map[string]int)
+		)
+		for _, item := range items {
+			key := accessors[aggregation.Field](item)
+			if t, ok := canonical(key).(time.Time); ok && aggregation.Bucket != "" {
+				key = BucketStart(t, aggregation.Bucket).Format(time.RFC3339)
+			}
+			// nil keys are apart from keys of empty string
+			index := "v" + canonicalString(key)
+			if isNil(key) {
+				index = "nil"
+			}
+			i, ok := indexes[index]
+			if !ok {
+				i = len(groups)
+				indexes[index] = i
+				groups = append(groups, Group{Key: key})
+			}
+			groups[i].Count++
+			if len(aggregation.Metrics) > 0 {
+				groups[i].Metrics = aggregateMetrics(groups[i].Metrics, aggregation.Metrics, item, accessors)
+			}
+		}
+		slices.SortStableFunc(groups, func(a, b Group) int { return compareValues(a.Key, b.Key) })
+		if groups == nil {
+			groups = []Group{}
+		}
+		facets = append(facets, Facet{Field: aggregation.Field, Bucket: aggregation.Bucket, Groups: groups[:min(len(groups), MaxFacetGroups)]})
+	}
+	return facets, nil
+}
+
+// aggregateMetrics returns metrics of group with given item, null values are skipped same as sql.
+func aggregateMetrics[T any](values map[string]any, metrics []Metric, item T, accessors Accessors[T]) map[string]any {
+	if values == nil {
+		values = make(map[string]any, len(metrics))
+	}
+	for _, metric := range metrics {
+		name := metric.String()
+		value := accessors[metric.Field](item)
+		if isNil(value) {
+			if _, ok := values[name]; !ok {
+				values[name] = nil
+			}
+			continue
+		}
+		current := values[name]
+		switch {
+		case metric.Func == AggregateSum:
+			sum, _ := current.(float64)
+			n, _ := canonical(value).(float64)
+			values[name] = sum + n
+		case isNil(current),
+			metric.Func == AggregateMin && compareValues(value, current) < 0,
+			metric.Func == AggregateMax && compareValues(value, current) > 0:
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// matchExpression evaluates expr by match of its comparisons, unlike Match it passes comparisons themselves.
+func matchExpression(expr Expr, match func(*Filter) bool) bool {
+	switch e := expr.(type) {
+	case AndExpr:
+		for _, expr := range e {
+			if !matchExpression(expr, match) {
+				return false
+			}
+		}
+		return true
+	case OrExpr:
+		for _, expr := range e {
+			if matchExpression(expr, match) {
+				return true
+			}
+		}
+		return false
+	case NotExpr:
+		return !matchExpression(e.Expr, match)
+	case *Comparison:
+		return match(&e.Filter)
+	}
+	return true
+}
+
+// keysetPage returns cursor page of items sorted by keyset, items are compared with cursor same as sorting.
+func keysetPage[T any](pagination *Pagination, keyset Keyset, items []T, accessors Accessors[T]) ([]T, error) {
+	if keyset.Values != nil {
+		var invalid bool
+		items = slices.DeleteFunc(items, func(item T) bool {
+			for i, sort := range keyset.Sort {
+				value := accessors[sort.Field](item)
+				cursor, ok := cursorValue(value, keyset.Values[i])
+				if !ok {
+					invalid = true
+					return true
+				}
+				c := compareValues(value, cursor)
+				if sort.Arrange == SortOrderDescending {
+					c = -c
+				}
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return true
+		})
+		if invalid {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	items = items[:min(len(items), pagination.PerPage+1)]
+	return Page(pagination, keyset, items, func(item T) ([]json.RawMessage, error) {
+		values := make([]json.RawMessage, len(keyset.Sort))
+		for i, sort := range keyset.Sort {
+			value := accessors[sort.Field](item)
+			if isNil(value) {
+				return nil, ErrCursorNotSupported
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = data
+		}
+		return values, nil
+	})
+}
+
+// cursorValue decodes value of cursor by type of given value, ok is false if it does not fit or is null
+// since cursors are made of non-null values. nil values need no decoding as they are before all values.
+func cursorValue(like any, data json.RawMessage) (value any, ok bool) {
+	if string(data) == "null" {
+		return nil, false
+	}
+	if isNil(like) {
+		return data, true
+	}
+	v := reflect.New(reflect.TypeOf(like))
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return nil, false
+	}
+	return v.Elem().Interface(), true
+}
+
+// matchFilter reports whether value matches filter of condition whose values are parsed by Fields.Values,
+// except like patterns which are compiled by likeMatcher.
+func matchFilter(value any, condition string, values []any) bool {
+	switch condition {
+	case FilterIn:
+		return slices.ContainsFunc(values, func(v any) bool { return compareValues(value, v) == 0 })
+	case FilterBetween:
+		return compareValues(value, values[0]) >= 0 && compareValues(value, values[1]) <= 0
+	case FilterLike, FilterILike:
+		return !isNil(value) && values[0].(*regexp.Regexp).MatchString(canonicalString(value))
+	}
+	// nulls are only unequal to values
+	if isNil(value) {
+		return condition == FilterNotEqual
+	}
+	c := compareValues(value, values[0])
+	switch condition {
+	case FilterEqual:
+		return c == 0
+	case FilterNotEqual:
+		return c != 0
+	case FilterGreater:
+		return c > 0
+	case FilterGreaterEqual:
+		return c >= 0
+	case FilterLess:
+		return c < 0
+	case FilterLessEqual:
+		return c <= 0
+	}
+	return true
+}
+
+// compareValues orders nils first, numbers numerically, booleans false first, times chronologically
+// and others by their string forms.
+func compareValues(a, b any) int {
+	x, y := canonical(a), canonical(b)
+	switch {
+	case x == nil && y == nil:
+		return 0
+	case x == nil:
+		return -1
+	case y == nil:
+		return 1
+	}
+	switch x := x.(type) {
+	case float64:
+		if y, ok := y.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	case bool:
+		if y, ok := y.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	case time.Time:
+		if y, ok := y.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(canonicalString(a), canonicalString(b))
+}
+
+// canonical returns value as float64, bool, time.Time, string or nil for comparison by its kind,
+// e.g. named numeric types are numbers even if they are fmt.Stringer, and uuids are strings.
+func canonical(value any) any {
+	if isNil(value) {
+		return nil
+	}
+	if t, ok := value.(time.Time); ok {
+		return t
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	}
+	switch v := v.Interface().(type) {
+	case time.Time:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func canonicalString(value any) string {
+	switch v := canonical(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// likeMatcher returns matcher of like pattern, see LikeRegexp.
+func likeMatcher(pattern string, insensitive bool) *regexp.Regexp {
+	flags := "(?s)"
+	if insensitive {
+		flags = "(?is)"
+	}
+	return regexp.MustCompile(flags + LikeRegexp(pattern))
+}
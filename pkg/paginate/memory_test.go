@@ -0,0 +1,133 @@
+package paginate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Score     float64   `db:"score"`
+	Active    bool      `db:"active"`
+	CreatedAt time.Time `db:"created_at"`
+	Secret    string    `db:"-"`
+	Note      *string
+}
+
+func items() []item {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []item{
+		{ID: 1, Name: "amir", Score: 9, Active: true, CreatedAt: day},
+		{ID: 2, Name: "Ali", Score: 10, Active: false, CreatedAt: day.AddDate(0, 0, 1)},
+		{ID: 3, Name: "sara", Score: 10, Active: true, CreatedAt: day.AddDate(0, 0, 2)},
+		{ID: 4, Name: "reza", Score: 2.5, Active: false, CreatedAt: day.AddDate(0, 0, 3)},
+	}
+}
+
+var itemFields = paginate.Fields{
+	Filter: map[string]string{"id": "id", "name": "name", "score": "score", "active": "active", "created_at": "created_at"},
+	Types: map[string]paginate.Field{
+		"id":         {Type: paginate.TypeInt},
+		"score":      {Type: paginate.TypeFloat},
+		"active":     {Type: paginate.TypeBool},
+		"created_at": {Type: paginate.TypeTime},
+	},
+}
+
+func ids(items []item) []int {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestTagAccessors(t *testing.T) {
+	accessors := paginate.TagAccessors[item]("db")
+	require.Len(t, accessors, 5)
+	require.NotContains(t, accessors, "Secret")
+	require.Equal(t, "amir", accessors["name"](items()[0]))
+}
+
+func TestList(t *testing.T) {
+	accessors := paginate.TagAccessors[item]("db")
+	byID := []paginate.Sort{{Field: "id", Arrange: paginate.SortOrderAscending}}
+
+	for _, tc := range []struct {
+		name       string
+		pagination paginate.Pagination
+		expected   []int
+		total      int64
+	}{
+		// numbers are compared numerically, not as strings
+		{"greater", paginate.Pagination{Sort: byID, Filters: []paginate.Filter{{Key: "score", Value: "9", Condition: paginate.FilterGreater}}}, []int{2, 3}, 2},
+		{"in", paginate.Pagination{Sort: byID, Filters: []paginate.Filter{{Key: "id", Value: "1,4", Condition: paginate.FilterIn}}}, []int{1, 4}, 2},
+		{"between times", paginate.Pagination{Sort: byID, Filters: []paginate.Filter{
+			{Key: "created_at", Value: "2024-01-02,2024-01-03", Condition: paginate.FilterBetween}}}, []int{2, 3}, 2},
+		{"bool", paginate.Pagination{Sort: byID, Filters: []paginate.Filter{{Key: "active", Value: "true", Condition: paginate.FilterEqual}}}, []int{1, 3}, 2},
+		{"like", paginate.Pagination{Sort: byID, Filters: []paginate.Filter{{Key: "name", Value: "a%", Condition: paginate.FilterLike}}}, []int{1}, 1},
+		{"ilike", paginate.Pagination{Sort: byID, Filters: []paginate.Filter{{Key: "name", Value: "a%", Condition: paginate.FilterILike}}}, []int{1, 2}, 2},
+		{"like single character", paginate.Pagination{Sort: byID, Filters: []paginate.Filter{{Key: "name", Value: "_li", Condition: paginate.FilterLike}}}, []int{2}, 1},
+		{"unknown filter is ignored", paginate.Pagination{Sort: byID, Filters: []paginate.Filter{{Key: "secret", Value: "x"}}}, []int{1, 2, 3, 4}, 4},
+		{"expression", paginate.Pagination{Sort: byID, Expression: "score<5,(active==true;name!=amir)"}, []int{3, 4}, 2},
+		{"multi sort", paginate.Pagination{Sort: []paginate.Sort{
+			{Field: "score", Arrange: paginate.SortOrderDescending},
+			{Field: "name", Arrange: paginate.SortOrderAscending},
+		}}, []int{2, 3, 1, 4}, 4},
+		{"page", paginate.Pagination{Page: 2, PerPage: 3, Sort: byID}, []int{4}, 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.pagination.Page == 0 {
+				tc.pagination.Page, tc.pagination.PerPage = 1, 10
+			}
+			got, err := paginate.List(items(), &tc.pagination, itemFields, accessors)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ids(got))
+			require.Equal(t, tc.total, tc.pagination.TotalItems)
+		})
+	}
+
+	_, err := paginate.List(items(), &paginate.Pagination{Page: 1, PerPage: 10, Sort: []paginate.Sort{{Field: "secret", Arrange: paginate.SortOrderAscending}}}, itemFields, accessors)
+	require.Equal(t, &paginate.FieldError{Param: "sort", Field: "secret", Allowed: []string{"active", "created_at", "id", "name", "score"}}, err)
+
+	_, err = paginate.List(items(), &paginate.Pagination{Page: 1, PerPage: 10, Expression: "secret==x"}, itemFields, accessors)
+	var fieldErr *paginate.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	require.Equal(t, "filter", fieldErr.Param)
+
+	_, err = paginate.List(items(), &paginate.Pagination{Page: 1, PerPage: 10, Filters: []paginate.Filter{
+		{Key: "score", Value: "high", Condition: paginate.FilterGreater}}}, itemFields, accessors)
+	var filterErr *paginate.FilterError
+	require.ErrorAs(t, err, &filterErr)
+}
+
+func TestListCursor(t *testing.T) {
+	accessors := paginate.TagAccessors[item]("db")
+	sort := []paginate.Sort{{Field: "score", Arrange: paginate.SortOrderDescending}}
+
+	first := &paginate.Pagination{PerPage: 2, Sort: sort, Cursor: &paginate.Cursor{}}
+	got, err := paginate.List(items(), first, itemFields, accessors)
+	require.NoError(t, err)
+	// ties are ordered by id of the same arrange as the last sort
+	require.Equal(t, []int{3, 2}, ids(got))
+	require.NotEmpty(t, first.NextCursor)
+
+	second := &paginate.Pagination{PerPage: 2, Sort: sort, Cursor: &paginate.Cursor{Token: first.NextCursor}}
+	got, err = paginate.List(items(), second, itemFields, accessors)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 4}, ids(got))
+	require.Empty(t, second.NextCursor)
+
+	back := &paginate.Pagination{PerPage: 2, Sort: sort, Cursor: &paginate.Cursor{Token: second.PrevCursor, Backward: true}}
+	got, err = paginate.List(items(), back, itemFields, accessors)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 2}, ids(got))
+
+	_, err = paginate.List(items(), &paginate.Pagination{PerPage: 2, Sort: sort, Cursor: &paginate.Cursor{}}, itemFields,
+		paginate.Accessors[item]{"score": func(i item) any { return i.Score }})
+	require.ErrorIs(t, err, paginate.ErrCursorNotSupported)
+}
@@ -16,6 +16,8 @@ const (
 	perPageParamName = "per_page"
 	sortParamName    = "sort"
 	fieldsParamName  = "fields"
+	countParamName   = "count"
+	searchParamName  = "q"
 
 	FilterEqual        = "eq"
 	FilterNotEqual     = "neq"
@@ -26,9 +28,19 @@ const (
 	FilterIn           = "in"
 	FilterBetween      = "between"
 	FilterLike         = "like"
+	FilterILike        = "ilike"
 
 	SortOrderAscending  = "asc"
 	SortOrderDescending = "desc"
+
+	// SortRelevance sorts searched lists by relevance of rows to their search, most relevant first if descending.
+	SortRelevance = "relevance"
+
+	// CountExact counts all rows of list, CountEstimated estimates them by statistics of database if it could
+	// and CountNone does not count them, has_more tells whether rows follow the page then.
+	CountExact     = "exact"
+	CountEstimated = "estimated"
+	CountNone      = "none"
 )
 
 type Filter struct {
@@ -49,11 +61,56 @@ type Pagination struct {
 	Sort       []Sort   `json:"sort,omitempty"`
 	Filters    []Filter `json:"filters,omitempty"`
 	TotalItems int64    `json:"total_items"`
+	// Estimated tells TotalItems is estimated by statistics of database, it is at least rows known to be listed.
+	Estimated bool `json:"estimated,omitempty"`
+	// HasMore tells whether rows follow the page, it is known without counting.
+	HasMore bool `json:"has_more"`
+	// Count is the count parameter, CountExact if empty, see CountMode.
+	Count string `json:"-"`
+	// Search is the q parameter, a full-text query of words which all must be in listed rows, see search.Terms.
+	Search string `json:"-"`
+	// Highlights are searched fields of listed rows having words of Search, highlighted by search.Highlight and
+	// keyed by ids of rows and names of fields, set by listing.
+	Highlights map[string]map[string]string `json:"-"`
+	// Expression is the filter parameter, a boolean expression of filters anded with Filters, see ParseExpression.
+	Expression string `json:"-"`
+	// Cursor is set for keyset pagination by after or before parameters, Page and TotalItems are not used then.
+	Cursor *Cursor `json:"-"`
+	// NextCursor and PrevCursor are tokens of adjacent pages of a cursor page, empty if there is no such page.
+	NextCursor string `json:"-"`
+	PrevCursor string `json:"-"`
+	// Links are urls of pages of list set by SetLinks.
+	Links *Links `json:"-"`
+	// Aggregations are facets requested by facet parameters, see ParseAggregation.
+	Aggregations []Aggregation `json:"-"`
+	// Facets are results of Aggregations in the same order, set by listing.
+	Facets []Facet `json:"-"`
+
+	where    Expr
+	whereErr error
 }
 
 type ListResponse struct {
-	Data       any         `json:"data"`
-	Pagination *Pagination `json:"pagination,omitempty"`
+	Data       any                          `json:"data"`
+	Pagination *Pagination                  `json:"pagination,omitempty"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+	PrevCursor string                       `json:"prev_cursor,omitempty"`
+	Facets     []Facet                      `json:"facets,omitempty"`
+	Highlights map[string]map[string]string `json:"highlights,omitempty"`
+	Links      *Links                       `json:"links,omitempty"`
+}
+
+// NewListResponse returns response of listed data by pagination having cursors of adjacent pages.
+func NewListResponse(data any, pagination *Pagination) ListResponse {
+	return ListResponse{
+		Data:       data,
+		Pagination: pagination,
+		NextCursor: pagination.NextCursor,
+		PrevCursor: pagination.PrevCursor,
+		Facets:     pagination.Facets,
+		Highlights: pagination.Highlights,
+		Links:      pagination.Links,
+	}
 }
 
 func ParseFromRequest(r *http.Request) *Pagination {
@@ -81,6 +138,20 @@ func ParseFromRequest(r *http.Request) *Pagination {
 		fields = strings.Split(f, ",")
 	}
 
+	var cursor *Cursor
+	if queries.Has(afterParamName) {
+		cursor = &Cursor{Token: queries.Get(afterParamName)}
+	} else if queries.Has(beforeParamName) {
+		cursor = &Cursor{Token: queries.Get(beforeParamName), Backward: true}
+	}
+
+	expression := queries.Get(filterParamName)
+
+	var aggregations []Aggregation
+	for _, facet := range queries[facetParamName] {
+		aggregations = append(aggregations, ParseAggregation(facet))
+	}
+
 	sort := []Sort{}
 
 	filters := []Filter{}
@@ -96,6 +167,12 @@ func ParseFromRequest(r *http.Request) *Pagination {
 			pageParamName,
 			perPageParamName,
 			fieldsParamName,
+			afterParamName,
+			beforeParamName,
+			filterParamName,
+			facetParamName,
+			countParamName,
+			searchParamName,
 		}, query) {
 			continue
 		}
@@ -135,16 +212,50 @@ func ParseFromRequest(r *http.Request) *Pagination {
 	}
 
 	return &Pagination{
-		Page:    page,
-		PerPage: perPage,
-		Fields:  fields,
-		Sort:    sort,
-		Filters: filters,
+		Page:         page,
+		PerPage:      perPage,
+		Fields:       fields,
+		Sort:         sort,
+		Filters:      filters,
+		Cursor:       cursor,
+		Expression:   expression,
+		Aggregations: aggregations,
+		Count:        queries.Get(countParamName),
+		Search:       queries.Get(searchParamName),
 	}
 }
 
+// SetTotalItems sets exact count of rows of list and whether rows follow the page.
 func (p *Pagination) SetTotalItems(totalItems int64) {
 	p.TotalItems = totalItems
+	p.HasMore = int64(p.Page*p.PerPage) < totalItems
+}
+
+// SetEstimatedTotalItems sets estimated count of rows of list, estimates below rows listed so far and the row after
+// them, if any, are raised to them since statistics could be stale. HasMore must be set before.
+func (p *Pagination) SetEstimatedTotalItems(estimate int64, rows int) {
+	listed := int64(max(p.Page-1, 0)*p.PerPage + rows)
+	if p.HasMore {
+		listed++
+	}
+	p.TotalItems, p.Estimated = max(estimate, listed), true
+}
+
+// CountMode returns how list is counted, unknown modes are returned as FieldError.
+func (p *Pagination) CountMode() (string, error) {
+	switch p.Count {
+	case "":
+		return CountExact, nil
+	case CountExact, CountEstimated, CountNone:
+		return p.Count, nil
+	}
+	return "", &FieldError{Param: countParamName, Field: p.Count, Allowed: []string{CountExact, CountEstimated, CountNone}}
+}
+
+// PageRows returns rows of page fetched with one more row than the page, which tells whether rows follow the page.
+func PageRows[T any](p *Pagination, rows []T) []T {
+	p.HasMore = len(rows) > p.PerPage
+	return rows[:min(len(rows), p.PerPage)]
 }
 
 func isValidFilterCondition(condition string) bool {
@@ -158,6 +269,7 @@ func isValidFilterCondition(condition string) bool {
 		FilterIn,
 		FilterBetween,
 		FilterLike,
+		FilterILike,
 	}, condition)
 }
 
@@ -17,6 +17,7 @@ func TestParseFromRequest(t *testing.T) {
 	params.Add("name", "smith")
 	params.Add("name", "like")
 	params.Add("age", "36")
+	params.Add("q", "amir zayi")
 
 	url := fmt.Sprintf("%s?%s", baseUrl, params.Encode())
 
@@ -32,4 +33,31 @@ func TestParseFromRequest(t *testing.T) {
 	require.Contains(t, pagination.Filters, paginate.Filter{
 		Key: "age", Value: "36", Condition: paginate.FilterEqual,
 	})
+
+	require.Equal(t, "amir zayi", pagination.Search)
+	require.Len(t, pagination.Filters, 2)
+}
+
+func TestCount(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/somewhere?count=none&page=2&per_page=2", http.NoBody)
+	require.NoError(t, err)
+	pagination := paginate.ParseFromRequest(r)
+	require.Empty(t, pagination.Filters)
+	mode, err := pagination.CountMode()
+	require.NoError(t, err)
+	require.Equal(t, paginate.CountNone, mode)
+
+	require.Equal(t, []int{3, 4}, paginate.PageRows(pagination, []int{3, 4, 5}))
+	require.True(t, pagination.HasMore)
+
+	// estimates are at least rows listed so far and the next one
+	pagination.SetEstimatedTotalItems(1, 2)
+	require.Equal(t, int64(5), pagination.TotalItems)
+	require.True(t, pagination.Estimated)
+
+	pagination.SetTotalItems(4)
+	require.False(t, pagination.HasMore)
+
+	_, err = (&paginate.Pagination{Count: "approximate"}).CountMode()
+	require.Equal(t, &paginate.FieldError{Param: "count", Field: "approximate", Allowed: []string{"exact", "estimated", "none"}}, err)
 }
@@ -0,0 +1,208 @@
+package paginate
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type is the type of a filter field, filter values are parsed and validated by it before listing.
+type Type string
+
+const (
+	TypeString Type = "string"
+	TypeInt    Type = "int"
+	TypeFloat  Type = "float"
+	TypeBool   Type = "bool"
+	TypeTime   Type = "time"
+	TypeUUID   Type = "uuid"
+	TypeEnum   Type = "enum"
+)
+
+// conditions are filter conditions allowed on types by default.
+var conditions = map[Type][]string{
+	TypeString: {FilterEqual, FilterNotEqual, FilterGreater, FilterGreaterEqual, FilterLess, FilterLessEqual,
+		FilterIn, FilterBetween, FilterLike, FilterILike},
+	TypeInt:   {FilterEqual, FilterNotEqual, FilterGreater, FilterGreaterEqual, FilterLess, FilterLessEqual, FilterIn, FilterBetween},
+	TypeFloat: {FilterEqual, FilterNotEqual, FilterGreater, FilterGreaterEqual, FilterLess, FilterLessEqual, FilterIn, FilterBetween},
+	TypeTime:  {FilterEqual, FilterNotEqual, FilterGreater, FilterGreaterEqual, FilterLess, FilterLessEqual, FilterIn, FilterBetween},
+	TypeBool:  {FilterEqual, FilterNotEqual},
+	TypeUUID:  {FilterEqual, FilterNotEqual, FilterIn},
+	TypeEnum:  {FilterEqual, FilterNotEqual, FilterIn},
+}
+
+// timeLayouts are layouts of time filter values, times without zone are in UTC.
+var timeLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// Field defines type of a filter field and conditions allowed on it.
+type Field struct {
+	Type Type
+	// Values are allowed values of enum fields.
+	Values []string
+	// Conditions are allowed conditions of field, conditions of Type are allowed if nil.
+	Conditions []string
+}
+
+// FilterError is returned for filters whose condition is not allowed on their field or whose values do not fit its type.
+type FilterError struct {
+	Field     string
+	Condition string
+	Value     string
+	// Allowed are conditions allowed on field for conditions which are not, or values of enum fields.
+	Allowed []string
+	Msg     string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("filter %s %s %q: %s", e.Field, e.Condition, e.Value, e.Msg)
+}
+
+// Values returns values of filter parsed by type of its field, e.g. int64, float64, bool, time.Time, uuid.UUID or string,
+// in filters have one or more values and between filters have two. like and ilike filters have their pattern, see LikeRegexp.
+// fields without definition in Types are strings, conditions not allowed on field and values not fitting its type
+// are returned as FilterError.
+func (f Fields) Values(filter Filter) ([]any, error) {
+	field, ok := f.Types[filter.Key]
+	if !ok {
+		field = Field{Type: TypeString}
+	}
+	allowed := field.Conditions
+	if allowed == nil {
+		allowed = conditions[field.Type]
+	}
+	filterErr := func(msg string, allowed []string) error {
+		return &FilterError{Field: filter.Key, Condition: filter.Condition, Value: filter.Value, Allowed: allowed, Msg: msg}
+	}
+	if !slices.Contains(allowed, filter.Condition) {
+		return nil, filterErr(fmt.Sprintf("%s is not allowed on %s field", filter.Condition, field.Type), allowed)
+	}
+
+	switch filter.Condition {
+	case FilterLike, FilterILike:
+		if err := validateLike(filter.Value); err != nil {
+			return nil, filterErr(err.Error(), nil)
+		}
+		return []any{filter.Value}, nil
+	}
+
+	raw := []string{filter.Value}
+	switch filter.Condition {
+	case FilterIn:
+		raw = strings.Split(filter.Value, ",")
+	case FilterBetween:
+		if raw = strings.Split(filter.Value, ","); len(raw) != 2 {
+			return nil, filterErr("between needs two values", nil)
+		}
+	}
+	values := make([]any, len(raw))
+	for i, v := range raw {
+		value, err := field.parse(v)
+		if err != nil {
+			return nil, filterErr(err.Error(), field.Values)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (f Field) parse(value string) (any, error) {
+	switch f.Type {
+	case TypeInt:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v, nil
+		}
+	case TypeFloat:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v, nil
+		}
+	case TypeBool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v, nil
+		}
+	case TypeTime:
+		for _, layout := range timeLayouts {
+			if v, err := time.Parse(layout, value); err == nil {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("value must be time of %s", strings.Join(timeLayouts, ", "))
+	case TypeUUID:
+		if v, err := uuid.Parse(value); err == nil {
+			return v, nil
+		}
+	case TypeEnum:
+		if slices.Contains(f.Values, value) {
+			return value, nil
+		}
+		return nil, fmt.Errorf("value must be one of allowed values")
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("value must be %s", f.Type)
+}
+
+// validateLike rejects patterns ending by a backslash which escapes nothing.
+func validateLike(pattern string) error {
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' {
+			if i == len(pattern)-1 {
+				return fmt.Errorf("pattern ends with escape character")
+			}
+			i++
+		}
+	}
+	return nil
+}
+
+// LikeRegexp returns anchored regular expression of like pattern, % matches any characters, _ a single one and
+// backslash escapes them, e.g. 50\% matches 50%. other characters match themselves.
+func LikeRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// LikeEscape returns like pattern escaped by escape character instead of backslash, for sql like ... ESCAPE clauses
+// whose backslashes are not portable, e.g. in mysql string literals.
+func LikeEscape(pattern string, escape rune) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteRune(escape)
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == escape:
+			b.WriteRune(escape)
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package paginate_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldsValues(t *testing.T) {
+	id := uuid.New()
+	fields := paginate.Fields{Types: map[string]paginate.Field{
+		"age":     {Type: paginate.TypeInt},
+		"score":   {Type: paginate.TypeFloat},
+		"active":  {Type: paginate.TypeBool},
+		"created": {Type: paginate.TypeTime},
+		"id":      {Type: paginate.TypeUUID},
+		"role":    {Type: paginate.TypeEnum, Values: []string{"User", "Admin"}},
+		"code":    {Type: paginate.TypeString, Conditions: []string{paginate.FilterEqual}},
+	}}
+
+	for _, tc := range []struct {
+		filter   paginate.Filter
+		expected []any
+	}{
+		{paginate.Filter{Key: "age", Value: "18", Condition: paginate.FilterGreater}, []any{int64(18)}},
+		{paginate.Filter{Key: "score", Value: "2.5,3", Condition: paginate.FilterBetween}, []any{2.5, 3.0}},
+		{paginate.Filter{Key: "active", Value: "true", Condition: paginate.FilterEqual}, []any{true}},
+		{paginate.Filter{Key: "created", Value: "2024-01-02", Condition: paginate.FilterLess},
+			[]any{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}},
+		{paginate.Filter{Key: "id", Value: id.String(), Condition: paginate.FilterIn}, []any{id}},
+		{paginate.Filter{Key: "role", Value: "User,Admin", Condition: paginate.FilterIn}, []any{"User", "Admin"}},
+		{paginate.Filter{Key: "name", Value: `a\%%`, Condition: paginate.FilterILike}, []any{`a\%%`}},
+	} {
+		t.Run(tc.filter.Key, func(t *testing.T) {
+			values, err := fields.Values(tc.filter)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, values)
+		})
+	}
+
+	for _, tc := range []struct {
+		filter   paginate.Filter
+		expected paginate.FilterError
+	}{
+		{paginate.Filter{Key: "age", Value: "old", Condition: paginate.FilterEqual},
+			paginate.FilterError{Field: "age", Condition: "eq", Value: "old", Msg: "value must be int"}},
+		{paginate.Filter{Key: "role", Value: "Root", Condition: paginate.FilterEqual},
+			paginate.FilterError{Field: "role", Condition: "eq", Value: "Root", Allowed: []string{"User", "Admin"}, Msg: "value must be one of allowed values"}},
+		{paginate.Filter{Key: "active", Value: "true", Condition: paginate.FilterGreater},
+			paginate.FilterError{Field: "active", Condition: "gt", Value: "true", Allowed: []string{"eq", "neq"}, Msg: "gt is not allowed on bool field"}},
+		{paginate.Filter{Key: "code", Value: "a", Condition: paginate.FilterNotEqual},
+			paginate.FilterError{Field: "code", Condition: "neq", Value: "a", Allowed: []string{"eq"}, Msg: "neq is not allowed on string field"}},
+		{paginate.Filter{Key: "age", Value: "1,2,3", Condition: paginate.FilterBetween},
+			paginate.FilterError{Field: "age", Condition: "between", Value: "1,2,3", Msg: "between needs two values"}},
+		{paginate.Filter{Key: "name", Value: `a\`, Condition: paginate.FilterLike},
+			paginate.FilterError{Field: "name", Condition: "like", Value: `a\`, Msg: "pattern ends with escape character"}},
+	} {
+		t.Run(tc.expected.Msg, func(t *testing.T) {
+			_, err := fields.Values(tc.filter)
+			var filterErr *paginate.FilterError
+			require.ErrorAs(t, err, &filterErr)
+			require.Equal(t, tc.expected, *filterErr)
+		})
+	}
+}
+
+func TestLikeRegexp(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{"a%", []string{"a", "amir"}, []string{"ba"}},
+		{"_li", []string{"ali"}, []string{"li", "aali"}},
+		{`50\%`, []string{"50%"}, []string{"500"}},
+		{"a.b", []string{"a.b"}, []string{"axb"}},
+	} {
+		re := regexp.MustCompile(paginate.LikeRegexp(tc.pattern))
+		for _, s := range tc.matches {
+			require.True(t, re.MatchString(s), "%s should match %s", tc.pattern, s)
+		}
+		for _, s := range tc.misses {
+			require.False(t, re.MatchString(s), "%s should not match %s", tc.pattern, s)
+		}
+	}
+}
+
+func TestLikeEscape(t *testing.T) {
+	require.Equal(t, "50!%", paginate.LikeEscape(`50\%`, '!'))
+	require.Equal(t, "a!!b!_%", paginate.LikeEscape(`a!b\_%`, '!'))
+}
@@ -0,0 +1,23 @@
+// Package requestinfo carries information of incoming request, like client ip and request id,
+// through context to lower layers.
+package requestinfo
+
+import "context"
+
+type Info struct {
+	IP        string
+	RequestID string
+}
+
+type infoKey struct{}
+
+// ContextWithInfo returns a copy of ctx which carries info of request.
+func ContextWithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// InfoFromContext returns info of request, zero info if ctx does not carry it.
+func InfoFromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"sync"
+)
+
+// Index is an inverted index of documents in memory, the in-memory counterpart of full-text indexes of databases.
+// it is safe for concurrent use.
+type Index struct {
+	mu sync.RWMutex
+	// postings are occurrences of terms in documents keyed by terms and ids of documents.
+	postings map[string]map[string]int
+	// lengths are counts of words of indexed documents keyed by their ids.
+	lengths map[string]int
+	// terms are distinct terms of indexed documents keyed by their ids, so documents are removed from their postings.
+	terms map[string][]string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]int),
+		lengths:  make(map[string]int),
+		terms:    make(map[string][]string),
+	}
+}
+
+// Add indexes words of texts of document of given id, replacing texts it is indexed by.
+func (i *Index) Add(id string, texts ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+	var length int
+	for _, text := range texts {
+		for _, word := range words(text) {
+			term := strings.ToLower(word)
+			if i.postings[term] == nil {
+				i.postings[term] = make(map[string]int)
+			}
+			if i.postings[term][id] == 0 {
+				i.terms[id] = append(i.terms[id], term)
+			}
+			i.postings[term][id]++
+			length++
+		}
+	}
+	i.lengths[id] = length
+}
+
+// Remove removes document of given id from index.
+func (i *Index) Remove(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+}
+
+// remove removes document of given id, caller must hold the lock.
+func (i *Index) remove(id string) {
+	for _, term := range i.terms[id] {
+		delete(i.postings[term], id)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.lengths, id)
+	delete(i.terms, id)
+}
+
+// Search returns ranks of documents having all terms keyed by their ids, more relevant documents have higher ranks.
+// documents are ranked by tf-idf, frequent terms in short documents are the most relevant, and terms of few documents
+// are worth more. nothing matches no terms.
+func (i *Index) Search(terms []string) map[string]float64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if len(terms) == 0 {
+		return nil
+	}
+	ranks := make(map[string]float64)
+	for id := range i.postings[terms[0]] {
+		ranks[id] = 0
+	}
+	for _, term := range terms {
+		documents := i.postings[term]
+		idf := math.Log(1 + float64(len(i.lengths))/float64(max(len(documents), 1)))
+		for id := range ranks {
+			occurrences, ok := documents[id]
+			if !ok {
+				delete(ranks, id)
+				continue
+			}
+			ranks[id] += float64(occurrences) / float64(i.lengths[id]) * idf
+		}
+	}
+	return ranks
+}
@@ -0,0 +1,96 @@
+// Package search matches documents by full-text queries of words, all words of a query must be in matched documents.
+// databases search their own full-text indexes, this package splits queries into words the same way for all of them,
+// highlights matched words and keeps an inverted index of documents in memory.
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// HighlightStart and HighlightEnd wrap matched words of highlighted text.
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+
+	// MaxTerms is the most terms of a query, following words are ignored.
+	MaxTerms = 10
+)
+
+// Terms returns lowercased words of text, words are runs of letters and digits, e.g. email amir@example.com has words
+// amir, example and com. each word is returned once in order of its first occurrence, at most MaxTerms words.
+// terms have no quotes or operators of databases, so they are never operators of their full-text queries.
+func Terms(text string) []string {
+	var terms []string
+	for _, word := range words(text) {
+		word = strings.ToLower(word)
+		if !slices.Contains(terms, word) {
+			terms = append(terms, word)
+		}
+		if len(terms) == MaxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// words returns runs of letters and digits of text.
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Highlight returns text with its words of terms wrapped by HighlightStart and HighlightEnd, ok is false if text
+// has no word of terms. text is html escaped so highlights are its only markup.
+func Highlight(text string, terms []string) (highlighted string, ok bool) {
+	var (
+		b     strings.Builder
+		start = -1
+	)
+	flush := func(end int) {
+		word := text[start:end]
+		if slices.Contains(terms, strings.ToLower(word)) {
+			ok = true
+			b.WriteString(HighlightStart + html.EscapeString(word) + HighlightEnd)
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		start = -1
+	}
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			flush(i)
+		}
+		if !isWord {
+			b.WriteString(html.EscapeString(string(r)))
+		}
+	}
+	if start >= 0 {
+		flush(len(text))
+	}
+	return b.String(), ok
+}
+
+// Highlights returns highlighted fields having words of terms keyed by their names, see Highlight.
+// it is nil if no field has them.
+func Highlights(fields map[string]string, terms []string) map[string]string {
+	var highlights map[string]string
+	for name, text := range fields {
+		highlighted, ok := Highlight(text, terms)
+		if !ok {
+			continue
+		}
+		if highlights == nil {
+			highlights = make(map[string]string)
+		}
+		highlights[name] = highlighted
+	}
+	return highlights
+}
@@ -0,0 +1,52 @@
+package search_test
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/amirzayi/clean_architect/pkg/search"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerms(t *testing.T) {
+	require.Equal(t, []string{"amir", "zayi", "example", "com"}, search.Terms(`Amir "zayi" amir@example.com -zayi*`))
+	require.Equal(t, []string{"josé", "۱۲"}, search.Terms("José ۱۲"))
+	require.Empty(t, search.Terms(`"*" -!`))
+	require.Len(t, search.Terms("a b c d e f g h i j k l"), search.MaxTerms)
+}
+
+func TestHighlight(t *testing.T) {
+	highlighted, ok := search.Highlight("Amir <Zayi>, amir@example.com", []string{"amir", "example"})
+	require.True(t, ok)
+	require.Equal(t, "<mark>Amir</mark> &lt;Zayi&gt;, <mark>amir</mark>@<mark>example</mark>.com", highlighted)
+
+	highlighted, ok = search.Highlight("amiri", []string{"amir"})
+	require.False(t, ok)
+	require.Equal(t, "amiri", highlighted)
+
+	require.Equal(t, map[string]string{"name": "<mark>Amir</mark> Zayi"},
+		search.Highlights(map[string]string{"name": "Amir Zayi", "email": "a@example.com"}, []string{"amir"}))
+	require.Nil(t, search.Highlights(map[string]string{"name": "Ali"}, []string{"amir"}))
+}
+
+func TestIndex(t *testing.T) {
+	index := search.NewIndex()
+	index.Add("1", "Amir Zayi", "amir@example.com")
+	index.Add("2", "Amir Amiri Zayi Zayi", "amiri@example.com")
+	index.Add("3", "Sara", "sara@example.com")
+
+	ranks := index.Search([]string{"amir", "zayi"})
+	require.Len(t, ranks, 2)
+	require.Greater(t, ranks["1"], ranks["2"], "short documents having terms more are more relevant")
+
+	ranks = index.Search([]string{"example"})
+	require.Len(t, ranks, 3)
+	require.Greater(t, index.Search([]string{"sara"})["3"], ranks["3"], "terms of fewer documents are worth more")
+
+	index.Add("1", "Ali")
+	require.Equal(t, []string{"2"}, slices.Collect(maps.Keys(index.Search([]string{"amir"}))))
+	index.Remove("2")
+	require.Empty(t, index.Search([]string{"amir"}))
+	require.Empty(t, index.Search(nil))
+}
@@ -0,0 +1,150 @@
+package sqlutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/jmoiron/sqlx"
+)
+
+// bucketFormats format times of buckets as their start in UTC for drivers, see paginate.Group.
+var bucketFormats = map[string]map[string]string{
+	"postgres": {
+		paginate.BucketHour:  `YYYY-MM-DD"T"HH24":00:00Z"`,
+		paginate.BucketDay:   `YYYY-MM-DD"T00:00:00Z"`,
+		paginate.BucketMonth: `YYYY-MM"-01T00:00:00Z"`,
+		paginate.BucketYear:  `YYYY"-01-01T00:00:00Z"`,
+	},
+	"mysql": {
+		paginate.BucketHour:  "%Y-%m-%dT%H:00:00Z",
+		paginate.BucketDay:   "%Y-%m-%dT00:00:00Z",
+		paginate.BucketMonth: "%Y-%m-01T00:00:00Z",
+		paginate.BucketYear:  "%Y-01-01T00:00:00Z",
+	},
+	"sqlite": {
+		paginate.BucketHour:  "%Y-%m-%dT%H:00:00Z",
+		paginate.BucketDay:   "%Y-%m-%dT00:00:00Z",
+		paginate.BucketMonth: "%Y-%m-01T00:00:00Z",
+		paginate.BucketYear:  "%Y-01-01T00:00:00Z",
+	},
+}
+
+// BuildAggregationQueries returns queries of aggregations of pagination on table, in the same order, with their shared args.
+// aggregations must be allowed by fields, see paginate.Fields.Aggregations.
+func BuildAggregationQueries(driverName, table string,
+	pagination *paginate.Pagination, fields paginate.Fields, predicates ...Predicate) ([]string, []any, error) {
+	predicates, err := expressionPredicates(pagination, fields, predicates)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aggregationQueries(driverName, table, pagination, fields, predicates...)
+}
+
+func aggregationQueries(driverName, table string,
+	pagination *paginate.Pagination, fields paginate.Fields, predicates ...Predicate) ([]string, []any, error) {
+	aggregations, err := fields.Aggregations(pagination.Aggregations)
+	if err != nil {
+		return nil, nil, err
+	}
+	whereQuery, args, err := whereQuery(pagination.Filters, fields, predicates)
+	if err != nil {
+		return nil, nil, err
+	}
+	queries := make([]string, 0, len(aggregations))
+	for _, aggregation := range aggregations {
+		queries = append(queries, aggregationQuery(driverName, table, aggregation, whereQuery))
+	}
+	return queries, args, nil
+}
+
+// aggregationQuery returns query of groups of aggregation, columns are the key, the count and metrics in order.
+func aggregationQuery(driverName, table string, aggregation paginate.Aggregation, whereQuery string) string {
+	key := aggregation.Field
+	if aggregation.Bucket != "" {
+		key = bucketQuery(driverName, key, aggregation.Bucket)
+	}
+	columns := []string{key + " AS facet_key", "count(1) AS facet_count"}
+	for i, metric := range aggregation.Metrics {
+		columns = append(columns, fmt.Sprintf("%s(%s) AS facet_m%d", strings.ToUpper(metric.Func), metric.Field, i))
+	}
+	return fmt.Sprintf("SELECT %s FROM %s %s GROUP BY %s ORDER BY facet_key LIMIT %d",
+		strings.Join(columns, ", "), table, whereQuery, key, paginate.MaxFacetGroups)
+}
+
+// bucketQuery returns expression of start of bucket of time column for the driver.
+func bucketQuery(driverName, column, bucket string) string {
+	switch driverName {
+	case "postgres", "pgx":
+		return fmt.Sprintf("to_char(%s AT TIME ZONE 'UTC', '%s')", column, bucketFormats["postgres"][bucket])
+	case "mysql":
+		return fmt.Sprintf("DATE_FORMAT(%s, '%s')", column, bucketFormats["mysql"][bucket])
+	}
+	return fmt.Sprintf("strftime('%s', %s)", bucketFormats["sqlite"][bucket], column)
+}
+
+// listFacets sets facets of aggregations of pagination on rows of table matching its filters and predicates,
+// facets are counted over all of those rows rather than the page, see paginate.Aggregation.
+func listFacets(ctx context.Context, db *sqlx.DB, table string,
+	pagination *paginate.Pagination, fields paginate.Fields, predicates ...Predicate) error {
+	if len(pagination.Aggregations) == 0 {
+		return nil
+	}
+	queries, args, err := aggregationQueries(db.DriverName(), table, pagination, fields, predicates...)
+	if err != nil {
+		return err
+	}
+	facets := make([]paginate.Facet, 0, len(queries))
+	for i, query := range queries {
+		groups, err := listGroups(ctx, db, query, args, pagination.Aggregations[i])
+		if err != nil {
+			return err
+		}
+		facets = append(facets, paginate.Facet{
+			Field:  pagination.Aggregations[i].Field,
+			Bucket: pagination.Aggregations[i].Bucket,
+			Groups: groups,
+		})
+	}
+	pagination.Facets = facets
+	return nil
+}
+
+func listGroups(ctx context.Context, db *sqlx.DB, query string, args []any, aggregation paginate.Aggregation) ([]paginate.Group, error) {
+	rows, err := db.QueryContext(ctx, db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []paginate.Group{}
+	for rows.Next() {
+		var group paginate.Group
+		metrics := make([]any, len(aggregation.Metrics))
+		dest := []any{&group.Key, &group.Count}
+		for i := range metrics {
+			dest = append(dest, &metrics[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		group.Key = scannedValue(group.Key)
+		if len(metrics) > 0 {
+			group.Metrics = make(map[string]any, len(metrics))
+			for i, metric := range aggregation.Metrics {
+				group.Metrics[metric.String()] = scannedValue(metrics[i])
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// scannedValue returns text of values scanned as bytes, e.g. by mysql, so they are not encoded as base64.
+func scannedValue(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
@@ -0,0 +1,123 @@
+package sqlutil
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func isPostgres(driverName string) bool {
+	return driverName == "postgres" || driverName == "pgx"
+}
+
+// selectCounted selects rows of query into dest and counts rows of countQuery in the same snapshot, concurrently
+// on postgres whose transactions could share their snapshot, one after another in one read-only transaction otherwise.
+// postgres lists take two connections of pool. it is used by exact count mode of pagination only.
+func selectCounted(ctx context.Context, db *sqlx.DB, dest any, query string, args []any, countQuery string, countArgs []any) (int64, error) {
+	if isPostgres(db.DriverName()) {
+		return selectCountedConcurrently(ctx, db, dest, query, args, countQuery, countArgs)
+	}
+
+	var count int64
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := tx.SelectContext(ctx, dest, tx.Rebind(query), args...); err != nil {
+		return 0, err
+	}
+	if err := tx.GetContext(ctx, &count, tx.Rebind(countQuery), countArgs...); err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+func selectCountedConcurrently(ctx context.Context, db *sqlx.DB, dest any, query string, args []any, countQuery string, countArgs []any) (int64, error) {
+	options := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	tx, err := db.BeginTxx(ctx, options)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var snapshot string
+	if err := tx.GetContext(ctx, &snapshot, "SELECT pg_export_snapshot()"); err != nil {
+		return 0, err
+	}
+	countTx, err := db.BeginTxx(ctx, options)
+	if err != nil {
+		return 0, err
+	}
+	defer countTx.Rollback()
+	// snapshot must be set before any query of transaction and could not be bound, it is generated by postgres.
+	if _, err := countTx.ExecContext(ctx, fmt.Sprintf("SET TRANSACTION SNAPSHOT '%s'", snapshot)); err != nil {
+		return 0, err
+	}
+
+	var (
+		count    int64
+		countErr error
+		wg       sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		countErr = countTx.GetContext(ctx, &count, countTx.Rebind(countQuery), countArgs...)
+	}()
+	err = tx.SelectContext(ctx, dest, tx.Rebind(query), args...)
+	wg.Wait()
+	if err = errors.Join(err, countErr); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// estimateCount estimates count of rows of table matching whereQuery by statistics of database, ok is false if
+// database could not estimate it. postgres estimates filtered rows by its planner and tables by their reltuples,
+// mysql estimates only tables, so rows out of tenant scope are never estimated, and sqlite keeps no statistics.
+func estimateCount(ctx context.Context, db *sqlx.DB, table, whereQuery string, args []any) (count int64, ok bool, err error) {
+	switch {
+	case isPostgres(db.DriverName()) && whereQuery == "":
+		var reltuples float64
+		if err := db.GetContext(ctx, &reltuples, "SELECT reltuples FROM pg_class WHERE oid = to_regclass($1)", table); err != nil {
+			return 0, false, err
+		}
+		// tables never analyzed have no reltuples
+		if reltuples >= 0 {
+			return int64(reltuples), true, nil
+		}
+		fallthrough
+
+	case isPostgres(db.DriverName()):
+		var plan []byte
+		query := fmt.Sprintf("EXPLAIN (FORMAT JSON) SELECT 1 FROM %s %s", table, whereQuery)
+		if err := db.GetContext(ctx, &plan, db.Rebind(query), args...); err != nil {
+			return 0, false, err
+		}
+		var plans []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			} `json:"Plan"`
+		}
+		if err := json.Unmarshal(plan, &plans); err != nil || len(plans) == 0 {
+			return 0, false, err
+		}
+		return int64(plans[0].Plan.Rows), true, nil
+
+	case db.DriverName() == "mysql" && whereQuery == "":
+		var rows sql.NullInt64
+		if err := db.GetContext(ctx, &rows,
+			"SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", table); err != nil {
+			return 0, false, err
+		}
+		return rows.Int64, rows.Valid, nil
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,150 @@
+package sqlutil
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// IDColumn is the tiebreaker of cursor pages, tables without it can not be listed by cursor.
+const IDColumn = "id"
+
+// keysetList selects cursor page of table ordered by sort of pagination and IDColumn, see paginate.Keyset.
+// pages are listed by keyset of sort and IDColumn instead of offset, sort fields must be mapped to columns of T,
+// rows are not counted.
+func keysetList[T any](ctx context.Context,
+	db *sqlx.DB, table string,
+	pagination *paginate.Pagination, fields paginate.Fields, predicates ...Predicate) ([]T, error) {
+	if _, err := fields.Sorts(pagination.Sort); err != nil {
+		return nil, err
+	}
+	projection, err := fields.Projection(pagination.Fields)
+	if err != nil {
+		return nil, err
+	}
+	keyset, err := pagination.Keyset(IDColumn)
+	if err != nil {
+		return nil, err
+	}
+	// sort of client is allowed, so only the tiebreaker is missing
+	sorts, err := fields.Sorts(keyset.Sort)
+	if err != nil {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	columns, err := keysetColumns[T](db.Mapper, sorts)
+	if err != nil {
+		return nil, err
+	}
+	if keyset.Values != nil {
+		predicate, err := keysetPredicate(keyset.Values, sorts, columns)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+
+	if len(projection) > 0 {
+		for _, sort := range sorts {
+			if !slices.Contains(projection, sort.Field) {
+				projection = append(projection, sort.Field)
+			}
+		}
+	}
+
+	// one more row tells whether there is a next page
+	var rows []T
+	query, args, err := buildQuery(table, projection, pagination.Filters, fields, sorts, sortArgs(fields, keyset.Sort),
+		pagination.PerPage+1, 0, predicates...)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.SelectContext(ctx, &rows, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	return paginate.Page(pagination, keyset, rows, func(row T) ([]json.RawMessage, error) {
+		return keysetValues(row, columns)
+	})
+}
+
+// keysetColumns returns fields of T of sorted columns, they must be mapped by db tags, e.g. expressions of json fields are not.
+func keysetColumns[T any](mapper *reflectx.Mapper, sorts []paginate.Sort) ([]*reflectx.FieldInfo, error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, paginate.ErrCursorNotSupported
+	}
+	fields := mapper.TypeMap(typ)
+
+	columns := make([]*reflectx.FieldInfo, len(sorts))
+	for i, sort := range sorts {
+		field := fields.GetByPath(sort.Field)
+		if field == nil {
+			return nil, paginate.ErrCursorNotSupported
+		}
+		columns[i] = field
+	}
+	return columns, nil
+}
+
+// keysetPredicate returns condition of rows after row of values by sorts of columns,
+// e.g. (a > ?) OR (a = ? AND b > ?) for ascending sort of a and b.
+func keysetPredicate(cursor []json.RawMessage, sorts []paginate.Sort, columns []*reflectx.FieldInfo) (Predicate, error) {
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		value := reflect.New(column.Field.Type)
+		if string(cursor[i]) == "null" || json.Unmarshal(cursor[i], value.Interface()) != nil {
+			return Predicate{}, paginate.ErrInvalidCursor
+		}
+		values[i] = value.Elem().Interface()
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+	for i, sort := range sorts {
+		var condition strings.Builder
+		condition.WriteString("(")
+		for j := range i {
+			condition.WriteString(sorts[j].Field + " = ? AND ")
+			args = append(args, values[j])
+		}
+		operator := " > ?"
+		if sort.Arrange == paginate.SortOrderDescending {
+			operator = " < ?"
+		}
+		condition.WriteString(sort.Field + operator + ")")
+		args = append(args, values[i])
+		conditions = append(conditions, condition.String())
+	}
+	return Predicate{Query: "(" + strings.Join(conditions, " OR ") + ")", Args: args}, nil
+}
+
+// keysetValues returns json of values of columns in row, rows having null values can not be a cursor.
+func keysetValues[T any](row T, columns []*reflectx.FieldInfo) ([]json.RawMessage, error) {
+	v := reflect.ValueOf(row)
+	values := make([]json.RawMessage, len(columns))
+	for i, column := range columns {
+		field := reflectx.FieldByIndexesReadOnly(v, column.Index)
+		if field.Kind() == reflect.Pointer && field.IsNil() {
+			return nil, paginate.ErrCursorNotSupported
+		}
+		if valuer, ok := field.Interface().(driver.Valuer); ok {
+			if value, err := valuer.Value(); err != nil || value == nil {
+				return nil, paginate.ErrCursorNotSupported
+			}
+		}
+		data, err := json.Marshal(field.Interface())
+		if err != nil {
+			return nil, err
+		}
+		values[i] = data
+	}
+	return values, nil
+}
@@ -0,0 +1,10 @@
+package sqlutil
+
+// QuoteIdentifier quotes given table or column name for the driver,
+// required for reserved words such as user.
+func QuoteIdentifier(driverName, name string) string {
+	if driverName == "mysql" {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
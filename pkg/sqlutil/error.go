@@ -0,0 +1,43 @@
+package sqlutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	postgresUniqueViolation = "23505"
+	mysqlDuplicateEntry     = 1062
+)
+
+// IsUniqueViolation reports whether err is caused by a unique or primary key constraint
+// on sqlite, postgres or mysql.
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+			sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == postgresUniqueViolation
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDuplicateEntry
+	}
+
+	// cgo based sqlite3 driver
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
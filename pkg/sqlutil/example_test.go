@@ -8,7 +8,7 @@ import (
 )
 
 func ExampleBuildPaginationQuery() {
-	query, args := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{
+	query, args, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{
 		Page:    3,
 		PerPage: 15,
 		Fields:  []string{"name", "id", "phone", "role", "status"},
@@ -20,7 +20,7 @@ func ExampleBuildPaginationQuery() {
 			{Key: "name", Value: "amir,admin,test", Condition: paginate.FilterIn},
 			{Key: "status", Value: "1,2", Condition: paginate.FilterIn},
 		},
-	}, map[string]string{
+	}, paginate.Fields{Filter: map[string]string{
 		"id":         "id",
 		"name":       "name",
 		"phone":      "phone",
@@ -28,7 +28,10 @@ func ExampleBuildPaginationQuery() {
 		"status":     "status",
 		"role":       "role",
 		"created_at": "created_at",
-	})
+	}})
+	if err != nil {
+		panic(err)
+	}
 	fmt.Println(query)
 	fmt.Println(args)
 
@@ -37,5 +40,5 @@ func ExampleBuildPaginationQuery() {
 	// WHERE name IN(?,?,?) AND status IN(?,?)
 	// ORDER BY id desc, name asc
 	// LIMIT ? offset ?
-	// [amir admin test 1 2 15 30]
+	// [amir admin test 1 2 16 30]
 }
@@ -0,0 +1,69 @@
+package sqlutil
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/amirzayi/clean_architect/pkg/paginate"
+)
+
+// expressionPredicates returns predicates with filter expression of pagination, see paginate.ParseExpression.
+// comparisons of fields out of filterable fields are rejected by paginate.FieldError unlike filters which are ignored.
+func expressionPredicates(pagination *paginate.Pagination, fields paginate.Fields, predicates []Predicate) ([]Predicate, error) {
+	expr, err := pagination.Where()
+	if err != nil || expr == nil {
+		return predicates, err
+	}
+	query, args, err := expressionQuery(expr, fields)
+	if err != nil {
+		return nil, err
+	}
+	// predicates of callers, e.g. tenant, are not modified
+	return append(slices.Clip(predicates), Predicate{Query: query, Args: args}), nil
+}
+
+// expressionQuery returns parenthesized condition of expr, e.g. ((status = ? OR status = ?) AND NOT (role = ?)).
+func expressionQuery(expr paginate.Expr, fields paginate.Fields) (string, []any, error) {
+	switch e := expr.(type) {
+	case paginate.AndExpr:
+		return joinExpressions([]paginate.Expr(e), " AND ", fields)
+	case paginate.OrExpr:
+		return joinExpressions([]paginate.Expr(e), " OR ", fields)
+	case paginate.NotExpr:
+		query, args, err := expressionQuery(e.Expr, fields)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT " + query, args, nil
+	case *paginate.Comparison:
+		field, ok := fields.Filter[e.Key]
+		if !ok {
+			return "", nil, &paginate.FieldError{Param: "filter", Field: e.Key, Allowed: slices.Sorted(maps.Keys(fields.Filter))}
+		}
+		values, err := fields.Values(e.Filter)
+		if err != nil {
+			return "", nil, err
+		}
+		query, args := filterQuery(field, e.Condition, values)
+		return "(" + query + ")", args, nil
+	}
+	return "", nil, fmt.Errorf("unknown expression %T", expr)
+}
+
+func joinExpressions(exprs []paginate.Expr, operator string, fields paginate.Fields) (string, []any, error) {
+	var (
+		queries []string
+		args    []any
+	)
+	for _, expr := range exprs {
+		query, exprArgs, err := expressionQuery(expr, fields)
+		if err != nil {
+			return "", nil, err
+		}
+		queries = append(queries, query)
+		args = append(args, exprArgs...)
+	}
+	return "(" + strings.Join(queries, operator) + ")", args, nil
+}
@@ -0,0 +1,55 @@
+package sqlutil
+
+import "fmt"
+
+// JSONType is the type which a field of json column is extracted and compared as.
+type JSONType int
+
+const (
+	JSONText JSONType = iota
+	// JSONNumber fields are compared numerically.
+	JSONNumber
+	// JSONBool fields are extracted as true or false text.
+	JSONBool
+)
+
+// JSONField returns expression of key of a json object column for the driver, to be used as queryable field,
+// so filters and sorts apply on it same as other columns. key is put in the query as is,
+// so it must be a plain identifier.
+func JSONField(driverName, column, key string, typ JSONType) string {
+	switch driverName {
+	case "postgres", "pgx":
+		field := fmt.Sprintf("(%s->>'%s')", column, key)
+		if typ == JSONNumber {
+			return field + "::numeric"
+		}
+		return field
+	case "mysql":
+		field := fmt.Sprintf("JSON_EXTRACT(%s, '$.%s')", column, key)
+		if typ == JSONNumber {
+			return fmt.Sprintf("CAST(%s AS DECIMAL(65,10))", field)
+		}
+		return fmt.Sprintf("JSON_UNQUOTE(%s)", field)
+	}
+
+	// sqlite keeps json booleans as integers, so their json type is used which is true or false.
+	switch typ {
+	case JSONNumber:
+		return fmt.Sprintf("CAST(json_extract(%s, '$.%s') AS REAL)", column, key)
+	case JSONBool:
+		return fmt.Sprintf("json_type(%s, '$.%s')", column, key)
+	}
+	return fmt.Sprintf("json_extract(%s, '$.%s')", column, key)
+}
+
+// JSONRemove returns expression of json object column without given key for the driver,
+// key must be a plain identifier same as JSONField.
+func JSONRemove(driverName, column, key string) string {
+	switch driverName {
+	case "postgres", "pgx":
+		return fmt.Sprintf("%s - '%s'", column, key)
+	case "mysql":
+		return fmt.Sprintf("JSON_REMOVE(%s, '$.%s')", column, key)
+	}
+	return fmt.Sprintf("json_remove(%s, '$.%s')", column, key)
+}
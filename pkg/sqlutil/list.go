@@ -6,109 +6,195 @@ import (
 	"strings"
 
 	"github.com/amirzayi/clean_architect/pkg/paginate"
+	"github.com/amirzayi/clean_architect/pkg/tenant"
 	"github.com/jmoiron/sqlx"
 )
 
+// Predicate is a raw sql condition which always applies on the query besides pagination filters.
+type Predicate struct {
+	Query string
+	Args  []any
+}
+
+// TenantColumn holds tenant of rows in tenant-scoped tables.
+const TenantColumn = "tenant_id"
+
+// TenantCondition returns condition of scope to be appended to where clause of raw queries,
+// condition and args are empty for tenant.AllTenants.
+func TenantCondition(scope tenant.Scope) (string, []any) {
+	if scope.All {
+		return "", nil
+	}
+	return " AND " + TenantColumn + "=?", []any{scope.ID}
+}
+
+// PaginatedList selects a page of rows of table by pagination, rows out of tenant scope are never listed,
+// tables without tenant column must be listed by tenant.AllTenants. sorts and projections out of fields
+// are rejected by paginate.FieldError, see keysetList, selectCounted and listFacets for modes of pagination.
 func PaginatedList[T any](ctx context.Context,
-	db *sqlx.DB, table string,
-	pagination *paginate.Pagination, queryableFields map[string]string) ([]T, error) {
+	db *sqlx.DB, table string, scope tenant.Scope,
+	pagination *paginate.Pagination, fields paginate.Fields, predicates ...Predicate) ([]T, error) {
 	var data []T
 
-	query, args := BuildPaginationQuery(table, pagination, queryableFields)
-	if err := db.SelectContext(ctx, &data, query, args...); err != nil {
+	mode, err := pagination.CountMode()
+	if err != nil {
+		return nil, err
+	}
+	if !scope.All {
+		predicates = append([]Predicate{{Query: TenantColumn + "=?", Args: []any{scope.ID}}}, predicates...)
+	}
+	predicates, err = expressionPredicates(pagination, fields, predicates)
+	if err != nil {
+		return nil, err
+	}
+	if err := listFacets(ctx, db, table, pagination, fields, predicates...); err != nil {
 		return nil, err
 	}
 
-	var count int64
-	whereQuery, whereArgs := whereQuery(pagination.Filters, queryableFields)
-	countQuery := fmt.Sprintf("SELECT count(1) FROM %s %s", table, whereQuery)
-	if err := db.GetContext(ctx, &count, countQuery, whereArgs...); err != nil {
+	if pagination.Cursor != nil {
+		return keysetList[T](ctx, db, table, pagination, fields, predicates...)
+	}
+
+	query, args, err := pageQuery(table, pagination, fields, predicates...)
+	if err != nil {
+		return nil, err
+	}
+	whereQuery, whereArgs, err := whereQuery(pagination.Filters, fields, predicates)
+	if err != nil {
 		return nil, err
 	}
 
+	if mode == paginate.CountEstimated {
+		estimate, ok, err := estimateCount(ctx, db, table, whereQuery, whereArgs)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if err := db.SelectContext(ctx, &data, db.Rebind(query), args...); err != nil {
+				return nil, err
+			}
+			data = paginate.PageRows(pagination, data)
+			pagination.SetEstimatedTotalItems(estimate, len(data))
+			return data, nil
+		}
+		// counted exactly by databases without statistics
+		mode = paginate.CountExact
+	}
+
+	if mode == paginate.CountNone {
+		if err := db.SelectContext(ctx, &data, db.Rebind(query), args...); err != nil {
+			return nil, err
+		}
+		return paginate.PageRows(pagination, data), nil
+	}
+
+	countQuery := fmt.Sprintf("SELECT count(1) FROM %s %s", table, whereQuery)
+	count, err := selectCounted(ctx, db, &data, query, args, countQuery, whereArgs)
+	if err != nil {
+		return nil, err
+	}
+	data = paginate.PageRows(pagination, data)
 	pagination.SetTotalItems(count)
 	return data, nil
 }
 
+// BuildPaginationQuery returns query of a page of table with one more row, see paginate.PageRows,
+// fields and sort of pagination must be allowed by fields.
 func BuildPaginationQuery(table string,
-	pagination *paginate.Pagination, queryableFields map[string]string) (string, []any) {
+	pagination *paginate.Pagination, fields paginate.Fields, predicates ...Predicate) (string, []any, error) {
+	predicates, err := expressionPredicates(pagination, fields, predicates)
+	if err != nil {
+		return "", nil, err
+	}
+	return pageQuery(table, pagination, fields, predicates...)
+}
+
+func pageQuery(table string,
+	pagination *paginate.Pagination, fields paginate.Fields, predicates ...Predicate) (string, []any, error) {
+	sorts, err := fields.Sorts(pagination.Sort)
+	if err != nil {
+		return "", nil, err
+	}
+	columns, err := fields.Projection(pagination.Fields)
+	if err != nil {
+		return "", nil, err
+	}
+	// one more row tells whether rows follow the page
+	return buildQuery(table, columns, pagination.Filters, fields, sorts, sortArgs(fields, pagination.Sort),
+		pagination.PerPage+1, (pagination.Page-1)*pagination.PerPage, predicates...)
+}
+
+// buildQuery returns query of given columns and sorts, which are already mapped to columns of table,
+// sortArgs are args of their expressions.
+func buildQuery(table string, columns []string, filters []paginate.Filter, fields paginate.Fields,
+	sorts []paginate.Sort, sortArgs []any, limit, offset int, predicates ...Predicate) (string, []any, error) {
 	var query strings.Builder
 
 	var args []any
 
-	query.WriteString(selectQuery(table, pagination.Fields))
+	query.WriteString(selectQuery(table, columns))
 	query.WriteString("\n")
 
-	whereQuery, whereArgs := whereQuery(pagination.Filters, queryableFields)
+	whereQuery, whereArgs, err := whereQuery(filters, fields, predicates)
+	if err != nil {
+		return "", nil, err
+	}
 	args = append(args, whereArgs...)
 	query.WriteString(whereQuery)
 	query.WriteString("\n")
 
-	query.WriteString(orderByQuery(pagination.Sort))
+	query.WriteString(orderByQuery(sorts))
 	query.WriteString("\n")
+	args = append(args, sortArgs...)
 
-	limit, limitArgs := limitQuery(pagination.Page, pagination.PerPage)
+	limitQuery, limitArgs := limitQuery(limit, offset)
 	args = append(args, limitArgs...)
-	query.WriteString(limit)
+	query.WriteString(limitQuery)
 
-	return query.String(), args
+	return query.String(), args, nil
 }
 
-func selectQuery(table string, fields []string) string {
+func selectQuery(table string, columns []string) string {
 	selectFields := "*"
-	if len(fields) > 0 {
-		selectFields = strings.Join(fields, ",")
+	if len(columns) > 0 {
+		selectFields = strings.Join(columns, ",")
 	}
 	return fmt.Sprintf("SELECT %s FROM %s", selectFields, table)
 }
 
-func whereQuery(filters []paginate.Filter, queryableFields map[string]string) (string, []any) {
-	if len(filters) == 0 {
-		return "", nil
+// whereQuery returns where clause of predicates and filters of filterable fields, values of filters are bound
+// by types of their fields and filters not fitting them are returned as paginate.FilterError.
+func whereQuery(filters []paginate.Filter, fields paginate.Fields, predicates []Predicate) (string, []any, error) {
+	if len(filters) == 0 && len(predicates) == 0 {
+		return "", nil, nil
 	}
 
 	var (
 		query                strings.Builder
-		where                string
 		hasAlreadyWhereQuery bool
 		args                 []any
 	)
 
 	query.WriteString("WHERE ")
 
+	for _, predicate := range predicates {
+		hasAlreadyWhereQuery = true
+		query.WriteString(predicate.Query)
+		query.WriteString(" AND ")
+		args = append(args, predicate.Args...)
+	}
+
 	for _, filter := range filters {
-		field, ok := queryableFields[filter.Key]
+		field, ok := fields.Filter[filter.Key]
 		if !ok {
 			continue
 		}
-
-		switch filter.Condition {
-		case paginate.FilterBetween:
-			values := strings.Split(filter.Value, ",")
-			if len(values) < 2 {
-				continue
-			}
-			where = fmt.Sprintf("%s BETWEEN ? AND ?", field)
-			args = append(args, values[0], values[1])
-
-		case paginate.FilterIn:
-			values := strings.Split(filter.Value, ",")
-			if len(values) < 2 {
-				continue
-			}
-
-			for _, v := range values {
-				args = append(args, v)
-			}
-			where = fmt.Sprintf("%s IN(?%s)", field, strings.Repeat(",?", len(values)-1))
-
-		default:
-			if strings.Contains(filter.Value, ",") {
-				continue
-			}
-			args = append(args, filter.Value)
-			where = fmt.Sprintf("%s %s ?", field, conditionToSql(filter.Condition))
+		values, err := fields.Values(filter)
+		if err != nil {
+			return "", nil, err
 		}
+		where, whereArgs := filterQuery(field, filter.Condition, values)
+		args = append(args, whereArgs...)
 
 		hasAlreadyWhereQuery = true
 		query.WriteString(where)
@@ -116,12 +202,45 @@ func whereQuery(filters []paginate.Filter, queryableFields map[string]string) (s
 	}
 
 	if !hasAlreadyWhereQuery {
-		return "", nil
+		return "", nil, nil
 	}
 
 	// remove last " AND " at end of query
-	whereQuery := strings.TrimRight(query.String(), " AND ")
-	return whereQuery, args
+	whereQuery := strings.TrimSuffix(query.String(), " AND ")
+	return whereQuery, args, nil
+}
+
+// likeEscape escapes wildcards of like patterns, backslash is not used since it is an escape of mysql strings.
+const likeEscape = '!'
+
+// filterQuery returns condition of given condition on field, values are parsed by paginate.Fields.Values.
+func filterQuery(field, condition string, values []any) (string, []any) {
+	switch condition {
+	case paginate.FilterBetween:
+		return fmt.Sprintf("%s BETWEEN ? AND ?", field), values
+
+	case paginate.FilterIn:
+		return fmt.Sprintf("%s IN(?%s)", field, strings.Repeat(",?", len(values)-1)), values
+
+	case paginate.FilterLike:
+		return fmt.Sprintf("%s LIKE ? ESCAPE '%c'", field, likeEscape), []any{paginate.LikeEscape(values[0].(string), likeEscape)}
+
+	case paginate.FilterILike:
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(?) ESCAPE '%c'", field, likeEscape), []any{paginate.LikeEscape(values[0].(string), likeEscape)}
+
+	default:
+		return fmt.Sprintf("%s %s ?", field, conditionToSql(condition)), values
+	}
+}
+
+// orderByQuery sorts by given sorts, their fields are mapped columns or expressions.
+// sortArgs returns args of expressions of sorts, which are not mapped to columns yet, see paginate.Fields.SortArgs.
+func sortArgs(fields paginate.Fields, sorts []paginate.Sort) []any {
+	var args []any
+	for _, sort := range sorts {
+		args = append(args, fields.SortArgs[sort.Field]...)
+	}
+	return args
 }
 
 func orderByQuery(sorts []paginate.Sort) string {
@@ -142,8 +261,8 @@ func orderByQuery(sorts []paginate.Sort) string {
 	return orderByQuery
 }
 
-func limitQuery(page, perPage int) (string, []any) {
-	return fmt.Sprintf("LIMIT ? offset ?"), []any{perPage, (page - 1) * perPage}
+func limitQuery(limit, offset int) (string, []any) {
+	return "LIMIT ? offset ?", []any{limit, offset}
 }
 
 func conditionToSql(condition string) string {
@@ -2,6 +2,7 @@ package sqlutil_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/amirzayi/clean_architect/pkg/paginate"
 	"github.com/amirzayi/clean_architect/pkg/sqlutil"
@@ -9,7 +10,7 @@ import (
 )
 
 func TestBuildPaginationQuery(t *testing.T) {
-	query, args := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{
+	query, args, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{
 		Page:    3,
 		PerPage: 15,
 		Fields:  []string{"name", "id", "phone", "role", "status"},
@@ -21,7 +22,7 @@ func TestBuildPaginationQuery(t *testing.T) {
 			{Key: "name", Value: "amir,admin,test", Condition: paginate.FilterIn},
 			{Key: "status", Value: "1,2", Condition: paginate.FilterIn},
 		},
-	}, map[string]string{
+	}, paginate.Fields{Filter: map[string]string{
 		"id":         "id",
 		"name":       "name",
 		"phone":      "phone",
@@ -29,13 +30,226 @@ func TestBuildPaginationQuery(t *testing.T) {
 		"status":     "status",
 		"role":       "role",
 		"created_at": "created_at",
-	})
+	}})
 
+	require.NoError(t, err)
 	require.NotEmpty(t, query)
 	require.NotEmpty(t, args)
 	require.Contains(t, query, "SELECT name,id,phone,role,status FROM user")
 	require.Contains(t, query, "WHERE name IN(?,?,?) AND status IN(?,?)")
 	require.Contains(t, query, "ORDER BY id desc, name asc")
 	require.Contains(t, query, "LIMIT ? offset ?")
-	require.Equal(t, args, []any{"amir", "admin", "test", "1", "2", 15, 30})
+	require.Equal(t, args, []any{"amir", "admin", "test", "1", "2", 16, 30})
+}
+
+func TestBuildPaginationQueryWithJSONField(t *testing.T) {
+	age := sqlutil.JSONField("sqlite", "attributes", "age", sqlutil.JSONNumber)
+	query, args, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{
+		Page:    1,
+		PerPage: 10,
+		Sort:    []paginate.Sort{{Field: "attributes.age", Arrange: paginate.SortOrderDescending}},
+		Filters: []paginate.Filter{{Key: "attributes.age", Value: "18", Condition: paginate.FilterGreaterEqual}},
+	}, paginate.Fields{Filter: map[string]string{"attributes.age": age}})
+
+	require.NoError(t, err)
+	require.Contains(t, query, "WHERE CAST(json_extract(attributes, '$.age') AS REAL) >= ?")
+	require.Contains(t, query, "ORDER BY CAST(json_extract(attributes, '$.age') AS REAL) desc")
+	require.Equal(t, []any{"18", 11, 0}, args)
+}
+
+func TestBuildPaginationQueryRejectsUnknownFields(t *testing.T) {
+	fields := paginate.Fields{
+		Filter: map[string]string{"name": "name", "phone": "phone_number"},
+		Sort:   map[string]string{"name": "name"},
+	}
+
+	for _, tc := range []struct {
+		name       string
+		pagination paginate.Pagination
+		expected   paginate.FieldError
+	}{
+		{"sort", paginate.Pagination{Sort: []paginate.Sort{{Field: "name;drop table user", Arrange: paginate.SortOrderAscending}}},
+			paginate.FieldError{Param: "sort", Field: "name;drop table user", Allowed: []string{"name"}}},
+		{"arrange", paginate.Pagination{Sort: []paginate.Sort{{Field: "name", Arrange: "asc, (select 1)"}}},
+			paginate.FieldError{Param: "sort", Field: "asc, (select 1)", Allowed: []string{"asc", "desc"}}},
+		{"projection", paginate.Pagination{Fields: []string{"password"}},
+			paginate.FieldError{Param: "fields", Field: "password", Allowed: []string{"name", "phone"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.pagination.Page, tc.pagination.PerPage = 1, 10
+			_, _, err := sqlutil.BuildPaginationQuery("user", &tc.pagination, fields)
+			var fieldErr *paginate.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, tc.expected, *fieldErr)
+		})
+	}
+
+	query, _, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{
+		Page:    1,
+		PerPage: 10,
+		Fields:  []string{"phone", "name"},
+	}, fields)
+	require.NoError(t, err)
+	require.Contains(t, query, "SELECT phone_number,name FROM user")
+}
+
+func TestJSONField(t *testing.T) {
+	tests := []struct {
+		driver string
+		typ    sqlutil.JSONType
+		want   string
+	}{
+		{"sqlite", sqlutil.JSONText, "json_extract(attributes, '$.k')"},
+		{"sqlite", sqlutil.JSONBool, "json_type(attributes, '$.k')"},
+		{"postgres", sqlutil.JSONText, "(attributes->>'k')"},
+		{"postgres", sqlutil.JSONNumber, "(attributes->>'k')::numeric"},
+		{"mysql", sqlutil.JSONText, "JSON_UNQUOTE(JSON_EXTRACT(attributes, '$.k'))"},
+		{"mysql", sqlutil.JSONNumber, "CAST(JSON_EXTRACT(attributes, '$.k') AS DECIMAL(65,10))"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, sqlutil.JSONField(tt.driver, "attributes", "k", tt.typ))
+	}
+}
+
+func TestBuildPaginationQueryWithExpression(t *testing.T) {
+	fields := paginate.Fields{
+		Filter: map[string]string{"name": "name", "status": "status", "role": "role", "created_at": "created_at"},
+		Types:  map[string]paginate.Field{"status": {Type: paginate.TypeInt}, "created_at": {Type: paginate.TypeTime}},
+	}
+	query, args, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{
+		Page:       1,
+		PerPage:    10,
+		Filters:    []paginate.Filter{{Key: "name", Value: "amir", Condition: paginate.FilterNotEqual}},
+		Expression: "(status==1,status=in=(2,3));!role==admin;created_at=gt=2024-01-01",
+	}, fields, sqlutil.Predicate{Query: "tenant_id=?", Args: []any{"t1"}})
+
+	require.NoError(t, err)
+	require.Contains(t, query, "WHERE tenant_id=? AND (((status = ?) OR (status IN(?,?))) AND NOT (role = ?) AND (created_at > ?)) AND name <> ?")
+	require.Equal(t, []any{"t1", int64(1), int64(2), int64(3), "admin", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "amir", 11, 0}, args)
+
+	for _, tc := range []struct {
+		expression string
+		expected   error
+	}{
+		{"status==1,password==x", &paginate.FieldError{Param: "filter", Field: "password", Allowed: []string{"created_at", "name", "role", "status"}}},
+		{"status==1;status=like=1%", &paginate.FilterError{Field: "status", Condition: paginate.FilterLike, Value: "1%",
+			Allowed: []string{"eq", "neq", "gt", "gte", "lt", "lte", "in", "between"}, Msg: "like is not allowed on int field"}},
+		{"status=1", &paginate.SyntaxError{Pos: 6, Msg: `unknown operator "=1"`}},
+	} {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, _, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{Page: 1, PerPage: 10, Expression: tc.expression}, fields)
+			require.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestBuildPaginationQueryWithTypedFilters(t *testing.T) {
+	fields := paginate.Fields{
+		Filter: map[string]string{"name": "name", "status": "status", "role": "role"},
+		Types: map[string]paginate.Field{
+			"status": {Type: paginate.TypeInt},
+			"role":   {Type: paginate.TypeEnum, Values: []string{"User", "Admin"}},
+		},
+	}
+	query, args, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{
+		Page:    1,
+		PerPage: 10,
+		Filters: []paginate.Filter{
+			{Key: "name", Value: `50\%!_%`, Condition: paginate.FilterLike},
+			{Key: "name", Value: "am%", Condition: paginate.FilterILike},
+			{Key: "status", Value: "1,2", Condition: paginate.FilterBetween},
+			{Key: "role", Value: "Admin", Condition: paginate.FilterIn},
+		},
+	}, fields)
+
+	require.NoError(t, err)
+	require.Contains(t, query, "WHERE name LIKE ? ESCAPE '!' AND LOWER(name) LIKE LOWER(?) ESCAPE '!' AND status BETWEEN ? AND ? AND role IN(?)")
+	require.Equal(t, []any{"50!%!!_%", "am%", int64(1), int64(2), "Admin", 11, 0}, args)
+
+	for _, tc := range []struct {
+		name   string
+		filter paginate.Filter
+	}{
+		{"value", paginate.Filter{Key: "status", Value: "abc", Condition: paginate.FilterEqual}},
+		{"enum", paginate.Filter{Key: "role", Value: "Root", Condition: paginate.FilterEqual}},
+		{"condition", paginate.Filter{Key: "role", Value: "Ad%", Condition: paginate.FilterLike}},
+		{"between", paginate.Filter{Key: "status", Value: "1", Condition: paginate.FilterBetween}},
+		{"escape", paginate.Filter{Key: "name", Value: `am\`, Condition: paginate.FilterLike}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{Page: 1, PerPage: 10, Filters: []paginate.Filter{tc.filter}}, fields)
+			var filterErr *paginate.FilterError
+			require.ErrorAs(t, err, &filterErr)
+			require.Equal(t, tc.filter.Key, filterErr.Field)
+		})
+	}
+}
+
+func TestBuildAggregationQueries(t *testing.T) {
+	fields := paginate.Fields{
+		Filter: map[string]string{"status": "status", "role": "role", "created_at": "created_at"},
+		Types:  map[string]paginate.Field{"status": {Type: paginate.TypeInt}, "created_at": {Type: paginate.TypeTime}},
+	}
+	pagination := &paginate.Pagination{
+		Filters: []paginate.Filter{{Key: "status", Value: "1", Condition: paginate.FilterGreater}},
+		Aggregations: []paginate.Aggregation{
+			paginate.ParseAggregation("role,max(created_at),sum(status)"),
+			paginate.ParseAggregation("created_at:day"),
+		},
+	}
+
+	queries, args, err := sqlutil.BuildAggregationQueries("sqlite", "user", pagination, fields, sqlutil.Predicate{Query: "tenant_id=?", Args: []any{"t1"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"SELECT role AS facet_key, count(1) AS facet_count, MAX(created_at) AS facet_m0, SUM(status) AS facet_m1 FROM user " +
+			"WHERE tenant_id=? AND status > ? GROUP BY role ORDER BY facet_key LIMIT 100",
+		"SELECT strftime('%Y-%m-%dT00:00:00Z', created_at) AS facet_key, count(1) AS facet_count FROM user " +
+			"WHERE tenant_id=? AND status > ? GROUP BY strftime('%Y-%m-%dT00:00:00Z', created_at) ORDER BY facet_key LIMIT 100",
+	}, queries)
+	require.Equal(t, []any{"t1", int64(1)}, args)
+
+	queries, _, err = sqlutil.BuildAggregationQueries("postgres", "user", pagination, fields)
+	require.NoError(t, err)
+	require.Contains(t, queries[1], `to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T00:00:00Z"')`)
+
+	queries, _, err = sqlutil.BuildAggregationQueries("mysql", "user", pagination, fields)
+	require.NoError(t, err)
+	require.Contains(t, queries[1], "DATE_FORMAT(created_at, '%Y-%m-%dT00:00:00Z')")
+
+	_, _, err = sqlutil.BuildAggregationQueries("sqlite", "user", &paginate.Pagination{
+		Aggregations: []paginate.Aggregation{paginate.ParseAggregation("role:day")},
+	}, fields)
+	require.Equal(t, &paginate.FieldError{Param: "facet", Field: "role:day", Allowed: []string{"created_at"}}, err)
+}
+
+func TestSearchIndex(t *testing.T) {
+	index := sqlutil.SearchIndex{Table: "user_search", Columns: []string{"name", "email"}}
+	terms := []string{"amir", "zayi"}
+
+	predicate, relevance := index.Search("sqlite", `"user"`, terms)
+	require.Equal(t, sqlutil.Predicate{Query: "id IN (SELECT id FROM user_search WHERE user_search MATCH ?)", Args: []any{`"amir" "zayi"`}}, predicate)
+	require.Equal(t, sqlutil.Predicate{Query: `(SELECT -bm25(user_search) FROM user_search WHERE user_search MATCH ? AND user_search.id = "user".id)`,
+		Args: []any{`"amir" "zayi"`}}, relevance)
+
+	vector := "to_tsvector('simple', translate(coalesce(name, '') || ' ' || coalesce(email, ''), '@.', '  '))"
+	require.Equal(t, vector, sqlutil.SearchVector(index.Columns))
+	predicate, relevance = index.Search("postgres", `"user"`, terms)
+	require.Equal(t, sqlutil.Predicate{Query: vector + " @@ plainto_tsquery('simple', ?)", Args: []any{"amir zayi"}}, predicate)
+	require.Equal(t, sqlutil.Predicate{Query: "ts_rank(" + vector + ", plainto_tsquery('simple', ?))", Args: []any{"amir zayi"}}, relevance)
+
+	predicate, relevance = index.Search("mysql", "`user`", terms)
+	require.Equal(t, sqlutil.Predicate{Query: "MATCH(name, email) AGAINST(? IN BOOLEAN MODE)", Args: []any{"+amir +zayi"}}, predicate)
+	require.Equal(t, predicate, relevance)
+
+	// terms of relevance are bound, never written into queries
+	fields := paginate.Fields{
+		Filter:   map[string]string{"name": "name"},
+		Sort:     map[string]string{"name": "name", paginate.SortRelevance: relevance.Query},
+		SortArgs: map[string][]any{paginate.SortRelevance: relevance.Args},
+	}
+	query, args, err := sqlutil.BuildPaginationQuery("user", &paginate.Pagination{Page: 1, PerPage: 10,
+		Sort: []paginate.Sort{{Field: paginate.SortRelevance, Arrange: paginate.SortOrderDescending}}}, fields, predicate)
+	require.NoError(t, err)
+	require.Contains(t, query, "ORDER BY MATCH(name, email) AGAINST(? IN BOOLEAN MODE) desc")
+	require.Equal(t, []any{"+amir +zayi", "+amir +zayi", 11, 0}, args)
 }
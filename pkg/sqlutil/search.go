@@ -0,0 +1,49 @@
+package sqlutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SearchIndex is a full-text index of text columns of a table, rows are searched by terms of search.Terms which all
+// must be in their columns. each driver has its own index, made by migrations of the table:
+//   - sqlite searches Table, an fts5 table of IDColumn, unindexed, and the columns kept up to date by triggers.
+//   - postgres searches a gin index of SearchVector of the columns, it is kept up to date by postgres.
+//   - mysql searches a fulltext index of the columns, whose words are at least 3 letters and not stopwords.
+type SearchIndex struct {
+	Table   string
+	Columns []string
+}
+
+// Search returns predicate of rows of table having all terms and expression of their relevance to be sorted by,
+// more relevant rows have higher relevance. terms are bound as args of both, see paginate.Fields.SortArgs.
+func (s SearchIndex) Search(driverName, table string, terms []string) (predicate, relevance Predicate) {
+	switch driverName {
+	case "postgres", "pgx":
+		query := strings.Join(terms, " ")
+		return Predicate{Query: SearchVector(s.Columns) + " @@ plainto_tsquery('simple', ?)", Args: []any{query}},
+			Predicate{Query: fmt.Sprintf("ts_rank(%s, plainto_tsquery('simple', ?))", SearchVector(s.Columns)), Args: []any{query}}
+
+	case "mysql":
+		query := "+" + strings.Join(terms, " +")
+		match := fmt.Sprintf("MATCH(%s) AGAINST(? IN BOOLEAN MODE)", strings.Join(s.Columns, ", "))
+		return Predicate{Query: match, Args: []any{query}}, Predicate{Query: match, Args: []any{query}}
+	}
+
+	// terms are quoted as strings of fts5, so they are never its operators, e.g. OR and NOT
+	query := `"` + strings.Join(terms, `" "`) + `"`
+	return Predicate{Query: fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s MATCH ?)", IDColumn, IDColumn, s.Table, s.Table), Args: []any{query}},
+		// bm25 of fts5 is lower for more relevant rows
+		Predicate{Query: fmt.Sprintf("(SELECT -bm25(%s) FROM %s WHERE %s MATCH ? AND %s.%s = %s.%s)",
+			s.Table, s.Table, s.Table, s.Table, IDColumn, table, IDColumn), Args: []any{query}}
+}
+
+// SearchVector returns tsvector of columns for postgres, expression indexes of searched tables must be of the same
+// expression. columns are searched by words of search.Terms, so dots and at signs, e.g. of emails, separate words.
+func SearchVector(columns []string) string {
+	texts := make([]string, 0, len(columns))
+	for _, column := range columns {
+		texts = append(texts, fmt.Sprintf("coalesce(%s, '')", column))
+	}
+	return fmt.Sprintf("to_tsvector('simple', translate(%s, '@.', '  '))", strings.Join(texts, " || ' ' || "))
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	data []byte
+	info Object
+}
+
+type inMemoryDriver struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+// NewInMemoryDriver returns a driver keeping blobs in memory, it is meant for development and tests.
+func NewInMemoryDriver() Driver {
+	return &inMemoryDriver{objects: make(map[string]memoryObject)}
+}
+
+func (d *inMemoryDriver) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.objects[key] = memoryObject{
+		data: data,
+		info: Object{
+			Key:         key,
+			ContentType: contentType,
+			Size:        int64(len(data)),
+			ModifiedAt:  time.Now().UTC(),
+		},
+	}
+	return nil
+}
+
+func (d *inMemoryDriver) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	object, ok := d.objects[key]
+	if !ok {
+		return nil, Object{}, ErrNotFound
+	}
+	// data is never modified after put, so it is shared by readers
+	return io.NopCloser(bytes.NewReader(object.data)), object.info, nil
+}
+
+func (d *inMemoryDriver) Stat(ctx context.Context, key string) (Object, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	object, ok := d.objects[key]
+	if !ok {
+		return Object{}, ErrNotFound
+	}
+	return object.info, nil
+}
+
+func (d *inMemoryDriver) Delete(ctx context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.objects, key)
+	return nil
+}
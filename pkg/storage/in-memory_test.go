@@ -0,0 +1,11 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/amirzayi/clean_architect/pkg/storage"
+)
+
+func TestInMemoryDriver(t *testing.T) {
+	testDriver(t, storage.NewInMemoryDriver())
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+type localDriver struct {
+	root string
+}
+
+// NewLocalDriver returns a driver keeping blobs as files under root directory, which is created if missing.
+// Content types are not kept, they are detected from first bytes of files by http.DetectContentType.
+func NewLocalDriver(root string) (Driver, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return localDriver{root: root}, nil
+}
+
+func (d localDriver) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(d.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file next to the target and renames it, so readers never see partial blobs.
+func (d localDriver) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d localDriver) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, Object{}, localError(err)
+	}
+	object, err := stat(key, file)
+	if err != nil {
+		file.Close()
+		return nil, Object{}, err
+	}
+	return file, object, nil
+}
+
+func (d localDriver) Stat(ctx context.Context, key string) (Object, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return Object{}, localError(err)
+	}
+	defer file.Close()
+	return stat(key, file)
+}
+
+func (d localDriver) Delete(ctx context.Context, key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// stat describes file of key and detects its content type, file is rewound for reading.
+func stat(key string, file *os.File) (Object, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return Object{}, err
+	}
+	if info.IsDir() {
+		return Object{}, ErrNotFound
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return Object{}, err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return Object{}, err
+	}
+	return Object{
+		Key:         key,
+		ContentType: http.DetectContentType(head[:n]),
+		Size:        info.Size(),
+		ModifiedAt:  info.ModTime().UTC(),
+	}, nil
+}
+
+func localError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
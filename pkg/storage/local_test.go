@@ -0,0 +1,26 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/pkg/storage"
+)
+
+func TestLocalDriver(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "blobs")
+	drv, err := storage.NewLocalDriver(root)
+	require.NoError(t, err)
+	testDriver(t, drv)
+
+	require.NoError(t, drv.Put(context.Background(), "a/b", bytes.NewReader(png), "image/png"))
+	entries, err := os.ReadDir(filepath.Join(root, "a"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files are removed")
+	require.Equal(t, "b", entries[0].Name())
+}
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	amzDateLayout  = "20060102T150405Z"
+	amzDayLayout   = "20060102"
+	s3Service      = "s3"
+	signingVersion = "AWS4-HMAC-SHA256"
+	// maxErrorBodySize is the maximum size of error responses read into errors.
+	maxErrorBodySize = 1 << 10
+)
+
+// S3Config configures an S3-compatible driver, e.g. AWS S3, MinIO or Ceph.
+type S3Config struct {
+	// Endpoint is the base url of service, e.g. https://s3.eu-west-1.amazonaws.com or http://127.0.0.1:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// Client sends requests, http.DefaultClient is used if nil.
+	Client *http.Client
+}
+
+type s3Driver struct {
+	cfg      S3Config
+	endpoint *url.URL
+	now      func() time.Time
+}
+
+// NewS3Driver returns a driver keeping blobs in a bucket of an S3-compatible service,
+// objects are addressed path-style, e.g. <endpoint>/<bucket>/<key>, and requests are signed by signature version 4.
+func NewS3Driver(cfg S3Config) (Driver, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" || cfg.Bucket == "" {
+		return nil, errors.New("s3: endpoint and bucket are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	return s3Driver{cfg: cfg, endpoint: endpoint, now: time.Now}, nil
+}
+
+// Put reads whole content into memory to sign its hash, so it is meant for small blobs, e.g. images.
+func (d s3Driver) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := d.do(ctx, http.MethodPut, key, header, data)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (d s3Driver) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, Object{}, err
+	}
+	resp, err := d.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	return resp.Body, s3Object(key, resp), nil
+}
+
+func (d s3Driver) Stat(ctx context.Context, key string) (Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return Object{}, err
+	}
+	resp, err := d.do(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return Object{}, err
+	}
+	resp.Body.Close()
+	return s3Object(key, resp), nil
+}
+
+func (d s3Driver) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	resp, err := d.do(ctx, http.MethodDelete, key, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// do sends a signed request for object of key and returns ErrNotFound for 404 and an error for other non-2xx responses.
+func (d s3Driver) do(ctx context.Context, method, key string, header http.Header, body []byte) (*http.Response, error) {
+	u := *d.endpoint
+	u.Path = d.endpoint.Path + "/" + d.cfg.Bucket + "/" + key
+	u.RawPath = uriEncode(u.Path, false)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	payloadHash := sha256.Sum256(body)
+	signV4(req, hex.EncodeToString(payloadHash[:]), d.cfg.AccessKey, d.cfg.SecretKey, d.cfg.Region, d.now())
+
+	resp, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return nil, fmt.Errorf("s3: %s %s: %s: %s", method, key, resp.Status, bytes.TrimSpace(message))
+}
+
+func s3Object(key string, resp *http.Response) Object {
+	object := Object{
+		Key:         key,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}
+	if size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		object.Size = size
+	}
+	if modifiedAt, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.ModifiedAt = modifiedAt.UTC()
+	}
+	return object
+}
+
+// signV4 adds x-amz-date, x-amz-content-sha256 and authorization headers of signature version 4 to req,
+// all headers of req are signed besides host.
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateLayout))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonical, signedHeaders := canonicalRequest(req, payloadHash)
+	scope := strings.Join([]string{now.Format(amzDayLayout), region, s3Service, "aws4_request"}, "/")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{signingVersion, now.Format(amzDateLayout), scope,
+		hex.EncodeToString(canonicalHash[:])}, "\n")
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{now.Format(amzDayLayout), region, s3Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingVersion, accessKey, scope, signedHeaders, signature))
+}
+
+func canonicalRequest(req *http.Request, payloadHash string) (canonical, signedHeaders string) {
+	headers := map[string]string{"host": req.Host}
+	if headers["host"] == "" {
+		headers["host"] = req.URL.Host
+	}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "authorization" {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	query := req.URL.Query()
+	queryKeys := make([]string, 0, len(query))
+	for k := range query {
+		queryKeys = append(queryKeys, k)
+	}
+	slices.Sort(queryKeys)
+	var pairs []string
+	for _, k := range queryKeys {
+		values := slices.Sorted(slices.Values(query[k]))
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	signedHeaders = strings.Join(names, ";")
+	return strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		strings.Join(pairs, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+// uriEncode escapes all bytes except unreserved characters of RFC 3986, and slashes if encodeSlash is false.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
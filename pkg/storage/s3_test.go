@@ -0,0 +1,119 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/pkg/storage"
+)
+
+// fakeS3 is a local stand-in of an S3-compatible service keeping objects of one bucket in memory,
+// it rejects requests not signed by its access key or with wrong payload hashes.
+type fakeS3 struct {
+	bucket, accessKey string
+
+	mu      sync.Mutex
+	objects map[string]fakeS3Object
+}
+
+type fakeS3Object struct {
+	data        []byte
+	contentType string
+	modifiedAt  time.Time
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/") {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+s.bucket+"/")
+	if !ok {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	hash := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(hash[:]) {
+		http.Error(w, "<Error><Code>XAmzContentSHA256Mismatch</Code></Error>", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = fakeS3Object{data: body, contentType: r.Header.Get("Content-Type"), modifiedAt: time.Now()}
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet, http.MethodHead:
+		object, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", object.modifiedAt.UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Driver(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{bucket: "blobs", accessKey: "access", objects: map[string]fakeS3Object{}})
+	t.Cleanup(server.Close)
+
+	drv, err := storage.NewS3Driver(storage.S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "blobs",
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+	require.NoError(t, err)
+	testDriver(t, drv)
+
+	denied, err := storage.NewS3Driver(storage.S3Config{Endpoint: server.URL, Bucket: "blobs", AccessKey: "other"})
+	require.NoError(t, err)
+	_, err = denied.Stat(context.Background(), "a")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = storage.NewS3Driver(storage.S3Config{Endpoint: server.URL})
+	require.Error(t, err, "bucket is required")
+}
+
+// TestS3DriverService runs only if S3_ENDPOINT is set, e.g. http://127.0.0.1:9000 of a MinIO server,
+// S3_BUCKET must exist and be accessible by S3_ACCESS_KEY and S3_SECRET_KEY.
+func TestS3DriverService(t *testing.T) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_ENDPOINT is not set")
+	}
+
+	drv, err := storage.NewS3Driver(storage.S3Config{
+		Endpoint:  endpoint,
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+	})
+	require.NoError(t, err)
+	testDriver(t, drv)
+}
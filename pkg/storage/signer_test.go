@@ -0,0 +1,47 @@
+package storage_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/pkg/storage"
+)
+
+func TestSigner(t *testing.T) {
+	signer := storage.NewSigner([]byte("secret"))
+	now := time.Now()
+
+	query := func(signed string) url.Values {
+		u, err := url.Parse(signed)
+		require.NoError(t, err)
+		return u.Query()
+	}
+
+	signed := signer.SignURL("/v2/files", "avatars/a b/original", now.Add(time.Minute))
+	require.True(t, strings.HasPrefix(signed, "/v2/files?"))
+	key, err := signer.Verify(query(signed), now)
+	require.NoError(t, err)
+	require.Equal(t, "avatars/a b/original", key)
+
+	_, err = signer.Verify(query(signed), now.Add(2*time.Minute))
+	require.ErrorIs(t, err, storage.ErrExpiredURL)
+
+	tampered := query(signed)
+	tampered.Set("key", "avatars/b/original")
+	_, err = signer.Verify(tampered, now)
+	require.ErrorIs(t, err, storage.ErrInvalidSignature)
+
+	extended := query(signed)
+	extended.Set("expires", "9999999999")
+	_, err = signer.Verify(extended, now)
+	require.ErrorIs(t, err, storage.ErrInvalidSignature)
+
+	_, err = storage.NewSigner([]byte("other")).Verify(query(signed), now)
+	require.ErrorIs(t, err, storage.ErrInvalidSignature)
+
+	require.Contains(t, signer.SignURL("https://cdn.example.com/files?v=2", "k", now), "?v=2&")
+}
@@ -0,0 +1,51 @@
+// Package storage keeps blobs, e.g. uploaded images, by their keys through pluggable drivers.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey returned for empty, too long or non-relative keys, e.g. keys having ".." segments.
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// maxKeyLength is the maximum length of keys in bytes, same as S3.
+const maxKeyLength = 1024
+
+// Object describes a stored blob.
+type Object struct {
+	Key         string
+	ContentType string
+	Size        int64
+	ModifiedAt  time.Time
+}
+
+// Driver stores blobs by keys, keys are slash separated relative paths, e.g. avatars/<id>/original.
+type Driver interface {
+	// Put creates or replaces blob of key by content of r.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get returns content of blob, caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	Stat(ctx context.Context, key string) (Object, error)
+	// Delete removes blob of key, deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidateKey returns ErrInvalidKey if key is not a clean relative slash separated path.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > maxKeyLength || strings.ContainsAny(key, "\\\x00") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
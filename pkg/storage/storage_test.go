@@ -0,0 +1,79 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amirzayi/clean_architect/pkg/storage"
+)
+
+// png is the smallest content detected as image/png, so drivers detecting content types agree with given ones.
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// testDriver runs same scenarios against given driver, which must be empty.
+func testDriver(t *testing.T, drv storage.Driver) {
+	ctx := context.Background()
+
+	_, _, err := drv.Get(ctx, "avatars/a/original")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = drv.Stat(ctx, "avatars/a/original")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, drv.Put(ctx, "avatars/a/original", bytes.NewReader(png), "image/png"))
+	object, err := drv.Stat(ctx, "avatars/a/original")
+	require.NoError(t, err)
+	require.Equal(t, "avatars/a/original", object.Key)
+	require.Equal(t, "image/png", object.ContentType)
+	require.EqualValues(t, len(png), object.Size)
+
+	r, object, err := drv.Get(ctx, "avatars/a/original")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, png, data)
+	require.EqualValues(t, len(png), object.Size)
+
+	replaced := append(bytes.Clone(png), "replaced"...)
+	require.NoError(t, drv.Put(ctx, "avatars/a/original", bytes.NewReader(replaced), "image/png"))
+	object, err = drv.Stat(ctx, "avatars/a/original")
+	require.NoError(t, err)
+	require.EqualValues(t, len(replaced), object.Size)
+
+	require.NoError(t, drv.Delete(ctx, "avatars/a/original"))
+	_, err = drv.Stat(ctx, "avatars/a/original")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+	require.NoError(t, drv.Delete(ctx, "avatars/a/original"), "deleting missing blob is not an error")
+
+	for _, key := range []string{"", "/abs", "a/../b", "a//b", "a/./b", `a\b`} {
+		require.ErrorIs(t, drv.Put(ctx, key, bytes.NewReader(png), "image/png"), storage.ErrInvalidKey, key)
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	for _, tc := range []struct {
+		key   string
+		valid bool
+	}{
+		{"avatars/a/original", true},
+		{"a b/ü.png", true},
+		{"", false},
+		{"/a", false},
+		{"a/", false},
+		{"../a", false},
+		{"a/./b", false},
+		{`a\b`, false},
+		{string(make([]byte, 1025)), false},
+	} {
+		err := storage.ValidateKey(tc.key)
+		if tc.valid {
+			require.NoError(t, err, tc.key)
+		} else {
+			require.ErrorIs(t, err, storage.ErrInvalidKey, tc.key)
+		}
+	}
+}
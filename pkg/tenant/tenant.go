@@ -0,0 +1,61 @@
+// Package tenant carries the tenant (organization) of a request through context,
+// tenant-scoped data of repositories and caches is partitioned by it.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Default is the tenant of single tenant deployments and of data created before organizations.
+var Default = uuid.Nil
+
+// Scope restricts queries to rows of a tenant, unless All is set.
+type Scope struct {
+	ID  uuid.UUID
+	All bool
+}
+
+// AllTenants scope is only meant for system-wide tasks, like background jobs and maintenance.
+var AllTenants = Scope{All: true}
+
+type (
+	idKey       struct{}
+	unscopedKey struct{}
+)
+
+// ContextWithID returns a copy of ctx which carries given tenant.
+func ContextWithID(ctx context.Context, id uuid.UUID) context.Context {
+	ctx = context.WithValue(ctx, unscopedKey{}, false)
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// ContextWithAllTenants returns a copy of ctx whose queries are not scoped by tenant.
+func ContextWithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+// FromContext returns tenant of ctx, Default if ctx does not carry it.
+func FromContext(ctx context.Context) uuid.UUID {
+	id, _ := ctx.Value(idKey{}).(uuid.UUID)
+	return id
+}
+
+// ScopeOf returns scope of queries issued with ctx.
+func ScopeOf(ctx context.Context) Scope {
+	if all, _ := ctx.Value(unscopedKey{}).(bool); all {
+		return AllTenants
+	}
+	return Scope{ID: FromContext(ctx)}
+}
+
+// Includes reports whether rows of given tenant are within scope.
+func (s Scope) Includes(id uuid.UUID) bool {
+	return s.All || s.ID == id
+}
+
+// Key returns key prefixed by tenant of ctx, so cached data never crosses tenants.
+func Key(ctx context.Context, key string) string {
+	return FromContext(ctx).String() + ":" + key
+}